@@ -0,0 +1,173 @@
+// Package cache provides a cache of typed values with namespaces, time to live and deduplication of
+// concurrent loads, backed by an in-process LRU or by Redis.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PlayEconomy37/Play.Common/configuration"
+	"github.com/go-redis/redis/v8"
+	"golang.org/x/sync/singleflight"
+)
+
+// Supported cache stores
+const (
+	MemoryStoreType = "Memory"
+	RedisStoreType  = "Redis"
+)
+
+const (
+	defaultMaxEntries = 10000           // Used if Cache.MaxEntries is empty
+	defaultTTL        = 5 * time.Minute // Used if Cache.TTL is empty
+)
+
+var (
+	// ErrCacheMiss is returned when a key isn't in the cache or has expired
+	ErrCacheMiss = errors.New("cache miss")
+
+	// ErrUnsupportedStore is returned when the configured cache store is not supported
+	ErrUnsupportedStore = errors.New("unsupported cache store")
+)
+
+// Make sure every implementation satisfies our interface
+var (
+	_ Store = (*MemoryStore)(nil)
+	_ Store = (*RedisStore)(nil)
+)
+
+// Store is an interface that defines a backend of the cache, which holds encoded values
+type Store interface {
+	// Get returns the value of the given key. It returns ErrCacheMiss if the key isn't in the store.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Set stores the value of the given key, which expires after the given time to live
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete removes the given keys from the store
+	Delete(ctx context.Context, keys ...string) error
+
+	// DeletePrefix removes every key starting with the given prefix from the store
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// NewStore creates the store selected in the Cache section of the configuration (Memory by default).
+// The Redis store uses the given client, which can be nil for the memory store.
+func NewStore(cfg *configuration.Config, client redis.UniversalClient) (Store, error) {
+	switch {
+	case cfg.Cache.Store == "" || strings.EqualFold(cfg.Cache.Store, MemoryStoreType):
+		maxEntries := cfg.Cache.MaxEntries
+		if maxEntries <= 0 {
+			maxEntries = defaultMaxEntries
+		}
+
+		return NewMemoryStore(maxEntries), nil
+	case strings.EqualFold(cfg.Cache.Store, RedisStoreType):
+		if client == nil {
+			return nil, errors.New("missing Redis client for the cache")
+		}
+
+		return NewRedisStore(client, cfg.ServiceName), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedStore, cfg.Cache.Store)
+	}
+}
+
+// Cache is a struct which caches values of type T, encoded as JSON, in a namespace of a store
+// (i.e. "users"), so that several caches can share the same store
+type Cache[T any] struct {
+	store     Store
+	namespace string
+	ttl       time.Duration
+	loads     singleflight.Group
+}
+
+// New creates a new Cache of the given namespace, whose values expire after the given time to live
+// (Cache.TTL of the configuration is a sensible default)
+func New[T any](store Store, namespace string, ttl time.Duration) *Cache[T] {
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+
+	return &Cache[T]{
+		store:     store,
+		namespace: namespace,
+		ttl:       ttl,
+	}
+}
+
+// key returns the key of the store holding the value of the given key of the cache
+func (c *Cache[T]) key(key string) string {
+	return c.namespace + ":" + key
+}
+
+// Get returns the value of the given key. It returns ErrCacheMiss if the key isn't in the cache.
+func (c *Cache[T]) Get(ctx context.Context, key string) (T, error) {
+	var value T
+
+	data, err := c.store.Get(ctx, c.key(key))
+	if err != nil {
+		return value, err
+	}
+
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, err
+	}
+
+	return value, nil
+}
+
+// Set caches the value of the given key
+func (c *Cache[T]) Set(ctx context.Context, key string, value T) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return c.store.Set(ctx, c.key(key), data, c.ttl)
+}
+
+// Delete removes the given keys from the cache, i.e. when their values have been updated
+func (c *Cache[T]) Delete(ctx context.Context, keys ...string) error {
+	storeKeys := make([]string, len(keys))
+	for i, key := range keys {
+		storeKeys[i] = c.key(key)
+	}
+
+	return c.store.Delete(ctx, storeKeys...)
+}
+
+// Clear removes every key of the namespace of the cache
+func (c *Cache[T]) Clear(ctx context.Context) error {
+	return c.store.DeletePrefix(ctx, c.namespace+":")
+}
+
+// GetOrLoad returns the cached value of the given key, or loads it with the given loader and caches it
+// if it isn't in the cache. Concurrent loads of the same key are deduplicated, so that a popular key
+// expiring doesn't send a burst of queries to the database. Errors of the loader are returned as is
+// and aren't cached, while failing to read or write the cache only degrades to calling the loader.
+func (c *Cache[T]) GetOrLoad(ctx context.Context, key string, loader func(ctx context.Context) (T, error)) (T, error) {
+	if value, err := c.Get(ctx, key); err == nil {
+		return value, nil
+	}
+
+	result, err, _ := c.loads.Do(key, func() (any, error) {
+		value, err := loader(ctx)
+		if err != nil {
+			return value, err
+		}
+
+		// The value is still returned if it can't be cached
+		_ = c.Set(ctx, key, value)
+
+		return value, nil
+	})
+
+	value, _ := result.(T)
+
+	return value, err
+}
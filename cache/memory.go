@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memoryEntry is an entry of a MemoryStore
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryStore is a Store holding values in memory, which evicts the least recently used entries once
+// the maximum number of entries is reached. Every instance of a service has its own values, so it is
+// best suited to values which can be slightly stale.
+type MemoryStore struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // Most recently used entries first
+}
+
+// NewMemoryStore creates a new MemoryStore holding at most the given number of entries
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	if maxEntries <= 0 {
+		maxEntries = defaultMaxEntries
+	}
+
+	return &MemoryStore{
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		order:      list.New(),
+	}
+}
+
+// Get returns the value of the given key. It returns ErrCacheMiss if the key isn't in the store.
+func (s *MemoryStore) Get(_ context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, exists := s.entries[key]
+	if !exists {
+		return nil, ErrCacheMiss
+	}
+
+	entry := element.Value.(*memoryEntry)
+	if time.Now().After(entry.expiresAt) {
+		s.remove(element)
+		return nil, ErrCacheMiss
+	}
+
+	s.order.MoveToFront(element)
+
+	return entry.value, nil
+}
+
+// Set stores the value of the given key, which expires after the given time to live
+func (s *MemoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expiresAt := time.Now().Add(ttl)
+
+	if element, exists := s.entries[key]; exists {
+		entry := element.Value.(*memoryEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		s.order.MoveToFront(element)
+
+		return nil
+	}
+
+	s.entries[key] = s.order.PushFront(&memoryEntry{key: key, value: value, expiresAt: expiresAt})
+
+	// Evict the least recently used entries
+	for s.order.Len() > s.maxEntries {
+		s.remove(s.order.Back())
+	}
+
+	return nil
+}
+
+// Delete removes the given keys from the store
+func (s *MemoryStore) Delete(_ context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		if element, exists := s.entries[key]; exists {
+			s.remove(element)
+		}
+	}
+
+	return nil
+}
+
+// DeletePrefix removes every key starting with the given prefix from the store
+func (s *MemoryStore) DeletePrefix(_ context.Context, prefix string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, element := range s.entries {
+		if strings.HasPrefix(key, prefix) {
+			s.remove(element)
+		}
+	}
+
+	return nil
+}
+
+// remove removes the given element from the store. The caller must hold the mutex.
+func (s *MemoryStore) remove(element *list.Element) {
+	s.order.Remove(element)
+	delete(s.entries, element.Value.(*memoryEntry).key)
+}
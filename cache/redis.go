@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// deletePrefixBatchSize is the number of keys scanned and deleted at once by DeletePrefix
+const deletePrefixBatchSize = 500
+
+// RedisStore is a Store backed by Redis, whose values are shared by every instance of a service
+type RedisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisStore creates a new RedisStore. Keys are prefixed with the given prefix
+// (i.e. the service name) to avoid collisions between services sharing the same Redis instance.
+func NewRedisStore(client redis.UniversalClient, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// redisKey returns the Redis key of the given key of the store
+func (s *RedisStore) redisKey(key string) string {
+	return s.prefix + ":cache:" + key
+}
+
+// Get returns the value of the given key. It returns ErrCacheMiss if the key isn't in the store.
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := s.client.Get(ctx, s.redisKey(key)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrCacheMiss
+		}
+
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// Set stores the value of the given key, which expires after the given time to live
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, s.redisKey(key), value, ttl).Err()
+}
+
+// Delete removes the given keys from the store
+func (s *RedisStore) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	redisKeys := make([]string, len(keys))
+	for i, key := range keys {
+		redisKeys[i] = s.redisKey(key)
+	}
+
+	return s.client.Del(ctx, redisKeys...).Err()
+}
+
+// DeletePrefix removes every key starting with the given prefix from the store. Keys are scanned
+// in batches rather than listed with KEYS, which would block Redis.
+func (s *RedisStore) DeletePrefix(ctx context.Context, prefix string) error {
+	var cursor uint64
+
+	for {
+		keys, nextCursor, err := s.client.Scan(ctx, cursor, s.redisKey(prefix)+"*", deletePrefixBatchSize).Result()
+		if err != nil {
+			return err
+		}
+
+		if len(keys) > 0 {
+			if err := s.client.Del(ctx, keys...).Err(); err != nil {
+				return err
+			}
+		}
+
+		if nextCursor == 0 {
+			return nil
+		}
+
+		cursor = nextCursor
+	}
+}
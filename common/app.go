@@ -1,10 +1,13 @@
 package common
 
 import (
+	"context"
 	"sync"
 
 	"github.com/PlayEconomy37/Play.Common/configuration"
 	"github.com/PlayEconomy37/Play.Common/logger"
+	"github.com/PlayEconomy37/Play.Common/opentelemetry"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -14,4 +17,24 @@ type App struct {
 	Logger    *logger.Logger
 	Tracer    trace.Tracer
 	WaitGroup sync.WaitGroup // Used to coordinate the graceful shutdown and our background goroutines
+
+	// Used to register the metrics of the middlewares, prometheus.DefaultRegisterer if nil
+	MetricsRegisterer prometheus.Registerer
+
+	// Business metrics of the service, created on first use
+	metricsOnce sync.Once
+	metrics     *opentelemetry.ServiceMetrics
+
+	// Used to stop the message broker consumers during the graceful shutdown
+	consumersOnce sync.Once
+	consumersCtx  context.Context
+	stopConsumers context.CancelFunc
+	drainCtx      context.Context
+
+	// Called by Serve at the end of the graceful shutdown
+	shutdownMu    sync.Mutex
+	shutdownHooks []ShutdownHook
+
+	// Run by the readiness probes
+	health healthCheckers
 }
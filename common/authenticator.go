@@ -0,0 +1,155 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PlayEconomy37/Play.Common/database"
+	"github.com/pascaldekloe/jwt"
+)
+
+// ErrInvalidAuthenticationToken is returned when an access token is missing, invalid or expired,
+// or when its user doesn't exist
+var ErrInvalidAuthenticationToken = errors.New("invalid or missing authentication token")
+
+// Authenticator is a struct which authenticates users with JWT access tokens, following the rules
+// of the Auth section of the configuration. It is shared by the HTTP and gRPC authentication.
+type Authenticator struct {
+	repository AuthRepository
+	checkToken func(ctx context.Context, token []byte) (*jwt.Claims, error)
+	issuer     string
+	audiences  []string
+	clockSkew  time.Duration
+}
+
+// NewAuthenticator creates a new Authenticator. Tokens are signed with the given RSA public key, or with
+// one of the keys published at Auth.JWKSURL if it is set, in which case publicKey can be empty.
+func (app *App) NewAuthenticator(repository AuthRepository, publicKey string) (*Authenticator, error) {
+	authCfg := app.Config.Auth
+
+	authenticator := &Authenticator{
+		repository: repository,
+		issuer:     authCfg.Issuer,
+		audiences:  authCfg.Audiences,
+		clockSkew:  authCfg.ClockSkew,
+	}
+
+	if authenticator.issuer == "" {
+		authenticator.issuer = app.Config.Authority
+	}
+
+	if len(authenticator.audiences) == 0 {
+		authenticator.audiences = []string{"http://localhost:3000"}
+	}
+
+	if authCfg.JWKSURL != "" {
+		authenticator.checkToken = newJWKSKeys(authCfg.JWKSURL, authCfg.JWKSRefreshInterval).Check
+		return authenticator, nil
+	}
+
+	rsaPublicKey, err := LoadRsaPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	authenticator.checkToken = func(_ context.Context, token []byte) (*jwt.Claims, error) {
+		return jwt.RSACheck(token, rsaPublicKey)
+	}
+
+	return authenticator, nil
+}
+
+// AuthenticateHeader authenticates the user of the access token of the given Authorization header
+// value, which is expected to be in the format "Bearer <token>"
+func (a *Authenticator) AuthenticateHeader(ctx context.Context, authorizationHeader string) (database.User, error) {
+	// We try to split the header into its constituent parts, and if the
+	// header isn't in the expected format the token is invalid
+	headerParts := strings.Split(authorizationHeader, " ")
+	if len(headerParts) != 2 || headerParts[0] != "Bearer" {
+		return database.User{}, ErrInvalidAuthenticationToken
+	}
+
+	return a.Authenticate(ctx, headerParts[1])
+}
+
+// Authenticate validates the given access token and retrieves its user. ErrInvalidAuthenticationToken
+// is returned if the token isn't valid, any other error is unexpected.
+func (a *Authenticator) Authenticate(ctx context.Context, token string) (database.User, error) {
+	// Parse the JWT and extract the claims. This will return an error if the JWT
+	// contents doesn't match the signature (i.e. the token has been tampered with)
+	// or the algorithm isn't valid.
+	claims, err := a.checkToken(ctx, []byte(token))
+	if err != nil {
+		if errors.Is(err, errJWKSFetch) {
+			return database.User{}, err
+		}
+
+		return database.User{}, ErrInvalidAuthenticationToken
+	}
+
+	// Check if the JWT is still valid at this moment in time, give or take the clock skew
+	if claims.AcceptTemporal(time.Now(), a.clockSkew) != nil {
+		return database.User{}, ErrInvalidAuthenticationToken
+	}
+
+	// Check that the issuer is our identity service
+	if claims.Issuer != a.issuer {
+		return database.User{}, ErrInvalidAuthenticationToken
+	}
+
+	// Check that the service is in the expected audiences for the JWT
+	if !acceptsAudience(claims, a.audiences) {
+		return database.User{}, ErrInvalidAuthenticationToken
+	}
+
+	// At this point, we know that the JWT is all OK and we can trust the data in
+	// it. We extract the user ID from the claims subject and convert it from a
+	// string into an int64.
+	userID, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil {
+		return database.User{}, err
+	}
+
+	// Retrieve the details of the user associated with the authentication token
+	user, err := a.repository.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return database.User{}, ErrInvalidAuthenticationToken
+		}
+
+		return database.User{}, err
+	}
+
+	return user, nil
+}
+
+// acceptsAudience returns whether the given claims are intended for one of the given audiences
+func acceptsAudience(claims *jwt.Claims, audiences []string) bool {
+	for _, audience := range audiences {
+		if claims.AcceptAudience(audience) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasPermissions returns whether the user with the given ID has every given permission. The permissions
+// are read from the repository rather than from the context, so that revoked permissions apply at once.
+func (app *App) HasPermissions(ctx context.Context, repository AuthRepository, userID int64, codes ...string) (bool, error) {
+	user, err := repository.GetByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, code := range codes {
+		if !user.GetPermissions().Include(code) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,51 @@
+package common
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PlayEconomy37/Play.Common/configuration"
+	"github.com/PlayEconomy37/Play.Common/logger"
+)
+
+// WatchConfig watches the configuration file the application was loaded from (with the same options) and registers a shutdown
+// hook which stops watching it. The log level is updated when Logging.Level changes, and services register
+// their own callbacks on the returned watcher (i.e. to update rate limits). App.Config keeps the startup
+// configuration, the current one is returned by the Config method of the watcher.
+func (app *App) WatchConfig(filePath string, opts ...configuration.LoadOption) (*configuration.Watcher, error) {
+	watcher, err := configuration.NewWatcher(filePath, configuration.DefaultReloadInterval, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	watcher.OnChange(func(change configuration.Change) {
+		app.Logger.Info("Configuration changed", map[string]string{"keys": fmt.Sprint(change.Keys)})
+
+		if !change.Changed("Logging.Level") {
+			return
+		}
+
+		level := logger.LevelInfo
+		if change.New.Logging.Level != "" {
+			newLevel, err := logger.ParseLevel(change.New.Logging.Level)
+			if err != nil {
+				app.Logger.Error(err, nil)
+				return
+			}
+
+			level = newLevel
+		}
+
+		app.Logger.SetLevel(level)
+	})
+
+	watcher.OnError(func(err error) {
+		app.Logger.Error(fmt.Errorf("unable to reload configuration: %w", err), nil)
+	})
+
+	app.OnShutdown(func(context.Context) error {
+		return watcher.Close()
+	})
+
+	return watcher, nil
+}
@@ -0,0 +1,81 @@
+package common
+
+import (
+	"context"
+	"time"
+
+	"github.com/PlayEconomy37/Play.Common/events"
+)
+
+// DefaultConsumerDrainTimeout is the time given to consumers to finish handling in-flight messages
+// during the graceful shutdown, before their channel is closed, if Server.DrainTimeout isn't configured
+const DefaultConsumerDrainTimeout = 30 * time.Second
+
+// StartConsumer starts the given consumer in a background goroutine tracked by the application WaitGroup.
+// When the application shuts down, the consumer stops receiving new messages, finishes the messages
+// it is handling within the drain timeout of the configuration and is then closed. Messages which haven't been
+// acknowledged by then are redelivered by the broker.
+func (app *App) StartConsumer(consumer events.Consumer) {
+	ctx := app.consumersContext()
+
+	app.WaitGroup.Add(1)
+
+	go func() {
+		defer app.WaitGroup.Done()
+		defer consumer.Close()
+
+		stopped := make(chan error, 1)
+		go func() {
+			stopped <- consumer.Start(ctx)
+		}()
+
+		var err error
+
+		select {
+		case err = <-stopped:
+		case <-ctx.Done():
+			drainCtx, cancel := context.WithTimeout(app.drainCtx, app.consumerDrainTimeout())
+
+			select {
+			case err = <-stopped:
+			case <-drainCtx.Done():
+				app.Logger.Warning("Consumer did not finish in-flight messages before the drain timeout", nil)
+			}
+
+			cancel()
+		}
+
+		if err != nil {
+			app.Logger.Error(err, nil)
+		}
+	}()
+}
+
+// StopConsumers makes every consumer started with StartConsumer stop receiving new messages. Their
+// drain ends when the given context is done, if the drain timeout hasn't expired before.
+// It is called by Serve during the graceful shutdown, with the rest of the shutdown budget.
+func (app *App) StopConsumers(ctx context.Context) {
+	app.consumersContext()
+
+	// Set before the cancellation, which consumers wait for before reading it
+	app.drainCtx = ctx
+	app.stopConsumers()
+}
+
+// consumerDrainTimeout returns the drain timeout of the configuration, or DefaultConsumerDrainTimeout
+func (app *App) consumerDrainTimeout() time.Duration {
+	if app.Config == nil {
+		return DefaultConsumerDrainTimeout
+	}
+
+	return durationOrDefault(app.Config.Server.DrainTimeout, DefaultConsumerDrainTimeout)
+}
+
+// consumersContext returns the context given to consumers and to the mailer, which is cancelled by StopConsumers
+func (app *App) consumersContext() context.Context {
+	app.consumersOnce.Do(func() {
+		app.consumersCtx, app.stopConsumers = context.WithCancel(context.Background())
+	})
+
+	return app.consumersCtx
+}
@@ -3,8 +3,12 @@ package common
 import (
 	"context"
 	"net/http"
+	"strconv"
 
 	"github.com/PlayEconomy37/Play.Common/database"
+	"github.com/PlayEconomy37/Play.Common/logger"
+	"github.com/PlayEconomy37/Play.Common/opentelemetry"
+	"golang.org/x/exp/slog"
 )
 
 // Define a custom contextKey type with the underlying type string
@@ -18,19 +22,58 @@ const userContextKey = contextKey("user")
 // ContextSetUser returns a new copy of the request with the provided
 // User struct added to the context
 func (app *App) ContextSetUser(r *http.Request, user database.User) *http.Request {
-	ctx := context.WithValue(r.Context(), userContextKey, user)
+	return r.WithContext(app.ContextWithUser(r.Context(), user))
+}
+
+// ContextWithUser returns a copy of the given context holding the provided User struct, whose ID
+// is added to the log entries of the request and propagated to the services called while handling it
+func (app *App) ContextWithUser(ctx context.Context, user database.User) context.Context {
+	ctx = context.WithValue(ctx, userContextKey, user)
+
+	// Add the user ID to the log entries of the request, unless the calling service propagated it
+	requestLogger := logger.FromContext(ctx)
+	if requestLogger != nil && opentelemetry.UserID(ctx) == "" {
+		ctx = logger.NewContext(ctx, requestLogger.With(slog.Int64(logger.UserIDProperty, user.ID)))
+	}
 
-	return r.WithContext(ctx)
+	// Propagate the user ID to the services called while handling the request
+	if baggageCtx, err := opentelemetry.WithUserID(ctx, strconv.FormatInt(user.ID, 10)); err == nil {
+		ctx = baggageCtx
+	}
+
+	return ctx
 }
 
 // ContextGetUser retrieves the User struct from the request context. The only
 // time that we'll use this helper is when we logically expect there to be User struct
 // value in the context, and if it doesn't exist it will firmly be an 'unexpected' error.
 func (app *App) ContextGetUser(r *http.Request) database.User {
-	user, ok := r.Context().Value(userContextKey).(database.User)
+	user, ok := app.UserFromContext(r.Context())
 	if !ok {
 		panic("missing user value in request context")
 	}
 
 	return user
 }
+
+// UserFromContext retrieves the User struct from the given context, if the user has been authenticated
+func (app *App) UserFromContext(ctx context.Context) (database.User, bool) {
+	user, ok := ctx.Value(userContextKey).(database.User)
+	return user, ok
+}
+
+// LoggerFromContext returns the logger of the request of the given context, which adds the method, route,
+// request ID and user ID of the request to every log entry, or the application logger outside of requests
+// (i.e. in background goroutines). The route is only known once the request has been routed.
+func (app *App) LoggerFromContext(ctx context.Context) *logger.Logger {
+	requestLogger := logger.FromContext(ctx)
+	if requestLogger == nil {
+		return app.Logger
+	}
+
+	if pattern := routePattern(ctx); pattern != "" {
+		return requestLogger.With(slog.String(logger.RouteProperty, pattern))
+	}
+
+	return requestLogger
+}
@@ -0,0 +1,91 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PlayEconomy37/Play.Common/discovery"
+)
+
+// registerService registers the instance of the service served at the given address with the registry of
+// the Discovery section of the configuration, and returns a function deregistering it. The instance is
+// registered under the service name, with an HTTP health check polling the readiness probe.
+func (app *App) registerService(address string, useTLS bool) (func(ctx context.Context) error, error) {
+	discoveryCfg := app.Config.Discovery
+
+	registry, err := discovery.NewRegistry(app.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	host, portValue, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	port, err := strconv.Atoi(portValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port in address %s: %w", address, err)
+	}
+
+	// Servers listening on every interface are advertised with the host name
+	if discoveryCfg.ServiceAddress != "" {
+		host = discoveryCfg.ServiceAddress
+	} else if host == "" || host == "0.0.0.0" || host == "::" {
+		if host, err = os.Hostname(); err != nil {
+			return nil, err
+		}
+	}
+
+	healthCheckPath := discoveryCfg.HealthCheckPath
+	if healthCheckPath == "" {
+		healthCheckPath = "/readyz"
+	}
+
+	scheme := "http"
+	if useTLS {
+		scheme = "https"
+	}
+
+	registration := discovery.Registration{
+		Instance: discovery.Instance{
+			ID:      fmt.Sprintf("%s-%s-%d", app.Config.ServiceName, host, port),
+			Service: app.Config.ServiceName,
+			Address: host,
+			Port:    port,
+			Tags:    discoveryCfg.Tags,
+			Meta:    map[string]string{},
+		},
+		HealthCheckURL:      fmt.Sprintf("%s://%s/%s", scheme, net.JoinHostPort(host, portValue), strings.TrimPrefix(healthCheckPath, "/")),
+		HealthCheckInterval: durationOrDefault(discoveryCfg.HealthCheckInterval, 10*time.Second),
+		DeregisterAfter:     durationOrDefault(discoveryCfg.DeregisterAfter, time.Minute),
+	}
+
+	// Let gRPC clients reach the gRPC server of the instance
+	if app.Config.GRPC.Address != "" {
+		if _, grpcPort, err := net.SplitHostPort(app.Config.GRPC.Address); err == nil {
+			registration.Meta[discovery.GRPCPortMeta] = grpcPort
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := registry.Register(ctx, registration); err != nil {
+		return nil, fmt.Errorf("unable to register the service: %w", err)
+	}
+
+	app.Logger.Info("Registered service", map[string]string{
+		"id":       registration.ID,
+		"registry": discoveryCfg.Type,
+	})
+
+	return func(ctx context.Context) error {
+		return registry.Deregister(ctx, registration.ID)
+	}, nil
+}
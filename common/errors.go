@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/PlayEconomy37/Play.Common/types"
+	"github.com/PlayEconomy37/Play.Common/validator"
 )
 
 // Generic helper for logging an error message
@@ -16,9 +17,15 @@ func (app *App) logError(r *http.Request, err error) {
 }
 
 // Generic helper for sending JSON-formatted error
-// messages to the client with a given status code
-func (app *App) errorResponse(w http.ResponseWriter, r *http.Request, status int, message any) {
+// messages to the client with a given status code,
+// along with the fields of the given envelopes
+func (app *App) errorResponse(w http.ResponseWriter, r *http.Request, status int, message any, extra ...types.Envelope) {
 	env := types.Envelope{"error": message}
+	for _, fields := range extra {
+		for key, value := range fields {
+			env[key] = value
+		}
+	}
 
 	err := app.WriteJSON(w, status, env, nil)
 	if err != nil {
@@ -54,10 +61,32 @@ func (app *App) BadRequestResponse(w http.ResponseWriter, r *http.Request, err e
 	app.errorResponse(w, r, http.StatusBadRequest, err.Error())
 }
 
+// FailedValidatorResponse will be used to send a 422 Unprocessable Entity status code and the
+// errors of the given Validator as a JSON response body, along with their machine-readable codes
+func (app *App) FailedValidatorResponse(w http.ResponseWriter, r *http.Request, v *validator.Validator) {
+	app.errorResponse(w, r, http.StatusUnprocessableEntity, v.Errors, types.Envelope{"codes": v.Codes})
+}
+
 // FailedValidationResponse will be used to send a 422 Unprocessable Entity status code and
-// the contents of the errors map from our Validator type as a JSON response body
+// the contents of the given errors map as a JSON response body, every error having the code
+// validator.DefaultCode.
+//
+// Deprecated: use FailedValidatorResponse, which sends the codes recorded by the Validator.
 func (app *App) FailedValidationResponse(w http.ResponseWriter, r *http.Request, errors map[string]string) {
-	app.errorResponse(w, r, http.StatusUnprocessableEntity, errors)
+	v := validator.New()
+	for key, message := range errors {
+		v.AddError(key, message)
+	}
+
+	app.FailedValidatorResponse(w, r, v)
+}
+
+// LocalizedValidationResponse will be used to send a 422 Unprocessable Entity status code and the
+// errors of the given Validator as a JSON response body, translated in the language requested
+// by the client through the Accept-Language header, along with their machine-readable codes
+func (app *App) LocalizedValidationResponse(w http.ResponseWriter, r *http.Request, v *validator.Validator) {
+	locale := validator.MatchLocale(r.Header.Get("Accept-Language"))
+	app.errorResponse(w, r, http.StatusUnprocessableEntity, v.Translate(locale), types.Envelope{"codes": v.Codes})
 }
 
 // EditConflictResponse will be used to send a 409 Conflict status code and
@@ -0,0 +1,107 @@
+package common
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/PlayEconomy37/Play.Common/logger"
+	"github.com/PlayEconomy37/Play.Common/validator"
+)
+
+func TestFailedValidationResponse(t *testing.T) {
+	rr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+
+	app := &App{Logger: logger.New(io.Discard, logger.LevelInfo)}
+	app.FailedValidationResponse(rr, r, map[string]string{"name": "must be provided"})
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("want %d; got %d", http.StatusUnprocessableEntity, rr.Code)
+	}
+
+	var body struct {
+		Error map[string]string `json:"error"`
+		Codes map[string]string `json:"codes"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+
+	if body.Error["name"] != "must be provided" {
+		t.Errorf("want %q; got %q", "must be provided", body.Error["name"])
+	}
+
+	if body.Codes["name"] != validator.DefaultCode {
+		t.Errorf("want %q; got %q", validator.DefaultCode, body.Codes["name"])
+	}
+}
+
+func TestFailedValidatorResponse(t *testing.T) {
+	rr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+
+	v := validator.New()
+	v.AddErrorWithCode("name", "too_short", "must be at least 3 characters long")
+	v.Check(false, "price", "must be provided")
+
+	app := &App{Logger: logger.New(io.Discard, logger.LevelInfo)}
+	app.FailedValidatorResponse(rr, r, v)
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("want %d; got %d", http.StatusUnprocessableEntity, rr.Code)
+	}
+
+	var body struct {
+		Error map[string]string `json:"error"`
+		Codes map[string]string `json:"codes"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+
+	if body.Error["name"] != "must be at least 3 characters long" {
+		t.Errorf("want %q; got %q", "must be at least 3 characters long", body.Error["name"])
+	}
+
+	if body.Codes["name"] != "too_short" {
+		t.Errorf("want %q; got %q", "too_short", body.Codes["name"])
+	}
+
+	if body.Codes["price"] != validator.DefaultCode {
+		t.Errorf("want %q; got %q", validator.DefaultCode, body.Codes["price"])
+	}
+}
+
+func TestLocalizedValidationResponse(t *testing.T) {
+	rr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+
+	v := validator.New()
+	v.AddError("name", "must be provided")
+
+	app := &App{Logger: logger.New(io.Discard, logger.LevelInfo)}
+	app.LocalizedValidationResponse(rr, r, v)
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("want %d; got %d", http.StatusUnprocessableEntity, rr.Code)
+	}
+
+	var body struct {
+		Error map[string]string `json:"error"`
+		Codes map[string]string `json:"codes"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, exists := body.Error["name"]; !exists {
+		t.Errorf("want an error for name; got %v", body.Error)
+	}
+
+	if body.Codes["name"] != validator.DefaultCode {
+		t.Errorf("want %q; got %q", validator.DefaultCode, body.Codes["name"])
+	}
+}
@@ -0,0 +1,115 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/PlayEconomy37/Play.Common/types"
+)
+
+// HealthCheckTimeout is the time given to the health checks of the application to complete
+const HealthCheckTimeout = 5 * time.Second
+
+// HealthChecker is a function which reports whether a dependency of the service (i.e. the database or
+// the message broker) is available, returning an error if it isn't
+type HealthChecker func(ctx context.Context) error
+
+// healthCheckers is a struct that holds the registered health checkers, keyed by name
+type healthCheckers struct {
+	mu       sync.RWMutex
+	checkers map[string]HealthChecker
+}
+
+// RegisterHealthChecker registers a health checker with the given name (i.e. "mongo"), replacing the
+// checker with the same name if any. The service is ready when every checker succeeds.
+//
+//	app.RegisterHealthChecker("mongo", func(ctx context.Context) error { return client.Ping(ctx, nil) })
+func (app *App) RegisterHealthChecker(name string, checker HealthChecker) {
+	app.health.mu.Lock()
+	defer app.health.mu.Unlock()
+
+	if app.health.checkers == nil {
+		app.health.checkers = map[string]HealthChecker{}
+	}
+
+	app.health.checkers[name] = checker
+}
+
+// CheckHealth runs the registered health checkers concurrently and returns the errors of the failing
+// ones keyed by name, which is empty if the service is ready. Checkers share the HealthCheckTimeout.
+func (app *App) CheckHealth(ctx context.Context) map[string]error {
+	app.health.mu.RLock()
+	checkers := make(map[string]HealthChecker, len(app.health.checkers))
+	for name, checker := range app.health.checkers {
+		checkers[name] = checker
+	}
+	app.health.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, HealthCheckTimeout)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		failures = map[string]error{}
+	)
+
+	for name, checker := range checkers {
+		wg.Add(1)
+
+		go func(name string, checker HealthChecker) {
+			defer wg.Done()
+
+			if err := checker(ctx); err != nil {
+				mu.Lock()
+				failures[name] = err
+				mu.Unlock()
+			}
+		}(name, checker)
+	}
+
+	wg.Wait()
+
+	return failures
+}
+
+// ReadinessHandler returns the handler of the readiness probe (i.e. /readyz), which responds with
+// a 200 OK status code if every health checker succeeds and a 503 Service Unavailable status code
+// along with the failing checks otherwise
+//
+//	router.Method(http.MethodGet, "/readyz", app.ReadinessHandler())
+func (app *App) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		failures := app.CheckHealth(r.Context())
+		if len(failures) == 0 {
+			if err := app.WriteJSON(w, http.StatusOK, types.Envelope{"status": "ready"}, nil); err != nil {
+				app.ServerErrorResponse(w, r, err)
+			}
+
+			return
+		}
+
+		names := make([]string, 0, len(failures))
+		for name := range failures {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		checks := make(map[string]string, len(failures))
+		for _, name := range names {
+			checks[name] = failures[name].Error()
+			app.Logger.Warning("Health check failed", map[string]string{
+				"check": name,
+				"error": failures[name].Error(),
+			})
+		}
+
+		env := types.Envelope{"status": "unavailable", "checks": checks}
+		if err := app.WriteJSON(w, http.StatusServiceUnavailable, env, nil); err != nil {
+			app.ServerErrorResponse(w, r, err)
+		}
+	})
+}
@@ -15,6 +15,8 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/PlayEconomy37/Play.Common/filters"
+	"github.com/PlayEconomy37/Play.Common/sanitize"
 	"github.com/PlayEconomy37/Play.Common/types"
 	"github.com/PlayEconomy37/Play.Common/validator"
 	"github.com/go-chi/chi/v5"
@@ -128,6 +130,47 @@ func (app *App) ReadJSON(w http.ResponseWriter, r *http.Request, target interfac
 	return nil
 }
 
+// ReadOption is a function used to configure optional behaviour of ReadAndValidateJSON
+type ReadOption func(*readOptions)
+
+// readOptions is a struct that holds the optional configuration of ReadAndValidateJSON
+type readOptions struct {
+	sanitize bool
+}
+
+// WithSanitizers makes ReadAndValidateJSON apply the sanitizers declared in the `sanitize`
+// tags of the target (i.e. `sanitize:"trim,lower"`) before validating it
+func WithSanitizers() ReadOption {
+	return func(opts *readOptions) {
+		opts.sanitize = true
+	}
+}
+
+// ReadAndValidateJSON reads JSON data from HTTP request to the specified target, which must be a
+// pointer to struct, and validates it against the rules declared in its `validate` tags.
+// It returns an error if the request body can't be decoded and the Validator holding the
+// validation errors otherwise.
+func (app *App) ReadAndValidateJSON(w http.ResponseWriter, r *http.Request, target any, opts ...ReadOption) (*validator.Validator, error) {
+	var options readOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	err := app.ReadJSON(w, r, target)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.sanitize {
+		sanitize.Struct(target)
+	}
+
+	v := validator.New()
+	v.Struct(target)
+
+	return v, nil
+}
+
 // ReadIDParam retrieves the URL parameter `id` from the current request context, then converts it to an integer
 func (app *App) ReadIDParam(r *http.Request) (int64, error) {
 	// Extract URL parameters from request context
@@ -233,6 +276,26 @@ func (app *App) ReadFloatFromQueryString(queryString url.Values, key string, def
 	return value
 }
 
+// ParseFilters is a helper function that reads the page, page_size, sort and search (or q) parameters
+// and the conditions on the declared fields from the query string of the request into filters,
+// starting from the given defaults, and validates them. Errors are recorded in the provided Validator
+// instance and the filters are safe to use even if there are errors, since unsupported sort values
+// fall back to the default sort.
+func (app *App) ParseFilters(r *http.Request, v *validator.Validator, opts filters.Options) filters.Filters {
+	queryString := r.URL.Query()
+
+	f := filters.NewFilters(opts.Defaults)
+	f.Page = app.ReadIntFromQueryString(queryString, "page", f.Page, v)
+	f.PageSize = app.ReadIntFromQueryString(queryString, "page_size", f.PageSize, v)
+	f.Sort = app.ReadStringFromQueryString(queryString, "sort", f.Sort)
+	f.Search = app.ReadStringFromQueryString(queryString, "search", app.ReadStringFromQueryString(queryString, "q", ""))
+	f.Query = filters.ParseQuery(queryString, v, opts.Fields...)
+
+	filters.ValidateFilters(v, f)
+
+	return f
+}
+
 // Background is a helper function which runs a function in a separate go routine and makes sure that we
 // recover any panic that happens in the go routine.
 // We pass in the context for opentelemetry tracing.
@@ -0,0 +1,130 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+const (
+	defaultJWKSRefreshInterval = time.Hour        // Used if Auth.JWKSRefreshInterval is empty
+	minJWKSRefreshInterval     = time.Minute      // Minimum time between two fetches
+	jwksRequestTimeout         = 10 * time.Second // Timeout of a request fetching the keys
+)
+
+// errJWKSFetch is returned when the keys of the JWKS URL can't be fetched
+var errJWKSFetch = errors.New("unable to fetch JWKS")
+
+// jwksKeys is a struct which checks the signature of access tokens against the keys published at a JWKS URL.
+// Keys are fetched again once the refresh interval has elapsed, or when a token isn't signed by a known key,
+// so that rotated keys are picked up.
+type jwksKeys struct {
+	url             string
+	refreshInterval time.Duration
+	client          *http.Client
+
+	mu        sync.Mutex
+	keys      *jwt.KeyRegister
+	expiresAt time.Time
+	lastFetch time.Time // Last fetch attempt, successful or not
+}
+
+// newJWKSKeys creates a new jwksKeys fetching keys from the given URL
+func newJWKSKeys(url string, refreshInterval time.Duration) *jwksKeys {
+	if refreshInterval <= 0 {
+		refreshInterval = defaultJWKSRefreshInterval
+	}
+
+	return &jwksKeys{
+		url:             url,
+		refreshInterval: refreshInterval,
+		client:          &http.Client{Timeout: jwksRequestTimeout},
+	}
+}
+
+// Check parses the given token if its signature checks out against one of the keys. Errors wrapping
+// errJWKSFetch are returned if the keys can't be fetched.
+func (k *jwksKeys) Check(ctx context.Context, token []byte) (*jwt.Claims, error) {
+	keys, err := k.register(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := keys.Check(token)
+	if !errors.Is(err, jwt.ErrSigMiss) {
+		return claims, err
+	}
+
+	// The token may be signed by a key published since the last fetch
+	refreshed, err := k.register(ctx, true)
+	if err != nil || refreshed == keys {
+		return nil, jwt.ErrSigMiss
+	}
+
+	return refreshed.Check(token)
+}
+
+// register returns the current keys, which are fetched again if they expired or if refresh is set.
+// Keys are fetched at most once a minute, so that invalid tokens can't flood the JWKS URL.
+func (k *jwksKeys) register(ctx context.Context, refresh bool) (*jwt.KeyRegister, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := time.Now()
+	if k.keys != nil && (now.Sub(k.lastFetch) < minJWKSRefreshInterval || (!refresh && now.Before(k.expiresAt))) {
+		return k.keys, nil
+	}
+
+	k.lastFetch = now
+
+	keys, err := k.fetch(ctx)
+	if err != nil {
+		// Keep using the current keys until the JWKS URL is available again
+		if k.keys != nil {
+			return k.keys, nil
+		}
+
+		return nil, fmt.Errorf("%w: %s", errJWKSFetch, err)
+	}
+
+	k.keys = keys
+	k.expiresAt = now.Add(k.refreshInterval)
+
+	return keys, nil
+}
+
+// fetch fetches the keys published at the JWKS URL
+func (k *jwksKeys) fetch(ctx context.Context) (*jwt.KeyRegister, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := k.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d from %s", res.StatusCode, req.URL.Host)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	var keys jwt.KeyRegister
+	if _, err := keys.LoadJWK(body); err != nil {
+		return nil, err
+	}
+
+	return &keys, nil
+}
@@ -0,0 +1,53 @@
+package common
+
+import (
+	"net/http"
+
+	"github.com/PlayEconomy37/Play.Common/logger"
+	"github.com/PlayEconomy37/Play.Common/types"
+	"github.com/PlayEconomy37/Play.Common/validator"
+)
+
+// LogLevelHandler is an admin handler used to read (GET) and change (PUT) the minimum severity level
+// of the application logger at runtime, i.e. PUT {"level": "debug"} to turn on debug logging without
+// redeploying the service. It must be mounted behind the Authenticate and RequirePermission middlewares.
+func (app *App) LogLevelHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var input struct {
+			Level string `json:"level"`
+		}
+
+		err := app.ReadJSON(w, r, &input)
+		if err != nil {
+			app.BadRequestResponse(w, r, err)
+			return
+		}
+
+		level, err := logger.ParseLevel(input.Level)
+		if err != nil {
+			v := validator.New()
+			v.AddErrorWithCode("level", "not_allowed", "must be one of debug, info, warning, error, fatal or off")
+
+			app.FailedValidatorResponse(w, r, v)
+			return
+		}
+
+		previous := app.Logger.Level()
+		app.Logger.SetLevel(level)
+
+		app.Logger.Warning("Log level changed", map[string]string{
+			"previous": previous.String(),
+			"level":    level.String(),
+		})
+	default:
+		app.MethodNotAllowedResponse(w, r)
+		return
+	}
+
+	err := app.WriteJSON(w, http.StatusOK, types.Envelope{"level": app.Logger.Level().String()}, nil)
+	if err != nil {
+		app.ServerErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,52 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/PlayEconomy37/Play.Common/configuration"
+	"github.com/PlayEconomy37/Play.Common/logger"
+)
+
+// SetupLogging sets the application logger to a logger writing to the log sinks of the configuration
+// and registers a shutdown hook which closes them on exit. It should be called before the other setup
+// methods so that the sinks are closed last and receive the logs of the other shutdown hooks.
+// The effective configuration is logged with its sensitive settings masked if Logging.LogConfig is set.
+func (app *App) SetupLogging() error {
+	log, err := logger.NewFromConfig(app.Config)
+	if err != nil {
+		return err
+	}
+
+	app.Logger = log
+	app.OnShutdown(func(context.Context) error {
+		return log.Close()
+	})
+
+	if app.Config.Logging.LogConfig {
+		app.LogConfig()
+	}
+
+	return nil
+}
+
+// LogConfig logs the effective configuration with its sensitive settings masked
+func (app *App) LogConfig() {
+	dump, err := configuration.DumpEffective(app.Config)
+	if err != nil {
+		app.Logger.Error(err, nil)
+		return
+	}
+
+	// Log the configuration on a single line
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, dump); err != nil {
+		app.Logger.Error(err, nil)
+		return
+	}
+
+	app.Logger.Info("Effective configuration", map[string]string{
+		"config": compact.String(),
+	})
+}
@@ -0,0 +1,10 @@
+package common
+
+import "github.com/PlayEconomy37/Play.Common/mailer"
+
+// StartMailer starts sending the emails queued in memory by the given mailer in a background goroutine
+// tracked by the application WaitGroup. When the application shuts down, the emails left in the queue
+// are sent before the goroutine completes.
+func (app *App) StartMailer(mailer *mailer.AsyncMailer) {
+	app.Background(app.consumersContext(), mailer.Run)
+}
@@ -0,0 +1,46 @@
+package common
+
+import (
+	"net/http"
+
+	"github.com/PlayEconomy37/Play.Common/opentelemetry"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// SetupMetrics sets up the Opentelemetry metrics SDK with the metrics configuration of the application,
+// exposing its metrics with the registerer of the application, and registers a shutdown hook which
+// pushes the last metrics on exit
+func (app *App) SetupMetrics() error {
+	meterProvider, err := opentelemetry.SetupMeter(app.Config, app.metricsRegisterer())
+	if err != nil {
+		return err
+	}
+
+	app.OnShutdown(meterProvider.Shutdown)
+
+	return nil
+}
+
+// Metrics returns the ServiceMetrics used to declare the business metrics of the service,
+// prefixed with the service name and registered with the registerer of the application
+//
+//	app.Metrics().Counter(opentelemetry.MetricOpts{Name: "items_purchased", Help: "...", Labels: []string{"item"}})
+func (app *App) Metrics() *opentelemetry.ServiceMetrics {
+	app.metricsOnce.Do(func() {
+		app.metrics = opentelemetry.NewServiceMetrics(app.Config.ServiceName, app.metricsRegisterer())
+	})
+
+	return app.metrics
+}
+
+// MetricsHandler returns the handler serving the metrics of the application to Prometheus, along with
+// the trace exemplars of the duration histograms. Metrics are gathered from the registerer of the
+// application if it is a prometheus.Gatherer (i.e. a *prometheus.Registry), from the default registry otherwise.
+func (app *App) MetricsHandler() http.Handler {
+	gatherer, ok := app.metricsRegisterer().(prometheus.Gatherer)
+	if !ok {
+		gatherer = prometheus.DefaultGatherer
+	}
+
+	return opentelemetry.MetricsHandler(gatherer)
+}
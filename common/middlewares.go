@@ -4,15 +4,26 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/PlayEconomy37/Play.Common/database"
+	"github.com/PlayEconomy37/Play.Common/logger"
 	"github.com/PlayEconomy37/Play.Common/opentelemetry"
 	"github.com/felixge/httpsnoop"
-	"github.com/pascaldekloe/jwt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/exp/slog"
+	"golang.org/x/time/rate"
 )
 
 // RecoverPanic is a middleware used to make sure that any panics are handled properly in our application
@@ -38,29 +49,112 @@ func (app *App) RecoverPanic(next http.Handler) http.Handler {
 	})
 }
 
-// HTTPMetrics is a middleware used to set HTTP metrics for every HTTP request
+// HTTPMetrics is a middleware used to set HTTP metrics for every HTTP request. Requests are labelled
+// with the chi route pattern they matched (i.e. "/items/{id}") rather than their path, so that the
+// cardinality of the metrics doesn't grow with the number of resources. Durations hold the ID of the
+// trace of the request as exemplar when the middleware is registered after the Tracing middleware.
 func (app *App) HTTPMetrics(appName string) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
-		// Create HTTP  metrics
-		httpMetrics := opentelemetry.CreateHTTPMetrics(appName)
+		// Create HTTP metrics, or retrieve them if they have already been registered
+		httpMetrics := opentelemetry.NewHTTPMetrics(appName, app.metricsRegisterer(), app.durationBuckets())
 
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Increment the number of requests received by 1
-			httpMetrics.TotalRequestsCounter.WithLabelValues(r.Method, r.URL.Path).Inc()
-
 			// This function wraps a http.Handler (in this case, the next function), executes the handler and then returns a Metrics struct
 			metrics := httpsnoop.CaptureMetrics(next, w, r)
 
-			// On the way back up the middleware chain, increment the number of responses sent by 1
-			httpMetrics.TotalResponsesCounter.WithLabelValues(r.Method, r.URL.Path, strconv.Itoa(metrics.Code)).Inc()
+			// The route pattern is only known once the request has been routed by the next handlers
+			route := httpMetrics.RouteLabel(routePattern(r.Context()))
+
+			// Increment the number of requests received by 1
+			httpMetrics.TotalRequestsCounter.WithLabelValues(r.Method, route).Inc()
 
-			// Get the request processing time in microseconds from httpsnoop and increment
-			// the cumulative processing time
-			httpMetrics.TotalProcessingTimeCounter.WithLabelValues(r.Method, r.URL.Path).Observe(float64(metrics.Duration.Microseconds()))
+			// On the way back up the middleware chain, increment the number of responses sent by 1
+			httpMetrics.TotalResponsesCounter.WithLabelValues(r.Method, route, strconv.Itoa(metrics.Code)).Inc()
+
+			// Get the request processing time from httpsnoop and observe it in microseconds
+			// and in seconds
+			httpMetrics.TotalProcessingTimeCounter.WithLabelValues(r.Method, route).Observe(float64(metrics.Duration.Microseconds()))
+			opentelemetry.ObserveWithExemplar(
+				r.Context(),
+				httpMetrics.RequestDurationHistogram.WithLabelValues(r.Method, route, strconv.Itoa(metrics.Code)),
+				metrics.Duration.Seconds(),
+			)
 		})
 	}
 }
 
+// metricsRegisterer returns the registerer used to register the metrics of the middlewares
+func (app *App) metricsRegisterer() prometheus.Registerer {
+	if app.MetricsRegisterer == nil {
+		return prometheus.DefaultRegisterer
+	}
+
+	return app.MetricsRegisterer
+}
+
+// durationBuckets returns the buckets of the duration histograms of the middlewares
+func (app *App) durationBuckets() []float64 {
+	if app.Config == nil {
+		return nil
+	}
+
+	return app.Config.Metrics.DurationBuckets
+}
+
+// Tracing is a middleware used to create a server span for every HTTP request. The trace context of
+// incoming requests (traceparent header) is propagated, so that spans are part of the trace of the caller.
+// Spans are named after the chi route pattern (i.e. "GET /items/{id}") once the request has been routed,
+// and hold the request ID set by chi's RequestID middleware or the X-Request-Id header. The baggage of
+// incoming requests is propagated too, and requests entering the system get the service name as origin.
+func (app *App) Tracing(next http.Handler) http.Handler {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		span := trace.SpanFromContext(r.Context())
+
+		if opentelemetry.Origin(r.Context()) == "" {
+			if ctx, err := opentelemetry.WithOrigin(r.Context(), app.Config.ServiceName); err == nil {
+				r = r.WithContext(ctx)
+			}
+		}
+
+		if requestID := requestID(r); requestID != "" {
+			span.SetAttributes(attribute.String("http.request_id", requestID))
+		}
+
+		next.ServeHTTP(w, r)
+
+		// The route pattern is only known once the request has been routed by the next handlers
+		if pattern := routePattern(r.Context()); pattern != "" {
+			span.SetName(r.Method + " " + pattern)
+			span.SetAttributes(semconv.HTTPRouteKey.String(pattern))
+		}
+	})
+
+	return otelhttp.NewHandler(handler, "", otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+		return r.Method
+	}))
+}
+
+// routePattern returns the chi route pattern matched by the request of the given context (i.e. "/items/{id}"),
+// or an empty string if it didn't match any route. The pattern is only available once the request has been
+// routed, i.e. to middlewares registered with the Use method of the router after calling the next handler.
+func routePattern(ctx context.Context) string {
+	routeContext := chi.RouteContext(ctx)
+	if routeContext == nil {
+		return ""
+	}
+
+	return routeContext.RoutePattern()
+}
+
+// requestID returns the request ID set by chi's RequestID middleware or the X-Request-Id header
+func requestID(r *http.Request) string {
+	if requestID := middleware.GetReqID(r.Context()); requestID != "" {
+		return requestID
+	}
+
+	return r.Header.Get(middleware.RequestIDHeader)
+}
+
 // SecureHeaders is a middleware used to instruct the user’s web browser to implement some
 // additional security measures to help prevent XSS and Clickjacking attacks
 func (app *App) SecureHeaders(next http.Handler) http.Handler {
@@ -72,9 +166,169 @@ func (app *App) SecureHeaders(next http.Handler) http.Handler {
 	})
 }
 
-// LogRequest is a middleware used to log every HTTP request that comes to our application
+// EnableCORS is a middleware used to allow cross-origin requests from the origins of the CORS section
+// of the configuration. Preflight requests are answered with the allowed methods and headers without
+// calling the next handler, so it must be registered before the router handles OPTIONS requests.
+func (app *App) EnableCORS(next http.Handler) http.Handler {
+	corsCfg := app.Config.CORS
+
+	allowedMethods := corsCfg.AllowedMethods
+	if len(allowedMethods) == 0 {
+		allowedMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	}
+
+	allowedHeaders := corsCfg.AllowedHeaders
+	if len(allowedHeaders) == 0 {
+		allowedHeaders = []string{"Authorization", "Content-Type"}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Responses vary based on the origin and, for preflight requests, the requested method
+		w.Header().Add("Vary", "Origin")
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+
+		origin := r.Header.Get("Origin")
+		if origin == "" || !allowsOrigin(corsCfg.AllowedOrigins, origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// The origin is echoed rather than "*", which browsers reject along with credentials
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+
+		if corsCfg.AllowCredentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if len(corsCfg.ExposedHeaders) > 0 {
+			w.Header().Set("Access-Control-Expose-Headers", strings.Join(corsCfg.ExposedHeaders, ", "))
+		}
+
+		// Check if the request is a preflight request
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(allowedMethods, ", "))
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(allowedHeaders, ", "))
+
+			if corsCfg.MaxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(corsCfg.MaxAge.Seconds())))
+			}
+
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowsOrigin returns whether the given origin is one of the allowed origins, "*" allowing any origin
+func allowsOrigin(allowedOrigins []string, origin string) bool {
+	for _, allowedOrigin := range allowedOrigins {
+		if allowedOrigin == "*" || strings.EqualFold(allowedOrigin, origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RateLimit is a middleware used to limit the number of requests of every client IP address with the
+// RateLimit section of the configuration. Requests over the limit get a 429 Too Many Requests response.
+// It must be registered after chi's RealIP middleware if the service runs behind a proxy.
+func (app *App) RateLimit(next http.Handler) http.Handler {
+	rateLimitCfg := app.Config.RateLimit
+	if !rateLimitCfg.Enabled {
+		return next
+	}
+
+	rps := rateLimitCfg.RPS
+	if rps <= 0 {
+		rps = 2
+	}
+
+	burst := rateLimitCfg.Burst
+	if burst <= 0 {
+		burst = 4
+	}
+
+	// client is a struct that holds the rate limiter of a client and the last time it made a request
+	type client struct {
+		limiter  *rate.Limiter
+		lastSeen time.Time
+	}
+
+	var (
+		mu          sync.Mutex
+		clients     = map[string]*client{}
+		lastCleanup = time.Now()
+	)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			ip = r.RemoteAddr
+		}
+
+		mu.Lock()
+
+		// Remove the clients which haven't made a request during the last three minutes once a minute,
+		// so that the map doesn't grow forever
+		now := time.Now()
+		if now.Sub(lastCleanup) > time.Minute {
+			for ip, client := range clients {
+				if now.Sub(client.lastSeen) > 3*time.Minute {
+					delete(clients, ip)
+				}
+			}
+
+			lastCleanup = now
+		}
+
+		if _, found := clients[ip]; !found {
+			clients[ip] = &client{limiter: rate.NewLimiter(rate.Limit(rps), burst)}
+		}
+
+		clients[ip].lastSeen = now
+		allowed := clients[ip].limiter.Allow()
+
+		mu.Unlock()
+
+		if !allowed {
+			app.RateLimitExceededResponse(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// LogRequest is a middleware used to log every HTTP request that comes to our application. It adds
+// a sub-logger holding the method and request ID of the request to the request context, which
+// handlers and repositories retrieve with LoggerFromContext, so it must be registered after
+// chi's RequestID middleware.
 func (app *App) LogRequest(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attrs := []slog.Attr{slog.String(logger.MethodProperty, r.Method)}
+		if requestID := requestID(r); requestID != "" {
+			attrs = append(attrs, slog.String(logger.RequestIDProperty, requestID))
+		}
+
+		// Add the entries of the baggage propagated by the calling service
+		baggageAttrs := []slog.Attr{
+			slog.String(logger.UserIDProperty, opentelemetry.UserID(r.Context())),
+			slog.String(logger.TenantIDProperty, opentelemetry.TenantID(r.Context())),
+			slog.String(logger.OriginProperty, opentelemetry.Origin(r.Context())),
+		}
+
+		for _, attr := range baggageAttrs {
+			if attr.Value() != "" {
+				attrs = append(attrs, attr)
+			}
+		}
+
+		requestLogger := app.Logger.With(attrs...)
+		r = r.WithContext(logger.NewContext(r.Context(), requestLogger))
+
 		properties := map[string]string{
 			"ipAddress": r.RemoteAddr,
 			"protocol":  r.Proto,
@@ -82,7 +336,7 @@ func (app *App) LogRequest(next http.Handler) http.Handler {
 			"url":       r.URL.RequestURI(),
 		}
 
-		app.Logger.Info(fmt.Sprintf("%s - %s %s %s", properties["ipAddress"], properties["protocol"], properties["method"], properties["url"]), properties)
+		requestLogger.Info(fmt.Sprintf("%s - %s %s %s", properties["ipAddress"], properties["protocol"], properties["method"], properties["url"]), properties)
 		next.ServeHTTP(w, r)
 	})
 }
@@ -93,10 +347,12 @@ type AuthRepository interface {
 }
 
 // Authenticate is a middleware used to authenticate a user before acessing a certain route.
-// It extracts a JWT access token from the Authorization header and validates it.
+// It extracts a JWT access token from the Authorization header and validates it against the
+// Auth section of the configuration. Tokens are signed with the given RSA public key, or with
+// one of the keys published at Auth.JWKSURL if it is set, in which case publicKey can be empty.
 func (app *App) Authenticate(repository AuthRepository, publicKey string) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
-		publicKey, err := LoadRsaPublicKey(publicKey)
+		authenticator, err := app.NewAuthenticator(repository, publicKey)
 		if err != nil {
 			app.Logger.Fatal(err, nil)
 		}
@@ -118,59 +374,11 @@ func (app *App) Authenticate(repository AuthRepository, publicKey string) func(n
 			}
 
 			// Otherwise, we expect the value of the Authorization header to be in the format
-			// "Bearer <token>". We try to split this into its constituent parts, and if the
-			// header isn't in the expected format we return a 401 Unauthorized response
-			headerParts := strings.Split(authorizationHeader, " ")
-			if len(headerParts) != 2 || headerParts[0] != "Bearer" {
-				app.InvalidAuthenticationTokenResponse(w, r)
-				return
-			}
-
-			// Extract the actual authentication token from the header parts
-			token := headerParts[1]
-
-			// Parse the JWT and extract the claims. This will return an error if the JWT
-			// contents doesn't match the signature (i.e. the token has been tampered with)
-			// or the algorithm isn't valid.
-
-			claims, err := jwt.RSACheck([]byte(token), publicKey)
-			if err != nil {
-				app.InvalidAuthenticationTokenResponse(w, r)
-				return
-			}
-
-			// Check if the JWT is still valid at this moment in time
-			if !claims.Valid(time.Now()) {
-				app.InvalidAuthenticationTokenResponse(w, r)
-				return
-			}
-
-			// Check that the issuer is our identity service
-			if claims.Issuer != app.Config.Authority {
-				app.InvalidAuthenticationTokenResponse(w, r)
-				return
-			}
-
-			// Check that the catalog service is in the expected audiences for the JWT
-			if !claims.AcceptAudience("http://localhost:3000") {
-				app.InvalidAuthenticationTokenResponse(w, r)
-				return
-			}
-
-			// At this point, we know that the JWT is all OK and we can trust the data in
-			// it. We extract the user ID from the claims subject and convert it from a
-			// string into an int64.
-			userID, err := strconv.ParseInt(claims.Subject, 10, 64)
-			if err != nil {
-				app.ServerErrorResponse(w, r, err)
-				return
-			}
-
-			// Retrieve the details of the user associated with the authentication token
-			user, err := repository.GetByID(r.Context(), userID)
+			// "Bearer <token>", whose token is validated and whose user is retrieved
+			user, err := authenticator.AuthenticateHeader(r.Context(), authorizationHeader)
 			if err != nil {
 				switch {
-				case errors.Is(err, database.ErrRecordNotFound):
+				case errors.Is(err, ErrInvalidAuthenticationToken):
 					app.InvalidAuthenticationTokenResponse(w, r)
 				default:
 					app.ServerErrorResponse(w, r, err)
@@ -198,20 +406,17 @@ func (app *App) RequirePermission(
 			// Retrieve the user from the request context
 			user := app.ContextGetUser(r)
 
-			// Get the slice of permissions for the user
-			user, err := repository.GetByID(r.Context(), user.ID)
+			// Check if the permissions of the user include the required permissions.
+			// If they don't, then return a 403 Forbidden response.
+			permitted, err := app.HasPermissions(r.Context(), repository, user.ID, codes...)
 			if err != nil {
 				app.ServerErrorResponse(w, r, err)
 				return
 			}
 
-			// Check if the slice includes the required permission. If it doesn't, then
-			// return a 403 Forbidden response.
-			for _, code := range codes {
-				if !user.GetPermissions().Include(code) {
-					app.NotPermittedResponse(w, r)
-					return
-				}
+			if !permitted {
+				app.NotPermittedResponse(w, r)
+				return
 			}
 
 			// Otherwise they have the required permission so we call the next handler in
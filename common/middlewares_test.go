@@ -123,9 +123,19 @@ func TestLogger(t *testing.T) {
 		t.Errorf("Failed to redirect STDOUT")
 	}
 
+	stdout := os.Stdout
 	os.Stdout = w
 
+	defer func() {
+		os.Stdout = stdout
+	}()
+
+	// Closed once the whole output has been copied to the buffer
+	done := make(chan struct{})
+
 	go func() {
+		defer close(done)
+
 		scanner := bufio.NewScanner(rFile)
 
 		for scanner.Scan() {
@@ -174,8 +184,9 @@ func TestLogger(t *testing.T) {
 		t.Errorf("want body to equal %q, got %q", "OK", string(body))
 	}
 
-	// Reset output
+	// Reset output and wait for the output to be copied
 	w.Close()
+	<-done
 
 	t.Log(buf)
 
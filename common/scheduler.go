@@ -0,0 +1,28 @@
+package common
+
+import (
+	"github.com/PlayEconomy37/Play.Common/opentelemetry"
+	"github.com/PlayEconomy37/Play.Common/scheduler"
+)
+
+// NewScheduler creates a job scheduler which logs with the logger of the application and keeps track of
+// the runs of its jobs in metrics named after the service name
+//
+//	s := app.NewScheduler()
+//	s.Add("purge-expired-tokens", "0 3 * * *", tokens.DeleteExpired, scheduler.WithLock(locker, time.Minute))
+//	app.StartScheduler(s)
+func (app *App) NewScheduler(opts ...scheduler.Option) *scheduler.Scheduler {
+	metrics := opentelemetry.NewSchedulerMetrics(app.Config.ServiceName, app.metricsRegisterer(), app.durationBuckets())
+
+	return scheduler.New(append([]scheduler.Option{
+		scheduler.WithLogger(app.Logger),
+		scheduler.WithMetrics(metrics),
+	}, opts...)...)
+}
+
+// StartScheduler runs the jobs of the given scheduler in a background goroutine tracked by the application
+// WaitGroup. When the application shuts down, no new run is started and the running jobs are given the
+// drain timeout of the scheduler (scheduler.DrainTimeout by default) to complete.
+func (app *App) StartScheduler(s *scheduler.Scheduler) {
+	app.Background(app.consumersContext(), s.Run)
+}
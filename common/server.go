@@ -3,40 +3,35 @@ package common
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 )
 
-// Serve creates and starts an HTTP server
+// Serve creates and starts an HTTP server with the server settings of the configuration.
+// HTTPS is served if a TLS certificate is configured.
 func (app *App) Serve(router http.Handler) error {
-	// Initialize a tls.Config struct to hold the non-default TLS settings we want
-	// the server to use
-	tlsConfig := &tls.Config{
-		// Go’s favored cipher suites are given preference and we help increase the likelihood that
-		// a strong cipher suite which also supports forward secrecy is used
-		PreferServerCipherSuites: true,
-
-		// Specify which elliptic curves should be given preference during the TLS handshake.
-		// Go supports a few elliptic curves, but as of Go 1.11 only tls.CurveP256 and tls.X25519
-		// have assembly implementations. The others are very CPU intensive, so omitting them helps
-		// ensure that our server will remain performant under heavy loads.
-		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256},
+	server, err := app.newServer(router)
+	if err != nil {
+		return err
 	}
 
-	// Declare a HTTP server
-	server := &http.Server{
-		Addr:         app.Config.Address,
-		Handler:      router,
-		ErrorLog:     log.New(app.Logger, "", 0), // The "" and 0 indicate that the logger.Logger instance should not use a prefix or any flags
-		IdleTimeout:  time.Minute,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 30 * time.Second,
-		TLSConfig:    tlsConfig,
+	tlsCfg := app.Config.TLS
+	useTLS := tlsCfg.CertFile != "" && tlsCfg.KeyFile != ""
+
+	// Register the service so that other services can discover it
+	var deregister func(ctx context.Context) error
+	if app.Config.Discovery.Register {
+		if deregister, err = app.registerService(server.Addr, useTLS); err != nil {
+			return err
+		}
 	}
 
 	// Create a shutdownError channel. We will use this to receive any errors returned
@@ -64,42 +59,25 @@ func (app *App) Serve(router http.Handler) error {
 			"signal": sig.String(),
 		})
 
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-
-		// Call Shutdown() on our server, passing in the context we just made.
-		// Shutdown() will return nil if the graceful shutdown was successful, or an
-		// error (which may happen because of a problem closing the listeners, or
-		// because the shutdown didn't complete before the 5-second context deadline is
-		// hit). We relay this return value to the shutdownError channel.
-		err := server.Shutdown(ctx)
-		if err != nil {
-			shutdownError <- err
-		}
-
-		// Log a message to say that we're waiting for any background goroutines to
-		// complete their tasks.
-		app.Logger.Info("Completing background tasks", map[string]string{
-			"addr": server.Addr,
-		})
-
-		// Call Wait() to block until our WaitGroup counter is zero --- essentially
-		// blocking until the background goroutines have finished. Then we return nil on
-		// the shutdownError channel, to indicate that the shutdown completed without
-		// any issues.
-		app.WaitGroup.Wait()
-		shutdownError <- nil
+		// Relay the result of the graceful shutdown to the shutdownError channel
+		shutdownError <- app.shutdown(server, server.Addr, deregister)
 	}()
 
 	app.Logger.Info("Starting server", map[string]string{
 		"addr": server.Addr,
+		"tls":  strconv.FormatBool(useTLS),
 	})
 
 	// Calling Shutdown() on our server will cause ListenAndServe() to immediately
 	// return a http.ErrServerClosed error. So if we see this error, it is actually a
 	// good thing and an indication that the graceful shutdown has started. So we check
 	// specifically for this, only returning the error if it is NOT http.ErrServerClosed.
-	err := server.ListenAndServe()
+	if useTLS {
+		err = server.ListenAndServeTLS(tlsCfg.CertFile, tlsCfg.KeyFile)
+	} else {
+		err = server.ListenAndServe()
+	}
+
 	if !errors.Is(err, http.ErrServerClosed) {
 		return err
 	}
@@ -120,3 +98,141 @@ func (app *App) Serve(router http.Handler) error {
 
 	return nil
 }
+
+// httpShutdowner is the part of http.Server stopped by the graceful shutdown
+type httpShutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+// shutdown gracefully stops the given HTTP server listening on the given address, the consumers and the background goroutines, then
+// runs the shutdown hooks. Every step runs even if stopping the HTTP server fails, i.e. when in-flight
+// requests don't complete in time, and the error of the HTTP server is returned at the end.
+func (app *App) shutdown(server httpShutdowner, addr string, deregister func(ctx context.Context) error) error {
+	// The shutdown budget is the time given to in-flight requests followed by the time
+	// given to consumers to finish in-flight messages, so that the drain can't outlast it
+	shutdownTimeout := durationOrDefault(app.Config.Server.ShutdownTimeout, 5*time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout+app.consumerDrainTimeout())
+	defer cancel()
+
+	requestsCtx, cancelRequests := context.WithTimeout(ctx, shutdownTimeout)
+	defer cancelRequests()
+
+	// Deregister the service first so that other services stop sending it requests
+	if deregister != nil {
+		if err := deregister(requestsCtx); err != nil {
+			app.Logger.Error(err, nil)
+		}
+	}
+
+	// Call Shutdown() on our server, passing in the context we just made.
+	// Shutdown() will return nil if the graceful shutdown was successful, or an
+	// error (which may happen because of a problem closing the listeners, or
+	// because the shutdown didn't complete before the context deadline is hit).
+	// The error is kept until the rest of the shutdown has completed.
+	err := server.Shutdown(requestsCtx)
+
+	// Stop receiving new messages from the message broker. Consumers finish handling
+	// in-flight messages within the rest of the shutdown budget before the background
+	// goroutines below complete.
+	app.StopConsumers(ctx)
+
+	// Log a message to say that we're waiting for any background goroutines to
+	// complete their tasks.
+	app.Logger.Info("Completing background tasks", map[string]string{
+		"addr": addr,
+	})
+
+	// Call Wait() to block until our WaitGroup counter is zero --- essentially
+	// blocking until the background goroutines have finished.
+	app.WaitGroup.Wait()
+
+	// Release the resources registered with OnShutdown, such as the tracer provider whose
+	// buffered spans would otherwise be lost
+	app.runShutdownHooks()
+
+	return err
+}
+
+// newServer creates the HTTP server serving the given router with the server and TLS settings of the configuration
+func (app *App) newServer(router http.Handler) (*http.Server, error) {
+	serverCfg := app.Config.Server
+
+	tlsConfig, err := app.serverTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	readTimeout := durationOrDefault(serverCfg.ReadTimeout, 10*time.Second)
+
+	// Declare a HTTP server
+	server := &http.Server{
+		Addr:              app.Config.Address,
+		Handler:           router,
+		ErrorLog:          log.New(app.Logger, "", 0), // The "" and 0 indicate that the logger.Logger instance should not use a prefix or any flags
+		IdleTimeout:       durationOrDefault(serverCfg.IdleTimeout, time.Minute),
+		ReadTimeout:       readTimeout,
+		ReadHeaderTimeout: durationOrDefault(serverCfg.ReadHeaderTimeout, readTimeout),
+		WriteTimeout:      durationOrDefault(serverCfg.WriteTimeout, 30*time.Second),
+		MaxHeaderBytes:    serverCfg.MaxHeaderBytes, // http.DefaultMaxHeaderBytes if zero
+		TLSConfig:         tlsConfig,
+	}
+
+	return server, nil
+}
+
+// serverTLSConfig creates the TLS configuration of the HTTP server from the TLS settings of the configuration
+func (app *App) serverTLSConfig() (*tls.Config, error) {
+	tlsCfg := app.Config.TLS
+
+	// Initialize a tls.Config struct to hold the non-default TLS settings we want
+	// the server to use
+	tlsConfig := &tls.Config{
+		// Go’s favored cipher suites are given preference and we help increase the likelihood that
+		// a strong cipher suite which also supports forward secrecy is used
+		PreferServerCipherSuites: true,
+
+		// Specify which elliptic curves should be given preference during the TLS handshake.
+		// Go supports a few elliptic curves, but as of Go 1.11 only tls.CurveP256 and tls.X25519
+		// have assembly implementations. The others are very CPU intensive, so omitting them helps
+		// ensure that our server will remain performant under heavy loads.
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256},
+
+		MinVersion: tls.VersionTLS12,
+	}
+
+	switch tlsCfg.MinVersion {
+	case "", "1.2":
+	case "1.3":
+		tlsConfig.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("unsupported minimum TLS version %q", tlsCfg.MinVersion)
+	}
+
+	// Require client certificates signed by the configured CA (mutual TLS)
+	if tlsCfg.ClientCAFile != "" {
+		clientCA, err := os.ReadFile(tlsCfg.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(clientCA) {
+			return nil, fmt.Errorf("no certificate found in %s", tlsCfg.ClientCAFile)
+		}
+
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
+
+// durationOrDefault returns the given duration, or the default duration if it isn't positive
+func durationOrDefault(duration, defaultDuration time.Duration) time.Duration {
+	if duration <= 0 {
+		return defaultDuration
+	}
+
+	return duration
+}
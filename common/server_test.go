@@ -0,0 +1,84 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+
+	"github.com/PlayEconomy37/Play.Common/configuration"
+	"github.com/PlayEconomy37/Play.Common/events"
+	"github.com/PlayEconomy37/Play.Common/logger"
+)
+
+// failingServer is an HTTP server whose in-flight requests don't complete before the shutdown deadline
+type failingServer struct{}
+
+func (failingServer) Shutdown(ctx context.Context) error {
+	return context.DeadlineExceeded
+}
+
+// drainingConsumer is a consumer handling an in-flight message until it is stopped
+type drainingConsumer struct {
+	events.Consumer
+
+	drained atomic.Bool
+	closed  atomic.Bool
+}
+
+func (c *drainingConsumer) Start(ctx context.Context) error {
+	<-ctx.Done()
+	c.drained.Store(true)
+
+	return nil
+}
+
+func (c *drainingConsumer) Close() error {
+	c.closed.Store(true)
+	return nil
+}
+
+func TestShutdownDrainsConsumersWhenServerShutdownFails(t *testing.T) {
+	app := &App{Config: &configuration.Config{}, Logger: logger.New(io.Discard, logger.LevelInfo)}
+
+	consumer := &drainingConsumer{}
+	app.StartConsumer(consumer)
+
+	err := app.shutdown(failingServer{}, "localhost:0", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want %v; got %v", context.DeadlineExceeded, err)
+	}
+
+	if !consumer.drained.Load() {
+		t.Error("want the consumer to finish its in-flight messages")
+	}
+
+	if !consumer.closed.Load() {
+		t.Error("want the consumer to be closed")
+	}
+}
+
+func TestShutdownRunsHooksWhenServerShutdownFails(t *testing.T) {
+	app := &App{Config: &configuration.Config{}, Logger: logger.New(io.Discard, logger.LevelInfo)}
+
+	var calls []string
+	app.OnShutdown(func(ctx context.Context) error {
+		calls = append(calls, "tracer provider")
+		return nil
+	})
+	app.OnShutdown(func(ctx context.Context) error {
+		calls = append(calls, "meter provider")
+		return errors.New("unable to export metrics")
+	})
+
+	err := app.shutdown(failingServer{}, "localhost:0", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want %v; got %v", context.DeadlineExceeded, err)
+	}
+
+	// Hooks run in the reverse order of their registration, even after a failing one
+	if len(calls) != 2 || calls[0] != "meter provider" || calls[1] != "tracer provider" {
+		t.Errorf("want [meter provider tracer provider]; got %v", calls)
+	}
+}
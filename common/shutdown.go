@@ -0,0 +1,58 @@
+package common
+
+import (
+	"context"
+	"time"
+
+	"github.com/PlayEconomy37/Play.Common/opentelemetry"
+)
+
+// ShutdownTimeout is the time given to the shutdown hooks to complete during the graceful shutdown
+const ShutdownTimeout = 10 * time.Second
+
+// ShutdownHook is a function called during the graceful shutdown to release a resource,
+// such as flushing buffered telemetry or closing a connection
+type ShutdownHook func(ctx context.Context) error
+
+// OnShutdown registers a hook which is called by Serve during the graceful shutdown, once the HTTP
+// server has stopped and the background goroutines have completed, even if in-flight requests didn't
+// complete in time. Hooks are called in the reverse
+// order of their registration, so that resources are released before the resources they depend on.
+func (app *App) OnShutdown(hook ShutdownHook) {
+	app.shutdownMu.Lock()
+	defer app.shutdownMu.Unlock()
+
+	app.shutdownHooks = append(app.shutdownHooks, hook)
+}
+
+// runShutdownHooks calls the registered shutdown hooks, which share the ShutdownTimeout.
+// Errors are logged so that a failing hook doesn't prevent the others from running.
+func (app *App) runShutdownHooks() {
+	app.shutdownMu.Lock()
+	hooks := app.shutdownHooks
+	app.shutdownHooks = nil
+	app.shutdownMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i](ctx); err != nil {
+			app.Logger.Error(err, nil)
+		}
+	}
+}
+
+// SetupTracing sets up Opentelemetry with the tracing configuration of the application, sets the
+// application tracer and registers a shutdown hook which exports the buffered spans on exit
+func (app *App) SetupTracing(isTest bool) error {
+	tracerProvider, err := opentelemetry.SetupTracer(app.Config, isTest)
+	if err != nil {
+		return err
+	}
+
+	app.Tracer = tracerProvider.Tracer(app.Config.ServiceName)
+	app.OnShutdown(tracerProvider.Shutdown)
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	tokenRequestTimeout = 10 * time.Second // Timeout of a request to the token endpoint
+	tokenExpiryLeeway   = time.Minute      // Tokens are renewed this long before they expire
+)
+
+// ErrMissingClientCredentials is returned when a token source is created without a client ID or token URL
+var ErrMissingClientCredentials = errors.New("missing client credentials")
+
+// TokenSource is an interface which provides the access tokens a service sends to the services it calls
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// ClientCredentialsTokenSource is a TokenSource requesting access tokens from the identity provider with
+// the OAuth2 client credentials flow, which are reused until they are about to expire
+type ClientCredentialsTokenSource struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       []string
+	client       *http.Client
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewClientCredentialsTokenSource creates a ClientCredentialsTokenSource requesting access tokens with the
+// given scopes from the given token endpoint, authenticated with the given client credentials
+func NewClientCredentialsTokenSource(tokenURL, clientID, clientSecret string, scopes ...string) (*ClientCredentialsTokenSource, error) {
+	if tokenURL == "" || clientID == "" {
+		return nil, ErrMissingClientCredentials
+	}
+
+	return &ClientCredentialsTokenSource{
+		tokenURL:     tokenURL,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		scopes:       scopes,
+		client:       &http.Client{Timeout: tokenRequestTimeout},
+	}, nil
+}
+
+// NewTokenSource creates a ClientCredentialsTokenSource with the given scopes from the Auth section of the
+// configuration. The token URL defaults to the token endpoint of the authority.
+func (app *App) NewTokenSource(scopes ...string) (*ClientCredentialsTokenSource, error) {
+	tokenURL := app.Config.Auth.TokenURL
+	if tokenURL == "" && app.Config.Authority != "" {
+		tokenURL = strings.TrimSuffix(app.Config.Authority, "/") + "/connect/token"
+	}
+
+	return NewClientCredentialsTokenSource(tokenURL, app.Config.Auth.ClientID, app.Config.Auth.ClientSecret, scopes...)
+}
+
+// Token returns the current access token, which is requested again if it is about to expire
+func (s *ClientCredentialsTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Now().Before(s.expiresAt) {
+		return s.token, nil
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(s.scopes) > 0 {
+		form.Set("scope", strings.Join(s.scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(s.clientID), url.QueryEscape(s.clientSecret))
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return "", fmt.Errorf("unexpected status code %d from %s: %s", res.StatusCode, req.URL.Host, message)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"` // Seconds
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return "", err
+	}
+
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("no access token in the response of %s", req.URL.Host)
+	}
+
+	s.token = token.AccessToken
+	s.expiresAt = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - tokenExpiryLeeway)
+
+	return s.token, nil
+}
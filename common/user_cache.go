@@ -0,0 +1,41 @@
+package common
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/PlayEconomy37/Play.Common/cache"
+	"github.com/PlayEconomy37/Play.Common/database"
+)
+
+// CachedAuthRepository is an AuthRepository caching the users of another AuthRepository, so that
+// authenticating a request doesn't query the database every time. Since permissions are then read
+// from the cache, services should call Invalidate when they consume the UserUpdated event of a user,
+// otherwise revoked permissions apply once the cached user expires.
+type CachedAuthRepository struct {
+	repository AuthRepository
+	cache      *cache.Cache[database.User]
+}
+
+// NewCachedAuthRepository wraps the given repository with the given cache
+//
+//	users := common.NewCachedAuthRepository(usersRepository, cache.New[database.User](store, "users", time.Minute))
+//	router.Use(app.Authenticate(users, publicKey))
+func NewCachedAuthRepository(repository AuthRepository, c *cache.Cache[database.User]) *CachedAuthRepository {
+	return &CachedAuthRepository{
+		repository: repository,
+		cache:      c,
+	}
+}
+
+// GetByID returns the user with the given ID from the cache, or from the repository if it isn't cached
+func (r *CachedAuthRepository) GetByID(ctx context.Context, id int64) (database.User, error) {
+	return r.cache.GetOrLoad(ctx, strconv.FormatInt(id, 10), func(ctx context.Context) (database.User, error) {
+		return r.repository.GetByID(ctx, id)
+	})
+}
+
+// Invalidate removes the user with the given ID from the cache
+func (r *CachedAuthRepository) Invalidate(ctx context.Context, id int64) error {
+	return r.cache.Delete(ctx, strconv.FormatInt(id, 10))
+}
@@ -1,47 +1,385 @@
 package configuration
 
 import (
+	"flag"
+	"os"
+	"time"
+
 	"github.com/knadh/koanf"
 	"github.com/knadh/koanf/parsers/json"
+	"github.com/knadh/koanf/providers/confmap"
 	"github.com/knadh/koanf/providers/env"
 	"github.com/knadh/koanf/providers/file"
 )
 
 // Config is a struct that holds the application configuration
 type Config struct {
-	Address     string `koanf:"Address"`
-	ServiceName string `koanf:"ServiceName"`
-	Authority   string `koanf:"Authority"`
-	DB          struct {
+	Address        string `koanf:"Address"`
+	ServiceName    string `koanf:"ServiceName"`
+	ServiceVersion string `koanf:"ServiceVersion"` // i.e. "1.4.2" or a commit SHA
+	Environment    string `koanf:"Environment"`    // i.e. "development", "staging" or "production"
+	Authority      string `koanf:"Authority"`
+	DB             struct {
 		Dsn           string `koanf:"Dsn"`
 		MaxIdleTimeMS int    `koanf:"MaxIdleTimeMs"`
 		MaxOpenConns  int    `koanf:"MaxOpenConns"`
 		MaxIdleConns  int    `koanf:"MaxIdleConns"`
+		Encryption    struct {
+			Enabled           bool   `koanf:"Enabled"`
+			KMSProvider       string `koanf:"KMSProvider"`
+			KeyVaultNamespace string `koanf:"KeyVaultNamespace"`
+			KeyAltName        string `koanf:"KeyAltName"`
+			LocalMasterKey    string `koanf:"LocalMasterKey"`
+			AWS               struct {
+				AccessKeyID     string `koanf:"AccessKeyId"`
+				SecretAccessKey string `koanf:"SecretAccessKey"`
+				Region          string `koanf:"Region"`
+				KeyARN          string `koanf:"KeyArn"`
+			} `koanf:"AWS"`
+		} `koanf:"Encryption"`
 	} `koanf:"DB"`
 	SMTP struct {
-		Host     string `koanf:"Host"`
-		Port     int    `koanf:"Port"`
-		Username string `koanf:"Username"`
-		Password string `koanf:"Password"`
-		Sender   string `koanf:"Sender"`
+		Host               string `koanf:"Host"`
+		Port               int    `koanf:"Port"`
+		Username           string `koanf:"Username"`
+		Password           string `koanf:"Password"`
+		Sender             string `koanf:"Sender"`
+		Encryption         string `koanf:"Encryption"`         // none (default), starttls or tls
+		Authentication     string `koanf:"Authentication"`     // plain (default), login, cram-md5 or none
+		InsecureSkipVerify bool   `koanf:"InsecureSkipVerify"` // Development only
+		DKIM               struct {
+			Domain         string `koanf:"Domain"` // DKIM signing is disabled if empty
+			Selector       string `koanf:"Selector"`
+			PrivateKey     string `koanf:"PrivateKey"`     // PEM encoded RSA key
+			PrivateKeyFile string `koanf:"PrivateKeyFile"` // Used if PrivateKey is empty
+		} `koanf:"DKIM"`
 	} `koanf:"SMTP"`
-	RabbitMQ struct {
-		Host     string `koanf:"Host"`
-		Port     int    `koanf:"Port"`
-		User     string `koanf:"User"`
-		Password string `koanf:"Password"`
+	Mail struct {
+		Provider string `koanf:"Provider"` // SMTP (default), SendGrid or SES
+		SendGrid struct {
+			APIKey string `koanf:"ApiKey"`
+		} `koanf:"SendGrid"`
+		SES struct {
+			Region          string `koanf:"Region"`
+			AccessKeyID     string `koanf:"AccessKeyId"`
+			SecretAccessKey string `koanf:"SecretAccessKey"`
+			SessionToken    string `koanf:"SessionToken"`
+		} `koanf:"SES"`
+	} `koanf:"Mail"`
+	MessageBroker string `koanf:"MessageBroker"` // RabbitMQ (default), AzureServiceBus or Kafka
+	RabbitMQ      struct {
+		Host      string                            `koanf:"Host"`
+		Port      int                               `koanf:"Port"`
+		User      string                            `koanf:"User"`
+		Password  string                            `koanf:"Password"`
+		Consumers map[string]RabbitMQConsumerConfig `koanf:"Consumers"` // Keyed by queue name
 	} `koanf:"RabbitMQ"`
+	AzureServiceBus struct {
+		ConnectionString string `koanf:"ConnectionString"`
+	} `koanf:"AzureServiceBus"`
+	Kafka struct {
+		Brokers        []string      `koanf:"Brokers"`
+		CommitInterval time.Duration `koanf:"CommitInterval"` // i.e. "1s", offsets are committed after every message if empty
+	} `koanf:"Kafka"`
+	Redis struct {
+		Address  string `koanf:"Address"`
+		Password string `koanf:"Password"`
+		DB       int    `koanf:"DB"`
+	} `koanf:"Redis"`
+	Cache struct {
+		Store      string        `koanf:"Store"`      // Memory (default) or Redis
+		MaxEntries int           `koanf:"MaxEntries"` // Entries kept by the memory store, 10000 if empty
+		TTL        time.Duration `koanf:"Ttl"`        // i.e. "5m", time to live of the cached values, 5 minutes if empty
+	} `koanf:"Cache"`
+	Pagination struct {
+		DefaultPageSize int `koanf:"DefaultPageSize"` // 20 if empty
+		MaxPageSize     int `koanf:"MaxPageSize"`     // 100 if empty
+		MaxPage         int `koanf:"MaxPage"`         // 10 million if empty
+	} `koanf:"Pagination"`
+	Server struct {
+		ReadTimeout       time.Duration `koanf:"ReadTimeout"`       // i.e. "10s", 10 seconds if empty
+		ReadHeaderTimeout time.Duration `koanf:"ReadHeaderTimeout"` // ReadTimeout if empty
+		WriteTimeout      time.Duration `koanf:"WriteTimeout"`      // 30 seconds if empty
+		IdleTimeout       time.Duration `koanf:"IdleTimeout"`       // One minute if empty
+		MaxHeaderBytes    int           `koanf:"MaxHeaderBytes"`    // 1 MB if empty
+		ShutdownTimeout   time.Duration `koanf:"ShutdownTimeout"`   // Time given to in-flight requests on shutdown, 5 seconds if empty
+		DrainTimeout      time.Duration `koanf:"DrainTimeout"`      // Time then given to consumers to finish in-flight messages, 30 seconds if empty
+	} `koanf:"Server"`
+	TLS struct {
+		CertFile     string `koanf:"CertFile"`     // PEM encoded, HTTPS is served if CertFile and KeyFile are set
+		KeyFile      string `koanf:"KeyFile"`      // PEM encoded
+		MinVersion   string `koanf:"MinVersion"`   // 1.2 (default) or 1.3
+		ClientCAFile string `koanf:"ClientCAFile"` // PEM encoded, client certificates are required and verified against it if set
+	} `koanf:"TLS"`
+	Auth struct {
+		Issuer              string        `koanf:"Issuer"`              // Issuer of access tokens, Authority if empty
+		Audiences           []string      `koanf:"Audiences"`           // Access tokens must be intended for one of them, "http://localhost:3000" if empty
+		JWKSURL             string        `koanf:"JwksUrl"`             // i.e. "https://identity/.well-known/jwks.json", replaces the RSA public key if set
+		JWKSRefreshInterval time.Duration `koanf:"JwksRefreshInterval"` // i.e. "15m", one hour if empty, keys are also fetched again for unknown signatures
+		ClockSkew           time.Duration `koanf:"ClockSkew"`           // i.e. "30s", leeway on the expiry and not before times of access tokens
+
+		// Credentials of the service, which calls other services with access tokens of the client credentials flow
+		TokenURL     string `koanf:"TokenUrl"` // i.e. "https://identity/connect/token", Authority + "/connect/token" if empty
+		ClientID     string `koanf:"ClientId"` // No access token is requested if empty
+		ClientSecret string `koanf:"ClientSecret"`
+	} `koanf:"Auth"`
+	CORS struct {
+		AllowedOrigins   []string      `koanf:"AllowedOrigins"`   // i.e. ["https://play.example.com"] or ["*"], cross-origin requests aren't allowed if empty
+		AllowedMethods   []string      `koanf:"AllowedMethods"`   // Preflight requests, GET, POST, PUT, PATCH and DELETE if empty
+		AllowedHeaders   []string      `koanf:"AllowedHeaders"`   // Preflight requests, Authorization and Content-Type if empty
+		ExposedHeaders   []string      `koanf:"ExposedHeaders"`   // Response headers readable by browsers, i.e. ["Link"]
+		AllowCredentials bool          `koanf:"AllowCredentials"` // Allows cookies and HTTP authentication
+		MaxAge           time.Duration `koanf:"MaxAge"`           // i.e. "10m", time browsers cache preflight responses, browser default if empty
+	} `koanf:"CORS"`
+	RateLimit struct {
+		Enabled bool    `koanf:"Enabled"`
+		RPS     float64 `koanf:"Rps"`   // Requests per second of each client IP address, 2 if empty
+		Burst   int     `koanf:"Burst"` // Requests allowed at once, 4 if empty
+	} `koanf:"RateLimit"`
+	GRPC struct {
+		Address    string `koanf:"Address"`    // i.e. ":5001", ":50051" if empty
+		Reflection bool   `koanf:"Reflection"` // Registers the reflection service (i.e. for grpcurl) outside of production
+
+		Clients map[string]GRPCClientConfig `koanf:"Clients"` // Keyed by target service name, i.e. "inventory"
+	} `koanf:"GRPC"`
+	Tracing struct {
+		Exporter   string            `koanf:"Exporter"`   // Jaeger (default), OTLP, OTLPHttp, Stdout or None
+		Endpoint   string            `koanf:"Endpoint"`   // i.e. "otel-collector:4317" or "https://api.honeycomb.io", exporter default if empty
+		Headers    map[string]string `koanf:"Headers"`    // Sent with every export, i.e. API keys of hosted backends
+		Insecure   bool              `koanf:"Insecure"`   // Disables TLS for OTLP exporters
+		CACertFile string            `koanf:"CACertFile"` // PEM encoded CA certificate of the backend, system pool if empty
+
+		// Sampling of new traces, traces started by another service follow its decision
+		SampleRatio   float64  `koanf:"SampleRatio"`   // Between 0 and 1, every trace is sampled if empty
+		SampleErrors  bool     `koanf:"SampleErrors"`  // Exports spans ending with an error even if their trace isn't sampled
+		SampledRoutes []string `koanf:"SampledRoutes"` // Always sampled, i.e. "/purchase" or "/items/*"
+
+		// Added to the service name, version and environment on every span and metric, i.e. {"team": "inventory"}
+		ResourceAttributes map[string]string `koanf:"ResourceAttributes"`
+	} `koanf:"Tracing"`
+	Metrics struct {
+		DurationBuckets []float64     `koanf:"DurationBuckets"` // Seconds, i.e. [0.01, 0.05, 0.1, 0.5, 1], Prometheus defaults if empty
+		Exporter        string        `koanf:"Exporter"`        // Prometheus (default), OTLP or OTLPHttp, metrics are exposed to Prometheus in every case
+		Endpoint        string        `koanf:"Endpoint"`        // OTLP endpoint, i.e. "otel-collector:4317", exporter default if empty
+		ExportInterval  time.Duration `koanf:"ExportInterval"`  // i.e. "30s", one minute if empty
+	} `koanf:"Metrics"`
+	Logging struct {
+		Level string          `koanf:"Level"` // debug, info (default), warning, error or off, sinks can only raise it
+		Sinks []LogSinkConfig `koanf:"Sinks"` // JSON entries written to stdout if empty
+
+		// Masking of sensitive data, obvious secrets such as JWTs and API keys are always masked unless disabled
+		RedactedKeys     []string `koanf:"RedactedKeys"`     // Masked properties and query parameters, i.e. ["email", "iban"], logger.DefaultRedactedKeys if empty
+		DisableRedaction bool     `koanf:"DisableRedaction"` // Development only
+
+		LogConfig bool `koanf:"LogConfig"` // Logs the effective configuration with sensitive settings masked at startup
+	} `koanf:"Logging"`
+	Secrets struct {
+		CacheTTL time.Duration `koanf:"CacheTTL"` // i.e. "15m", secrets are fetched again once expired when the configuration is reloaded, 5 minutes if empty
+		Vault    struct {
+			Address   string `koanf:"Address"`   // i.e. "https://vault:8200", VAULT_ADDR if empty
+			Token     string `koanf:"Token"`     // VAULT_TOKEN if empty
+			Namespace string `koanf:"Namespace"` // Vault Enterprise only, VAULT_NAMESPACE if empty
+		} `koanf:"Vault"`
+		AWS struct {
+			Region          string `koanf:"Region"`          // AWS_REGION if empty
+			AccessKeyID     string `koanf:"AccessKeyId"`     // AWS_ACCESS_KEY_ID if empty
+			SecretAccessKey string `koanf:"SecretAccessKey"` // AWS_SECRET_ACCESS_KEY if empty
+			SessionToken    string `koanf:"SessionToken"`    // AWS_SESSION_TOKEN if empty
+		} `koanf:"AWS"`
+		Azure struct {
+			TenantID     string `koanf:"TenantId"`     // AZURE_TENANT_ID if empty
+			ClientID     string `koanf:"ClientId"`     // AZURE_CLIENT_ID if empty
+			ClientSecret string `koanf:"ClientSecret"` // AZURE_CLIENT_SECRET if empty
+		} `koanf:"Azure"`
+	} `koanf:"Secrets"`
+	Remote struct {
+		Type     string `koanf:"Type"`     // Consul or Etcd, settings are only read from files and environment variables if empty
+		Address  string `koanf:"Address"`  // i.e. "http://consul:8500", CONSUL_HTTP_ADDR or the local agent if empty for Consul
+		Prefix   string `koanf:"Prefix"`   // i.e. "config/catalog/", the key "config/catalog/RateLimit/Rps" sets RateLimit.Rps
+		Token    string `koanf:"Token"`    // Consul ACL token, CONSUL_HTTP_TOKEN if empty
+		Username string `koanf:"Username"` // etcd only, if authentication is enabled
+		Password string `koanf:"Password"` // etcd only
+	} `koanf:"Remote"`
+	Discovery struct {
+		Type                string            `koanf:"Type"`                // Consul, services are neither registered nor discovered if empty
+		Address             string            `koanf:"Address"`             // i.e. "http://consul:8500", CONSUL_HTTP_ADDR or the local agent if empty
+		Token               string            `koanf:"Token"`               // Consul ACL token, CONSUL_HTTP_TOKEN if empty
+		Register            bool              `koanf:"Register"`            // Registers the service when Serve starts and deregisters it on shutdown
+		ServiceAddress      string            `koanf:"ServiceAddress"`      // Address advertised to other services, the host name if empty
+		Tags                []string          `koanf:"Tags"`                // i.e. ["v1"]
+		HealthCheckPath     string            `koanf:"HealthCheckPath"`     // Polled by Consul, "/readyz" if empty
+		HealthCheckInterval time.Duration     `koanf:"HealthCheckInterval"` // 10 seconds if empty
+		DeregisterAfter     time.Duration     `koanf:"DeregisterAfter"`     // Unhealthy instances are removed after, one minute if empty
+		RefreshInterval     time.Duration     `koanf:"RefreshInterval"`     // Addresses of the discovered services are refreshed every, 30 seconds if empty
+		Services            map[string]string `koanf:"Services"`            // Registered names keyed by logical service name, i.e. {"inventory": "play-inventory"}, the logical name if missing
+	} `koanf:"Discovery"`
 	RSA struct {
 		PublicKey  string `koanf:"PublicKey"`
 		PrivateKey string `koanf:"PrivateKey"`
 	} `koanf:"RSA"`
 }
 
-// LoadConfig reads configuration from a given file and from environment variables
-// (i.e. SMTP__Host=...).
-func LoadConfig(filePath string) (*Config, error) {
+// RabbitMQConsumerConfig is a struct that holds the configuration of a single RabbitMQ consumer
+type RabbitMQConsumerConfig struct {
+	Prefetch     int  `koanf:"Prefetch"`
+	Workers      int  `koanf:"Workers"`
+	OrderedByKey bool `koanf:"OrderedByKey"`
+}
+
+// GRPCClientConfig is a struct that holds the configuration of the gRPC client of a single service
+type GRPCClientConfig struct {
+	Target         string        `koanf:"Target"`         // i.e. "dns:///inventory:5001", or "discovery:///inventory" with Discovery.Type
+	Timeout        time.Duration `koanf:"Timeout"`        // Deadline of the unary calls made without one, 10 seconds if empty
+	MaxAttempts    int           `koanf:"MaxAttempts"`    // Attempts of the calls failing with Unavailable, 3 if empty, 1 disables retries
+	InitialBackoff time.Duration `koanf:"InitialBackoff"` // Randomized delay before the first retry, doubled at every retry, 100 milliseconds if empty
+	MaxBackoff     time.Duration `koanf:"MaxBackoff"`     // One second if empty
+	Scopes         []string      `koanf:"Scopes"`         // Scopes of the access token sent with every call, i.e. ["inventory"]
+	Insecure       bool          `koanf:"Insecure"`       // Disables TLS, i.e. inside a service mesh
+	CACertFile     string        `koanf:"CACertFile"`     // PEM encoded CA certificate of the service, system pool if empty
+}
+
+// LogSinkConfig is a struct that holds the configuration of a single log sink
+type LogSinkConfig struct {
+	Type    string            `koanf:"Type"`    // Stdout (default), Stderr, File, Syslog or Loki
+	Level   string            `koanf:"Level"`   // Minimum level of the entries written to the sink, the level of the Logger if empty
+	Format  string            `koanf:"Format"`  // JSON (default) or Text, Stdout, Stderr and File only
+	Path    string            `koanf:"Path"`    // File only, i.e. "/var/log/play/audit.log"
+	Address string            `koanf:"Address"` // Syslog server, i.e. "udp://syslog:514" (local daemon if empty), or Loki push URL
+	Tag     string            `koanf:"Tag"`     // Syslog only, service name if empty
+	Labels  map[string]string `koanf:"Labels"`  // Loki only, added to the service name label of the stream
+
+	// Rotation of File sinks, the file grows forever if neither MaxSize nor RotateEvery is set
+	MaxSize     int           `koanf:"MaxSize"`     // Megabytes, rotated when it would be exceeded, 100 if only RotateEvery is set
+	RotateEvery time.Duration `koanf:"RotateEvery"` // i.e. "24h", rotated at every multiple of the interval since the epoch (midnight UTC for days)
+	MaxBackups  int           `koanf:"MaxBackups"`  // Rotated files kept, all of them if empty
+	MaxAge      int           `koanf:"MaxAge"`      // Days rotated files are kept, forever if empty
+	Compress    bool          `koanf:"Compress"`    // Gzips rotated files
+}
+
+// LoadOption is a function which adds a configuration source to the file and the environment variables
+type LoadOption func(opts *loadOptions)
+
+// loadOptions is a struct that holds the extra configuration sources
+type loadOptions struct {
+	environment string // Name of the environment overlay, APP_ENV if empty
+	flags       *flag.FlagSet
+}
+
+// LoadConfig reads configuration from a given file, the overlay of the environment selected with APP_ENV
+// (i.e. config.production.json next to config.json), the remote store selected in the Remote section
+// (Consul KV or etcd) and environment variables (i.e. SMTP__Host=...). Values referencing secrets (i.e. "vault:secret/data/smtp#password")
+// are replaced with the secrets fetched from the secret stores.
+func LoadConfig(filePath string, opts ...LoadOption) (*Config, error) {
+	config, _, err := load(filePath, newSecretCache(), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// LoadConfigInto reads configuration from a given file and from environment variables into a service-specific
+// configuration struct, so that service settings live in the same file and follow the same environment
+// variable overrides. The struct embeds Config with the squash tag so that the common sections keep their keys:
+//
+//	type CatalogConfig struct {
+//		configuration.Config `koanf:",squash"`
+//		Catalog              struct {
+//			MaxItemsPerPage int `koanf:"MaxItemsPerPage"`
+//		} `koanf:"Catalog"`
+//	}
+//
+//	cfg, err := configuration.LoadConfigInto[CatalogConfig]("config.json")
+//	app := &common.App{Config: &cfg.Config}
+func LoadConfigInto[T any](filePath string, opts ...LoadOption) (*T, error) {
+	var config T
+
+	if _, err := loadInto(filePath, &config, newSecretCache(), opts); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// load reads configuration from a given file and from environment variables, and returns it
+// along with its flattened values (i.e. "SMTP.Host"), which are used to detect changes.
+// Secrets are fetched unless they are in the given cache.
+func load(filePath string, secrets *secretCache, opts []LoadOption) (*Config, map[string]any, error) {
 	var config Config
 
+	values, err := loadInto(filePath, &config, secrets, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &config, values, nil
+}
+
+// loadInto reads configuration from a given file, from environment variables and from the sources of the
+// given options into the given struct, resolving secret references, and returns its flattened values
+func loadInto(filePath string, config any, secrets *secretCache, opts []LoadOption) (map[string]any, error) {
+	configReader, err := newReader(filePath, newLoadOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := resolveSecrets(configReader, secrets); err != nil {
+		return nil, err
+	}
+
+	err = configReader.Unmarshal("", config)
+	if err != nil {
+		return nil, err
+	}
+
+	return configReader.All(), nil
+}
+
+// newLoadOptions returns the extra configuration sources of the given options
+func newLoadOptions(opts []LoadOption) loadOptions {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.environment == "" {
+		options.environment = os.Getenv(EnvironmentVariable)
+	}
+
+	return options
+}
+
+// newReader returns a koanf instance holding the configuration read from a given file, its environment
+// overlay, the remote store selected in the Remote section, environment variables and the sources of
+// the given options, in increasing order of precedence
+func newReader(filePath string, options loadOptions) (*koanf.Koanf, error) {
+	configReader, err := readSources(filePath, options, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// The remote store is configured with the Remote section, whose values can't be stored remotely
+	var cfg Config
+	if err := configReader.Unmarshal("Remote", &cfg.Remote); err != nil {
+		return nil, err
+	}
+
+	if cfg.Remote.Type == "" {
+		return configReader, nil
+	}
+
+	remote, err := remoteValues(&cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the sources again so that environment variables and flags take precedence over the remote store
+	return readSources(filePath, options, remote)
+}
+
+// readSources returns a koanf instance holding the configuration read from a given file, its environment
+// overlay, the given remote values, environment variables and the sources of the given options
+func readSources(filePath string, options loadOptions, remote map[string]any) (*koanf.Koanf, error) {
 	configReader := koanf.New(".")
 
 	// Load JSON config
@@ -49,6 +387,28 @@ func LoadConfig(filePath string) (*Config, error) {
 		return nil, err
 	}
 
+	// Load the JSON config of the environment, if any, and deep merge it into the loaded config
+	if options.environment != "" {
+		overlay := overlayPath(filePath, options.environment)
+
+		if _, err := os.Stat(overlay); err == nil {
+			if err := configReader.Load(file.Provider(overlay), json.Parser()); err != nil {
+				return nil, err
+			}
+		}
+
+		if !configReader.Exists("Environment") {
+			configReader.Load(confmap.Provider(map[string]any{"Environment": options.environment}, "."), nil)
+		}
+	}
+
+	// Load remote settings and merge into the loaded config
+	if len(remote) > 0 {
+		if err := configReader.Load(confmap.Provider(remote, "."), nil); err != nil {
+			return nil, err
+		}
+	}
+
 	// Load environment variables and merge into the loaded config
 	configReader.Load(
 		env.Provider(
@@ -59,10 +419,12 @@ func LoadConfig(filePath string) (*Config, error) {
 		nil,
 	)
 
-	err := configReader.Unmarshal("", &config)
-	if err != nil {
-		return nil, err
+	// Load flags which are set and merge into the loaded config
+	if options.flags != nil {
+		if err := configReader.Load(confmap.Provider(flagValues(options.flags), "."), nil); err != nil {
+			return nil, err
+		}
 	}
 
-	return &config, nil
+	return configReader, nil
 }
@@ -0,0 +1,81 @@
+package configuration
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// EnvironmentVariable is the environment variable which selects the environment overlay of the configuration
+const EnvironmentVariable = "APP_ENV"
+
+// WithEnvironment selects the environment overlay of the configuration (i.e. "production"
+// for config.production.json) in place of the APP_ENV environment variable
+func WithEnvironment(environment string) LoadOption {
+	return func(opts *loadOptions) {
+		opts.environment = environment
+	}
+}
+
+// overlayPath returns the path of the overlay of the given configuration file for the given environment,
+// i.e. "config/config.production.json" for "config/config.json"
+func overlayPath(filePath, environment string) string {
+	ext := filepath.Ext(filePath)
+	return strings.TrimSuffix(filePath, ext) + "." + environment + ext
+}
+
+// DumpConfig returns the effective configuration of the configuration struct T as indented JSON, once the
+// file, its environment overlay, environment variables and the sources of the given options have been
+// merged, so that it is possible to check which value won. Secret references aren't resolved, sensitive
+// settings are masked like with DumpEffective, and environment variables which aren't settings of T are left out.
+//
+//	dump, err := configuration.DumpConfig[configuration.Config]("config.json")
+func DumpConfig[T any](filePath string, opts ...LoadOption) ([]byte, error) {
+	configReader, err := newReader(filePath, newLoadOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := sectionKeys(reflect.TypeOf((*T)(nil)).Elem())
+
+	values := configReader.Raw()
+	for key := range values {
+		if !keys[key] {
+			delete(values, key)
+		}
+	}
+
+	return json.MarshalIndent(maskValues(values), "", "  ")
+}
+
+// sectionKeys returns the top-level keys of the given configuration struct type
+func sectionKeys(structType reflect.Type) map[string]bool {
+	keys := map[string]bool{}
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("koanf"), ",")
+
+		// Embedded sections with the squash option keep the keys of their settings
+		if field.Anonymous && options == "squash" && field.Type.Kind() == reflect.Struct {
+			for key := range sectionKeys(field.Type) {
+				keys[key] = true
+			}
+
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		keys[name] = true
+	}
+
+	return keys
+}
@@ -0,0 +1,124 @@
+package configuration
+
+import (
+	"flag"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// configFlag is a flag.Value which overrides the configuration value with the given key when it is set
+type configFlag struct {
+	key    string // i.e. "DB.Dsn"
+	isBool bool
+	value  string
+}
+
+// String returns the value of the flag
+func (f *configFlag) String() string {
+	if f == nil {
+		return ""
+	}
+
+	return f.value
+}
+
+// Set sets the value of the flag, which is converted to the type of the setting when the configuration is loaded
+func (f *configFlag) Set(value string) error {
+	if f.isBool {
+		if _, err := strconv.ParseBool(value); err != nil {
+			return err
+		}
+	}
+
+	f.value = value
+
+	return nil
+}
+
+// IsBoolFlag returns whether the flag can be set without a value (i.e. --smtp.insecureskipverify)
+func (f *configFlag) IsBoolFlag() bool {
+	return f.isBool
+}
+
+// RegisterFlags registers a flag on the given flag set (flag.CommandLine if nil) for every setting of the
+// configuration struct T, named after the lower-case key of the setting (i.e. --db.dsn or --smtp.port).
+// Flags which are set override the file and environment variables when the configuration is loaded with
+// the WithFlags option. Maps and lists of sections can't be set with flags.
+//
+//	configuration.RegisterFlags[configuration.Config](nil)
+//	flag.Parse()
+//
+//	cfg, err := configuration.LoadConfig("config.json", configuration.WithFlags(nil))
+func RegisterFlags[T any](fs *flag.FlagSet) {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+
+	registerFlags(fs, reflect.TypeOf((*T)(nil)).Elem(), "")
+}
+
+// registerFlags registers a flag for every setting of the given struct type, whose keys start with the given prefix
+func registerFlags(fs *flag.FlagSet, structType reflect.Type, prefix string) {
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("koanf"), ",")
+
+		// Embedded sections with the squash option keep the keys of their settings
+		if field.Anonymous && options == "squash" && field.Type.Kind() == reflect.Struct {
+			registerFlags(fs, field.Type, prefix)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		key := prefix + name
+
+		switch kind := field.Type.Kind(); {
+		case kind == reflect.Struct:
+			registerFlags(fs, field.Type, key+".")
+		case kind == reflect.Map, kind == reflect.Interface, kind == reflect.Pointer,
+			kind == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct:
+			continue
+		default:
+			flagName := strings.ToLower(key)
+			if fs.Lookup(flagName) != nil {
+				continue
+			}
+
+			fs.Var(&configFlag{key: key, isBool: kind == reflect.Bool}, flagName, "Overrides "+key)
+		}
+	}
+}
+
+// WithFlags makes the loaded configuration hold the values of the flags registered with RegisterFlags
+// which are set on the given flag set (flag.CommandLine if nil), which must have been parsed.
+// Flags take precedence over environment variables and files.
+func WithFlags(fs *flag.FlagSet) LoadOption {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+
+	return func(opts *loadOptions) {
+		opts.flags = fs
+	}
+}
+
+// flagValues returns the values of the configuration flags which are set on the given flag set, keyed by setting
+func flagValues(fs *flag.FlagSet) map[string]any {
+	values := map[string]any{}
+
+	fs.Visit(func(f *flag.Flag) {
+		if configFlag, ok := f.Value.(*configFlag); ok {
+			values[configFlag.key] = configFlag.value
+		}
+	})
+
+	return values
+}
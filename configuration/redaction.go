@@ -0,0 +1,214 @@
+package configuration
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MaskedValue replaces the values of sensitive settings in configuration dumps
+const MaskedValue = "[REDACTED]"
+
+// sensitiveKeys are the endings of the names of the settings whose values are masked in configuration dumps,
+// i.e. "SMTP.Password", "Secrets.AWS.SecretAccessKey" or the values of "Tracing.Headers"
+var sensitiveKeys = []string{"password", "secret", "token", "apikey", "accesskey", "privatekey", "masterkey", "headers"}
+
+// credentialKeys are the endings of the names of the settings holding connection strings, whose credentials
+// are masked in configuration dumps while the rest (i.e. the host) is kept. URLs with a password are masked the same way.
+var credentialKeys = []string{"dsn", "connectionstring"}
+
+// credentialPairPattern matches the credentials of connection strings made of key=value pairs,
+// i.e. "password=..." in "host=db user=play password=..." or "SharedAccessKey=..." in Azure connection strings
+var credentialPairPattern = regexp.MustCompile(`(?i)((?:password|pwd|secret|sharedaccesskey|accountkey)\s*=\s*)[^;\s]+`)
+
+// String returns the configuration as indented JSON with the values of sensitive settings masked
+func (c *Config) String() string {
+	dump, err := DumpEffective(c)
+	if err != nil {
+		return err.Error()
+	}
+
+	return string(dump)
+}
+
+// DumpEffective returns the given loaded configuration struct (i.e. a *Config or a service-specific
+// configuration struct) as indented JSON keyed like the configuration file, with the values of
+// sensitive settings (passwords, secrets, tokens, keys) and the credentials of connection strings
+// masked, so that it can be logged to check which value won without leaking credentials.
+//
+//	log.Info("Effective configuration", map[string]string{"config": cfg.String()})
+func DumpEffective(config any) ([]byte, error) {
+	return json.MarshalIndent(maskValues(structValues(reflect.ValueOf(config))), "", "  ")
+}
+
+// structValues returns the settings of the given configuration value keyed like the configuration file
+func structValues(value reflect.Value) any {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+
+		value = value.Elem()
+	}
+
+	if value.Type() == reflect.TypeOf(time.Duration(0)) {
+		return time.Duration(value.Int()).String()
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		values := map[string]any{}
+		addStructValues(values, value)
+
+		return values
+	case reflect.Map:
+		if value.IsNil() {
+			return nil
+		}
+
+		values := make(map[string]any, value.Len())
+		for iter := value.MapRange(); iter.Next(); {
+			values[iter.Key().String()] = structValues(iter.Value())
+		}
+
+		return values
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return nil
+		}
+
+		values := make([]any, value.Len())
+		for i := range values {
+			values[i] = structValues(value.Index(i))
+		}
+
+		return values
+	default:
+		return value.Interface()
+	}
+}
+
+// addStructValues adds the settings of the given configuration struct to the given values, keyed by koanf tag
+func addStructValues(values map[string]any, value reflect.Value) {
+	structType := value.Type()
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("koanf"), ",")
+
+		// Embedded sections with the squash option keep the keys of their settings
+		if field.Anonymous && options == "squash" && field.Type.Kind() == reflect.Struct {
+			addStructValues(values, value.Field(i))
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		values[name] = structValues(value.Field(i))
+	}
+}
+
+// maskValues masks the values of the sensitive settings of the given configuration values in place
+// and returns them. Secret references are kept since they don't hold secrets.
+func maskValues(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, nested := range value {
+			switch {
+			case isEmptyValue(nested):
+			case matchesKey(key, sensitiveKeys):
+				value[key] = maskSensitive(nested)
+			case matchesKey(key, credentialKeys):
+				if str, ok := nested.(string); ok {
+					value[key] = maskCredentials(str)
+				} else {
+					value[key] = MaskedValue
+				}
+			default:
+				value[key] = maskValues(nested)
+			}
+		}
+	case []any:
+		for i, nested := range value {
+			value[i] = maskValues(nested)
+		}
+	case string:
+		if strings.Contains(value, "://") {
+			return maskCredentials(value)
+		}
+	}
+
+	return value
+}
+
+// maskSensitive masks the given value of a sensitive setting, or every value of a sensitive map setting
+func maskSensitive(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, nested := range value {
+			if !isEmptyValue(nested) {
+				value[key] = maskSensitive(nested)
+			}
+		}
+
+		return value
+	case string:
+		if isSecretReference(value) {
+			return value
+		}
+	}
+
+	return MaskedValue
+}
+
+// maskCredentials masks the password of the given URL or the credentials of the given key=value connection string
+func maskCredentials(value string) string {
+	if isSecretReference(value) {
+		return value
+	}
+
+	if u, err := url.Parse(value); err == nil && u.User != nil {
+		if _, hasPassword := u.User.Password(); hasPassword {
+			u.User = url.UserPassword(u.User.Username(), MaskedValue)
+			return strings.Replace(u.String(), url.QueryEscape(MaskedValue), MaskedValue, 1)
+		}
+	}
+
+	return credentialPairPattern.ReplaceAllString(value, "${1}"+MaskedValue)
+}
+
+// matchesKey returns whether the given setting name ends with one of the given lower-case key endings
+func matchesKey(name string, keys []string) bool {
+	name = strings.ToLower(name)
+
+	for _, key := range keys {
+		if strings.HasSuffix(name, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isEmptyValue returns whether the given setting is unset, which is kept as is so that it can be told apart
+func isEmptyValue(value any) bool {
+	switch value := value.(type) {
+	case nil:
+		return true
+	case string:
+		return value == ""
+	case map[string]any:
+		return len(value) == 0
+	default:
+		return false
+	}
+}
@@ -0,0 +1,211 @@
+package configuration
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Remote configuration stores which can be selected in the configuration
+const (
+	ConsulRemote = "Consul" // Consul KV
+	EtcdRemote   = "Etcd"   // etcd v3 through its JSON gateway
+)
+
+// remoteRequestTimeout is the timeout of the requests made to read the remote configuration
+const remoteRequestTimeout = 10 * time.Second
+
+// ErrUnsupportedRemote is returned when the configured remote configuration store is not supported
+var ErrUnsupportedRemote = errors.New("unsupported remote configuration store")
+
+// remoteValues reads the settings stored under the prefix of the Remote section of the configuration
+// and returns them keyed by setting, i.e. "RateLimit.Rps" for the key "config/catalog/RateLimit/Rps"
+// if the prefix is "config/catalog/"
+func remoteValues(cfg *Config) (map[string]any, error) {
+	remoteCfg := cfg.Remote
+
+	ctx, cancel := context.WithTimeout(context.Background(), remoteRequestTimeout)
+	defer cancel()
+
+	client := &http.Client{Timeout: remoteRequestTimeout}
+	prefix := strings.TrimPrefix(remoteCfg.Prefix, "/")
+
+	var (
+		pairs map[string]string
+		err   error
+	)
+
+	switch store := remoteCfg.Type; {
+	case strings.EqualFold(store, ConsulRemote):
+		address := valueOrEnv(remoteCfg.Address, "CONSUL_HTTP_ADDR")
+		if address == "" {
+			address = "http://localhost:8500"
+		}
+
+		pairs, err = consulPairs(ctx, client, address, prefix, valueOrEnv(remoteCfg.Token, "CONSUL_HTTP_TOKEN"))
+	case strings.EqualFold(store, EtcdRemote):
+		address := remoteCfg.Address
+		if address == "" {
+			address = "http://localhost:2379"
+		}
+
+		pairs, err = etcdPairs(ctx, client, address, prefix, remoteCfg.Username, remoteCfg.Password)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedRemote, store)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to read remote configuration: %w", err)
+	}
+
+	values := make(map[string]any, len(pairs))
+	for key, value := range pairs {
+		// The prefix itself doesn't hold a setting
+		key = strings.Trim(strings.TrimPrefix(key, prefix), "/")
+		if key == "" {
+			continue
+		}
+
+		values[strings.ReplaceAll(key, "/", ".")] = value
+	}
+
+	return values, nil
+}
+
+// consulPairs returns the keys and values stored in Consul KV under the given prefix
+func consulPairs(ctx context.Context, client *http.Client, address, prefix, token string) (map[string]string, error) {
+	endpoint := strings.TrimSuffix(address, "/") + "/v1/kv/" + prefix + "?recurse=true"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if token != "" {
+		req.Header.Set("X-Consul-Token", token)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	// Consul responds with a 404 status code if there is no key under the prefix
+	if res.StatusCode == http.StatusNotFound {
+		return map[string]string{}, nil
+	}
+
+	if res.StatusCode >= http.StatusMultipleChoices {
+		message, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return nil, fmt.Errorf("unexpected status code %d from %s: %s", res.StatusCode, req.URL.Host, message)
+	}
+
+	var entries []struct {
+		Key   string `json:"Key"`
+		Value []byte `json:"Value"` // Base64 encoded, null for folders
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&entries); err != nil {
+		return nil, err
+	}
+
+	pairs := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if entry.Value != nil {
+			pairs[entry.Key] = string(entry.Value)
+		}
+	}
+
+	return pairs, nil
+}
+
+// etcdPairs returns the keys and values stored in etcd under the given prefix. A token is requested
+// first if a username is given, which requires authentication to be enabled on the cluster.
+func etcdPairs(ctx context.Context, client *http.Client, address, prefix, username, password string) (map[string]string, error) {
+	address = strings.TrimSuffix(address, "/")
+
+	var token string
+
+	if username != "" {
+		var auth struct {
+			Token string `json:"token"`
+		}
+
+		err := postJSON(ctx, client, address+"/v3/auth/authenticate", "", map[string]string{
+			"name":     username,
+			"password": password,
+		}, &auth)
+		if err != nil {
+			return nil, err
+		}
+
+		token = auth.Token
+	}
+
+	// Keys are encoded in base64 by the gateway, and the range of the keys
+	// starting with the prefix ends at the prefix with its last byte incremented
+	var rangeResponse struct {
+		Kvs []struct {
+			Key   []byte `json:"key"`
+			Value []byte `json:"value"`
+		} `json:"kvs"`
+	}
+
+	err := postJSON(ctx, client, address+"/v3/kv/range", token, map[string]string{
+		"key":       base64.StdEncoding.EncodeToString([]byte(prefix)),
+		"range_end": base64.StdEncoding.EncodeToString(prefixRangeEnd(prefix)),
+	}, &rangeResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := make(map[string]string, len(rangeResponse.Kvs))
+	for _, kv := range rangeResponse.Kvs {
+		pairs[string(kv.Key)] = string(kv.Value)
+	}
+
+	return pairs, nil
+}
+
+// prefixRangeEnd returns the end of the etcd range of the keys starting with the given prefix,
+// which is every key if the prefix is empty
+func prefixRangeEnd(prefix string) []byte {
+	end := []byte(prefix)
+
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+
+	return []byte{0}
+}
+
+// postJSON sends the given body encoded in JSON to the given URL and decodes the JSON response into dst
+func postJSON(ctx context.Context, client *http.Client, endpoint, token string, body, dst any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+
+	return fetchJSON(client, req, dst)
+}
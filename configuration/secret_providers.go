@@ -0,0 +1,258 @@
+package configuration
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PlayEconomy37/Play.Common/internal/awssig"
+)
+
+// secretRequestTimeout is the timeout of a request to a secret store
+const secretRequestTimeout = 10 * time.Second
+
+// vaultProvider is a SecretProvider which reads secrets from HashiCorp Vault through its HTTP API
+type vaultProvider struct {
+	address   string
+	token     string
+	namespace string
+	client    *http.Client
+}
+
+// newVaultProvider creates a vaultProvider from the Secrets section of the configuration
+func newVaultProvider(cfg *Config) (SecretProvider, error) {
+	vaultCfg := cfg.Secrets.Vault
+
+	provider := &vaultProvider{
+		address:   strings.TrimSuffix(valueOrEnv(vaultCfg.Address, "VAULT_ADDR"), "/"),
+		token:     valueOrEnv(vaultCfg.Token, "VAULT_TOKEN"),
+		namespace: valueOrEnv(vaultCfg.Namespace, "VAULT_NAMESPACE"),
+		client:    &http.Client{Timeout: secretRequestTimeout},
+	}
+
+	if provider.address == "" || provider.token == "" {
+		return nil, errors.New("missing Vault address or token")
+	}
+
+	return provider, nil
+}
+
+// GetSecret reads the secret with the given path (i.e. "secret/data/smtp") and returns its fields
+// as a JSON object. Both versions of the KV secrets engine are supported.
+func (p *vaultProvider) GetSecret(ctx context.Context, path string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.address+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("X-Vault-Token", p.token)
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+
+	var secret struct {
+		Data map[string]any `json:"data"`
+	}
+
+	if err := fetchJSON(p.client, req, &secret); err != nil {
+		return "", err
+	}
+
+	// The KV secrets engine version 2 nests the fields of the secret along with its metadata
+	fields := secret.Data
+	if nested, ok := fields["data"].(map[string]any); ok && fields["metadata"] != nil {
+		fields = nested
+	}
+
+	value, err := json.Marshal(fields)
+
+	return string(value), err
+}
+
+// awsSecretsProvider is a SecretProvider which reads secrets from AWS Secrets Manager through its HTTP API
+type awsSecretsProvider struct {
+	region      string
+	credentials awssig.Credentials
+	url         string
+	client      *http.Client
+}
+
+// newAWSSecretsProvider creates an awsSecretsProvider from the Secrets section of the configuration
+func newAWSSecretsProvider(cfg *Config) (SecretProvider, error) {
+	awsCfg := cfg.Secrets.AWS
+
+	provider := &awsSecretsProvider{
+		region: valueOrEnv(awsCfg.Region, "AWS_REGION"),
+		credentials: awssig.Credentials{
+			AccessKeyID:     valueOrEnv(awsCfg.AccessKeyID, "AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: valueOrEnv(awsCfg.SecretAccessKey, "AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    valueOrEnv(awsCfg.SessionToken, "AWS_SESSION_TOKEN"),
+		},
+		client: &http.Client{Timeout: secretRequestTimeout},
+	}
+
+	if provider.region == "" || provider.credentials.AccessKeyID == "" || provider.credentials.SecretAccessKey == "" {
+		return nil, errors.New("missing AWS region or credentials")
+	}
+
+	provider.url = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com/", provider.region)
+
+	return provider, nil
+}
+
+// GetSecret reads the current version of the secret with the given name or ARN (i.e. "play/db-dsn")
+func (p *awsSecretsProvider) GetSecret(ctx context.Context, path string) (string, error) {
+	body, err := json.Marshal(map[string]string{"SecretId": path})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	awssig.Sign(req, body, p.credentials, p.region, "secretsmanager", time.Now())
+
+	var secret struct {
+		SecretString string `json:"SecretString"`
+		SecretBinary string `json:"SecretBinary"` // Base64 encoded
+	}
+
+	if err := fetchJSON(p.client, req, &secret); err != nil {
+		return "", err
+	}
+
+	if secret.SecretString != "" || secret.SecretBinary == "" {
+		return secret.SecretString, nil
+	}
+
+	value, err := base64.StdEncoding.DecodeString(secret.SecretBinary)
+
+	return string(value), err
+}
+
+// azureKeyVaultProvider is a SecretProvider which reads secrets from Azure Key Vault through its HTTP API,
+// authenticated as an Azure AD application with the client credentials flow
+type azureKeyVaultProvider struct {
+	tenantID     string
+	clientID     string
+	clientSecret string
+	client       *http.Client
+
+	mu             sync.Mutex
+	token          string
+	tokenExpiresAt time.Time
+}
+
+// newAzureKeyVaultProvider creates an azureKeyVaultProvider from the Secrets section of the configuration
+func newAzureKeyVaultProvider(cfg *Config) (SecretProvider, error) {
+	azureCfg := cfg.Secrets.Azure
+
+	provider := &azureKeyVaultProvider{
+		tenantID:     valueOrEnv(azureCfg.TenantID, "AZURE_TENANT_ID"),
+		clientID:     valueOrEnv(azureCfg.ClientID, "AZURE_CLIENT_ID"),
+		clientSecret: valueOrEnv(azureCfg.ClientSecret, "AZURE_CLIENT_SECRET"),
+		client:       &http.Client{Timeout: secretRequestTimeout},
+	}
+
+	if provider.tenantID == "" || provider.clientID == "" || provider.clientSecret == "" {
+		return nil, errors.New("missing Azure tenant ID or client credentials")
+	}
+
+	return provider, nil
+}
+
+// GetSecret reads the current version of the secret with the given path, made of the vault name and
+// the secret name (i.e. "play-vault/db-dsn"), optionally followed by a version
+func (p *azureKeyVaultProvider) GetSecret(ctx context.Context, path string) (string, error) {
+	vaultName, secretPath, found := strings.Cut(path, "/")
+	if !found || vaultName == "" || secretPath == "" {
+		return "", fmt.Errorf("invalid Azure Key Vault secret path %q, expected <vault>/<secret>", path)
+	}
+
+	token, err := p.accessToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	secretURL := fmt.Sprintf("https://%s.vault.azure.net/secrets/%s?api-version=7.3", vaultName, secretPath)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, secretURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var secret struct {
+		Value string `json:"value"`
+	}
+
+	if err := fetchJSON(p.client, req, &secret); err != nil {
+		return "", err
+	}
+
+	return secret.Value, nil
+}
+
+// accessToken returns an Azure AD access token for Key Vault, which is reused until it is about to expire
+func (p *azureKeyVaultProvider) accessToken(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token != "" && time.Now().Before(p.tokenExpiresAt) {
+		return p.token, nil
+	}
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {p.clientID},
+		"client_secret": {p.clientSecret},
+		"scope":         {"https://vault.azure.net/.default"},
+	}
+
+	tokenURL := fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/v2.0/token", url.PathEscape(p.tenantID))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"` // Seconds
+	}
+
+	if err := fetchJSON(p.client, req, &token); err != nil {
+		return "", err
+	}
+
+	// Renew the token a minute before it expires
+	p.token = token.AccessToken
+	p.tokenExpiresAt = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+
+	return p.token, nil
+}
+
+// valueOrEnv returns the given configuration value, or the value of the given environment variable if it is empty
+func valueOrEnv(value, envName string) string {
+	if value != "" {
+		return value
+	}
+
+	return os.Getenv(envName)
+}
@@ -0,0 +1,220 @@
+package configuration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/knadh/koanf"
+	"github.com/knadh/koanf/providers/confmap"
+)
+
+// Schemes of the secret references which can be used as configuration values in place of secrets.
+// The secret is fetched when the configuration is loaded, and a field of a secret holding several
+// fields (i.e. a JSON object) is selected with "#<field>".
+const (
+	VaultSecretScheme = "vault" // Vault KV secret, i.e. "vault:secret/data/smtp#password"
+	AWSSecretScheme   = "awssm" // AWS Secrets Manager secret, i.e. "awssm:play/db-dsn"
+	AzureSecretScheme = "azkv"  // Azure Key Vault secret, i.e. "azkv:play-vault/db-dsn"
+)
+
+const (
+	defaultSecretCacheTTL = 5 * time.Minute  // Used if Secrets.CacheTTL is empty
+	secretsTimeout        = 30 * time.Second // Timeout of the resolution of every secret of the configuration
+)
+
+// SecretProvider is an interface that fetches the secrets referenced in configuration values
+type SecretProvider interface {
+	// GetSecret returns the secret with the given path, i.e. "secret/data/smtp" for "vault:secret/data/smtp#password".
+	// Secrets holding several fields are returned as JSON objects.
+	GetSecret(ctx context.Context, path string) (string, error)
+}
+
+// SecretProviderFactory is a function which creates a SecretProvider from the Secrets section of the configuration
+type SecretProviderFactory func(cfg *Config) (SecretProvider, error)
+
+var (
+	secretProvidersMu sync.RWMutex
+	secretProviders   = map[string]SecretProviderFactory{
+		VaultSecretScheme: newVaultProvider,
+		AWSSecretScheme:   newAWSSecretsProvider,
+		AzureSecretScheme: newAzureKeyVaultProvider,
+	}
+)
+
+// RegisterSecretProvider registers the provider of the secret references with the given scheme
+// (i.e. "gcpsm" for "gcpsm:projects/play/secrets/db-dsn"), replacing the built-in provider of the scheme if any
+func RegisterSecretProvider(scheme string, factory SecretProviderFactory) {
+	secretProvidersMu.Lock()
+	defer secretProvidersMu.Unlock()
+
+	secretProviders[scheme] = factory
+}
+
+// secretProviderFactory returns the factory of the provider of the secret references with the given scheme
+func secretProviderFactory(scheme string) (SecretProviderFactory, bool) {
+	secretProvidersMu.RLock()
+	defer secretProvidersMu.RUnlock()
+
+	factory, ok := secretProviders[scheme]
+
+	return factory, ok
+}
+
+// secretCache is a struct that holds the fetched secrets until they expire, so that reloading
+// the configuration doesn't fetch every secret again. Expired secrets are fetched again,
+// which picks up rotated secrets.
+type secretCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedSecret // Keyed by scheme and path
+}
+
+// cachedSecret is a struct that holds a fetched secret along with its expiry time
+type cachedSecret struct {
+	value     string
+	expiresAt time.Time
+}
+
+// newSecretCache returns a new empty secretCache
+func newSecretCache() *secretCache {
+	return &secretCache{entries: map[string]cachedSecret{}}
+}
+
+// resolveSecrets replaces the secret references of the loaded configuration with the secrets they reference
+func resolveSecrets(configReader *koanf.Koanf, cache *secretCache) error {
+	references := map[string]string{}
+	for key, value := range configReader.All() {
+		if reference, ok := value.(string); ok && isSecretReference(reference) {
+			references[key] = reference
+		}
+	}
+
+	if len(references) == 0 {
+		return nil
+	}
+
+	// Providers are configured with the Secrets section, whose values can't be secret references
+	var cfg Config
+	if err := configReader.Unmarshal("Secrets", &cfg.Secrets); err != nil {
+		return err
+	}
+
+	ttl := cfg.Secrets.CacheTTL
+	if ttl <= 0 {
+		ttl = defaultSecretCacheTTL
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), secretsTimeout)
+	defer cancel()
+
+	providers := map[string]SecretProvider{}
+	resolved := make(map[string]any, len(references))
+
+	for key, reference := range references {
+		scheme, path, _ := strings.Cut(reference, ":")
+		path, field, _ := strings.Cut(path, "#")
+
+		secret, err := cache.get(scheme+":"+path, ttl, func() (string, error) {
+			provider, exists := providers[scheme]
+			if !exists {
+				factory, _ := secretProviderFactory(scheme)
+
+				var err error
+
+				provider, err = factory(&cfg)
+				if err != nil {
+					return "", err
+				}
+
+				providers[scheme] = provider
+			}
+
+			return provider.GetSecret(ctx, path)
+		})
+		if err != nil {
+			return fmt.Errorf("unable to resolve secret of %s: %w", key, err)
+		}
+
+		if field != "" {
+			secret, err = secretField(secret, field)
+			if err != nil {
+				return fmt.Errorf("unable to resolve secret of %s: %w", key, err)
+			}
+		}
+
+		resolved[key] = secret
+	}
+
+	return configReader.Load(confmap.Provider(resolved, "."), nil)
+}
+
+// isSecretReference returns whether the given configuration value references a secret
+func isSecretReference(value string) bool {
+	scheme, path, found := strings.Cut(value, ":")
+	if !found || path == "" {
+		return false
+	}
+
+	_, ok := secretProviderFactory(scheme)
+
+	return ok
+}
+
+// get returns the cached secret with the given key, or fetches and caches it if it isn't cached or expired
+func (c *secretCache) get(key string, ttl time.Duration, fetch func() (string, error)) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, exists := c.entries[key]; exists && time.Now().Before(entry.expiresAt) {
+		return entry.value, nil
+	}
+
+	value, err := fetch()
+	if err != nil {
+		return "", err
+	}
+
+	c.entries[key] = cachedSecret{value: value, expiresAt: time.Now().Add(ttl)}
+
+	return value, nil
+}
+
+// secretField returns the field with the given name of a secret holding a JSON object
+func secretField(secret, field string) (string, error) {
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fmt.Errorf("secret isn't a JSON object: %w", err)
+	}
+
+	value, exists := fields[field]
+	if !exists {
+		return "", fmt.Errorf("secret has no field %q", field)
+	}
+
+	if str, ok := value.(string); ok {
+		return str, nil
+	}
+
+	return fmt.Sprint(value), nil
+}
+
+// fetchJSON sends a request to a secret or configuration store and decodes its JSON response into dst
+func fetchJSON(client *http.Client, req *http.Request, dst any) error {
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusMultipleChoices {
+		message, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("unexpected status code %d from %s: %s", res.StatusCode, req.URL.Host, message)
+	}
+
+	return json.NewDecoder(res.Body).Decode(dst)
+}
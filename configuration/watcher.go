@@ -0,0 +1,250 @@
+package configuration
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultReloadInterval is the interval at which a Watcher re-reads the configuration if none is given,
+// so that changes of environment variables and of the remote store (which aren't watched) are picked up
+const DefaultReloadInterval = 30 * time.Second
+
+// Change is a struct that holds a change of configuration detected by a Watcher
+type Change struct {
+	Old  *Config
+	New  *Config
+	Keys []string // Sorted changed keys, i.e. "Logging.Level"
+}
+
+// Changed returns whether the given key or one of the keys of the given section changed,
+// i.e. "Logging.Level" or "Logging"
+func (c Change) Changed(key string) bool {
+	for _, changedKey := range c.Keys {
+		if changedKey == key || strings.HasPrefix(changedKey, key+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ChangeCallback is a function called by a Watcher when the configuration changes
+type ChangeCallback func(change Change)
+
+// Watcher is a struct which reloads the configuration when its file changes and periodically, and calls
+// the registered callbacks when the result differs from the current configuration, so that services can
+// apply new settings (i.e. the log level) without restarting. Invalid configurations are ignored.
+type Watcher struct {
+	filePath string
+	reloadMu sync.Mutex   // Serializes reloads so that callbacks see changes in order
+	secrets  *secretCache // Secrets are fetched again once expired, which renews rotated secrets
+	opts     []LoadOption
+
+	mu             sync.RWMutex
+	config         *Config
+	values         map[string]any
+	callbacks      []ChangeCallback
+	errorCallbacks []func(err error)
+
+	fsWatcher *fsnotify.Watcher
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewWatcher loads the configuration from the given file, environment variables and options like LoadConfig,
+// then watches the file and reloads the configuration at the given interval (DefaultReloadInterval if zero)
+// until the watcher is closed
+func NewWatcher(filePath string, interval time.Duration, opts ...LoadOption) (*Watcher, error) {
+	if interval <= 0 {
+		interval = DefaultReloadInterval
+	}
+
+	secrets := newSecretCache()
+
+	config, values, err := load(filePath, secrets, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Watch the directory rather than the file, since editors and Kubernetes replace
+	// files (i.e. with a symlink swap) rather than writing to them
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fsWatcher.Add(filepath.Dir(filePath)); err != nil {
+		fsWatcher.Close()
+		return nil, err
+	}
+
+	w := &Watcher{
+		filePath:  filePath,
+		secrets:   secrets,
+		opts:      opts,
+		config:    config,
+		values:    values,
+		fsWatcher: fsWatcher,
+		done:      make(chan struct{}),
+	}
+
+	w.wg.Add(1)
+	go w.run(interval)
+
+	return w, nil
+}
+
+// Config returns the current configuration, which must not be modified
+func (w *Watcher) Config() *Config {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return w.config
+}
+
+// OnChange registers a callback which is called with every change of the configuration.
+// Callbacks are called one after the other from the goroutine of the watcher.
+func (w *Watcher) OnChange(callback ChangeCallback) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.callbacks = append(w.callbacks, callback)
+}
+
+// OnError registers a callback which is called when the configuration can't be reloaded
+// or the file can't be watched
+func (w *Watcher) OnError(callback func(err error)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.errorCallbacks = append(w.errorCallbacks, callback)
+}
+
+// Reload reloads the configuration and calls the registered callbacks if it changed, i.e. on SIGHUP.
+// The current configuration is kept if the new one can't be loaded.
+func (w *Watcher) Reload() error {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	config, values, err := load(w.filePath, w.secrets, w.opts)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+
+	change := Change{Old: w.config, New: config, Keys: diffValues(w.values, values)}
+	if len(change.Keys) == 0 {
+		w.mu.Unlock()
+		return nil
+	}
+
+	w.config = config
+	w.values = values
+	callbacks := w.callbacks
+
+	w.mu.Unlock()
+
+	for _, callback := range callbacks {
+		callback(change)
+	}
+
+	return nil
+}
+
+// Close stops watching the configuration
+func (w *Watcher) Close() error {
+	var err error
+
+	w.closeOnce.Do(func() {
+		close(w.done)
+		err = w.fsWatcher.Close()
+	})
+
+	w.wg.Wait()
+
+	return err
+}
+
+// run reloads the configuration when the file changes and at the given interval until the watcher is closed
+func (w *Watcher) run(interval time.Duration) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// The file and its environment overlay are watched
+	fileNames := map[string]bool{filepath.Clean(w.filePath): true}
+	if environment := newLoadOptions(w.opts).environment; environment != "" {
+		fileNames[filepath.Clean(overlayPath(w.filePath, environment))] = true
+	}
+
+	for {
+		var err error
+
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			err = w.Reload()
+		case event, ok := <-w.fsWatcher.Events:
+			if !ok {
+				return
+			}
+
+			// Kubernetes updates mounted files by swapping a "..data" symlink in the same directory
+			if fileNames[filepath.Clean(event.Name)] || strings.Contains(event.Name, "..data") {
+				err = w.Reload()
+			}
+		case watchErr, ok := <-w.fsWatcher.Errors:
+			if !ok {
+				return
+			}
+
+			err = watchErr
+		}
+
+		if err != nil {
+			w.reportError(err)
+		}
+	}
+}
+
+// reportError calls the registered error callbacks with the given error
+func (w *Watcher) reportError(err error) {
+	w.mu.RLock()
+	callbacks := w.errorCallbacks
+	w.mu.RUnlock()
+
+	for _, callback := range callbacks {
+		callback(err)
+	}
+}
+
+// diffValues returns the sorted keys whose value differs between the given flattened configurations
+func diffValues(old, new map[string]any) []string {
+	var keys []string
+
+	for key, value := range new {
+		if oldValue, exists := old[key]; !exists || !reflect.DeepEqual(oldValue, value) {
+			keys = append(keys, key)
+		}
+	}
+
+	for key := range old {
+		if _, exists := new[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,167 @@
+// Package containertest starts throwaway MongoDB, Postgres and RabbitMQ containers for the integration
+// tests of our services, and returns clients and configurations connected to them. Containers are
+// removed when the test which started them completes.
+//
+// Tests using containers are skipped when run with -short or when Docker isn't available:
+//
+//	func TestItemsRepository(t *testing.T) {
+//		mongo := containertest.StartMongo(t)
+//		repo := database.NewMongoRepository[int64, Item](mongo.Client, "catalog", "items")
+//		...
+//	}
+//
+// Several containers can fill in the same configuration, i.e. to create a whole application:
+//
+//	cfg := &configuration.Config{ServiceName: "trading"}
+//	containertest.StartMongo(t, containertest.WithConfig(cfg))
+//	containertest.StartRabbitMQ(t, containertest.WithConfig(cfg))
+package containertest
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/PlayEconomy37/Play.Common/configuration"
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// defaultStartupTimeout is the time given to a container to be ready, which includes pulling its image
+const defaultStartupTimeout = 2 * time.Minute
+
+// Option is a function used to configure optional behaviour of the containers
+type Option func(*options)
+
+// options is a struct that holds the optional configuration of a container
+type options struct {
+	image          string
+	config         *configuration.Config
+	startupTimeout time.Duration
+	migrations     *embed.FS
+}
+
+// WithImage sets the image of the container, i.e. "mongo:5.0" to test against the version used in production
+func WithImage(image string) Option {
+	return func(opts *options) {
+		opts.image = image
+	}
+}
+
+// WithConfig makes the container fill in the connection settings of the given configuration instead
+// of a new one, so that a single configuration can point to several containers
+func WithConfig(cfg *configuration.Config) Option {
+	return func(opts *options) {
+		opts.config = cfg
+	}
+}
+
+// WithStartupTimeout sets the time given to the container to be ready, two minutes by default
+func WithStartupTimeout(timeout time.Duration) Option {
+	return func(opts *options) {
+		opts.startupTimeout = timeout
+	}
+}
+
+// WithMigrations applies the migrations of the "migrations" directory of the given embedded files
+// to the Postgres database once it is ready
+func WithMigrations(embeddedFiles embed.FS) Option {
+	return func(opts *options) {
+		opts.migrations = &embeddedFiles
+	}
+}
+
+// newOptions returns the options of a container with the given default image
+func newOptions(image string, opts []Option) options {
+	containerOpts := options{image: image, startupTimeout: defaultStartupTimeout}
+
+	for _, opt := range opts {
+		opt(&containerOpts)
+	}
+
+	if containerOpts.config == nil {
+		containerOpts.config = &configuration.Config{}
+	}
+
+	// Metrics of the clients are named after the service
+	if containerOpts.config.ServiceName == "" {
+		containerOpts.config.ServiceName = "test"
+	}
+
+	return containerOpts
+}
+
+// start starts a container exposing the given port, waits for it to be ready and returns its host and
+// mapped port. The container is terminated when the test completes.
+func start(t testing.TB, req testcontainers.ContainerRequest, port string, startupTimeout time.Duration) (testcontainers.Container, string, int) {
+	t.Helper()
+
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	skipWithoutDocker(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), startupTimeout)
+	defer cancel()
+
+	req.ExposedPorts = []string{port}
+	req.WaitingFor = wait.ForAll(req.WaitingFor, wait.ForListeningPort(nat.Port(port))).WithDeadline(startupTimeout)
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if container != nil {
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			if err := container.Terminate(ctx); err != nil {
+				t.Logf("unable to terminate the %s container: %s", req.Image, err)
+			}
+		})
+	}
+
+	if err != nil {
+		t.Fatalf("unable to start the %s container: %s", req.Image, err)
+	}
+
+	host, err := container.Host(ctx)
+	if err != nil {
+		t.Fatalf("unable to get the host of the %s container: %s", req.Image, err)
+	}
+
+	mappedPort, err := container.MappedPort(ctx, nat.Port(port))
+	if err != nil {
+		t.Fatalf("unable to get the port of the %s container: %s", req.Image, err)
+	}
+
+	return container, host, mappedPort.Int()
+}
+
+// skipWithoutDocker skips the test if Docker isn't available
+func skipWithoutDocker(t testing.TB) {
+	t.Helper()
+
+	provider, err := testcontainers.ProviderDocker.GetProvider()
+	if err != nil {
+		t.Skipf("skipping integration test, Docker isn't available: %s", err)
+	}
+	defer provider.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := provider.Health(ctx); err != nil {
+		t.Skipf("skipping integration test, Docker isn't available: %s", err)
+	}
+}
+
+// endpoint returns the host and port of a container as an address
+func endpoint(host string, port int) string {
+	return fmt.Sprintf("%s:%d", host, port)
+}
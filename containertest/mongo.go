@@ -0,0 +1,53 @@
+package containertest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/PlayEconomy37/Play.Common/configuration"
+	"github.com/PlayEconomy37/Play.Common/database"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// DefaultMongoImage is the image of the MongoDB containers if none is given to WithImage
+const DefaultMongoImage = "mongo:6.0"
+
+// Mongo is a struct holding a running MongoDB container along with a client connected to it
+type Mongo struct {
+	Container testcontainers.Container
+	Config    *configuration.Config // DB.Dsn points to the container
+	Client    *mongo.Client         // Created with database.NewMongoClient
+}
+
+// StartMongo starts a MongoDB container and returns a client connected to it, which is disconnected
+// when the test completes
+func StartMongo(t testing.TB, opts ...Option) *Mongo {
+	t.Helper()
+
+	containerOpts := newOptions(DefaultMongoImage, opts)
+
+	container, host, port := start(t, testcontainers.ContainerRequest{
+		Image:      containerOpts.image,
+		WaitingFor: wait.ForLog("Waiting for connections"),
+	}, "27017/tcp", containerOpts.startupTimeout)
+
+	cfg := containerOpts.config
+	cfg.DB.Dsn = "mongodb://" + endpoint(host, port) + "/?directConnection=true"
+
+	client, err := database.NewMongoClient(cfg)
+	if err != nil {
+		t.Fatalf("unable to connect to the MongoDB container: %s", err)
+	}
+
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		_ = client.Disconnect(ctx)
+	})
+
+	return &Mongo{Container: container, Config: cfg, Client: client}
+}
@@ -0,0 +1,67 @@
+package containertest
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"testing"
+
+	"github.com/PlayEconomy37/Play.Common/configuration"
+	"github.com/PlayEconomy37/Play.Common/database"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	// DefaultPostgresImage is the image of the Postgres containers if none is given to WithImage
+	DefaultPostgresImage = "postgres:15-alpine"
+
+	postgresUser     = "play"
+	postgresPassword = "play"
+	postgresDatabase = "test"
+)
+
+// Postgres is a struct holding a running Postgres container along with a connection pool to it
+type Postgres struct {
+	Container testcontainers.Container
+	Config    *configuration.Config // DB.Dsn points to the container
+	DB        *sql.DB               // Created with database.NewPostgresDB
+}
+
+// StartPostgres starts a Postgres container and returns a connection pool to it, which is closed when
+// the test completes. The migrations given to WithMigrations are applied before it is returned.
+func StartPostgres(t testing.TB, opts ...Option) *Postgres {
+	t.Helper()
+
+	containerOpts := newOptions(DefaultPostgresImage, opts)
+
+	// The server is restarted once the database is initialized
+	container, host, port := start(t, testcontainers.ContainerRequest{
+		Image: containerOpts.image,
+		Env: map[string]string{
+			"POSTGRES_USER":     postgresUser,
+			"POSTGRES_PASSWORD": postgresPassword,
+			"POSTGRES_DB":       postgresDatabase,
+		},
+		WaitingFor: wait.ForLog("database system is ready to accept connections").WithOccurrence(2),
+	}, "5432/tcp", containerOpts.startupTimeout)
+
+	cfg := containerOpts.config
+	cfg.DB.Dsn = fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=disable", postgresUser, postgresPassword, endpoint(host, port), postgresDatabase)
+
+	var migrations embed.FS
+	if containerOpts.migrations != nil {
+		migrations = *containerOpts.migrations
+	}
+
+	db, err := database.NewPostgresDB(cfg, containerOpts.migrations != nil, migrations)
+	if err != nil {
+		t.Fatalf("unable to connect to the Postgres container: %s", err)
+	}
+
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	return &Postgres{Container: container, Config: cfg, DB: db}
+}
@@ -0,0 +1,62 @@
+package containertest
+
+import (
+	"testing"
+
+	"github.com/PlayEconomy37/Play.Common/configuration"
+	"github.com/PlayEconomy37/Play.Common/events"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	// DefaultRabbitMQImage is the image of the RabbitMQ containers if none is given to WithImage
+	DefaultRabbitMQImage = "rabbitmq:3.11-alpine"
+
+	// The default guest user may only connect from localhost
+	rabbitMQUser     = "play"
+	rabbitMQPassword = "play"
+)
+
+// RabbitMQ is a struct holding a running RabbitMQ container along with a connection to it
+type RabbitMQ struct {
+	Container  testcontainers.Container
+	Config     *configuration.Config // RabbitMQ section points to the container
+	Connection *amqp.Connection      // Created with events.NewRabbitMQConnection
+}
+
+// StartRabbitMQ starts a RabbitMQ container and returns a connection to it, which is closed when
+// the test completes. The configuration can be given to events.NewBroker to publish and consume events.
+func StartRabbitMQ(t testing.TB, opts ...Option) *RabbitMQ {
+	t.Helper()
+
+	containerOpts := newOptions(DefaultRabbitMQImage, opts)
+
+	container, host, port := start(t, testcontainers.ContainerRequest{
+		Image: containerOpts.image,
+		Env: map[string]string{
+			"RABBITMQ_DEFAULT_USER": rabbitMQUser,
+			"RABBITMQ_DEFAULT_PASS": rabbitMQPassword,
+		},
+		WaitingFor: wait.ForLog("Server startup complete"),
+	}, "5672/tcp", containerOpts.startupTimeout)
+
+	cfg := containerOpts.config
+	cfg.MessageBroker = ""
+	cfg.RabbitMQ.Host = host
+	cfg.RabbitMQ.Port = port
+	cfg.RabbitMQ.User = rabbitMQUser
+	cfg.RabbitMQ.Password = rabbitMQPassword
+
+	conn, err := events.NewRabbitMQConnection(cfg)
+	if err != nil {
+		t.Fatalf("unable to connect to the RabbitMQ container: %s", err)
+	}
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return &RabbitMQ{Container: container, Config: cfg, Connection: conn}
+}
@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PlayEconomy37/Play.Common/cache"
+	"github.com/PlayEconomy37/Play.Common/filters"
+	"github.com/PlayEconomy37/Play.Common/types"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CachedRepository is a repository decorator which caches the entities fetched by ID. Cached entities
+// are removed when they are updated or deleted through the repository, and the whole cache is cleared
+// by bulk operations since the entities they affect aren't known.
+type CachedRepository[K any, T types.MongoEntity[K, T]] struct {
+	types.BulkMongoRepository[K, T]
+	cache *cache.Cache[T]
+}
+
+// NewCachedRepository wraps the given repository with the given cache, which shouldn't be shared with
+// another repository
+//
+//	repository := database.NewCachedRepository(
+//		database.NewMongoRepository[int64, Item](client, "Catalog", "items"),
+//		cache.New[Item](store, "items", app.Config.Cache.TTL),
+//	)
+func NewCachedRepository[K any, T types.MongoEntity[K, T]](
+	repository types.BulkMongoRepository[K, T],
+	c *cache.Cache[T],
+) types.BulkMongoRepository[K, T] {
+	return &CachedRepository[K, T]{
+		BulkMongoRepository: repository,
+		cache:               c,
+	}
+}
+
+// cacheKey returns the cache key of the entity with the given ID
+func cacheKey[K any](id K) string {
+	return fmt.Sprint(id)
+}
+
+// GetByID returns the entity with the given ID from the cache, or from the repository if it isn't cached
+func (r *CachedRepository[K, T]) GetByID(ctx context.Context, id K) (T, error) {
+	return r.cache.GetOrLoad(ctx, cacheKey(id), func(ctx context.Context) (T, error) {
+		return r.BulkMongoRepository.GetByID(ctx, id)
+	})
+}
+
+// Update updates the given entity and removes it from the cache
+func (r *CachedRepository[K, T]) Update(ctx context.Context, entity T) error {
+	if err := r.BulkMongoRepository.Update(ctx, entity); err != nil {
+		return err
+	}
+
+	return r.cache.Delete(ctx, cacheKey(entity.GetID()))
+}
+
+// Delete deletes the entity with the given ID and removes it from the cache
+func (r *CachedRepository[K, T]) Delete(ctx context.Context, id K) error {
+	if err := r.BulkMongoRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	return r.cache.Delete(ctx, cacheKey(id))
+}
+
+// DeleteByFilter deletes the entities matching the given filter and clears the cache
+func (r *CachedRepository[K, T]) DeleteByFilter(ctx context.Context, filter primitive.M, allowEmptyFilter bool) (int64, error) {
+	deleted, err := r.BulkMongoRepository.DeleteByFilter(ctx, filter, allowEmptyFilter)
+	if err != nil || deleted == 0 {
+		return deleted, err
+	}
+
+	return deleted, r.cache.Clear(ctx)
+}
+
+// UpdateByFilter updates the entities matching the given filter and clears the cache
+func (r *CachedRepository[K, T]) UpdateByFilter(ctx context.Context, filter primitive.M, update primitive.M, allowEmptyFilter bool) (int64, error) {
+	updated, err := r.BulkMongoRepository.UpdateByFilter(ctx, filter, update, allowEmptyFilter)
+	if err != nil || updated == 0 {
+		return updated, err
+	}
+
+	return updated, r.cache.Clear(ctx)
+}
+
+// GetAll returns the entities matching the given filter from the repository, which aren't cached
+func (r *CachedRepository[K, T]) GetAll(ctx context.Context, filter primitive.M, findOpts filters.Filters) ([]T, filters.Metadata, error) {
+	return r.BulkMongoRepository.GetAll(ctx, filter, findOpts)
+}
@@ -8,7 +8,14 @@ const defaultTimeout = 3 * time.Second
 
 // DefaultPrice is used as a default value when validating `min_price` and `max_price`.
 // Very specific value that is difficult to replicate by client.
+//
+// Deprecated: build price filters with filters.Query (i.e. NewQuery().Range("price", min, max))
+// and leave unset bounds as nil instead of comparing against this sentinel.
 const DefaultPrice = 51.43243344285539
 
 // UsersCollection is used as the collection name for storing users in mongoDB
 const UsersCollection = "users"
+
+// UpdatedAtField is the document field holding the update time of entities implementing types.Timestamps,
+// which is set by the bulk updates of repositories with timestamps enabled
+const UpdatedAtField = "updatedAt"
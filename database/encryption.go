@@ -0,0 +1,184 @@
+package database
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/PlayEconomy37/Play.Common/configuration"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Encryption algorithms supported by MongoDB client-side field level encryption.
+// Deterministic encryption always produces the same ciphertext for a given value,
+// which allows querying by equality on encrypted fields (i.e. finding a user by email).
+const (
+	DeterministicAlgorithm = "AEAD_AES_256_CBC_HMAC_SHA_512-Deterministic"
+	RandomAlgorithm        = "AEAD_AES_256_CBC_HMAC_SHA_512-Random"
+)
+
+// ErrUnsupportedKMSProvider is returned when the configured KMS provider is not supported
+var ErrUnsupportedKMSProvider = errors.New("unsupported KMS provider")
+
+// kmsProviders builds the KMS providers map expected by the MongoDB driver from our configuration
+func kmsProviders(cfg *configuration.Config) (map[string]map[string]interface{}, error) {
+	encryptionCfg := cfg.DB.Encryption
+
+	switch encryptionCfg.KMSProvider {
+	case "", "local":
+		// The local master key is a base64 encoded 96 bytes key
+		masterKey, err := base64.StdEncoding.DecodeString(encryptionCfg.LocalMasterKey)
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string]map[string]interface{}{
+			"local": {"key": masterKey},
+		}, nil
+	case "aws":
+		return map[string]map[string]interface{}{
+			"aws": {
+				"accessKeyId":     encryptionCfg.AWS.AccessKeyID,
+				"secretAccessKey": encryptionCfg.AWS.SecretAccessKey,
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedKMSProvider, encryptionCfg.KMSProvider)
+	}
+}
+
+// autoEncryptionOptions returns the auto encryption options used by the mongo client.
+// Automatic encryption is bypassed (it requires MongoDB Enterprise or Atlas) but automatic
+// decryption is still performed by the driver, so documents read from the database
+// are decrypted transparently. Encryption is done explicitly in the repository layer.
+func autoEncryptionOptions(cfg *configuration.Config) (*options.AutoEncryptionOptions, error) {
+	providers, err := kmsProviders(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return options.AutoEncryption().
+		SetKeyVaultNamespace(cfg.DB.Encryption.KeyVaultNamespace).
+		SetKmsProviders(providers).
+		SetBypassAutoEncryption(true), nil
+}
+
+// FieldEncrypter is a struct used to explicitly encrypt values and document fields
+// before they are written to MongoDB.
+// Note that the binary must be built with the `cse` build tag and libmongocrypt installed.
+type FieldEncrypter struct {
+	clientEncryption *mongo.ClientEncryption
+	keyID            primitive.Binary
+	algorithm        string
+}
+
+// NewFieldEncrypter creates a new FieldEncrypter with given configuration. The data key
+// identified by the configured key alt name is created in the key vault if it doesn't exist yet.
+func NewFieldEncrypter(client *mongo.Client, cfg *configuration.Config) (*FieldEncrypter, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	providers, err := kmsProviders(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.ClientEncryption().
+		SetKeyVaultNamespace(cfg.DB.Encryption.KeyVaultNamespace).
+		SetKmsProviders(providers)
+
+	clientEncryption, err := mongo.NewClientEncryption(client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	keyID, err := getOrCreateDataKey(ctx, clientEncryption, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FieldEncrypter{
+		clientEncryption: clientEncryption,
+		keyID:            keyID,
+		algorithm:        DeterministicAlgorithm,
+	}, nil
+}
+
+// getOrCreateDataKey retrieves the id of the data key with the configured key alt name,
+// creating the data key if it doesn't exist
+func getOrCreateDataKey(ctx context.Context, clientEncryption *mongo.ClientEncryption, cfg *configuration.Config) (primitive.Binary, error) {
+	encryptionCfg := cfg.DB.Encryption
+
+	var key struct {
+		ID primitive.Binary `bson:"_id"`
+	}
+
+	err := clientEncryption.GetKeyByAltName(ctx, encryptionCfg.KeyAltName).Decode(&key)
+	if err == nil {
+		return key.ID, nil
+	}
+
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return primitive.Binary{}, err
+	}
+
+	dataKeyOpts := options.DataKey().SetKeyAltNames([]string{encryptionCfg.KeyAltName})
+
+	provider := encryptionCfg.KMSProvider
+	if provider == "" {
+		provider = "local"
+	}
+
+	if provider == "aws" {
+		dataKeyOpts.SetMasterKey(bson.M{
+			"region": encryptionCfg.AWS.Region,
+			"key":    encryptionCfg.AWS.KeyARN,
+		})
+	}
+
+	return clientEncryption.CreateDataKey(ctx, provider, dataKeyOpts)
+}
+
+// EncryptValue encrypts a single value. This can be used to build filters that
+// query by equality on deterministically encrypted fields.
+func (fe *FieldEncrypter) EncryptValue(ctx context.Context, value any) (primitive.Binary, error) {
+	valueType, data, err := bson.MarshalValue(value)
+	if err != nil {
+		return primitive.Binary{}, err
+	}
+
+	opts := options.Encrypt().
+		SetKeyID(fe.keyID).
+		SetAlgorithm(fe.algorithm)
+
+	return fe.clientEncryption.Encrypt(ctx, bson.RawValue{Type: valueType, Value: data}, opts)
+}
+
+// EncryptFields encrypts the given top level fields of a document in place.
+// Fields missing from the document are ignored.
+func (fe *FieldEncrypter) EncryptFields(ctx context.Context, document bson.M, fields ...string) error {
+	for _, field := range fields {
+		value, ok := document[field]
+		if !ok || value == nil {
+			continue
+		}
+
+		encrypted, err := fe.EncryptValue(ctx, value)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt field %q: %w", field, err)
+		}
+
+		document[field] = encrypted
+	}
+
+	return nil
+}
+
+// Close closes the underlying key vault client resources
+func (fe *FieldEncrypter) Close(ctx context.Context) error {
+	return fe.clientEncryption.Close(ctx)
+}
@@ -2,10 +2,13 @@ package database
 
 import (
 	"context"
+	"errors"
 	"log"
 	"time"
 
 	"github.com/PlayEconomy37/Play.Common/configuration"
+	"github.com/PlayEconomy37/Play.Common/opentelemetry"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
@@ -16,15 +19,39 @@ func NewMongoClient(cfg *configuration.Config) (*mongo.Client, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
 	defer cancel()
 
+	// Register connection pool metrics with Prometheus. A collector may already be registered
+	// when several clients are created, in which case we keep feeding the existing one.
+	poolCollector := opentelemetry.NewMongoPoolCollector(cfg.ServiceName)
+	if err := prometheus.Register(poolCollector); err != nil {
+		alreadyRegisteredErr := prometheus.AlreadyRegisteredError{}
+		if !errors.As(err, &alreadyRegisteredErr) {
+			return nil, err
+		}
+
+		poolCollector = alreadyRegisteredErr.ExistingCollector.(*opentelemetry.MongoPoolCollector)
+	}
+
 	// MongoDB connection options
 	maxOpenConns := uint64(cfg.DB.MaxOpenConns)
 	maxIdleTime := time.Duration(cfg.DB.MaxIdleTimeMS)
+	commandMetrics := opentelemetry.CreateMongoCommandMetrics(cfg.ServiceName)
 	opts := options.Client()
-	opts.Monitor = otelmongo.NewMonitor() // Opentelemetry tracing
+	opts.Monitor = commandMetrics.CommandMonitor(otelmongo.NewMonitor()) // Opentelemetry tracing and command metrics
+	opts.PoolMonitor = poolCollector.PoolMonitor()                       // Connection pool metrics
 	opts.MaxPoolSize = &maxOpenConns
 	opts.MaxConnIdleTime = &maxIdleTime
 	opts.ApplyURI(cfg.DB.Dsn)
 
+	// Enable automatic decryption of client-side encrypted fields
+	if cfg.DB.Encryption.Enabled {
+		autoEncryptionOpts, err := autoEncryptionOptions(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		opts.SetAutoEncryptionOptions(autoEncryptionOpts)
+	}
+
 	// Connect to MongoDB
 	mongoClient, err := mongo.Connect(ctx, opts)
 	if err != nil {
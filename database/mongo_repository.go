@@ -3,6 +3,9 @@ package database
 import (
 	"context"
 	"errors"
+	"fmt"
+	"reflect"
+	"time"
 
 	"github.com/PlayEconomy37/Play.Common/filters"
 	"github.com/PlayEconomy37/Play.Common/types"
@@ -25,6 +28,10 @@ var (
 	// ErrDuplicateKey is returned when trying to insert a document
 	// which contains a duplicate key (unique key which already exists in the database)
 	ErrDuplicateKey = errors.New("duplicate key")
+
+	// ErrEmptyFilter is returned when trying to run a bulk operation with an empty filter
+	// (which would affect every document in the collection) without explicitly allowing it
+	ErrEmptyFilter = errors.New("empty filter")
 )
 
 // IsDuplicateKey returns whether err informs of a duplicate key error because
@@ -41,16 +48,121 @@ func IsDuplicateKey(err error) bool {
 	return false
 }
 
+// Compile-time check that MongoRepository satisfies the generic repository interface from the
+// types package, which is the single repository API shared by our services
+var _ types.BulkMongoRepository[int64, User] = (*MongoRepository[int64, User])(nil)
+
 // MongoRepository is a generic MongoDB repository struct
 type MongoRepository[K any, T types.MongoEntity[K, T]] struct {
 	collection *mongo.Collection
+	options    repositoryOptions
 }
 
-// NewMongoRepository creates a new MongoDB repository
-func NewMongoRepository[K any, T types.MongoEntity[K, T]](client *mongo.Client, database, collection string) types.MongoRepository[K, T] {
-	return &MongoRepository[K, T]{
+// NewMongoRepository creates a new MongoDB repository, which also supports bulk operations
+func NewMongoRepository[K any, T types.MongoEntity[K, T]](
+	client *mongo.Client,
+	database, collection string,
+	opts ...RepositoryOption,
+) types.BulkMongoRepository[K, T] {
+	repo := &MongoRepository[K, T]{
 		collection: client.Database(database).Collection(collection),
 	}
+
+	for _, opt := range opts {
+		opt(&repo.options)
+	}
+
+	return repo
+}
+
+// now returns the current time truncated to milliseconds, which is the precision
+// used by MongoDB to store dates
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+// stampCreated sets the creation and update times of an entity if timestamps are enabled
+// and the entity implements the types.Timestamps interface
+func (repo MongoRepository[K, T]) stampCreated(entity T) T {
+	if !repo.options.timestamps {
+		return entity
+	}
+
+	timestamped, ok := any(entity).(types.Timestamps[T])
+	if !ok {
+		return entity
+	}
+
+	createdAt := now()
+	entity = timestamped.SetCreatedAt(createdAt)
+
+	return any(entity).(types.Timestamps[T]).SetUpdatedAt(createdAt)
+}
+
+// assignID sets the ID of an entity with the ID generator of the repository if one is set, the entity
+// implements the types.IDSetter interface and its ID isn't set yet
+func (repo MongoRepository[K, T]) assignID(entity T) (T, error) {
+	if repo.options.generateID == nil {
+		return entity, nil
+	}
+
+	setter, ok := any(entity).(types.IDSetter[K, T])
+	if !ok {
+		return entity, nil
+	}
+
+	if currentID := reflect.ValueOf(entity.GetID()); currentID.IsValid() && !currentID.IsZero() {
+		return entity, nil
+	}
+
+	generated := repo.options.generateID()
+
+	id, ok := generated.(K)
+	if !ok {
+		return entity, fmt.Errorf("ID generator returns %T instead of %T", generated, *new(K))
+	}
+
+	return setter.SetID(id), nil
+}
+
+// stampUpdated sets the update time of an entity if timestamps are enabled
+// and the entity implements the types.Timestamps interface
+func (repo MongoRepository[K, T]) stampUpdated(entity T) T {
+	if !repo.options.timestamps {
+		return entity
+	}
+
+	timestamped, ok := any(entity).(types.Timestamps[T])
+	if !ok {
+		return entity
+	}
+
+	return timestamped.SetUpdatedAt(now())
+}
+
+// toDocument converts an entity into the document that will be written to the database,
+// encrypting the configured fields if client-side field level encryption is enabled
+func (repo MongoRepository[K, T]) toDocument(ctx context.Context, entity T) (any, error) {
+	if repo.options.encrypter == nil || len(repo.options.encryptedFields) == 0 {
+		return entity, nil
+	}
+
+	data, err := bson.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	var document bson.M
+	if err := bson.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	err = repo.options.encrypter.EncryptFields(ctx, document, repo.options.encryptedFields...)
+	if err != nil {
+		return nil, err
+	}
+
+	return document, nil
 }
 
 // GetByID retrieves a specific document from the collection by its id
@@ -105,7 +217,8 @@ func (repo MongoRepository[K, T]) GetByFilter(ctx context.Context, filter primit
 	return item, nil
 }
 
-// GetAll retrieves all documents from the collection
+// GetAll retrieves all documents from the collection. Unless the filters skip counting, the
+// total number of matching documents is counted to compute the pagination metadata.
 func (repo MongoRepository[K, T]) GetAll(
 	ctx context.Context,
 	filter primitive.M,
@@ -114,10 +227,17 @@ func (repo MongoRepository[K, T]) GetAll(
 	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
 	defer cancel()
 
+	limit := findOpts.Limit()
+
+	// Without count, one more document is fetched to know whether there are more documents after this page
+	if findOpts.SkipCount {
+		limit++
+	}
+
 	// Find options
 	findOptions := options.Find()
 	findOptions.SetSkip(int64(findOpts.Offset()))
-	findOptions.SetLimit(int64(findOpts.Limit()))
+	findOptions.SetLimit(int64(limit))
 	findOptions.SetSort(
 		bson.D{
 			{Key: findOpts.SortColumn(), Value: findOpts.SortDirectionMongo()},
@@ -132,12 +252,6 @@ func (repo MongoRepository[K, T]) GetAll(
 		return items, filters.Metadata{}, err
 	}
 
-	// Get total number of records that exist in database with given filters
-	count, err := repo.collection.CountDocuments(ctx, filter)
-	if err != nil {
-		return items, filters.Metadata{}, err
-	}
-
 	defer cursor.Close(ctx)
 
 	for cursor.Next(ctx) {
@@ -155,9 +269,24 @@ func (repo MongoRepository[K, T]) GetAll(
 		return items, filters.Metadata{}, err
 	}
 
+	if findOpts.SkipCount {
+		hasMore := len(items) > findOpts.Limit()
+		if hasMore {
+			items = items[:findOpts.Limit()]
+		}
+
+		return items, filters.CalculateMetadataWithoutCount(findOpts.Page, findOpts.Limit(), hasMore), nil
+	}
+
+	// Get total number of records that exist in database with given filters
+	count, err := repo.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return items, filters.Metadata{}, err
+	}
+
 	// Generate a Metadata struct, passing in the total document count and pagination
 	// parameters from the client
-	metadata := filters.CalculateMetadata(int(count), findOpts.Page, findOpts.PageSize)
+	metadata := filters.CalculateMetadata(int(count), findOpts.Page, findOpts.Limit())
 
 	return items, metadata, nil
 }
@@ -167,7 +296,17 @@ func (repo MongoRepository[K, T]) Create(ctx context.Context, MongoEntity T) (*K
 	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
 	defer cancel()
 
-	result, err := repo.collection.InsertOne(ctx, MongoEntity)
+	MongoEntity, err := repo.assignID(MongoEntity)
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := repo.toDocument(ctx, repo.stampCreated(MongoEntity))
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := repo.collection.InsertOne(ctx, document)
 	if err != nil {
 		switch {
 		case IsDuplicateKey(err):
@@ -179,7 +318,8 @@ func (repo MongoRepository[K, T]) Create(ctx context.Context, MongoEntity T) (*K
 
 	id, ok := (result.InsertedID).(K)
 	if !ok {
-		return nil, err
+		// IDs with a custom BSON encoding (i.e. ULIDs) are returned by the driver in their raw form
+		id = MongoEntity.GetID()
 	}
 
 	return &id, nil
@@ -190,11 +330,12 @@ func (repo MongoRepository[K, T]) Update(ctx context.Context, MongoEntity T) err
 	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
 	defer cancel()
 
-	result, err := repo.collection.UpdateOne(
-		ctx,
-		bson.M{"_id": MongoEntity.GetID(), "version": MongoEntity.GetVersion()},
-		bson.M{"$set": MongoEntity.SetVersion(MongoEntity.GetVersion() + 1)},
-	)
+	filter, document, err := repo.prepareUpdate(ctx, MongoEntity)
+	if err != nil {
+		return err
+	}
+
+	result, err := repo.collection.UpdateOne(ctx, filter, bson.M{"$set": document})
 	if err != nil {
 		return err
 	}
@@ -207,6 +348,20 @@ func (repo MongoRepository[K, T]) Update(ctx context.Context, MongoEntity T) err
 	return nil
 }
 
+// prepareUpdate returns the filter matching the current version of the given entity and the document
+// replacing it with the next version. The expected version is read before SetVersion is called, since
+// entities whose SetVersion has a pointer receiver are modified in place.
+func (repo MongoRepository[K, T]) prepareUpdate(ctx context.Context, entity T) (bson.M, any, error) {
+	filter := bson.M{"_id": entity.GetID(), "version": entity.GetVersion()}
+
+	document, err := repo.toDocument(ctx, repo.stampUpdated(entity.SetVersion(entity.GetVersion()+1)))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return filter, document, nil
+}
+
 // Delete deletes a specific document from the collection
 func (repo MongoRepository[K, T]) Delete(ctx context.Context, id K) error {
 	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
@@ -224,3 +379,129 @@ func (repo MongoRepository[K, T]) Delete(ctx context.Context, id K) error {
 
 	return nil
 }
+
+// DeleteByFilter deletes all documents from the collection matching the given filter and returns
+// the number of deleted documents. An empty filter would wipe the whole collection, so it is
+// rejected with ErrEmptyFilter unless allowEmptyFilter is explicitly set to true.
+func (repo MongoRepository[K, T]) DeleteByFilter(ctx context.Context, filter primitive.M, allowEmptyFilter bool) (int64, error) {
+	if len(filter) == 0 && !allowEmptyFilter {
+		return 0, ErrEmptyFilter
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	result, err := repo.collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// UpdateByFilter applies the given update document (i.e. bson.M{"$set": ...}) to all documents
+// from the collection matching the given filter and returns the number of modified documents.
+// The version of every updated document is incremented so that concurrent updates relying on
+// the document version detect the change, and its UpdatedAtField is set if timestamps are enabled.
+// An empty filter is rejected with ErrEmptyFilter unless allowEmptyFilter is explicitly set to true.
+func (repo MongoRepository[K, T]) UpdateByFilter(
+	ctx context.Context,
+	filter primitive.M,
+	update primitive.M,
+	allowEmptyFilter bool,
+) (int64, error) {
+	if len(filter) == 0 && !allowEmptyFilter {
+		return 0, ErrEmptyFilter
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	result, err := repo.collection.UpdateMany(ctx, filter, repo.stampUpdatedMany(withVersionIncrement(update)))
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// stampUpdatedMany returns a copy of the given update document which also sets the update time
+// of the documents if timestamps are enabled, unless the update already changes it
+func (repo MongoRepository[K, T]) stampUpdatedMany(update primitive.M) primitive.M {
+	if !repo.options.timestamps || updatesField(update, UpdatedAtField, "$set", "$unset", "$currentDate") {
+		return update
+	}
+
+	return withOperatorField(update, "$set", UpdatedAtField, now())
+}
+
+// withVersionIncrement returns a copy of the given update document which also increments
+// the document version, or the update itself if it already changes the version
+func withVersionIncrement(update primitive.M) primitive.M {
+	if updatesField(update, "version", "$set", "$inc", "$unset") {
+		return update
+	}
+
+	return withOperatorField(update, "$inc", "version", 1)
+}
+
+// updatesField returns whether one of the given operators of the update document (i.e. "$set")
+// changes the given field. Operators can be given as primitive.M or primitive.D.
+func updatesField(update primitive.M, field string, operators ...string) bool {
+	for _, operator := range operators {
+		switch fields := update[operator].(type) {
+		case primitive.M:
+			if _, exists := fields[field]; exists {
+				return true
+			}
+		case map[string]any:
+			if _, exists := fields[field]; exists {
+				return true
+			}
+		case primitive.D:
+			for _, element := range fields {
+				if element.Key == field {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// withOperatorField returns a copy of the given update document whose operator (i.e. "$inc") also
+// sets the given field to the given value. The operator keeps its type, primitive.M or primitive.D.
+func withOperatorField(update primitive.M, operator, field string, value any) primitive.M {
+	result := make(primitive.M, len(update)+1)
+	for key, existing := range update {
+		result[key] = existing
+	}
+
+	switch fields := update[operator].(type) {
+	case nil:
+		result[operator] = primitive.M{field: value}
+	case primitive.M:
+		merged := make(primitive.M, len(fields)+1)
+		for key, existing := range fields {
+			merged[key] = existing
+		}
+
+		merged[field] = value
+		result[operator] = merged
+	case map[string]any:
+		merged := make(primitive.M, len(fields)+1)
+		for key, existing := range fields {
+			merged[key] = existing
+		}
+
+		merged[field] = value
+		result[operator] = merged
+	case primitive.D:
+		merged := make(primitive.D, len(fields), len(fields)+1)
+		copy(merged, fields)
+		result[operator] = append(merged, primitive.E{Key: field, Value: value})
+	}
+
+	return result
+}
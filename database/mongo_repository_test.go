@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// pointerItem is an entity whose SetVersion has a pointer receiver and modifies the entity in place
+type pointerItem struct {
+	ID      int64 `bson:"_id"`
+	Version int32 `bson:"version"`
+}
+
+func (i *pointerItem) GetID() int64 {
+	return i.ID
+}
+
+func (i *pointerItem) GetVersion() int32 {
+	return i.Version
+}
+
+func (i *pointerItem) SetVersion(version int32) *pointerItem {
+	i.Version = version
+	return i
+}
+
+// valueItem is an entity whose SetVersion has a value receiver and returns a modified copy
+type valueItem struct {
+	ID      int64 `bson:"_id"`
+	Version int32 `bson:"version"`
+}
+
+func (i valueItem) GetID() int64 {
+	return i.ID
+}
+
+func (i valueItem) GetVersion() int32 {
+	return i.Version
+}
+
+func (i valueItem) SetVersion(version int32) valueItem {
+	i.Version = version
+	return i
+}
+
+func TestPrepareUpdatePointerReceiver(t *testing.T) {
+	repo := MongoRepository[int64, *pointerItem]{}
+
+	filter, document, err := repo.prepareUpdate(context.Background(), &pointerItem{ID: 1, Version: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The filter must match the version stored in the database, not the incremented one
+	if filter["version"] != int32(3) {
+		t.Errorf("want filter version 3; got %v", filter["version"])
+	}
+
+	if version := document.(*pointerItem).Version; version != 4 {
+		t.Errorf("want document version 4; got %d", version)
+	}
+}
+
+func TestPrepareUpdateValueReceiver(t *testing.T) {
+	repo := MongoRepository[int64, valueItem]{}
+
+	filter, document, err := repo.prepareUpdate(context.Background(), valueItem{ID: 1, Version: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if filter["version"] != int32(3) {
+		t.Errorf("want filter version 3; got %v", filter["version"])
+	}
+
+	if version := document.(valueItem).Version; version != 4 {
+		t.Errorf("want document version 4; got %d", version)
+	}
+}
+
+func TestWithVersionIncrement(t *testing.T) {
+	tests := []struct {
+		name   string
+		update primitive.M
+		want   primitive.M
+	}{
+		{
+			name:   "set as map",
+			update: primitive.M{"$set": primitive.M{"name": "Potion"}},
+			want:   primitive.M{"$set": primitive.M{"name": "Potion"}, "$inc": primitive.M{"version": 1}},
+		},
+		{
+			name:   "set as document",
+			update: primitive.M{"$set": primitive.D{{Key: "name", Value: "Potion"}}},
+			want:   primitive.M{"$set": primitive.D{{Key: "name", Value: "Potion"}}, "$inc": primitive.M{"version": 1}},
+		},
+		{
+			name:   "version set as map",
+			update: primitive.M{"$set": primitive.M{"version": int32(7)}},
+			want:   primitive.M{"$set": primitive.M{"version": int32(7)}},
+		},
+		{
+			name:   "version set as document",
+			update: primitive.M{"$set": primitive.D{{Key: "version", Value: int32(7)}}},
+			want:   primitive.M{"$set": primitive.D{{Key: "version", Value: int32(7)}}},
+		},
+		{
+			name:   "inc as document",
+			update: primitive.M{"$inc": primitive.D{{Key: "quantity", Value: 2}}},
+			want:   primitive.M{"$inc": primitive.D{{Key: "quantity", Value: 2}, {Key: "version", Value: 1}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withVersionIncrement(tt.update); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want %v; got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestStampUpdatedMany(t *testing.T) {
+	repo := MongoRepository[int64, valueItem]{options: repositoryOptions{timestamps: true}}
+
+	stamped := repo.stampUpdatedMany(primitive.M{"$set": primitive.D{{Key: "name", Value: "Potion"}}})
+
+	set, ok := stamped["$set"].(primitive.D)
+	if !ok || len(set) != 2 || set[1].Key != UpdatedAtField {
+		t.Errorf("want %s added to $set; got %v", UpdatedAtField, stamped)
+	}
+
+	// An update time given by the caller is kept
+	update := primitive.M{"$set": primitive.M{UpdatedAtField: "given"}}
+	if got := repo.stampUpdatedMany(update); !reflect.DeepEqual(got, update) {
+		t.Errorf("want %v; got %v", update, got)
+	}
+
+	// Nothing is stamped without timestamps
+	repo.options.timestamps = false
+	if got := repo.stampUpdatedMany(primitive.M{}); len(got) != 0 {
+		t.Errorf("want no update time; got %v", got)
+	}
+}
@@ -0,0 +1,27 @@
+package database
+
+import (
+	"context"
+
+	"github.com/PlayEconomy37/Play.Common/configuration"
+	"github.com/go-redis/redis/v8"
+)
+
+// NewRedisClient creates a new Redis client with given configuration
+func NewRedisClient(cfg *configuration.Config) (*redis.Client, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.Address,
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	// Ping Redis to make sure it is up and running
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
@@ -0,0 +1,45 @@
+package database
+
+// RepositoryOption is a function used to configure optional behaviour of a MongoRepository
+type RepositoryOption func(*repositoryOptions)
+
+// repositoryOptions is a struct that holds the optional configuration of a MongoRepository
+type repositoryOptions struct {
+	encrypter       *FieldEncrypter
+	encryptedFields []string
+	timestamps      bool
+	generateID      func() any
+}
+
+// WithEncryptedFields makes the repository encrypt the given document fields (by their bson name)
+// with client-side field level encryption before writing them to the database. Decryption is done
+// automatically by the mongo client when encryption is enabled in the configuration.
+func WithEncryptedFields(encrypter *FieldEncrypter, fields ...string) RepositoryOption {
+	return func(opts *repositoryOptions) {
+		opts.encrypter = encrypter
+		opts.encryptedFields = fields
+	}
+}
+
+// WithTimestamps makes the repository stamp the creation time on Create and the update time
+// on Update for entities implementing the types.Timestamps interface, and the UpdatedAtField
+// on UpdateByFilter
+func WithTimestamps() RepositoryOption {
+	return func(opts *repositoryOptions) {
+		opts.timestamps = true
+	}
+}
+
+// WithIDGenerator makes the repository set the ID of entities implementing the types.IDSetter interface
+// with the given generator on Create, unless their ID is already set. The generator must return IDs
+// of the type of the IDs of the repository.
+//
+//	database.NewMongoRepository[ids.ULID, Item](client, "catalog", "items", database.WithIDGenerator(ids.NewULID))
+//	database.NewMongoRepository[int64, Order](client, "trading", "orders", database.WithIDGenerator(snowflake.Next))
+func WithIDGenerator[K any](generate func() K) RepositoryOption {
+	return func(opts *repositoryOptions) {
+		opts.generateID = func() any {
+			return generate()
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// ConsulClient is a Registry backed by the HTTP API of Consul
+type ConsulClient struct {
+	address string
+	token   string
+	client  *http.Client
+}
+
+// NewConsulClient creates a new ConsulClient sending requests to the given address (CONSUL_HTTP_ADDR or
+// the local agent if empty) with the given ACL token (CONSUL_HTTP_TOKEN if empty)
+func NewConsulClient(address, token string) *ConsulClient {
+	if address == "" {
+		address = os.Getenv("CONSUL_HTTP_ADDR")
+	}
+
+	if address == "" {
+		address = "http://localhost:8500"
+	}
+
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+
+	if token == "" {
+		token = os.Getenv("CONSUL_HTTP_TOKEN")
+	}
+
+	return &ConsulClient{
+		address: strings.TrimSuffix(address, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: registryRequestTimeout},
+	}
+}
+
+// Register registers the given instance with the local agent, along with its HTTP health check
+func (c *ConsulClient) Register(ctx context.Context, registration Registration) error {
+	service := map[string]any{
+		"ID":      registration.ID,
+		"Name":    registration.Service,
+		"Address": registration.Address,
+		"Port":    registration.Port,
+		"Tags":    registration.Tags,
+		"Meta":    registration.Meta,
+	}
+
+	if registration.HealthCheckURL != "" {
+		service["Check"] = map[string]any{
+			"HTTP":                           registration.HealthCheckURL,
+			"Interval":                       registration.HealthCheckInterval.String(),
+			"Timeout":                        registration.HealthCheckInterval.String(),
+			"DeregisterCriticalServiceAfter": registration.DeregisterAfter.String(),
+		}
+	}
+
+	body, err := json.Marshal(service)
+	if err != nil {
+		return err
+	}
+
+	return c.do(ctx, http.MethodPut, "/v1/agent/service/register", bytes.NewReader(body), nil)
+}
+
+// Deregister removes the instance with the given ID from the local agent
+func (c *ConsulClient) Deregister(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodPut, "/v1/agent/service/deregister/"+url.PathEscape(id), nil, nil)
+}
+
+// Instances returns the instances of the service with the given name whose health checks pass
+func (c *ConsulClient) Instances(ctx context.Context, service string) ([]Instance, error) {
+	var entries []struct {
+		Node struct {
+			Address string `json:"Address"`
+		} `json:"Node"`
+		Service struct {
+			ID      string            `json:"ID"`
+			Service string            `json:"Service"`
+			Address string            `json:"Address"`
+			Port    int               `json:"Port"`
+			Tags    []string          `json:"Tags"`
+			Meta    map[string]string `json:"Meta"`
+		} `json:"Service"`
+	}
+
+	if err := c.do(ctx, http.MethodGet, "/v1/health/service/"+url.PathEscape(service)+"?passing=true", nil, &entries); err != nil {
+		return nil, err
+	}
+
+	instances := make([]Instance, 0, len(entries))
+	for _, entry := range entries {
+		// Instances registered without an address are reachable at the address of their node
+		address := entry.Service.Address
+		if address == "" {
+			address = entry.Node.Address
+		}
+
+		instances = append(instances, Instance{
+			ID:      entry.Service.ID,
+			Service: entry.Service.Service,
+			Address: address,
+			Port:    entry.Service.Port,
+			Tags:    entry.Service.Tags,
+			Meta:    entry.Service.Meta,
+		})
+	}
+
+	return instances, nil
+}
+
+// do sends a request to the Consul API and decodes its JSON response into dst if it isn't nil
+func (c *ConsulClient) do(ctx context.Context, method, path string, body io.Reader, dst any) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.address+path, body)
+	if err != nil {
+		return err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.token != "" {
+		req.Header.Set("X-Consul-Token", c.token)
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusMultipleChoices {
+		message, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("unexpected status code %d from %s: %s", res.StatusCode, req.URL.Host, message)
+	}
+
+	if dst == nil {
+		return nil
+	}
+
+	return json.NewDecoder(res.Body).Decode(dst)
+}
@@ -0,0 +1,84 @@
+// Package discovery registers services with a service registry and resolves the logical names of other
+// services into the addresses of their healthy instances, for environments without a service mesh.
+// Kubernetes services don't need it since they are resolved by DNS (i.e. "dns:///inventory:5001").
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PlayEconomy37/Play.Common/configuration"
+)
+
+// Supported service registries
+const (
+	ConsulRegistry = "Consul"
+)
+
+// GRPCPortMeta is the metadata key holding the port of the gRPC server of an instance, if it serves gRPC
+const GRPCPortMeta = "grpc_port"
+
+// registryRequestTimeout is the timeout of the requests made to the service registry
+const registryRequestTimeout = 10 * time.Second
+
+var (
+	// ErrUnsupportedRegistry is returned when the configured service registry is not supported
+	ErrUnsupportedRegistry = errors.New("unsupported service registry")
+
+	// ErrNoInstance is returned when a service has no healthy instance
+	ErrNoInstance = errors.New("no healthy instance")
+)
+
+// Make sure every implementation satisfies our interface
+var _ Registry = (*ConsulClient)(nil)
+
+// Instance is a struct that holds an instance of a service
+type Instance struct {
+	ID      string
+	Service string
+	Address string
+	Port    int
+	Tags    []string
+	Meta    map[string]string
+}
+
+// HostPort returns the address and port of the instance, i.e. "10.0.0.12:4000"
+func (i Instance) HostPort() string {
+	return net.JoinHostPort(i.Address, strconv.Itoa(i.Port))
+}
+
+// Registration is a struct that holds the instance of a service to register, along with the HTTP
+// endpoint polled by the registry to check its health
+type Registration struct {
+	Instance
+	HealthCheckURL      string
+	HealthCheckInterval time.Duration
+	DeregisterAfter     time.Duration
+}
+
+// Registry is an interface that defines a service registry
+type Registry interface {
+	// Register registers the given instance, replacing the instance with the same ID if any
+	Register(ctx context.Context, registration Registration) error
+
+	// Deregister removes the instance with the given ID
+	Deregister(ctx context.Context, id string) error
+
+	// Instances returns the healthy instances of the service with the given name
+	Instances(ctx context.Context, service string) ([]Instance, error)
+}
+
+// NewRegistry creates the service registry selected in the Discovery section of the configuration
+func NewRegistry(cfg *configuration.Config) (Registry, error) {
+	switch registry := cfg.Discovery.Type; {
+	case strings.EqualFold(registry, ConsulRegistry):
+		return NewConsulClient(cfg.Discovery.Address, cfg.Discovery.Token), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedRegistry, registry)
+	}
+}
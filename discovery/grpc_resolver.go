@@ -0,0 +1,122 @@
+package discovery
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+)
+
+// GRPCScheme is the scheme of the gRPC targets resolved by the Resolver, i.e. "discovery:///inventory"
+const GRPCScheme = "discovery"
+
+// GRPCResolver returns a gRPC resolver builder resolving the targets of the GRPCScheme into the gRPC
+// addresses of the healthy instances of the service, which are refreshed at the refresh interval
+//
+//	conn, err := grpc.Dial("discovery:///inventory", grpc.WithResolvers(resolver.GRPCResolver()))
+func (r *Resolver) GRPCResolver() resolver.Builder {
+	return &grpcResolverBuilder{resolver: r}
+}
+
+// grpcResolverBuilder is a resolver.Builder creating a grpcResolver for every target
+type grpcResolverBuilder struct {
+	resolver *Resolver
+}
+
+// Scheme returns the scheme of the targets of the builder
+func (b *grpcResolverBuilder) Scheme() string {
+	return GRPCScheme
+}
+
+// Build starts watching the instances of the service of the given target
+func (b *grpcResolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	service := strings.TrimPrefix(target.URL.Path, "/")
+	if service == "" {
+		service = target.URL.Opaque
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	r := &grpcResolver{
+		resolver:   b.resolver,
+		service:    service,
+		cc:         cc,
+		cancel:     cancel,
+		resolveNow: make(chan struct{}, 1),
+		stopped:    make(chan struct{}),
+	}
+
+	go r.watch(ctx)
+
+	return r, nil
+}
+
+// grpcResolver is a resolver.Resolver updating the addresses of a client connection with the
+// instances of a service
+type grpcResolver struct {
+	resolver   *Resolver
+	service    string
+	cc         resolver.ClientConn
+	cancel     context.CancelFunc
+	resolveNow chan struct{}
+	stopped    chan struct{}
+}
+
+// ResolveNow asks for the instances of the service to be refreshed, i.e. after a connection failure
+func (r *grpcResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.resolveNow <- struct{}{}:
+	default:
+	}
+}
+
+// Close stops watching the instances of the service
+func (r *grpcResolver) Close() {
+	r.cancel()
+	<-r.stopped
+}
+
+// watch updates the addresses of the client connection at the refresh interval, or when asked to
+func (r *grpcResolver) watch(ctx context.Context) {
+	defer close(r.stopped)
+
+	ticker := time.NewTicker(r.resolver.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		r.update(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.resolveNow:
+		}
+	}
+}
+
+// update sends the addresses of the healthy instances of the service to the client connection. Instances
+// serving gRPC on another port than the registered one advertise it in their GRPCPortMeta metadata.
+func (r *grpcResolver) update(ctx context.Context) {
+	instances, err := r.resolver.Instances(ctx, r.service)
+	if err != nil {
+		r.cc.ReportError(err)
+		return
+	}
+
+	addresses := make([]resolver.Address, 0, len(instances))
+	for _, instance := range instances {
+		address := instance.HostPort()
+		if grpcPort := instance.Meta[GRPCPortMeta]; grpcPort != "" {
+			address = net.JoinHostPort(instance.Address, grpcPort)
+		}
+
+		addresses = append(addresses, resolver.Address{Addr: address})
+	}
+
+	if err := r.cc.UpdateState(resolver.State{Addresses: addresses}); err != nil {
+		r.cc.ReportError(err)
+	}
+}
@@ -0,0 +1,118 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/PlayEconomy37/Play.Common/configuration"
+)
+
+// defaultRefreshInterval is the interval at which the instances of a service are refreshed
+// if Discovery.RefreshInterval is empty
+const defaultRefreshInterval = 30 * time.Second
+
+// resolvedService is a struct that holds the last known instances of a service
+type resolvedService struct {
+	instances   []Instance
+	refreshedAt time.Time
+	next        int // Index of the instance returned by the next call to Resolve
+}
+
+// Resolver is a struct which resolves the logical names of services (i.e. "inventory") into their healthy
+// instances, which are cached for the refresh interval and balanced in a round-robin fashion
+type Resolver struct {
+	registry        Registry
+	names           map[string]string
+	refreshInterval time.Duration
+
+	mu       sync.Mutex
+	services map[string]*resolvedService
+}
+
+// NewResolver creates a new Resolver discovering services with the given registry. Logical names are
+// mapped to registered names with Discovery.Services of the given configuration.
+func NewResolver(registry Registry, cfg *configuration.Config) *Resolver {
+	refreshInterval := cfg.Discovery.RefreshInterval
+	if refreshInterval <= 0 {
+		refreshInterval = defaultRefreshInterval
+	}
+
+	return &Resolver{
+		registry:        registry,
+		names:           cfg.Discovery.Services,
+		refreshInterval: refreshInterval,
+		services:        map[string]*resolvedService{},
+	}
+}
+
+// ServiceName returns the registered name of the service with the given logical name
+func (r *Resolver) ServiceName(service string) string {
+	if name, exists := r.names[service]; exists && name != "" {
+		return name
+	}
+
+	return service
+}
+
+// Instances returns the healthy instances of the service with the given logical name. The last known
+// instances are returned if the registry can't be reached, so that calls keep flowing during its outages.
+func (r *Resolver) Instances(ctx context.Context, service string) ([]Instance, error) {
+	r.mu.Lock()
+	resolved, exists := r.services[service]
+	if exists && time.Since(resolved.refreshedAt) < r.refreshInterval {
+		instances := resolved.instances
+		r.mu.Unlock()
+
+		return instances, nil
+	}
+	r.mu.Unlock()
+
+	instances, err := r.registry.Instances(ctx, r.ServiceName(service))
+	if err != nil {
+		if exists && len(resolved.instances) > 0 {
+			return resolved.instances, nil
+		}
+
+		return nil, fmt.Errorf("unable to discover service %s: %w", service, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if resolved, exists = r.services[service]; !exists {
+		resolved = &resolvedService{}
+		r.services[service] = resolved
+	}
+
+	resolved.instances = instances
+	resolved.refreshedAt = time.Now()
+
+	return instances, nil
+}
+
+// Resolve returns the next healthy instance of the service with the given logical name, in a round-robin
+// fashion. It returns ErrNoInstance if the service has no healthy instance.
+//
+//	instance, err := resolver.Resolve(ctx, "inventory")
+//	res, err := http.Get("http://" + instance.HostPort() + "/items")
+func (r *Resolver) Resolve(ctx context.Context, service string) (Instance, error) {
+	instances, err := r.Instances(ctx, service)
+	if err != nil {
+		return Instance{}, err
+	}
+
+	if len(instances) == 0 {
+		return Instance{}, fmt.Errorf("%w: %s", ErrNoInstance, service)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	resolved := r.services[service]
+	instance := instances[resolved.next%len(instances)]
+	resolved.next = (resolved.next + 1) % len(instances)
+
+	return instance, nil
+}
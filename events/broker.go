@@ -0,0 +1,134 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus"
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus/admin"
+	"github.com/PlayEconomy37/Play.Common/configuration"
+	"github.com/PlayEconomy37/Play.Common/opentelemetry"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Supported message brokers
+const (
+	RabbitMQBroker        = "RabbitMQ"
+	AzureServiceBusBroker = "AzureServiceBus"
+	KafkaBroker           = "Kafka"
+)
+
+// ErrUnsupportedBroker is returned when the configured message broker is not supported
+var ErrUnsupportedBroker = errors.New("unsupported message broker")
+
+// Make sure every implementation satisfies our interfaces
+var (
+	_ Publisher        = (*RabbitMQPublisher)(nil)
+	_ Publisher        = (*AzureServiceBusPublisher)(nil)
+	_ Publisher        = (*KafkaPublisher)(nil)
+	_ Publisher        = (*InMemoryBus)(nil)
+	_ Consumer         = (*RabbitMQConsumer)(nil)
+	_ Consumer         = (*AzureServiceBusConsumer)(nil)
+	_ Consumer         = (*KafkaConsumer)(nil)
+	_ Consumer         = (*InMemoryConsumer)(nil)
+	_ Replier          = (*RabbitMQPublisher)(nil)
+	_ MessagePublisher = (*RabbitMQPublisher)(nil)
+	_ OutboxStore      = (*MongoOutboxStore)(nil)
+)
+
+// Broker is a struct which creates publishers and consumers for the message broker selected
+// in the configuration, so that services don't depend on a specific broker implementation.
+// Exchanges map to Service Bus and Kafka topics, and queues to Service Bus subscriptions
+// and Kafka consumer groups.
+type Broker struct {
+	name            string
+	rabbitMQ        *amqp.Connection
+	serviceBus      *azservicebus.Client
+	serviceBusAdmin *admin.Client
+	kafkaBrokers    []string
+	kafkaCommits    time.Duration
+	metrics         *opentelemetry.MessageBrokerMetrics
+}
+
+// NewBroker connects to the message broker selected in the configuration (RabbitMQ by default).
+// Publishers and consumers created by the broker keep track of their messages in metrics named
+// after the service name.
+func NewBroker(cfg *configuration.Config) (*Broker, error) {
+	broker := &Broker{
+		name:    cfg.MessageBroker,
+		metrics: messageBrokerMetrics(cfg.ServiceName),
+	}
+
+	var err error
+
+	switch broker.name {
+	case "", RabbitMQBroker:
+		broker.name = RabbitMQBroker
+		broker.rabbitMQ, err = NewRabbitMQConnection(cfg)
+	case AzureServiceBusBroker:
+		broker.serviceBus, err = NewAzureServiceBusConnection(cfg.AzureServiceBus.ConnectionString)
+		if err == nil {
+			broker.serviceBusAdmin, err = NewAzureServiceBusAdminClient(cfg.AzureServiceBus.ConnectionString)
+		}
+	case KafkaBroker:
+		broker.kafkaBrokers = cfg.Kafka.Brokers
+		broker.kafkaCommits = cfg.Kafka.CommitInterval
+	default:
+		err = fmt.Errorf("%w: %s", ErrUnsupportedBroker, broker.name)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return broker, nil
+}
+
+// Name returns the name of the message broker
+func (b *Broker) Name() string {
+	return b.name
+}
+
+// NewPublisher creates a publisher of events to the given exchange
+func (b *Broker) NewPublisher(ctx context.Context, exchange string, opts ...PublisherOption) (Publisher, error) {
+	opts = append([]PublisherOption{WithPublisherMetrics(b.metrics)}, opts...)
+
+	switch b.name {
+	case KafkaBroker:
+		return NewKafkaPublisher(b.kafkaBrokers, exchange, opts...), nil
+	case AzureServiceBusBroker:
+		return NewAzureServiceBusPublisher(ctx, b.serviceBus, b.serviceBusAdmin, exchange, opts...)
+	}
+
+	return NewRabbitMQPublisher(b.rabbitMQ, exchange, opts...)
+}
+
+// NewConsumer creates a consumer of events published to the given exchange, through the given queue
+func (b *Broker) NewConsumer(exchange, queue string, opts ...ConsumerOption) (Consumer, error) {
+	opts = append([]ConsumerOption{WithConsumerMetrics(b.metrics)}, opts...)
+
+	switch b.name {
+	case KafkaBroker:
+		opts = append([]ConsumerOption{WithCommitInterval(b.kafkaCommits)}, opts...)
+		return NewKafkaConsumer(b.kafkaBrokers, exchange, queue, opts...), nil
+	case AzureServiceBusBroker:
+		return NewAzureServiceBusConsumer(b.serviceBus, b.serviceBusAdmin, exchange, queue, opts...), nil
+	}
+
+	return NewRabbitMQConsumer(b.rabbitMQ, exchange, queue, opts...)
+}
+
+// Close closes the connection to the message broker. Kafka connections are owned
+// by publishers and consumers, so they must be closed individually.
+func (b *Broker) Close() error {
+	switch b.name {
+	case KafkaBroker:
+		return nil
+	case AzureServiceBusBroker:
+		return b.serviceBus.Close(context.Background())
+	default:
+		return b.rabbitMQ.Close()
+	}
+}
@@ -0,0 +1,82 @@
+package events
+
+import "strconv"
+
+// Types of the catalog events
+const (
+	CatalogItemCreatedEventType = "CatalogItemCreated"
+	CatalogItemUpdatedEventType = "CatalogItemUpdated"
+	CatalogItemDeletedEventType = "CatalogItemDeleted"
+)
+
+// CatalogItemCreatedEvent is the event sent whenever an item is added to the catalog
+type CatalogItemCreatedEvent struct {
+	ItemID      int64   `json:"item_id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Price       float64 `json:"price"`
+}
+
+// EventType returns the type of the event
+func (e CatalogItemCreatedEvent) EventType() string {
+	return CatalogItemCreatedEventType
+}
+
+// EventVersion returns the version of the event schema
+func (e CatalogItemCreatedEvent) EventVersion() int {
+	return 1
+}
+
+// OrderingKey returns the id of the item, so that events of the same item are processed in order
+func (e CatalogItemCreatedEvent) OrderingKey() string {
+	return strconv.FormatInt(e.ItemID, 10)
+}
+
+// CatalogItemUpdatedEvent is the event sent whenever an item of the catalog is updated
+type CatalogItemUpdatedEvent struct {
+	ItemID      int64   `json:"item_id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Price       float64 `json:"price"`
+}
+
+// EventType returns the type of the event
+func (e CatalogItemUpdatedEvent) EventType() string {
+	return CatalogItemUpdatedEventType
+}
+
+// EventVersion returns the version of the event schema
+func (e CatalogItemUpdatedEvent) EventVersion() int {
+	return 1
+}
+
+// OrderingKey returns the id of the item, so that events of the same item are processed in order
+func (e CatalogItemUpdatedEvent) OrderingKey() string {
+	return strconv.FormatInt(e.ItemID, 10)
+}
+
+// CatalogItemDeletedEvent is the event sent whenever an item is removed from the catalog
+type CatalogItemDeletedEvent struct {
+	ItemID int64 `json:"item_id"`
+}
+
+// EventType returns the type of the event
+func (e CatalogItemDeletedEvent) EventType() string {
+	return CatalogItemDeletedEventType
+}
+
+// EventVersion returns the version of the event schema
+func (e CatalogItemDeletedEvent) EventVersion() int {
+	return 1
+}
+
+// OrderingKey returns the id of the item, so that events of the same item are processed in order
+func (e CatalogItemDeletedEvent) OrderingKey() string {
+	return strconv.FormatInt(e.ItemID, 10)
+}
+
+func init() {
+	RegisterEventType[CatalogItemCreatedEvent](DefaultRegistry)
+	RegisterEventType[CatalogItemUpdatedEvent](DefaultRegistry)
+	RegisterEventType[CatalogItemDeletedEvent](DefaultRegistry)
+}
@@ -0,0 +1,132 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CloudEventsContentType is the content type of messages in CloudEvents structured JSON format
+const CloudEventsContentType = "application/cloudevents+json"
+
+// CloudEventsSpecVersion is the version of the CloudEvents specification we support
+const CloudEventsSpecVersion = "1.0"
+
+// ErrInvalidCloudEvent is returned when consuming a message which is not a valid CloudEvent
+var ErrInvalidCloudEvent = errors.New("invalid cloud event")
+
+// CloudEvent is a struct that holds an event in CloudEvents 1.0 structured JSON format.
+// The envelope metadata which has no CloudEvents attribute is carried by extension attributes.
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Time            time.Time       `json:"time,omitempty"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+	DataBase64      []byte          `json:"data_base64,omitempty"`
+	CorrelationID   string          `json:"correlationid,omitempty"`
+	CausationID     string          `json:"causationid,omitempty"`
+	EventVersion    int             `json:"eventversion,omitempty"`
+}
+
+// WithCloudEvents makes the publisher wrap events in CloudEvents 1.0 structured JSON format, so that
+// external systems can consume them. The producer set with WithProducer is used as the event source.
+// Consumers unwrap such messages automatically.
+func WithCloudEvents() PublisherOption {
+	return func(opts *publisherOptions) {
+		opts.cloudEvents = true
+	}
+}
+
+// encodeCloudEvent replaces the body of the message by a CloudEvent holding the envelope
+// metadata and the original body as data
+func encodeCloudEvent(msg *Message) error {
+	cloudEvent := CloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		ID:              msg.ID,
+		Source:          msg.Producer,
+		Type:            msg.Type,
+		Time:            msg.OccurredAt,
+		DataContentType: msg.ContentType,
+		CorrelationID:   msg.CorrelationID,
+		CausationID:     msg.CausationID,
+		EventVersion:    msg.Version,
+	}
+
+	// The source is required and must be a URI-reference
+	if cloudEvent.Source == "" {
+		cloudEvent.Source = "/"
+	}
+
+	if isJSONContentType(msg.ContentType) {
+		cloudEvent.Data = msg.Body
+	} else {
+		cloudEvent.DataBase64 = msg.Body
+	}
+
+	body, err := json.Marshal(cloudEvent)
+	if err != nil {
+		return err
+	}
+
+	msg.ContentType = CloudEventsContentType
+	msg.Body = body
+
+	return nil
+}
+
+// decodeCloudEvent replaces a message in CloudEvents structured JSON format by the event it holds,
+// mapping the CloudEvents attributes to the message envelope
+func decodeCloudEvent(msg *Message) error {
+	var cloudEvent CloudEvent
+
+	if err := json.Unmarshal(msg.Body, &cloudEvent); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCloudEvent, err)
+	}
+
+	if cloudEvent.SpecVersion != CloudEventsSpecVersion || cloudEvent.ID == "" || cloudEvent.Source == "" || cloudEvent.Type == "" {
+		return fmt.Errorf("%w: missing required attributes", ErrInvalidCloudEvent)
+	}
+
+	msg.ID = cloudEvent.ID
+	msg.Type = cloudEvent.Type
+	msg.Producer = cloudEvent.Source
+
+	if !cloudEvent.Time.IsZero() {
+		msg.OccurredAt = cloudEvent.Time
+	}
+
+	if cloudEvent.CorrelationID != "" {
+		msg.CorrelationID = cloudEvent.CorrelationID
+	}
+
+	if cloudEvent.CausationID != "" {
+		msg.CausationID = cloudEvent.CausationID
+	}
+
+	msg.Version = DefaultEventVersion
+	if cloudEvent.EventVersion > 0 {
+		msg.Version = cloudEvent.EventVersion
+	}
+
+	// Data without a content type is JSON according to the specification
+	msg.ContentType = cloudEvent.DataContentType
+	if msg.ContentType == "" {
+		msg.ContentType = JSONContentType
+	}
+
+	msg.Body = cloudEvent.Data
+	if cloudEvent.DataBase64 != nil {
+		msg.Body = cloudEvent.DataBase64
+	}
+
+	return nil
+}
+
+// isJSONContentType returns whether data with the given content type can be embedded as is in a CloudEvent
+func isJSONContentType(contentType string) bool {
+	return contentType == "" || contentType == JSONContentType
+}
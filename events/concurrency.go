@@ -0,0 +1,74 @@
+package events
+
+import (
+	"hash/fnv"
+
+	"github.com/PlayEconomy37/Play.Common/configuration"
+)
+
+// OrderingKeyHeader is the header used to set the ordering key of a message. Messages with
+// the same ordering key are processed sequentially when ordered processing is enabled.
+const OrderingKeyHeader = "x-ordering-key"
+
+// OrderedEvent is an interface implemented by events which must be processed in order relatively
+// to other events with the same ordering key (i.e. the id of the aggregate they relate to)
+type OrderedEvent interface {
+	Event
+	OrderingKey() string
+}
+
+// WithPrefetch limits the number of unacknowledged messages delivered to the consumer
+func WithPrefetch(prefetch int) ConsumerOption {
+	return func(opts *consumerOptions) {
+		opts.prefetch = prefetch
+	}
+}
+
+// WithWorkers sets the number of messages handled in parallel by the consumer
+func WithWorkers(workers int) ConsumerOption {
+	return func(opts *consumerOptions) {
+		opts.workers = workers
+	}
+}
+
+// WithOrderedProcessing makes messages sharing the same ordering key be processed sequentially,
+// while messages with different keys are still processed in parallel by the consumer workers.
+// The ordering key is read from the OrderingKeyHeader header and falls back to the routing key.
+func WithOrderedProcessing() ConsumerOption {
+	return func(opts *consumerOptions) {
+		opts.orderedByKey = true
+	}
+}
+
+// WithConsumerConfig applies the prefetch, workers and ordering settings from the given configuration
+func WithConsumerConfig(cfg configuration.RabbitMQConsumerConfig) ConsumerOption {
+	return func(opts *consumerOptions) {
+		opts.prefetch = cfg.Prefetch
+		opts.workers = cfg.Workers
+		opts.orderedByKey = cfg.OrderedByKey
+	}
+}
+
+// setOrderingKey stores the ordering key of an OrderedEvent in the message headers
+func setOrderingKey(event Event, msg *Message) {
+	if ordered, ok := event.(OrderedEvent); ok && ordered.OrderingKey() != "" {
+		msg.Headers[OrderingKeyHeader] = ordered.OrderingKey()
+	}
+}
+
+// orderingKey returns the ordering key of a message
+func orderingKey(msg *Message) string {
+	if key, ok := msg.Headers[OrderingKeyHeader].(string); ok && key != "" {
+		return key
+	}
+
+	return msg.RoutingKey
+}
+
+// workerIndex returns the index of the worker in charge of the given ordering key
+func workerIndex(key string, workers int) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+
+	return int(hash.Sum32() % uint32(workers))
+}
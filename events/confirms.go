@@ -0,0 +1,114 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+var (
+	// ErrPublishNacked is returned when the broker refuses to take responsibility for a message
+	ErrPublishNacked = errors.New("message was nacked by the broker")
+
+	// ErrUnroutable is returned when a message could not be routed to any queue
+	// and has been returned by the broker
+	ErrUnroutable = errors.New("message is unroutable")
+)
+
+// WithConfirms puts the publisher channel in confirm mode and publishes messages as mandatory,
+// so that Publish only succeeds once the broker has accepted the message and routed it to a queue
+func WithConfirms() PublisherOption {
+	return func(opts *publisherOptions) {
+		opts.confirms = true
+	}
+}
+
+// Confirmation is a struct used to await the broker confirmation of a published message
+type Confirmation struct {
+	publisher *RabbitMQPublisher
+	messageID string
+	deferred  *amqp.DeferredConfirmation
+}
+
+// Wait blocks until the broker has confirmed the message or the given context is done. It returns
+// ErrPublishNacked or ErrUnroutable if the message has not been accepted by the broker.
+// It returns immediately when confirms are not enabled on the publisher.
+func (c *Confirmation) Wait(ctx context.Context) error {
+	err := c.wait(ctx)
+	c.publisher.recordResult(err)
+
+	return err
+}
+
+// wait is an internal method which waits for the broker confirmation
+func (c *Confirmation) wait(ctx context.Context) error {
+	if c.deferred == nil {
+		return nil
+	}
+
+	acked := make(chan bool, 1)
+	go func() {
+		acked <- c.deferred.Wait()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case ack := <-acked:
+		// The broker sends back unroutable messages before acknowledging them
+		if returned, ok := c.publisher.returns.pop(c.messageID); ok {
+			return fmt.Errorf("%w: %s", ErrUnroutable, returned.ReplyText)
+		}
+
+		if !ack {
+			return ErrPublishNacked
+		}
+
+		return nil
+	}
+}
+
+// returnTracker is a struct that keeps track of the messages returned by the broker
+type returnTracker struct {
+	mutex    sync.Mutex
+	returned map[string]amqp.Return
+}
+
+// pop removes and returns the returned message with the given id, if any
+func (t *returnTracker) pop(messageID string) (amqp.Return, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	returned, ok := t.returned[messageID]
+	if ok {
+		delete(t.returned, messageID)
+	}
+
+	return returned, ok
+}
+
+// enableConfirms puts the publisher channel in confirm mode and starts listening for returned messages
+func (p *RabbitMQPublisher) enableConfirms() error {
+	if err := p.channel.Confirm(false); err != nil {
+		return err
+	}
+
+	p.returns = &returnTracker{returned: map[string]amqp.Return{}}
+
+	// The returns channel is unbuffered so that the broker dispatch waits for every return
+	// to be received before processing the following acknowledgement
+	returns := p.channel.NotifyReturn(make(chan amqp.Return))
+
+	go func() {
+		for returned := range returns {
+			p.returns.mutex.Lock()
+			p.returns.returned[returned.MessageId] = returned
+			p.returns.mutex.Unlock()
+		}
+	}()
+
+	return nil
+}
@@ -3,6 +3,8 @@ package events
 import (
 	"fmt"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus"
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus/admin"
 	"github.com/PlayEconomy37/Play.Common/configuration"
 	amqp "github.com/rabbitmq/amqp091-go"
 )
@@ -21,6 +23,12 @@ func NewRabbitMQConnection(cfg *configuration.Config) (*amqp.Connection, error)
 }
 
 // NewAzureServiceBusConnection initializes new Azure Service Bus connection
-// func NewAzureServiceBusConnection(connectionString string) (*azservicebus.Client, error) {
-// 	return azservicebus.NewClientFromConnectionString(connectionString, nil)
-// }
+func NewAzureServiceBusConnection(connectionString string) (*azservicebus.Client, error) {
+	return azservicebus.NewClientFromConnectionString(connectionString, nil)
+}
+
+// NewAzureServiceBusAdminClient initializes new Azure Service Bus administration client,
+// used to declare topics and subscriptions
+func NewAzureServiceBusAdminClient(connectionString string) (*admin.Client, error) {
+	return admin.NewClientFromConnectionString(connectionString, nil)
+}
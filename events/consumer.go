@@ -0,0 +1,361 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/PlayEconomy37/Play.Common/logger"
+	"github.com/PlayEconomy37/Play.Common/opentelemetry"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrDeliveriesClosed is returned when the broker closes the deliveries channel of a consumer
+var ErrDeliveriesClosed = errors.New("deliveries channel closed")
+
+// Consumer is an interface that defines a message broker consumer
+type Consumer interface {
+	HandlerRegistrar
+	Use(middlewares ...Middleware)
+
+	// Start starts consuming messages and blocks until the given context is cancelled
+	// or the consumer stops receiving messages. Once the context is cancelled, no new
+	// message is handled and Start returns when in-flight messages have been handled.
+	Start(ctx context.Context) error
+	Close() error
+}
+
+// RequeuePolicy is a custom type which defines whether failed messages are requeued or not
+type RequeuePolicy int8
+
+// Supported requeue policies
+const (
+	RequeueOnce   RequeuePolicy = iota // Requeue failed messages unless they have already been redelivered
+	RequeueNever                       // Never requeue failed messages
+	RequeueAlways                      // Always requeue failed messages
+)
+
+// ConsumerOption is a function used to configure optional behaviour of a consumer
+type ConsumerOption func(*consumerOptions)
+
+// consumerOptions is a struct that holds the optional configuration of a consumer
+type consumerOptions struct {
+	metrics        *opentelemetry.MessageBrokerMetrics
+	logger         *logger.Logger
+	requeuePolicy  RequeuePolicy
+	bindingKeys    []string
+	deadLetter     bool
+	maxAttempts    int
+	retryPolicy    *RetryPolicy
+	prefetch       int
+	workers        int
+	orderedByKey   bool
+	sessions       bool
+	commitInterval time.Duration
+}
+
+// WithConsumerMetrics makes the consumer keep track of incoming messages in the given metrics
+func WithConsumerMetrics(metrics *opentelemetry.MessageBrokerMetrics) ConsumerOption {
+	return func(opts *consumerOptions) {
+		opts.metrics = metrics
+	}
+}
+
+// WithConsumerLogger makes the consumer log handler failures with the given logger
+func WithConsumerLogger(logger *logger.Logger) ConsumerOption {
+	return func(opts *consumerOptions) {
+		opts.logger = logger
+	}
+}
+
+// WithRequeuePolicy sets the policy used to requeue messages whose handler failed
+func WithRequeuePolicy(policy RequeuePolicy) ConsumerOption {
+	return func(opts *consumerOptions) {
+		opts.requeuePolicy = policy
+	}
+}
+
+// WithBindingKeys binds the consumer queue to the exchange with the given routing keys,
+// in addition to the event types of the registered handlers
+func WithBindingKeys(keys ...string) ConsumerOption {
+	return func(opts *consumerOptions) {
+		opts.bindingKeys = append(opts.bindingKeys, keys...)
+	}
+}
+
+// RabbitMQConsumer is a Consumer which consumes events from a RabbitMQ queue
+// bound to a topic exchange
+type RabbitMQConsumer struct {
+	*Router
+	channel  *amqp.Channel
+	exchange string
+	queue    string
+	options  consumerOptions
+
+	// Retry policies per event type, overriding the default retry policy from the options
+	retryPolicies map[string]RetryPolicy
+
+	// AMQP channels must not be used concurrently for publishing
+	publishMutex sync.Mutex
+}
+
+// NewRabbitMQConsumer creates a new RabbitMQ consumer. It opens a dedicated channel on the given
+// connection and declares a durable topic exchange and a durable queue with the given names.
+func NewRabbitMQConsumer(conn *amqp.Connection, exchange, queue string, opts ...ConsumerOption) (*RabbitMQConsumer, error) {
+	channel, err := conn.Channel()
+	if err != nil {
+		return nil, err
+	}
+
+	consumer := &RabbitMQConsumer{
+		Router:        NewRouter(),
+		channel:       channel,
+		exchange:      exchange,
+		queue:         queue,
+		retryPolicies: map[string]RetryPolicy{},
+	}
+
+	for _, opt := range opts {
+		opt(&consumer.options)
+	}
+
+	err = consumer.declareTopology()
+	if err != nil {
+		channel.Close()
+		return nil, err
+	}
+
+	return consumer, nil
+}
+
+// declareTopology declares the exchange and queue of the consumer, plus the
+// dead-letter exchange and queue if dead-lettering is enabled
+func (c *RabbitMQConsumer) declareTopology() error {
+	err := c.channel.ExchangeDeclare(c.exchange, amqp.ExchangeTopic, true, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+
+	var queueArgs amqp.Table
+
+	if c.options.deadLetter {
+		queueArgs, err = c.declareDeadLetterTopology()
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = c.channel.QueueDeclare(c.queue, true, false, false, false, queueArgs)
+
+	return err
+}
+
+// Start binds the queue to the exchange for every registered event type and starts
+// consuming messages with the configured prefetch and number of workers.
+// It blocks until the given context is cancelled.
+func (c *RabbitMQConsumer) Start(ctx context.Context) error {
+	bindingKeys := append(c.EventTypes(), c.options.bindingKeys...)
+	for _, key := range bindingKeys {
+		if err := c.channel.QueueBind(c.queue, key, c.exchange, false, nil); err != nil {
+			return err
+		}
+	}
+
+	if c.options.prefetch > 0 {
+		if err := c.channel.Qos(c.options.prefetch, 0, false); err != nil {
+			return err
+		}
+	}
+
+	deliveries, err := c.channel.Consume(c.queue, "", false, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+
+	return c.consume(ctx, deliveries)
+}
+
+// consume distributes deliveries to the consumer workers until the given context is cancelled
+// or the deliveries channel is closed, then waits for in-flight messages to be handled.
+// When ordered processing is enabled, every worker gets its own queue and messages are
+// assigned to workers by ordering key. Otherwise, all workers share the same queue.
+func (c *RabbitMQConsumer) consume(ctx context.Context, deliveries <-chan amqp.Delivery) error {
+	workers := c.options.workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	workerQueues := make([]chan amqp.Delivery, 1)
+	if c.options.orderedByKey {
+		workerQueues = make([]chan amqp.Delivery, workers)
+	}
+
+	for i := range workerQueues {
+		workerQueues[i] = make(chan amqp.Delivery)
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		workerQueue := workerQueues[i%len(workerQueues)]
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for delivery := range workerQueue {
+				c.handleDelivery(detach(ctx), delivery)
+			}
+		}()
+	}
+
+	// Stop the workers once they have handled the deliveries already dispatched to them
+	defer func() {
+		for _, workerQueue := range workerQueues {
+			close(workerQueue)
+		}
+
+		wg.Wait()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case delivery, ok := <-deliveries:
+			if !ok {
+				return ErrDeliveriesClosed
+			}
+
+			workerQueue := workerQueues[0]
+			if c.options.orderedByKey {
+				workerQueue = workerQueues[workerIndex(orderingKey(messageFromDelivery(delivery)), workers)]
+			}
+
+			select {
+			case workerQueue <- delivery:
+			case <-ctx.Done():
+				// The delivery will be redelivered by the broker since it hasn't been acknowledged
+				return nil
+			}
+		}
+	}
+}
+
+// handleDelivery processes a single delivery and acknowledges it depending on the handler result
+func (c *RabbitMQConsumer) handleDelivery(ctx context.Context, delivery amqp.Delivery) {
+	msg := messageFromDelivery(delivery)
+
+	observe := observeProcessing(c.options.metrics, c.queue, msg.Type)
+
+	err := c.process(ctx, msg)
+	observe(err)
+
+	if err != nil {
+		if c.options.logger != nil {
+			c.options.logger.Error(err, map[string]string{
+				"queue":      c.queue,
+				"message_id": msg.ID,
+				"event_type": msg.Type,
+			})
+		}
+
+		c.handleFailure(ctx, delivery, msg.Type, err)
+
+		return
+	}
+
+	delivery.Ack(false)
+}
+
+// process dispatches the message to its handler inside a tracing span, recovering any panic
+func (c *RabbitMQConsumer) process(ctx context.Context, msg *Message) (err error) {
+	// Continue the trace started by the publisher and expose the envelope to handlers,
+	// so that events published by them are correlated with this one
+	ctx = ExtractTraceContext(ctx, msg)
+	ctx = ContextWithEnvelope(ctx, msg.Envelope)
+
+	ctx, span := tracer.Start(
+		ctx,
+		c.queue+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String("rabbitmq"),
+			semconv.MessagingDestinationKey.String(c.queue),
+			semconv.MessagingDestinationKindQueue,
+			semconv.MessagingOperationProcess,
+			semconv.MessagingMessageIDKey.String(msg.ID),
+			semconv.MessagingConversationIDKey.String(msg.CorrelationID),
+			semconv.MessagingRabbitmqRoutingKeyKey.String(msg.RoutingKey),
+		),
+	)
+	defer span.End()
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%s", recovered)
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}()
+
+	return c.Dispatch(ctx, msg)
+}
+
+// shouldRequeue returns whether a failed delivery must be requeued according to the requeue policy
+func (c *RabbitMQConsumer) shouldRequeue(delivery amqp.Delivery) bool {
+	switch c.options.requeuePolicy {
+	case RequeueAlways:
+		return true
+	case RequeueNever:
+		return false
+	default:
+		return !delivery.Redelivered
+	}
+}
+
+// Close closes the consumer channel
+func (c *RabbitMQConsumer) Close() error {
+	return c.channel.Close()
+}
+
+// messageFromDelivery converts an AMQP delivery into a Message
+func messageFromDelivery(delivery amqp.Delivery) *Message {
+	headers := make(map[string]any, len(delivery.Headers))
+	for key, value := range delivery.Headers {
+		headers[key] = value
+	}
+
+	// Messages published without a type fall back to their routing key
+	eventType := delivery.Type
+	if eventType == "" {
+		eventType = delivery.RoutingKey
+	}
+
+	msg := &Message{
+		Envelope: Envelope{
+			ID:            delivery.MessageId,
+			Type:          eventType,
+			OccurredAt:    delivery.Timestamp,
+			CorrelationID: delivery.CorrelationId,
+			Producer:      delivery.AppId,
+		},
+		ContentType: delivery.ContentType,
+		RoutingKey:  delivery.RoutingKey,
+		ReplyTo:     delivery.ReplyTo,
+		Headers:     headers,
+		Body:        delivery.Body,
+	}
+
+	envelopeFromHeaders(&msg.Envelope, headers)
+
+	return msg
+}
@@ -0,0 +1,198 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Headers used to keep track of failed deliveries
+const (
+	AttemptsHeader           = "x-attempts"
+	FailureReasonHeader      = "x-failure-reason"
+	FailedAtHeader           = "x-failed-at"
+	FailedQueueHeader        = "x-failed-queue"
+	OriginalExchangeHeader   = "x-original-exchange"
+	OriginalRoutingKeyHeader = "x-original-routing-key"
+)
+
+// ErrDeadLetterDisabled is returned when trying to re-drive dead letters on a consumer
+// which doesn't have dead-lettering enabled
+var ErrDeadLetterDisabled = errors.New("dead-lettering is not enabled for this consumer")
+
+// WithDeadLetterQueue enables dead-lettering. Failed messages are retried immediately up to maxAttempts
+// times in total (unless a retry policy applies) and are then routed to a dead-letter queue named
+// `<queue>.dlq` with failure metadata headers.
+// Note that RabbitMQ doesn't allow changing the arguments of an existing queue, so enabling
+// dead-lettering on an existing queue requires deleting it first.
+func WithDeadLetterQueue(maxAttempts int) ConsumerOption {
+	return func(opts *consumerOptions) {
+		opts.deadLetter = true
+		opts.maxAttempts = maxAttempts
+	}
+}
+
+// DeadLetterExchange returns the name of the dead-letter exchange used by the consumer
+func (c *RabbitMQConsumer) DeadLetterExchange() string {
+	return c.exchange + ".dlx"
+}
+
+// DeadLetterQueue returns the name of the dead-letter queue used by the consumer
+func (c *RabbitMQConsumer) DeadLetterQueue() string {
+	return c.queue + ".dlq"
+}
+
+// declareDeadLetterTopology declares the dead-letter exchange and queue and returns the arguments
+// of the main queue so that messages rejected without requeue are also dead-lettered
+func (c *RabbitMQConsumer) declareDeadLetterTopology() (amqp.Table, error) {
+	err := c.channel.ExchangeDeclare(c.DeadLetterExchange(), amqp.ExchangeDirect, true, false, false, false, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = c.channel.QueueDeclare(c.DeadLetterQueue(), true, false, false, false, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.channel.QueueBind(c.DeadLetterQueue(), c.queue, c.DeadLetterExchange(), false, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return amqp.Table{
+		"x-dead-letter-exchange":    c.DeadLetterExchange(),
+		"x-dead-letter-routing-key": c.queue,
+	}, nil
+}
+
+// deadLetter routes a failed delivery to the dead-letter exchange with failure metadata headers.
+// The original delivery is acknowledged once the copy has been published.
+func (c *RabbitMQConsumer) deadLetter(ctx context.Context, delivery amqp.Delivery, attempts int, handlerErr error) {
+	c.republish(ctx, delivery, c.DeadLetterExchange(), c.queue, amqp.Table{
+		AttemptsHeader:      int32(attempts),
+		FailureReasonHeader: handlerErr.Error(),
+		FailedAtHeader:      time.Now().UTC().Format(time.RFC3339),
+		FailedQueueHeader:   c.queue,
+	})
+}
+
+// republish publishes a copy of a delivery with additional headers to the given exchange
+// and routing key, keeping track of the original exchange and routing key. The original delivery
+// is acknowledged once the copy has been published, or requeued if publishing failed so that it isn't lost.
+func (c *RabbitMQConsumer) republish(ctx context.Context, delivery amqp.Delivery, exchange, routingKey string, headers amqp.Table) {
+	publishing := publishingFromDelivery(delivery)
+	for key, value := range headers {
+		publishing.Headers[key] = value
+	}
+
+	if _, ok := publishing.Headers[OriginalExchangeHeader]; !ok {
+		publishing.Headers[OriginalExchangeHeader] = delivery.Exchange
+		publishing.Headers[OriginalRoutingKeyHeader] = delivery.RoutingKey
+	}
+
+	c.publishMutex.Lock()
+	err := c.channel.PublishWithContext(ctx, exchange, routingKey, false, false, publishing)
+	c.publishMutex.Unlock()
+
+	if err != nil {
+		delivery.Nack(false, true)
+		return
+	}
+
+	delivery.Ack(false)
+}
+
+// RedriveDeadLetters moves up to limit messages (all of them if limit is lower or equal to 0)
+// from the dead-letter queue back to their original exchange and routing key, resetting their
+// attempts and failure headers. It returns the number of re-driven messages.
+func (c *RabbitMQConsumer) RedriveDeadLetters(ctx context.Context, limit int) (int, error) {
+	if !c.options.deadLetter {
+		return 0, ErrDeadLetterDisabled
+	}
+
+	redriven := 0
+
+	for limit <= 0 || redriven < limit {
+		delivery, ok, err := c.channel.Get(c.DeadLetterQueue(), false)
+		if err != nil {
+			return redriven, err
+		}
+
+		// Dead-letter queue is empty
+		if !ok {
+			break
+		}
+
+		publishing := publishingFromDelivery(delivery)
+
+		exchange, _ := publishing.Headers[OriginalExchangeHeader].(string)
+		routingKey, _ := publishing.Headers[OriginalRoutingKeyHeader].(string)
+		if routingKey == "" {
+			exchange, routingKey = c.exchange, delivery.Type
+		}
+
+		for _, header := range []string{
+			AttemptsHeader,
+			FailureReasonHeader,
+			FailedAtHeader,
+			FailedQueueHeader,
+			OriginalExchangeHeader,
+			OriginalRoutingKeyHeader,
+			"x-death",
+		} {
+			delete(publishing.Headers, header)
+		}
+
+		c.publishMutex.Lock()
+		err = c.channel.PublishWithContext(ctx, exchange, routingKey, false, false, publishing)
+		c.publishMutex.Unlock()
+
+		if err != nil {
+			delivery.Nack(false, true)
+			return redriven, err
+		}
+
+		delivery.Ack(false)
+		redriven++
+	}
+
+	return redriven, nil
+}
+
+// deliveryAttempts returns the number of failed attempts recorded in the delivery headers
+func deliveryAttempts(headers amqp.Table) int {
+	switch attempts := headers[AttemptsHeader].(type) {
+	case int32:
+		return int(attempts)
+	case int64:
+		return int(attempts)
+	case int:
+		return attempts
+	default:
+		return 0
+	}
+}
+
+// publishingFromDelivery creates a persistent copy of a delivery that can be published again
+func publishingFromDelivery(delivery amqp.Delivery) amqp.Publishing {
+	headers := amqp.Table{}
+	for key, value := range delivery.Headers {
+		headers[key] = value
+	}
+
+	return amqp.Publishing{
+		Headers:       headers,
+		ContentType:   delivery.ContentType,
+		DeliveryMode:  amqp.Persistent,
+		CorrelationId: delivery.CorrelationId,
+		ReplyTo:       delivery.ReplyTo,
+		MessageId:     delivery.MessageId,
+		Timestamp:     delivery.Timestamp,
+		Type:          delivery.Type,
+		AppId:         delivery.AppId,
+		Body:          delivery.Body,
+	}
+}
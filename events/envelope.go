@@ -0,0 +1,114 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Headers used to carry the envelope metadata which has no equivalent message property
+const (
+	EventVersionHeader = "x-event-version"
+	CausationIDHeader  = "x-causation-id"
+)
+
+// DefaultEventVersion is the version of events which do not implement VersionedEvent
+const DefaultEventVersion = 1
+
+// VersionedEvent is an interface implemented by events which have evolved over time
+type VersionedEvent interface {
+	Event
+	EventVersion() int
+}
+
+// Envelope is a struct that holds the metadata of an event sent through the message broker.
+// The correlation id is shared by every event of the same flow while the causation id is
+// the id of the event which caused this one to be published.
+type Envelope struct {
+	ID            string
+	Type          string
+	Version       int
+	OccurredAt    time.Time
+	CorrelationID string
+	CausationID   string
+	Producer      string
+}
+
+// envelopeContextKey is the key used to store the envelope in a context
+type envelopeContextKey struct{}
+
+// ContextWithEnvelope returns a copy of the context holding the given envelope
+func ContextWithEnvelope(ctx context.Context, envelope Envelope) context.Context {
+	return context.WithValue(ctx, envelopeContextKey{}, envelope)
+}
+
+// EnvelopeFromContext returns the envelope of the event being handled
+func EnvelopeFromContext(ctx context.Context) (Envelope, bool) {
+	envelope, ok := ctx.Value(envelopeContextKey{}).(Envelope)
+	return envelope, ok
+}
+
+// WithProducer sets the name of the service publishing events, usually cfg.ServiceName
+func WithProducer(serviceName string) PublisherOption {
+	return func(opts *publisherOptions) {
+		opts.producer = serviceName
+	}
+}
+
+// eventVersion returns the version of the given event
+func eventVersion(event Event) int {
+	if versioned, ok := event.(VersionedEvent); ok {
+		return versioned.EventVersion()
+	}
+
+	return DefaultEventVersion
+}
+
+// correlate sets the correlation and causation ids of a message published while handling
+// another event. Messages starting a new flow are correlated with themselves.
+func correlate(ctx context.Context, msg *Message) {
+	cause, ok := EnvelopeFromContext(ctx)
+	if !ok {
+		msg.CorrelationID = msg.ID
+		return
+	}
+
+	msg.CorrelationID = cause.CorrelationID
+	if msg.CorrelationID == "" {
+		msg.CorrelationID = cause.ID
+	}
+
+	msg.CausationID = cause.ID
+}
+
+// setEnvelopeHeaders stores the envelope metadata which has no message property in the message headers
+func setEnvelopeHeaders(msg *Message) {
+	msg.Headers[EventVersionHeader] = int32(msg.Version)
+
+	if msg.CausationID != "" {
+		msg.Headers[CausationIDHeader] = msg.CausationID
+	}
+}
+
+// envelopeFromHeaders reads the envelope metadata stored in the message headers
+func envelopeFromHeaders(envelope *Envelope, headers map[string]any) {
+	envelope.Version = DefaultEventVersion
+
+	switch version := headers[EventVersionHeader].(type) {
+	case int32:
+		envelope.Version = int(version)
+	case int64:
+		envelope.Version = int(version)
+	case int:
+		envelope.Version = version
+	case string:
+		if parsed, err := strconv.Atoi(version); err == nil {
+			envelope.Version = parsed
+		}
+	}
+
+	if causationID, ok := headers[CausationIDHeader]; ok {
+		envelope.CausationID = fmt.Sprint(causationID)
+	}
+}
@@ -0,0 +1,65 @@
+package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+// tracerName is the name of the tracer used to instrument our message broker code
+const tracerName = "github.com/PlayEconomy37/Play.Common/events"
+
+// tracer is the Opentelemetry tracer used by publishers and consumers
+var tracer = otel.Tracer(tracerName)
+
+// JSONContentType is the content type of messages serialized as JSON
+const JSONContentType = "application/json"
+
+// Event is an interface implemented by every event sent through our message broker.
+// The event type is used as the routing key of published messages and to dispatch
+// incoming messages to the right handler.
+type Event interface {
+	EventType() string
+}
+
+// Message is a struct that holds a message exchanged through the message broker,
+// independently of the underlying broker implementation
+type Message struct {
+	Envelope
+	ContentType string
+	RoutingKey  string
+	ReplyTo     string // Address to which replies must be sent, for RPC requests
+	Headers     map[string]any
+	Body        []byte
+}
+
+// NewMessage serializes the given event with the given serializer into a new message with a unique id.
+// The event is serialized to JSON if no serializer is provided.
+func NewMessage(event Event, serializer Serializer) (*Message, error) {
+	if serializer == nil {
+		serializer = JSONSerializer{}
+	}
+
+	body, err := serializer.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &Message{
+		Envelope: Envelope{
+			ID:         uuid.NewString(),
+			Type:       event.EventType(),
+			Version:    eventVersion(event),
+			OccurredAt: time.Now().UTC(),
+		},
+		ContentType: serializer.ContentType(),
+		RoutingKey:  event.EventType(),
+		Headers:     map[string]any{},
+		Body:        body,
+	}
+
+	setOrderingKey(event, msg)
+
+	return msg, nil
+}
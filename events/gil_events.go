@@ -0,0 +1,62 @@
+package events
+
+import "strconv"
+
+// Types of the gil events
+const (
+	GilDebitedEventType  = "GilDebited"
+	GilCreditedEventType = "GilCredited"
+)
+
+// GilDebitedEvent is the event sent whenever gil is withdrawn from the balance of an user.
+// PurchaseID is set when the gil has been debited as part of a purchase.
+type GilDebitedEvent struct {
+	UserID     int64   `json:"user_id"`
+	Amount     float64 `json:"amount"`
+	Balance    float64 `json:"balance"`
+	PurchaseID string  `json:"purchase_id,omitempty"`
+}
+
+// EventType returns the type of the event
+func (e GilDebitedEvent) EventType() string {
+	return GilDebitedEventType
+}
+
+// EventVersion returns the version of the event schema
+func (e GilDebitedEvent) EventVersion() int {
+	return 1
+}
+
+// OrderingKey returns the id of the user, so that events of the same balance are processed in order
+func (e GilDebitedEvent) OrderingKey() string {
+	return strconv.FormatInt(e.UserID, 10)
+}
+
+// GilCreditedEvent is the event sent whenever gil is added to the balance of an user.
+// PurchaseID is set when the gil has been credited as part of a purchase (i.e. a refund).
+type GilCreditedEvent struct {
+	UserID     int64   `json:"user_id"`
+	Amount     float64 `json:"amount"`
+	Balance    float64 `json:"balance"`
+	PurchaseID string  `json:"purchase_id,omitempty"`
+}
+
+// EventType returns the type of the event
+func (e GilCreditedEvent) EventType() string {
+	return GilCreditedEventType
+}
+
+// EventVersion returns the version of the event schema
+func (e GilCreditedEvent) EventVersion() int {
+	return 1
+}
+
+// OrderingKey returns the id of the user, so that events of the same balance are processed in order
+func (e GilCreditedEvent) OrderingKey() string {
+	return strconv.FormatInt(e.UserID, 10)
+}
+
+func init() {
+	RegisterEventType[GilDebitedEvent](DefaultRegistry)
+	RegisterEventType[GilCreditedEvent](DefaultRegistry)
+}
@@ -0,0 +1,85 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PlayEconomy37/Play.Common/opentelemetry"
+)
+
+// DefaultInboxClaimTTL is the time after which a message claimed by a consumer which crashed
+// before completing it can be claimed again
+const DefaultInboxClaimTTL = 5 * time.Minute
+
+// InProgressRedeliveryDelay is the time after which a message claimed by another consumer is redelivered
+// by the RabbitMQ consumer, to be handled if that consumer released its claim or skipped if it completed it
+const InProgressRedeliveryDelay = 10 * time.Second
+
+var (
+	// ErrAlreadyProcessed is returned by an InboxStore when a message has already been processed
+	ErrAlreadyProcessed = errors.New("message already processed")
+
+	// ErrMessageInProgress is returned by an InboxStore when a message is currently claimed by another
+	// consumer, which may still fail to process it
+	ErrMessageInProgress = errors.New("message is being processed by another consumer")
+)
+
+// InboxStore is an interface that defines a store keeping track of the messages processed by a consumer
+type InboxStore interface {
+	// Claim marks the message as being processed until the claim expires. ErrAlreadyProcessed is
+	// returned if the message is processed, and ErrMessageInProgress if it is claimed by someone else.
+	Claim(ctx context.Context, consumer, messageID string, ttl time.Duration) error
+	// Complete marks a claimed message as processed
+	Complete(ctx context.Context, consumer, messageID string) error
+	// Release removes the claim of a message which failed to be processed, so that it can be retried
+	Release(ctx context.Context, consumer, messageID string) error
+}
+
+// IdempotentMiddleware creates a middleware which skips messages already processed by the given consumer,
+// so that redelivered messages are handled only once. Messages without an id are always handled.
+// Skipped duplicates are counted in the given metrics, which can be nil. Messages claimed by another
+// consumer fail with ErrMessageInProgress, which the RabbitMQ consumer doesn't count as a failed attempt
+// and always redelivers after the InProgressRedeliveryDelay, so that they are handled if that consumer
+// releases its claim or crashes.
+func IdempotentMiddleware(store InboxStore, consumer string, metrics *opentelemetry.MessageBrokerMetrics) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) error {
+			if msg.ID == "" {
+				return next(ctx, msg)
+			}
+
+			err := store.Claim(ctx, consumer, msg.ID, DefaultInboxClaimTTL)
+			if err != nil {
+				if errors.Is(err, ErrAlreadyProcessed) {
+					if metrics != nil {
+						metrics.DuplicateMessagesCounter.WithLabelValues(consumer).Inc()
+					}
+
+					return nil
+				}
+
+				if errors.Is(err, ErrMessageInProgress) {
+					return fmt.Errorf("%w: %s", ErrMessageInProgress, msg.ID)
+				}
+
+				return err
+			}
+
+			if err := next(ctx, msg); err != nil {
+				// Use a fresh context so that the claim is released even if the handler context was canceled
+				releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+
+				if releaseErr := store.Release(releaseCtx, consumer, msg.ID); releaseErr != nil {
+					return fmt.Errorf("%w (failed to release inbox claim: %v)", err, releaseErr)
+				}
+
+				return err
+			}
+
+			return store.Complete(ctx, consumer, msg.ID)
+		}
+	}
+}
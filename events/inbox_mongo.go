@@ -0,0 +1,102 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// InboxCollection is used as the collection name for storing processed messages in MongoDB
+const InboxCollection = "inbox"
+
+// MongoInboxStore is an InboxStore backed by a MongoDB collection
+type MongoInboxStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoInboxStore creates a new MongoInboxStore which stores messages in the inbox collection of the given database
+func NewMongoInboxStore(client *mongo.Client, database string) *MongoInboxStore {
+	return &MongoInboxStore{collection: client.Database(database).Collection(InboxCollection)}
+}
+
+// EnsureIndexes creates a TTL index removing processed messages after the given retention.
+// Retention must be longer than the time a message can stay in a queue.
+func (s *MongoInboxStore) EnsureIndexes(ctx context.Context, retention time.Duration) error {
+	_, err := s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "processed_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(retention.Seconds())),
+	})
+
+	return err
+}
+
+// Claim marks the message as being processed by the given consumer until the claim expires
+func (s *MongoInboxStore) Claim(ctx context.Context, consumer, messageID string, ttl time.Duration) error {
+	now := time.Now().UTC()
+
+	// Only match an expired claim. If the message is unknown it is inserted by the upsert,
+	// and if it is processed or claimed the upsert fails with a duplicate key error.
+	filter := bson.M{
+		"_id":           inboxID(consumer, messageID),
+		"processed_at":  bson.M{"$exists": false},
+		"claim_expires": bson.M{"$lte": now},
+	}
+	update := bson.M{"$set": bson.M{"claim_expires": now.Add(ttl)}}
+
+	_, err := s.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return s.claimError(ctx, consumer, messageID)
+	}
+
+	return err
+}
+
+// claimError returns ErrAlreadyProcessed if the message which couldn't be claimed has been processed,
+// or ErrMessageInProgress if another consumer holds a live claim on it
+func (s *MongoInboxStore) claimError(ctx context.Context, consumer, messageID string) error {
+	var document struct {
+		ProcessedAt *time.Time `bson:"processed_at"`
+	}
+
+	err := s.collection.FindOne(ctx, bson.M{"_id": inboxID(consumer, messageID)}).Decode(&document)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+
+	// The claim may have been released in the meantime, in which case the message is retried
+	if document.ProcessedAt == nil {
+		return ErrMessageInProgress
+	}
+
+	return ErrAlreadyProcessed
+}
+
+// Complete marks a claimed message as processed
+func (s *MongoInboxStore) Complete(ctx context.Context, consumer, messageID string) error {
+	update := bson.M{
+		"$set":   bson.M{"processed_at": time.Now().UTC()},
+		"$unset": bson.M{"claim_expires": ""},
+	}
+
+	_, err := s.collection.UpdateByID(ctx, inboxID(consumer, messageID), update)
+
+	return err
+}
+
+// Release removes the claim of a message which failed to be processed
+func (s *MongoInboxStore) Release(ctx context.Context, consumer, messageID string) error {
+	filter := bson.M{"_id": inboxID(consumer, messageID), "processed_at": bson.M{"$exists": false}}
+
+	_, err := s.collection.DeleteOne(ctx, filter)
+
+	return err
+}
+
+// inboxID returns the id of the inbox document of a message handled by a consumer
+func inboxID(consumer, messageID string) string {
+	return consumer + ":" + messageID
+}
@@ -0,0 +1,58 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeInboxStore is an InboxStore whose Claim returns the given error
+type fakeInboxStore struct {
+	claimErr error
+}
+
+func (s *fakeInboxStore) Claim(ctx context.Context, consumer, messageID string, ttl time.Duration) error {
+	return s.claimErr
+}
+
+func (s *fakeInboxStore) Complete(ctx context.Context, consumer, messageID string) error {
+	return nil
+}
+
+func (s *fakeInboxStore) Release(ctx context.Context, consumer, messageID string) error {
+	return nil
+}
+
+func TestIdempotentMiddleware(t *testing.T) {
+	tests := []struct {
+		name        string
+		claimErr    error
+		wantHandled bool
+		wantErr     error
+	}{
+		{name: "new message", claimErr: nil, wantHandled: true},
+		{name: "processed message", claimErr: ErrAlreadyProcessed, wantHandled: false},
+		{name: "message in progress", claimErr: ErrMessageInProgress, wantHandled: false, wantErr: ErrMessageInProgress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handled := false
+			handler := IdempotentMiddleware(&fakeInboxStore{claimErr: tt.claimErr}, "test", nil)(func(ctx context.Context, msg *Message) error {
+				handled = true
+				return nil
+			})
+
+			err := handler(context.Background(), &Message{Envelope: Envelope{ID: "1"}})
+
+			if handled != tt.wantHandled {
+				t.Errorf("want handled %t; got %t", tt.wantHandled, handled)
+			}
+
+			if tt.wantErr == nil && err != nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("want error %v; got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
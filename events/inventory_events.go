@@ -0,0 +1,35 @@
+package events
+
+import "strconv"
+
+// InventoryItemGrantedEventType is the type of the InventoryItemGrantedEvent
+const InventoryItemGrantedEventType = "InventoryItemGranted"
+
+// InventoryItemGrantedEvent is the event sent whenever catalog items are added to the inventory of an user.
+// PurchaseID is set when the items have been granted as part of a purchase.
+type InventoryItemGrantedEvent struct {
+	UserID        int64  `json:"user_id"`
+	CatalogItemID int64  `json:"catalog_item_id"`
+	Quantity      int    `json:"quantity"`
+	TotalQuantity int    `json:"total_quantity"`
+	PurchaseID    string `json:"purchase_id,omitempty"`
+}
+
+// EventType returns the type of the event
+func (e InventoryItemGrantedEvent) EventType() string {
+	return InventoryItemGrantedEventType
+}
+
+// EventVersion returns the version of the event schema
+func (e InventoryItemGrantedEvent) EventVersion() int {
+	return 1
+}
+
+// OrderingKey returns the id of the user, so that events of the same inventory are processed in order
+func (e InventoryItemGrantedEvent) OrderingKey() string {
+	return strconv.FormatInt(e.UserID, 10)
+}
+
+func init() {
+	RegisterEventType[InventoryItemGrantedEvent](DefaultRegistry)
+}
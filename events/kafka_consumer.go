@@ -0,0 +1,222 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithCommitInterval sets the interval at which the offsets of handled messages are committed.
+// By default, offsets are committed synchronously after every message, which minimizes redeliveries
+// at the cost of throughput. Kafka only.
+func WithCommitInterval(interval time.Duration) ConsumerOption {
+	return func(opts *consumerOptions) {
+		opts.commitInterval = interval
+	}
+}
+
+// KafkaConsumer is a Consumer which consumes events from a Kafka topic as part of a consumer group.
+// Messages of a partition are handled sequentially, so concurrency is achieved by running several
+// consumers of the same group. Since Kafka doesn't redeliver messages, failed messages are retried
+// in place according to the retry policy, then written to the `<group>.dlq` topic when dead-lettering
+// is enabled, or skipped otherwise.
+type KafkaConsumer struct {
+	*Router
+	reader     *kafka.Reader
+	deadLetter *kafka.Writer
+	topic      string
+	group      string
+	options    consumerOptions
+}
+
+// NewKafkaConsumer creates a new Kafka consumer of the given topic, in the given consumer group
+func NewKafkaConsumer(brokers []string, topic, group string, opts ...ConsumerOption) *KafkaConsumer {
+	consumer := &KafkaConsumer{
+		Router: NewRouter(),
+		topic:  topic,
+		group:  group,
+	}
+
+	for _, opt := range opts {
+		opt(&consumer.options)
+	}
+
+	consumer.reader = kafka.NewReader(kafka.ReaderConfig{
+		Brokers:        brokers,
+		Topic:          topic,
+		GroupID:        group,
+		CommitInterval: consumer.options.commitInterval,
+		StartOffset:    kafka.FirstOffset,
+	})
+
+	if consumer.options.deadLetter {
+		consumer.deadLetter = &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Topic:                  consumer.DeadLetterTopic(),
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			BatchTimeout:           10 * time.Millisecond,
+			AllowAutoTopicCreation: true,
+		}
+	}
+
+	return consumer
+}
+
+// DeadLetterTopic returns the name of the topic to which failed messages are written
+func (c *KafkaConsumer) DeadLetterTopic() string {
+	return c.group + ".dlq"
+}
+
+// Start fetches messages from the topic and commits their offset once they have been handled.
+// Messages of event types without handler are skipped. It blocks until the given context is cancelled.
+func (c *KafkaConsumer) Start(ctx context.Context) error {
+	for {
+		message, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		msg := messageFromKafka(message)
+
+		if c.hasHandler(msg.Type) {
+			if err := c.handleMessage(ctx, message, msg); err != nil {
+				// The offset isn't committed, so the message is fetched again after a restart
+				if ctx.Err() != nil {
+					return nil
+				}
+
+				return err
+			}
+		}
+
+		// Commit even if the consumer is stopping, since the message has been handled
+		if err := c.reader.CommitMessages(detach(ctx), message); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+	}
+}
+
+// handleMessage processes a single message, retrying it according to the retry policy.
+// An error is only returned if the message couldn't be dead-lettered.
+func (c *KafkaConsumer) handleMessage(ctx context.Context, message kafka.Message, msg *Message) error {
+	policy := RetryPolicy{MaxAttempts: c.options.maxAttempts}
+	if c.options.retryPolicy != nil {
+		policy = *c.options.retryPolicy
+	}
+
+	for attempts := 1; ; attempts++ {
+		observe := observeProcessing(c.options.metrics, c.group, msg.Type)
+
+		err := c.process(detach(ctx), msg)
+		observe(err)
+
+		if err == nil {
+			return nil
+		}
+
+		if c.options.logger != nil {
+			c.options.logger.Error(err, map[string]string{
+				"topic":      c.topic,
+				"group":      c.group,
+				"message_id": msg.ID,
+				"event_type": msg.Type,
+				"attempt":    strconv.Itoa(attempts),
+			})
+		}
+
+		if IsPermanent(err) || attempts >= policy.MaxAttempts {
+			return c.deadLetterMessage(ctx, message, attempts, err)
+		}
+
+		select {
+		case <-time.After(policy.Delay(attempts)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// deadLetterMessage writes a failed message to the dead-letter topic with failure metadata headers
+func (c *KafkaConsumer) deadLetterMessage(ctx context.Context, message kafka.Message, attempts int, handlerErr error) error {
+	if c.deadLetter == nil {
+		return nil
+	}
+
+	headers := append(
+		message.Headers,
+		kafka.Header{Key: AttemptsHeader, Value: []byte(strconv.Itoa(attempts))},
+		kafka.Header{Key: FailureReasonHeader, Value: []byte(handlerErr.Error())},
+		kafka.Header{Key: FailedAtHeader, Value: []byte(time.Now().UTC().Format(time.RFC3339))},
+		kafka.Header{Key: FailedQueueHeader, Value: []byte(c.group)},
+		kafka.Header{Key: OriginalExchangeHeader, Value: []byte(message.Topic)},
+	)
+
+	return c.deadLetter.WriteMessages(ctx, kafka.Message{
+		Key:     message.Key,
+		Value:   message.Value,
+		Headers: headers,
+		Time:    message.Time,
+	})
+}
+
+// process dispatches the message to its handler inside a tracing span, recovering any panic
+func (c *KafkaConsumer) process(ctx context.Context, msg *Message) (err error) {
+	ctx = ExtractTraceContext(ctx, msg)
+	ctx = ContextWithEnvelope(ctx, msg.Envelope)
+
+	ctx, span := tracer.Start(
+		ctx,
+		c.topic+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String("kafka"),
+			semconv.MessagingDestinationKey.String(c.topic),
+			semconv.MessagingDestinationKindTopic,
+			semconv.MessagingOperationProcess,
+			semconv.MessagingMessageIDKey.String(msg.ID),
+			semconv.MessagingConversationIDKey.String(msg.CorrelationID),
+			semconv.MessagingKafkaConsumerGroupKey.String(c.group),
+		),
+	)
+	defer span.End()
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%s", recovered)
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}()
+
+	return c.Dispatch(ctx, msg)
+}
+
+// Close closes the consumer reader and dead-letter writer
+func (c *KafkaConsumer) Close() error {
+	if c.deadLetter != nil {
+		if err := c.deadLetter.Close(); err != nil {
+			c.reader.Close()
+			return err
+		}
+	}
+
+	return c.reader.Close()
+}
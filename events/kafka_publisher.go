@@ -0,0 +1,186 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Headers used to carry the envelope metadata of Kafka messages, which have no message properties
+const (
+	MessageIDHeader     = "x-message-id"
+	EventTypeHeader     = "x-event-type"
+	ContentTypeHeader   = "content-type"
+	CorrelationIDHeader = "x-correlation-id"
+)
+
+// KafkaPublisher is a Publisher which publishes events to a Kafka topic.
+// Messages are partitioned by ordering key, so that events sharing the same key are consumed in order.
+type KafkaPublisher struct {
+	writer  *kafka.Writer
+	topic   string
+	options publisherOptions
+}
+
+// NewKafkaPublisher creates a new Kafka publisher writing events to the given topic, which is
+// created if it doesn't exist yet and the brokers allow it
+func NewKafkaPublisher(brokers []string, topic string, opts ...PublisherOption) *KafkaPublisher {
+	publisher := &KafkaPublisher{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Topic:                  topic,
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			BatchTimeout:           10 * time.Millisecond,
+			AllowAutoTopicCreation: true,
+		},
+		topic: topic,
+	}
+
+	for _, opt := range opts {
+		opt(&publisher.options)
+	}
+
+	return publisher
+}
+
+// Publish serializes the given event and writes it to the topic, using the ordering key
+// of the message as partitioning key. It waits until the message is acknowledged by all in-sync replicas.
+func (p *KafkaPublisher) Publish(ctx context.Context, event Event) error {
+	ctx, span := tracer.Start(
+		ctx,
+		p.topic+" send",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String("kafka"),
+			semconv.MessagingDestinationKey.String(p.topic),
+			semconv.MessagingDestinationKindTopic,
+		),
+	)
+	defer span.End()
+
+	err := p.publish(ctx, event, span)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	p.recordResult(err)
+
+	return err
+}
+
+// publish is an internal method which serializes and writes the event
+func (p *KafkaPublisher) publish(ctx context.Context, event Event, span trace.Span) error {
+	msg, err := NewMessage(event, p.options.serializer)
+	if err != nil {
+		return err
+	}
+
+	msg.Producer = p.options.producer
+	correlate(ctx, msg)
+	setEnvelopeHeaders(msg)
+
+	if p.options.cloudEvents {
+		if err := encodeCloudEvent(msg); err != nil {
+			return err
+		}
+	}
+
+	key := orderingKey(msg)
+
+	span.SetAttributes(
+		semconv.MessagingMessageIDKey.String(msg.ID),
+		semconv.MessagingConversationIDKey.String(msg.CorrelationID),
+		semconv.MessagingKafkaMessageKeyKey.String(key),
+	)
+
+	// Propagate the trace context to consumers through the message headers
+	InjectTraceContext(ctx, msg)
+
+	return p.writer.WriteMessages(ctx, kafkaMessageFromMessage(msg, key))
+}
+
+// recordResult keeps track of the publishing result in the metrics
+func (p *KafkaPublisher) recordResult(err error) {
+	if p.options.metrics == nil {
+		return
+	}
+
+	if err != nil {
+		p.options.metrics.ErrorOutgoingMessagesCounter.WithLabelValues(p.topic).Inc()
+		return
+	}
+
+	p.options.metrics.OutgoingMessagesCounter.WithLabelValues(p.topic).Inc()
+}
+
+// Close flushes pending messages and closes the publisher writer
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
+
+// kafkaMessageFromMessage converts a Message into a Kafka message with the given key
+func kafkaMessageFromMessage(msg *Message, key string) kafka.Message {
+	headers := []kafka.Header{
+		{Key: MessageIDHeader, Value: []byte(msg.ID)},
+		{Key: EventTypeHeader, Value: []byte(msg.Type)},
+		{Key: ContentTypeHeader, Value: []byte(msg.ContentType)},
+		{Key: CorrelationIDHeader, Value: []byte(msg.CorrelationID)},
+	}
+
+	if msg.Producer != "" {
+		headers = append(headers, kafka.Header{Key: ProducerProperty, Value: []byte(msg.Producer)})
+	}
+
+	for name, value := range msg.Headers {
+		headers = append(headers, kafka.Header{Key: name, Value: []byte(fmt.Sprint(value))})
+	}
+
+	return kafka.Message{
+		Key:     []byte(key),
+		Value:   msg.Body,
+		Headers: headers,
+		Time:    msg.OccurredAt,
+	}
+}
+
+// messageFromKafka converts a Kafka message into a Message
+func messageFromKafka(message kafka.Message) *Message {
+	msg := &Message{
+		Envelope: Envelope{
+			OccurredAt: message.Time,
+		},
+		RoutingKey: string(message.Key),
+		Headers:    make(map[string]any, len(message.Headers)),
+		Body:       message.Value,
+	}
+
+	for _, header := range message.Headers {
+		value := string(header.Value)
+
+		switch header.Key {
+		case MessageIDHeader:
+			msg.ID = value
+		case EventTypeHeader:
+			msg.Type = value
+		case ContentTypeHeader:
+			msg.ContentType = value
+		case CorrelationIDHeader:
+			msg.CorrelationID = value
+		case ProducerProperty:
+			msg.Producer = value
+		default:
+			msg.Headers[header.Key] = value
+		}
+	}
+
+	envelopeFromHeaders(&msg.Envelope, msg.Headers)
+
+	return msg
+}
@@ -0,0 +1,188 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// InMemoryBus is a message bus which delivers events to in-memory consumers without any broker.
+// It is meant to be used in tests to assert event flows. With synchronous delivery, events are
+// handled before Publish returns and handler errors are returned by Publish. With buffered delivery,
+// events are queued and handled by the consumers once started.
+type InMemoryBus struct {
+	mutex      sync.Mutex
+	bufferSize int
+	serializer Serializer
+	published  []Event
+	consumers  []*InMemoryConsumer
+
+	// Closed and replaced whenever an event is published, to wake up WaitFor callers
+	notify chan struct{}
+}
+
+// NewInMemoryBus creates a new in-memory bus. Events are delivered synchronously if bufferSize is 0,
+// otherwise every consumer buffers up to bufferSize events.
+func NewInMemoryBus(bufferSize int) *InMemoryBus {
+	return &InMemoryBus{
+		bufferSize: bufferSize,
+		notify:     make(chan struct{}),
+	}
+}
+
+// SetSerializer sets the serializer used to encode published events (JSON by default)
+func (b *InMemoryBus) SetSerializer(serializer Serializer) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.serializer = serializer
+}
+
+// NewConsumer creates a new consumer receiving every event published on the bus after its creation
+func (b *InMemoryBus) NewConsumer() *InMemoryConsumer {
+	consumer := &InMemoryConsumer{Router: NewRouter()}
+	if b.bufferSize > 0 {
+		consumer.messages = make(chan *Message, b.bufferSize)
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.consumers = append(b.consumers, consumer)
+
+	return consumer
+}
+
+// Publish records the event and delivers it to the consumers which have a handler for its type
+func (b *InMemoryBus) Publish(ctx context.Context, event Event) error {
+	b.mutex.Lock()
+
+	msg, err := NewMessage(event, b.serializer)
+	if err != nil {
+		b.mutex.Unlock()
+		return err
+	}
+
+	correlate(ctx, msg)
+
+	b.published = append(b.published, event)
+	consumers := append([]*InMemoryConsumer(nil), b.consumers...)
+
+	close(b.notify)
+	b.notify = make(chan struct{})
+
+	b.mutex.Unlock()
+
+	for _, consumer := range consumers {
+		if !consumer.hasHandler(msg.Type) {
+			continue
+		}
+
+		if err := consumer.deliver(ctx, copyMessage(msg)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PublishedEvents returns every event published so far, in order
+func (b *InMemoryBus) PublishedEvents() []Event {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return append([]Event(nil), b.published...)
+}
+
+// WaitFor waits until an event of the given type has been published and returns the first one.
+// It returns immediately if such an event has already been published.
+func (b *InMemoryBus) WaitFor(ctx context.Context, eventType string) (Event, error) {
+	for {
+		b.mutex.Lock()
+		notify := b.notify
+
+		for _, event := range b.published {
+			if event.EventType() == eventType {
+				b.mutex.Unlock()
+				return event, nil
+			}
+		}
+
+		b.mutex.Unlock()
+
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Reset forgets the events published so far
+func (b *InMemoryBus) Reset() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.published = nil
+}
+
+// Close is a no-op, it only exists to implement the Publisher interface
+func (b *InMemoryBus) Close() error {
+	return nil
+}
+
+// InMemoryConsumer is a Consumer receiving events from an InMemoryBus
+type InMemoryConsumer struct {
+	*Router
+
+	// Nil when events are delivered synchronously
+	messages chan *Message
+}
+
+// Start handles buffered events until the given context is cancelled.
+// With synchronous delivery, it only blocks until the given context is cancelled.
+func (c *InMemoryConsumer) Start(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg := <-c.messages:
+			c.dispatch(ctx, msg)
+		}
+	}
+}
+
+// deliver handles the message right away with synchronous delivery, otherwise buffers it
+func (c *InMemoryConsumer) deliver(ctx context.Context, msg *Message) error {
+	if c.messages == nil {
+		return c.dispatch(ctx, msg)
+	}
+
+	select {
+	case c.messages <- msg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// dispatch executes the message handler with the message envelope in the context
+func (c *InMemoryConsumer) dispatch(ctx context.Context, msg *Message) error {
+	return c.Dispatch(ContextWithEnvelope(ctx, msg.Envelope), msg)
+}
+
+// Close is a no-op, it only exists to implement the Consumer interface
+func (c *InMemoryConsumer) Close() error {
+	return nil
+}
+
+// copyMessage returns a copy of the message which can be modified independently by each consumer
+func copyMessage(msg *Message) *Message {
+	copied := *msg
+
+	copied.Headers = make(map[string]any, len(msg.Headers))
+	for key, value := range msg.Headers {
+		copied.Headers[key] = value
+	}
+
+	return &copied
+}
@@ -0,0 +1,51 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/PlayEconomy37/Play.Common/opentelemetry"
+)
+
+// brokerMetrics holds the message broker metrics created for each application, since
+// Prometheus metrics can only be registered once per process
+var brokerMetrics = struct {
+	sync.Mutex
+	byAppName map[string]*opentelemetry.MessageBrokerMetrics
+}{byAppName: map[string]*opentelemetry.MessageBrokerMetrics{}}
+
+// messageBrokerMetrics returns the message broker metrics of the given application, creating them if needed
+func messageBrokerMetrics(appName string) *opentelemetry.MessageBrokerMetrics {
+	brokerMetrics.Lock()
+	defer brokerMetrics.Unlock()
+
+	metrics, ok := brokerMetrics.byAppName[appName]
+	if !ok {
+		metrics = opentelemetry.CreateMessageBrokerMetrics(appName)
+		brokerMetrics.byAppName[appName] = metrics
+	}
+
+	return metrics
+}
+
+// observeProcessing counts an incoming message and returns a function which records
+// the outcome and the processing duration of the message once it has been handled
+func observeProcessing(metrics *opentelemetry.MessageBrokerMetrics, queue, eventType string) func(err error) {
+	if metrics == nil {
+		return func(error) {}
+	}
+
+	metrics.IncomingMessagesCounter.WithLabelValues(queue, eventType).Inc()
+	started := time.Now()
+
+	return func(err error) {
+		metrics.ProcessingDurationHistogram.WithLabelValues(queue, eventType).Observe(time.Since(started).Seconds())
+
+		if err != nil {
+			metrics.ErrorMessagesCounter.WithLabelValues(queue, eventType).Inc()
+			return
+		}
+
+		metrics.SuccessMessagesCounter.WithLabelValues(queue, eventType).Inc()
+	}
+}
@@ -0,0 +1,180 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// OutboxCollection is used as the collection name for storing outbox messages in MongoDB
+const OutboxCollection = "outbox"
+
+// OutboxStore is an interface that defines a transactional outbox. Events are added to the outbox
+// in the same transaction as the business changes which produced them, then relayed to the
+// message broker by an OutboxRelay.
+type OutboxStore interface {
+	Add(ctx context.Context, event Event) error
+	Pending(ctx context.Context, limit int) ([]*Message, error)
+	MarkSent(ctx context.Context, ids ...string) error
+	// Stats returns the number of pending messages and the time at which the oldest one occurred
+	Stats(ctx context.Context) (int64, time.Time, error)
+}
+
+// outboxDocument is the document stored in MongoDB for every outbox message
+type outboxDocument struct {
+	ID            string         `bson:"_id"`
+	Type          string         `bson:"type"`
+	Version       int            `bson:"version"`
+	OccurredAt    time.Time      `bson:"occurred_at"`
+	CorrelationID string         `bson:"correlation_id,omitempty"`
+	CausationID   string         `bson:"causation_id,omitempty"`
+	Producer      string         `bson:"producer,omitempty"`
+	ContentType   string         `bson:"content_type"`
+	RoutingKey    string         `bson:"routing_key"`
+	Headers       map[string]any `bson:"headers"`
+	Body          []byte         `bson:"body"`
+	SentAt        *time.Time     `bson:"sent_at,omitempty"`
+}
+
+// MongoOutboxStore is an OutboxStore backed by a MongoDB collection
+type MongoOutboxStore struct {
+	collection *mongo.Collection
+	options    publisherOptions
+}
+
+// NewMongoOutboxStore creates a new MongoOutboxStore which stores messages in the outbox collection
+// of the given database. The serializer and producer publisher options apply to added events.
+func NewMongoOutboxStore(client *mongo.Client, database string, opts ...PublisherOption) *MongoOutboxStore {
+	store := &MongoOutboxStore{collection: client.Database(database).Collection(OutboxCollection)}
+
+	for _, opt := range opts {
+		opt(&store.options)
+	}
+
+	return store
+}
+
+// Add serializes the event and stores it in the outbox. Pass the context of a MongoDB session
+// transaction so that the event is only stored if the business changes are committed.
+func (s *MongoOutboxStore) Add(ctx context.Context, event Event) error {
+	msg, err := NewMessage(event, s.options.serializer)
+	if err != nil {
+		return err
+	}
+
+	msg.Producer = s.options.producer
+	correlate(ctx, msg)
+	setEnvelopeHeaders(msg)
+
+	if s.options.cloudEvents {
+		if err := encodeCloudEvent(msg); err != nil {
+			return err
+		}
+	}
+
+	// Keep the trace context of the request which produced the event
+	InjectTraceContext(ctx, msg)
+
+	_, err = s.collection.InsertOne(ctx, outboxDocument{
+		ID:            msg.ID,
+		Type:          msg.Type,
+		Version:       msg.Version,
+		OccurredAt:    msg.OccurredAt,
+		CorrelationID: msg.CorrelationID,
+		CausationID:   msg.CausationID,
+		Producer:      msg.Producer,
+		ContentType:   msg.ContentType,
+		RoutingKey:    msg.RoutingKey,
+		Headers:       msg.Headers,
+		Body:          msg.Body,
+	})
+
+	return err
+}
+
+// Pending returns up to limit messages which haven't been sent yet, oldest first
+func (s *MongoOutboxStore) Pending(ctx context.Context, limit int) ([]*Message, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "occurred_at", Value: 1}}).SetLimit(int64(limit))
+
+	cursor, err := s.collection.Find(ctx, bson.M{"sent_at": bson.M{"$exists": false}}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var documents []outboxDocument
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+
+	messages := make([]*Message, 0, len(documents))
+	for _, document := range documents {
+		messages = append(messages, &Message{
+			Envelope: Envelope{
+				ID:            document.ID,
+				Type:          document.Type,
+				Version:       document.Version,
+				OccurredAt:    document.OccurredAt,
+				CorrelationID: document.CorrelationID,
+				CausationID:   document.CausationID,
+				Producer:      document.Producer,
+			},
+			ContentType: document.ContentType,
+			RoutingKey:  document.RoutingKey,
+			Headers:     document.Headers,
+			Body:        document.Body,
+		})
+	}
+
+	return messages, nil
+}
+
+// MarkSent marks the messages with the given ids as sent
+func (s *MongoOutboxStore) MarkSent(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := s.collection.UpdateMany(
+		ctx,
+		bson.M{"_id": bson.M{"$in": ids}},
+		bson.M{"$set": bson.M{"sent_at": time.Now().UTC()}},
+	)
+
+	return err
+}
+
+// Stats returns the number of pending messages and the time at which the oldest one occurred
+func (s *MongoOutboxStore) Stats(ctx context.Context) (int64, time.Time, error) {
+	filter := bson.M{"sent_at": bson.M{"$exists": false}}
+
+	count, err := s.collection.CountDocuments(ctx, filter)
+	if err != nil || count == 0 {
+		return count, time.Time{}, err
+	}
+
+	var oldest outboxDocument
+
+	opts := options.FindOne().SetSort(bson.D{{Key: "occurred_at", Value: 1}})
+	if err := s.collection.FindOne(ctx, filter, opts).Decode(&oldest); err != nil {
+		return 0, time.Time{}, err
+	}
+
+	return count, oldest.OccurredAt, nil
+}
+
+// EnsureIndexes creates the index used to find pending messages, and a TTL index removing
+// sent messages after the given retention
+func (s *MongoOutboxStore) EnsureIndexes(ctx context.Context, retention time.Duration) error {
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "occurred_at", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "sent_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(retention.Seconds())),
+		},
+	})
+
+	return err
+}
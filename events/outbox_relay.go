@@ -0,0 +1,198 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/PlayEconomy37/Play.Common/locks"
+	"github.com/PlayEconomy37/Play.Common/logger"
+	"github.com/PlayEconomy37/Play.Common/opentelemetry"
+)
+
+// Default settings of the outbox relay
+const (
+	DefaultRelayInterval  = time.Second
+	DefaultRelayBatchSize = 100
+	OutboxRelayLockName   = "outbox-relay" // Name of the lock of the election of the relay
+)
+
+// MessagePublisher is an interface implemented by publishers able to publish already serialized messages
+type MessagePublisher interface {
+	PublishMessage(ctx context.Context, msg *Message) error
+}
+
+// OutboxRelayOption is a function used to configure optional behaviour of an outbox relay
+type OutboxRelayOption func(*OutboxRelay)
+
+// WithRelayInterval sets the interval at which the outbox is polled
+func WithRelayInterval(interval time.Duration) OutboxRelayOption {
+	return func(r *OutboxRelay) {
+		r.interval = interval
+	}
+}
+
+// WithRelayBatchSize sets the maximum number of messages relayed per poll
+func WithRelayBatchSize(batchSize int) OutboxRelayOption {
+	return func(r *OutboxRelay) {
+		r.batchSize = batchSize
+	}
+}
+
+// WithRelayMetrics makes the relay keep track of the outbox lag in the given metrics
+func WithRelayMetrics(metrics *opentelemetry.OutboxMetrics) OutboxRelayOption {
+	return func(r *OutboxRelay) {
+		r.metrics = metrics
+	}
+}
+
+// WithRelayLogger makes the relay log failures with the given logger
+func WithRelayLogger(logger *logger.Logger) OutboxRelayOption {
+	return func(r *OutboxRelay) {
+		r.logger = logger
+	}
+}
+
+// OutboxRelay is a worker which publishes pending outbox messages and marks them as sent.
+// Only the leader elected among the replicas relays messages, which keeps messages in order.
+// Messages are relayed at least once, so consumers should be idempotent.
+type OutboxRelay struct {
+	store     OutboxStore
+	publisher MessagePublisher
+	election  *locks.Election
+	interval  time.Duration
+	batchSize int
+	metrics   *opentelemetry.OutboxMetrics
+	logger    *logger.Logger
+}
+
+// NewOutboxRelay creates a new outbox relay which relays messages while this replica is the leader of
+// the given election. The publisher should have confirms enabled so that messages are only marked
+// as sent once the broker has accepted them.
+//
+//	election := locks.NewElection(locker, events.OutboxRelayLockName)
+//	relay := events.NewOutboxRelay(store, publisher, election)
+func NewOutboxRelay(store OutboxStore, publisher MessagePublisher, election *locks.Election, opts ...OutboxRelayOption) *OutboxRelay {
+	relay := &OutboxRelay{
+		store:     store,
+		publisher: publisher,
+		election:  election,
+		interval:  DefaultRelayInterval,
+		batchSize: DefaultRelayBatchSize,
+	}
+
+	for _, opt := range opts {
+		opt(relay)
+	}
+
+	return relay
+}
+
+// Run polls the outbox while this replica is the leader, until the given context is cancelled
+func (r *OutboxRelay) Run(ctx context.Context) error {
+	return r.election.RunWhenLeader(ctx, r.poll)
+}
+
+// poll polls the outbox until the given context is cancelled, which happens when this replica
+// steps down
+func (r *OutboxRelay) poll(ctx context.Context) error {
+	r.setLeader(true)
+	defer r.setLeader(false)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		relayed := r.relay(ctx)
+		r.recordLag(ctx)
+
+		// Keep going right away while there is a backlog
+		if relayed == r.batchSize {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// relay publishes a batch of pending messages in order and returns the number of relayed messages.
+// It stops at the first failure so that messages aren't published out of order.
+func (r *OutboxRelay) relay(ctx context.Context) int {
+	messages, err := r.store.Pending(ctx, r.batchSize)
+	if err != nil {
+		r.logError(err, nil)
+		return 0
+	}
+
+	for i, msg := range messages {
+		err := r.publisher.PublishMessage(ctx, msg)
+		if err == nil {
+			err = r.store.MarkSent(ctx, msg.ID)
+		}
+
+		if err != nil {
+			if r.metrics != nil {
+				r.metrics.ErrorMessagesCounter.Inc()
+			}
+
+			r.logError(err, map[string]string{"message_id": msg.ID, "event_type": msg.Type})
+
+			return i
+		}
+
+		if r.metrics != nil {
+			r.metrics.RelayedMessagesCounter.Inc()
+		}
+	}
+
+	return len(messages)
+}
+
+// recordLag keeps track of the number of pending messages and the age of the oldest one
+func (r *OutboxRelay) recordLag(ctx context.Context) {
+	if r.metrics == nil {
+		return
+	}
+
+	pending, oldest, err := r.store.Stats(ctx)
+	if err != nil {
+		r.logError(err, nil)
+		return
+	}
+
+	r.metrics.PendingMessagesGauge.Set(float64(pending))
+
+	if pending == 0 {
+		r.metrics.LagGauge.Set(0)
+		return
+	}
+
+	r.metrics.LagGauge.Set(time.Since(oldest).Seconds())
+}
+
+// setLeader keeps track of the leadership of this replica in the metrics
+func (r *OutboxRelay) setLeader(leader bool) {
+	if r.metrics == nil {
+		return
+	}
+
+	if leader {
+		r.metrics.LeaderGauge.Set(1)
+	} else {
+		r.metrics.LeaderGauge.Set(0)
+	}
+}
+
+// logError logs the given error if the relay has a logger
+func (r *OutboxRelay) logError(err error, properties map[string]string) {
+	if r.logger != nil {
+		r.logger.Error(err, properties)
+	}
+}
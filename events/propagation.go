@@ -0,0 +1,60 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+)
+
+// headersCarrier is a map of message headers which implements the propagation.TextMapCarrier
+// interface, so that trace context can be injected into and extracted from messages
+type headersCarrier map[string]any
+
+// Get returns the value associated with the given key
+func (c headersCarrier) Get(key string) string {
+	value, ok := c[key]
+	if !ok {
+		return ""
+	}
+
+	switch value := value.(type) {
+	case string:
+		return value
+	case []byte:
+		return string(value)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// Set stores the given key-value pair
+func (c headersCarrier) Set(key string, value string) {
+	c[key] = value
+}
+
+// Keys lists the keys stored in the carrier
+func (c headersCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// InjectTraceContext injects the trace context (and baggage) from the given context
+// into the message headers using the global propagator
+func InjectTraceContext(ctx context.Context, msg *Message) {
+	if msg.Headers == nil {
+		msg.Headers = map[string]any{}
+	}
+
+	otel.GetTextMapPropagator().Inject(ctx, headersCarrier(msg.Headers))
+}
+
+// ExtractTraceContext returns a copy of the given context holding the trace context
+// (and baggage) extracted from the message headers using the global propagator
+func ExtractTraceContext(ctx context.Context, msg *Message) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, headersCarrier(msg.Headers))
+}
@@ -0,0 +1,259 @@
+package events
+
+import (
+	"context"
+	"sync"
+
+	"github.com/PlayEconomy37/Play.Common/opentelemetry"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Publisher is an interface that defines a message broker publisher
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+	Close() error
+}
+
+// PublisherOption is a function used to configure optional behaviour of a publisher
+type PublisherOption func(*publisherOptions)
+
+// publisherOptions is a struct that holds the optional configuration of a publisher
+type publisherOptions struct {
+	metrics     *opentelemetry.MessageBrokerMetrics
+	confirms    bool
+	serializer  Serializer
+	producer    string
+	cloudEvents bool
+}
+
+// WithPublisherMetrics makes the publisher keep track of outgoing messages in the given metrics
+func WithPublisherMetrics(metrics *opentelemetry.MessageBrokerMetrics) PublisherOption {
+	return func(opts *publisherOptions) {
+		opts.metrics = metrics
+	}
+}
+
+// WithSerializer sets the serializer used to encode published events (JSON by default).
+// The content type of the serializer is set on every message so that consumers can decode it.
+func WithSerializer(serializer Serializer) PublisherOption {
+	return func(opts *publisherOptions) {
+		opts.serializer = serializer
+	}
+}
+
+// RabbitMQPublisher is a Publisher which publishes events to a RabbitMQ topic exchange
+type RabbitMQPublisher struct {
+	channel  *amqp.Channel
+	exchange string
+	options  publisherOptions
+
+	// AMQP channels must not be used concurrently for publishing
+	mutex sync.Mutex
+
+	// Unroutable messages returned by the broker, keyed by message id
+	returns *returnTracker
+}
+
+// NewRabbitMQPublisher creates a new RabbitMQ publisher. It opens a dedicated channel on the given
+// connection and declares a durable topic exchange with the given name.
+func NewRabbitMQPublisher(conn *amqp.Connection, exchange string, opts ...PublisherOption) (*RabbitMQPublisher, error) {
+	channel, err := conn.Channel()
+	if err != nil {
+		return nil, err
+	}
+
+	err = channel.ExchangeDeclare(exchange, amqp.ExchangeTopic, true, false, false, false, nil)
+	if err != nil {
+		channel.Close()
+		return nil, err
+	}
+
+	publisher := &RabbitMQPublisher{
+		channel:  channel,
+		exchange: exchange,
+	}
+
+	for _, opt := range opts {
+		opt(&publisher.options)
+	}
+
+	if publisher.options.confirms {
+		if err := publisher.enableConfirms(); err != nil {
+			channel.Close()
+			return nil, err
+		}
+	}
+
+	return publisher, nil
+}
+
+// Publish serializes the given event and publishes it as a persistent message,
+// using the event type as routing key. When confirms are enabled, it waits until
+// the broker has confirmed the message.
+func (p *RabbitMQPublisher) Publish(ctx context.Context, event Event) error {
+	confirmation, err := p.PublishAsync(ctx, event)
+	if err != nil {
+		return err
+	}
+
+	return confirmation.Wait(ctx)
+}
+
+// PublishAsync serializes the given event and publishes it as a persistent message without
+// waiting for the broker confirmation. The returned Confirmation can be awaited later on.
+func (p *RabbitMQPublisher) PublishAsync(ctx context.Context, event Event) (*Confirmation, error) {
+	ctx, span := p.startSpan(ctx, event.EventType())
+	defer span.End()
+
+	confirmation, err := p.publish(ctx, event, span)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		p.recordResult(err)
+
+		return nil, err
+	}
+
+	return confirmation, nil
+}
+
+// publish is an internal method which serializes and publishes the event
+func (p *RabbitMQPublisher) publish(ctx context.Context, event Event, span trace.Span) (*Confirmation, error) {
+	msg, err := p.newMessage(ctx, event, p.options.serializer)
+	if err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(
+		semconv.MessagingMessageIDKey.String(msg.ID),
+		semconv.MessagingConversationIDKey.String(msg.CorrelationID),
+	)
+
+	// Propagate the trace context to consumers through the message headers
+	InjectTraceContext(ctx, msg)
+
+	return p.send(ctx, msg)
+}
+
+// PublishMessage publishes an already serialized message as is and waits until the broker has
+// confirmed it when confirms are enabled. It is used to relay messages stored beforehand, which
+// already carry their envelope and trace context.
+func (p *RabbitMQPublisher) PublishMessage(ctx context.Context, msg *Message) error {
+	ctx, span := p.startSpan(ctx, msg.RoutingKey)
+	defer span.End()
+
+	span.SetAttributes(
+		semconv.MessagingMessageIDKey.String(msg.ID),
+		semconv.MessagingConversationIDKey.String(msg.CorrelationID),
+	)
+
+	confirmation, err := p.send(ctx, msg)
+	if err == nil {
+		err = confirmation.Wait(ctx)
+	} else {
+		p.recordResult(err)
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
+
+// startSpan starts a producer span for a message published with the given routing key
+func (p *RabbitMQPublisher) startSpan(ctx context.Context, routingKey string) (context.Context, trace.Span) {
+	return tracer.Start(
+		ctx,
+		p.exchange+" send",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String("rabbitmq"),
+			semconv.MessagingDestinationKey.String(p.exchange),
+			semconv.MessagingDestinationKindTopic,
+			semconv.MessagingRabbitmqRoutingKeyKey.String(routingKey),
+		),
+	)
+}
+
+// send is an internal method which publishes a message to the exchange
+func (p *RabbitMQPublisher) send(ctx context.Context, msg *Message) (*Confirmation, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	// Messages are published as mandatory when confirms are enabled, so that
+	// unroutable messages are returned by the broker instead of silently dropped
+	deferred, err := p.channel.PublishWithDeferredConfirmWithContext(
+		ctx,
+		p.exchange,
+		msg.RoutingKey,
+		p.options.confirms,
+		false,
+		publishingFromMessage(msg),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Confirmation{publisher: p, messageID: msg.ID, deferred: deferred}, nil
+}
+
+// newMessage serializes the event with the given serializer into a new message carrying
+// the publisher envelope metadata
+func (p *RabbitMQPublisher) newMessage(ctx context.Context, event Event, serializer Serializer) (*Message, error) {
+	msg, err := NewMessage(event, serializer)
+	if err != nil {
+		return nil, err
+	}
+
+	msg.Producer = p.options.producer
+	correlate(ctx, msg)
+	setEnvelopeHeaders(msg)
+
+	if p.options.cloudEvents {
+		if err := encodeCloudEvent(msg); err != nil {
+			return nil, err
+		}
+	}
+
+	return msg, nil
+}
+
+// recordResult keeps track of the publishing result in the metrics
+func (p *RabbitMQPublisher) recordResult(err error) {
+	if p.options.metrics == nil {
+		return
+	}
+
+	if err != nil {
+		p.options.metrics.ErrorOutgoingMessagesCounter.WithLabelValues(p.exchange).Inc()
+		return
+	}
+
+	p.options.metrics.OutgoingMessagesCounter.WithLabelValues(p.exchange).Inc()
+}
+
+// Close closes the publisher channel
+func (p *RabbitMQPublisher) Close() error {
+	return p.channel.Close()
+}
+
+// publishingFromMessage converts a Message into an AMQP publishing
+func publishingFromMessage(msg *Message) amqp.Publishing {
+	return amqp.Publishing{
+		Headers:       msg.Headers,
+		ContentType:   msg.ContentType,
+		DeliveryMode:  amqp.Persistent,
+		CorrelationId: msg.CorrelationID,
+		ReplyTo:       msg.ReplyTo,
+		MessageId:     msg.ID,
+		Timestamp:     msg.OccurredAt,
+		Type:          msg.Type,
+		AppId:         msg.Producer,
+		Body:          msg.Body,
+	}
+}
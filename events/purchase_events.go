@@ -0,0 +1,87 @@
+package events
+
+// Types of the purchase events
+const (
+	PurchaseRequestedEventType = "PurchaseRequested"
+	PurchaseCompletedEventType = "PurchaseCompleted"
+	PurchaseFaultedEventType   = "PurchaseFaulted"
+)
+
+// PurchaseRequestedEvent is the event sent whenever an user wants to buy catalog items.
+// It starts the purchase saga, whose events all share the same PurchaseID.
+type PurchaseRequestedEvent struct {
+	PurchaseID string `json:"purchase_id"`
+	UserID     int64  `json:"user_id"`
+	ItemID     int64  `json:"item_id"`
+	Quantity   int    `json:"quantity"`
+}
+
+// EventType returns the type of the event
+func (e PurchaseRequestedEvent) EventType() string {
+	return PurchaseRequestedEventType
+}
+
+// EventVersion returns the version of the event schema
+func (e PurchaseRequestedEvent) EventVersion() int {
+	return 1
+}
+
+// OrderingKey returns the id of the purchase, so that events of the same purchase are processed in order
+func (e PurchaseRequestedEvent) OrderingKey() string {
+	return e.PurchaseID
+}
+
+// PurchaseCompletedEvent is the event sent once the gil of an user has been debited
+// and the purchased items have been granted
+type PurchaseCompletedEvent struct {
+	PurchaseID string  `json:"purchase_id"`
+	UserID     int64   `json:"user_id"`
+	ItemID     int64   `json:"item_id"`
+	Quantity   int     `json:"quantity"`
+	TotalPrice float64 `json:"total_price"`
+}
+
+// EventType returns the type of the event
+func (e PurchaseCompletedEvent) EventType() string {
+	return PurchaseCompletedEventType
+}
+
+// EventVersion returns the version of the event schema
+func (e PurchaseCompletedEvent) EventVersion() int {
+	return 1
+}
+
+// OrderingKey returns the id of the purchase, so that events of the same purchase are processed in order
+func (e PurchaseCompletedEvent) OrderingKey() string {
+	return e.PurchaseID
+}
+
+// PurchaseFaultedEvent is the event sent when a purchase could not be completed
+type PurchaseFaultedEvent struct {
+	PurchaseID string `json:"purchase_id"`
+	UserID     int64  `json:"user_id"`
+	ItemID     int64  `json:"item_id"`
+	Quantity   int    `json:"quantity"`
+	Reason     string `json:"reason"`
+}
+
+// EventType returns the type of the event
+func (e PurchaseFaultedEvent) EventType() string {
+	return PurchaseFaultedEventType
+}
+
+// EventVersion returns the version of the event schema
+func (e PurchaseFaultedEvent) EventVersion() int {
+	return 1
+}
+
+// OrderingKey returns the id of the purchase, so that events of the same purchase are processed in order
+func (e PurchaseFaultedEvent) OrderingKey() string {
+	return e.PurchaseID
+}
+
+func init() {
+	RegisterEventType[PurchaseRequestedEvent](DefaultRegistry)
+	RegisterEventType[PurchaseCompletedEvent](DefaultRegistry)
+	RegisterEventType[PurchaseFaultedEvent](DefaultRegistry)
+}
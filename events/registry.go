@@ -0,0 +1,110 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// ErrUnknownEventType is returned when decoding a message whose event type isn't registered
+var ErrUnknownEventType = errors.New("unknown event type")
+
+// Registry is a struct which maps event type names to Go types, so that messages of several
+// event types coming from a single queue can be decoded into the right struct
+type Registry struct {
+	mutex sync.RWMutex
+	types map[string]reflect.Type
+}
+
+// DefaultRegistry is the registry holding the shared event types of this package
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry creates a new empty Registry
+func NewRegistry() *Registry {
+	return &Registry{types: map[string]reflect.Type{}}
+}
+
+// RegisterEventType registers T under its event type in the given registry. The zero value of T
+// is used to retrieve the event type.
+func RegisterEventType[T Event](registry *Registry) {
+	var zero T
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	registry.types[zero.EventType()] = reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// EventTypes returns the registered event types, sorted by name
+func (r *Registry) EventTypes() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	eventTypes := make([]string, 0, len(r.types))
+	for eventType := range r.types {
+		eventTypes = append(eventTypes, eventType)
+	}
+
+	sort.Strings(eventTypes)
+
+	return eventTypes
+}
+
+// Decode deserializes the message body into a new value of the Go type registered for the message
+// event type, with the serializer matching the message content type
+func (r *Registry) Decode(msg *Message) (Event, error) {
+	r.mutex.RLock()
+	eventType, ok := r.types[msg.Type]
+	r.mutex.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEventType, msg.Type)
+	}
+
+	serializer, err := SerializerFor(msg.ContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pointer event types (i.e. protobuf messages) are decoded into a new value they point to
+	if eventType.Kind() == reflect.Pointer {
+		event := reflect.New(eventType.Elem()).Interface()
+		if err := serializer.Unmarshal(msg.Body, event); err != nil {
+			return nil, err
+		}
+
+		return event.(Event), nil
+	}
+
+	event := reflect.New(eventType)
+	if err := serializer.Unmarshal(msg.Body, event.Interface()); err != nil {
+		return nil, err
+	}
+
+	return event.Elem().Interface().(Event), nil
+}
+
+// HandleRegistered registers a single handler for every event type of the given registry.
+// Messages are decoded into their registered Go type, so the handler can switch on the event type:
+//
+//	events.HandleRegistered(consumer, events.DefaultRegistry, func(ctx context.Context, event events.Event) error {
+//		switch e := event.(type) {
+//		case events.UserUpdatedEvent:
+//			...
+//		}
+//	})
+func HandleRegistered(registrar HandlerRegistrar, registry *Registry, handler TypedHandler[Event]) {
+	for _, eventType := range registry.EventTypes() {
+		registrar.Register(eventType, func(ctx context.Context, msg *Message) error {
+			event, err := registry.Decode(msg)
+			if err != nil {
+				return Permanent(fmt.Errorf("failed to decode %s event: %w", msg.Type, err))
+			}
+
+			return handler(ctx, event)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// PermanentError is an error returned by a handler to signal that processing the message
+// will never succeed, so the message must not be retried
+type PermanentError struct {
+	Err error
+}
+
+// Error returns the message of the wrapped error
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent wraps the given error into a PermanentError
+func Permanent(err error) error {
+	return &PermanentError{Err: err}
+}
+
+// IsPermanent returns true if the error is a PermanentError or if no handler exists for the message,
+// in which case retrying is pointless
+func IsPermanent(err error) bool {
+	var permanentErr *PermanentError
+
+	return errors.As(err, &permanentErr) || errors.Is(err, ErrNoHandler)
+}
+
+// RetryPolicy is a struct that defines how failed messages are retried with an exponential backoff
+type RetryPolicy struct {
+	MaxAttempts  int           // Maximum number of attempts, including the first one
+	InitialDelay time.Duration // Delay before the first retry
+	MaxDelay     time.Duration // Upper bound of the delay between two attempts
+	Multiplier   float64       // Factor by which the delay grows after every attempt
+}
+
+// DefaultRetryPolicy is a sensible retry policy for transient downstream failures
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:  5,
+	InitialDelay: time.Second,
+	MaxDelay:     time.Minute,
+	Multiplier:   2,
+}
+
+// Delay returns the delay to wait before the next attempt, given the number of attempts made so far
+func (p RetryPolicy) Delay(attempts int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(p.InitialDelay) * math.Pow(multiplier, float64(attempts-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		return p.MaxDelay
+	}
+
+	return time.Duration(delay)
+}
+
+// WithRetryPolicy sets the default retry policy used for every event type
+func WithRetryPolicy(policy RetryPolicy) ConsumerOption {
+	return func(opts *consumerOptions) {
+		opts.retryPolicy = &policy
+	}
+}
+
+// SetRetryPolicy sets the retry policy used for messages of the given event type,
+// overriding the default retry policy of the consumer
+func (c *RabbitMQConsumer) SetRetryPolicy(eventType string, policy RetryPolicy) {
+	c.retryPolicies[eventType] = policy
+}
+
+// retryPolicy returns the retry policy that applies to the given event type, if any
+func (c *RabbitMQConsumer) retryPolicy(eventType string) (RetryPolicy, bool) {
+	if policy, ok := c.retryPolicies[eventType]; ok {
+		return policy, true
+	}
+
+	if c.options.retryPolicy != nil {
+		return *c.options.retryPolicy, true
+	}
+
+	return RetryPolicy{}, false
+}
+
+// failureDecision is what happens to a delivery whose handler failed
+type failureDecision struct {
+	delay      time.Duration // Republished to a delay queue for this delay if positive
+	retry      bool          // Republished to the consumer queue
+	deadLetter bool          // Moved to the dead-letter queue
+	requeue    bool          // Otherwise rejected, and requeued if true
+	attempts   int           // Number of attempts made so far, recorded when republishing
+}
+
+// handleFailure decides what happens to a delivery whose handler failed (see decideFailure)
+// and applies the decision
+func (c *RabbitMQConsumer) handleFailure(ctx context.Context, delivery amqp.Delivery, eventType string, handlerErr error) {
+	decision := c.decideFailure(delivery, eventType, handlerErr)
+
+	switch {
+	case decision.delay > 0:
+		delayQueue, err := c.declareDelayQueue(decision.delay)
+		if err != nil {
+			delivery.Nack(false, true)
+			return
+		}
+
+		c.republish(ctx, delivery, "", delayQueue, amqp.Table{AttemptsHeader: int32(decision.attempts)})
+	case decision.retry:
+		// Default exchange routes directly to the queue
+		c.republish(ctx, delivery, "", c.queue, amqp.Table{AttemptsHeader: int32(decision.attempts)})
+	case decision.deadLetter:
+		c.deadLetter(ctx, delivery, decision.attempts, handlerErr)
+	default:
+		delivery.Nack(false, decision.requeue)
+	}
+}
+
+// decideFailure decides what happens to a delivery whose handler failed. Messages claimed by another
+// consumer aren't failed attempts: they are delayed for the InProgressRedeliveryDelay whatever the
+// policies, so that they are handled if that consumer releases its claim. Retryable failures are
+// delayed with an exponential backoff when a retry policy applies (or retried immediately when only
+// dead-lettering is enabled). Permanent failures and exhausted retries are dead-lettered when enabled,
+// otherwise the delivery is rejected according to the requeue policy.
+func (c *RabbitMQConsumer) decideFailure(delivery amqp.Delivery, eventType string, handlerErr error) failureDecision {
+	if errors.Is(handlerErr, ErrMessageInProgress) {
+		return failureDecision{delay: InProgressRedeliveryDelay, attempts: deliveryAttempts(delivery.Headers)}
+	}
+
+	permanent := IsPermanent(handlerErr)
+	attempts := deliveryAttempts(delivery.Headers) + 1
+
+	policy, hasPolicy := c.retryPolicy(eventType)
+
+	maxAttempts := c.options.maxAttempts
+	if hasPolicy {
+		maxAttempts = policy.MaxAttempts
+	}
+
+	canRetry := !permanent && attempts < maxAttempts
+
+	switch {
+	case canRetry && hasPolicy:
+		return failureDecision{delay: policy.Delay(attempts), attempts: attempts}
+	case canRetry && c.options.deadLetter:
+		return failureDecision{retry: true, attempts: attempts}
+	case c.options.deadLetter:
+		return failureDecision{deadLetter: true, attempts: attempts}
+	default:
+		return failureDecision{requeue: !permanent && !hasPolicy && c.shouldRequeue(delivery), attempts: attempts}
+	}
+}
+
+// declareDelayQueue declares a queue without consumers in which messages wait for the given delay
+// before being dead-lettered back to the consumer queue. We use one queue per delay since RabbitMQ
+// only expires messages at the head of a queue. Delay queues delete themselves once unused.
+func (c *RabbitMQConsumer) declareDelayQueue(delay time.Duration) (string, error) {
+	name := fmt.Sprintf("%s.retry.%d", c.queue, delay.Milliseconds())
+
+	_, err := c.channel.QueueDeclare(name, true, false, false, false, amqp.Table{
+		"x-message-ttl":             delay.Milliseconds(),
+		"x-expires":                 (delay + time.Minute).Milliseconds(),
+		"x-dead-letter-exchange":    "",
+		"x-dead-letter-routing-key": c.queue,
+	})
+
+	return name, err
+}
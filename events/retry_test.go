@@ -0,0 +1,93 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestDecideFailure(t *testing.T) {
+	inProgress := fmt.Errorf("%w: 1", ErrMessageInProgress)
+	transient := errors.New("downstream unavailable")
+
+	tests := []struct {
+		name     string
+		options  consumerOptions
+		delivery amqp.Delivery
+		err      error
+		want     failureDecision
+	}{
+		{
+			name: "In progress with the default options",
+			err:  inProgress,
+			want: failureDecision{delay: InProgressRedeliveryDelay},
+		},
+		{
+			name:     "In progress redelivered",
+			delivery: amqp.Delivery{Redelivered: true},
+			err:      inProgress,
+			want:     failureDecision{delay: InProgressRedeliveryDelay},
+		},
+		{
+			name:     "In progress keeps the attempts",
+			options:  consumerOptions{deadLetter: true, maxAttempts: 3},
+			delivery: amqp.Delivery{Headers: amqp.Table{AttemptsHeader: int32(2)}},
+			err:      inProgress,
+			want:     failureDecision{delay: InProgressRedeliveryDelay, attempts: 2},
+		},
+		{
+			name:     "In progress with a retry policy",
+			options:  consumerOptions{retryPolicy: &DefaultRetryPolicy},
+			delivery: amqp.Delivery{Headers: amqp.Table{AttemptsHeader: int32(DefaultRetryPolicy.MaxAttempts)}},
+			err:      inProgress,
+			want:     failureDecision{delay: InProgressRedeliveryDelay, attempts: DefaultRetryPolicy.MaxAttempts},
+		},
+		{
+			name: "Requeued once",
+			err:  transient,
+			want: failureDecision{requeue: true, attempts: 1},
+		},
+		{
+			name:     "Not requeued once redelivered",
+			delivery: amqp.Delivery{Redelivered: true},
+			err:      transient,
+			want:     failureDecision{attempts: 1},
+		},
+		{
+			name: "Permanent",
+			err:  Permanent(transient),
+			want: failureDecision{attempts: 1},
+		},
+		{
+			name:    "Retried with a retry policy",
+			options: consumerOptions{retryPolicy: &DefaultRetryPolicy},
+			err:     transient,
+			want:    failureDecision{delay: DefaultRetryPolicy.Delay(1), attempts: 1},
+		},
+		{
+			name:    "Retried with dead-lettering",
+			options: consumerOptions{deadLetter: true, maxAttempts: 3},
+			err:     transient,
+			want:    failureDecision{retry: true, attempts: 1},
+		},
+		{
+			name:     "Dead-lettered once retries are exhausted",
+			options:  consumerOptions{deadLetter: true, maxAttempts: 3},
+			delivery: amqp.Delivery{Headers: amqp.Table{AttemptsHeader: int32(2)}},
+			err:      transient,
+			want:     failureDecision{deadLetter: true, attempts: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &RabbitMQConsumer{options: tt.options, retryPolicies: map[string]RetryPolicy{}}
+
+			if got := c.decideFailure(tt.delivery, "ItemCreated", tt.err); got != tt.want {
+				t.Errorf("want %+v; got %+v", tt.want, got)
+			}
+		})
+	}
+}
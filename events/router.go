@@ -0,0 +1,106 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNoHandler is returned when dispatching a message for which no handler has been registered
+var ErrNoHandler = errors.New("no handler registered for event type")
+
+// Handler is a function used to process an incoming message
+type Handler func(ctx context.Context, msg *Message) error
+
+// TypedHandler is a function used to process an incoming event which has already been decoded
+type TypedHandler[T Event] func(ctx context.Context, event T) error
+
+// Middleware is a function which wraps a Handler to add behaviour before or after it runs
+type Middleware func(next Handler) Handler
+
+// HandlerRegistrar is an interface implemented by consumers to register handlers per event type
+type HandlerRegistrar interface {
+	Register(eventType string, handler Handler)
+}
+
+// Router is a struct which dispatches incoming messages to the handler registered for their event type.
+// It is embedded by every consumer implementation.
+type Router struct {
+	handlers    map[string]Handler
+	middlewares []Middleware
+	upcasters   map[upcasterKey]Upcaster
+}
+
+// NewRouter creates a new Router with no handlers
+func NewRouter() *Router {
+	return &Router{handlers: map[string]Handler{}, upcasters: map[upcasterKey]Upcaster{}}
+}
+
+// Register registers the handler for the given event type
+func (r *Router) Register(eventType string, handler Handler) {
+	r.handlers[eventType] = handler
+}
+
+// Use appends middlewares which will wrap every registered handler. Middlewares are
+// executed in the order in which they are added.
+func (r *Router) Use(middlewares ...Middleware) {
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+// EventTypes returns the event types for which a handler has been registered
+func (r *Router) EventTypes() []string {
+	eventTypes := make([]string, 0, len(r.handlers))
+	for eventType := range r.handlers {
+		eventTypes = append(eventTypes, eventType)
+	}
+
+	return eventTypes
+}
+
+// hasHandler returns whether a handler has been registered for the given event type
+func (r *Router) hasHandler(eventType string) bool {
+	_, ok := r.handlers[eventType]
+	return ok
+}
+
+// Dispatch executes the handler registered for the message event type, wrapped by the router middlewares.
+// Messages in CloudEvents format are unwrapped and messages of an older event version are upcasted beforehand.
+func (r *Router) Dispatch(ctx context.Context, msg *Message) error {
+	if msg.ContentType == CloudEventsContentType {
+		if err := decodeCloudEvent(msg); err != nil {
+			return Permanent(err)
+		}
+	}
+
+	handler, ok := r.handlers[msg.Type]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNoHandler, msg.Type)
+	}
+
+	if err := r.upcast(msg); err != nil {
+		return err
+	}
+
+	for i := len(r.middlewares) - 1; i >= 0; i-- {
+		handler = r.middlewares[i](handler)
+	}
+
+	return handler(ctx, msg)
+}
+
+// Handle registers a typed handler for events of type T. Incoming messages are decoded into
+// a T, with the serializer matching their content type, before calling the handler.
+// The zero value of T is used to retrieve the event type, so pointer event types
+// (i.e. protobuf messages) must not dereference their receiver in EventType.
+func Handle[T Event](registrar HandlerRegistrar, handler TypedHandler[T]) {
+	var zero T
+
+	registrar.Register(zero.EventType(), func(ctx context.Context, msg *Message) error {
+		event, err := decodeEvent[T](msg)
+		if err != nil {
+			return Permanent(fmt.Errorf("failed to decode %s event: %w", msg.Type, err))
+		}
+
+		return handler(ctx, event)
+	})
+}
@@ -0,0 +1,280 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DirectReplyTo is the RabbitMQ pseudo-queue used to receive replies without declaring a reply queue
+const DirectReplyTo = "amq.rabbitmq.reply-to"
+
+// RPCErrorHeader is the header carrying the error returned by the handler of an RPC request
+const RPCErrorHeader = "x-rpc-error"
+
+// DefaultRPCTimeout is the time after which a call fails if its context has no deadline
+const DefaultRPCTimeout = 10 * time.Second
+
+var (
+	// ErrRPCClientClosed is returned when the RPC client channel is closed while waiting for a reply
+	ErrRPCClientClosed = errors.New("rpc client closed")
+
+	// ErrNoReplyAddress is returned when handling an RPC request which doesn't say where to reply
+	ErrNoReplyAddress = errors.New("rpc request has no reply address")
+)
+
+// RemoteError is returned by RPCClient.Call when the handler of the request failed
+type RemoteError struct {
+	Message string
+}
+
+func (e *RemoteError) Error() string {
+	return "rpc handler failed: " + e.Message
+}
+
+// EventType returns the type of the reply sent when an RPC handler fails
+func (e *RemoteError) EventType() string {
+	return "RPCError"
+}
+
+// RPCHandler is a function used to handle an RPC request and return its response
+type RPCHandler[Req Event, Resp Event] func(ctx context.Context, request Req) (Resp, error)
+
+// Replier is an interface implemented by publishers able to reply to RPC requests
+type Replier interface {
+	Reply(ctx context.Context, request *Message, response Event, handlerErr error) error
+}
+
+// HandleRPC registers a handler for RPC requests of type Req. The response returned by the handler,
+// or its error, is sent back to the caller with the given replier. Requests are acknowledged once
+// replied to, even if the handler failed, since the error is reported to the caller.
+func HandleRPC[Req Event, Resp Event](registrar HandlerRegistrar, replier Replier, handler RPCHandler[Req, Resp]) {
+	var zero Req
+
+	registrar.Register(zero.EventType(), func(ctx context.Context, msg *Message) error {
+		if msg.ReplyTo == "" {
+			return Permanent(fmt.Errorf("%w: %s", ErrNoReplyAddress, msg.ID))
+		}
+
+		request, err := decodeEvent[Req](msg)
+		if err != nil {
+			return replier.Reply(ctx, msg, nil, fmt.Errorf("failed to decode %s request: %w", msg.Type, err))
+		}
+
+		response, err := handler(ctx, request)
+
+		return replier.Reply(ctx, msg, response, err)
+	})
+}
+
+// Reply sends the response to an RPC request, or the error of its handler, to the request reply address
+func (p *RabbitMQPublisher) Reply(ctx context.Context, request *Message, response Event, handlerErr error) error {
+	if request.ReplyTo == "" {
+		return ErrNoReplyAddress
+	}
+
+	// Errors are always sent as JSON since they can't be represented with every serializer
+	event, serializer := response, p.options.serializer
+	if handlerErr != nil {
+		event, serializer = &RemoteError{Message: handlerErr.Error()}, JSONSerializer{}
+	}
+
+	msg, err := p.newMessage(ctx, event, serializer)
+	if err != nil {
+		return err
+	}
+
+	if handlerErr != nil {
+		msg.Headers[RPCErrorHeader] = handlerErr.Error()
+	}
+
+	InjectTraceContext(ctx, msg)
+
+	publishing := publishingFromMessage(msg)
+	publishing.DeliveryMode = amqp.Transient
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	// Replies go through the default exchange, which routes them directly to the reply queue
+	return p.channel.PublishWithContext(ctx, "", request.ReplyTo, false, false, publishing)
+}
+
+// RPCClient is a struct which sends RPC requests through a RabbitMQ exchange and waits for their
+// replies, using the direct reply-to feature of RabbitMQ. Replies are matched with requests
+// through their causation id.
+type RPCClient struct {
+	channel  *amqp.Channel
+	exchange string
+	options  publisherOptions
+
+	// AMQP channels must not be used concurrently for publishing
+	mutex sync.Mutex
+
+	// Pending calls waiting for their reply, keyed by request id
+	pendingMutex sync.Mutex
+	pending      map[string]chan *Message
+
+	// Closed once the replies channel is closed
+	done chan struct{}
+}
+
+// NewRPCClient creates a new RPC client sending requests to the given exchange. It opens a dedicated
+// channel on the given connection and declares a durable topic exchange with the given name.
+func NewRPCClient(conn *amqp.Connection, exchange string, opts ...PublisherOption) (*RPCClient, error) {
+	channel, err := conn.Channel()
+	if err != nil {
+		return nil, err
+	}
+
+	err = channel.ExchangeDeclare(exchange, amqp.ExchangeTopic, true, false, false, false, nil)
+	if err != nil {
+		channel.Close()
+		return nil, err
+	}
+
+	// Direct reply-to requires consuming in no-ack mode on the channel used to publish requests
+	replies, err := channel.Consume(DirectReplyTo, "", true, true, false, false, nil)
+	if err != nil {
+		channel.Close()
+		return nil, err
+	}
+
+	client := &RPCClient{
+		channel:  channel,
+		exchange: exchange,
+		pending:  map[string]chan *Message{},
+		done:     make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(&client.options)
+	}
+
+	go client.dispatchReplies(replies)
+
+	return client, nil
+}
+
+// Call sends the request and decodes its response into reply, which must be a pointer.
+// It fails with a RemoteError if the request handler failed, or once the context is done.
+// Calls without context deadline time out after DefaultRPCTimeout.
+func (c *RPCClient) Call(ctx context.Context, request Event, reply any) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, DefaultRPCTimeout)
+		defer cancel()
+	}
+
+	ctx, span := tracer.Start(
+		ctx,
+		c.exchange+" "+request.EventType(),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String("rabbitmq"),
+			semconv.MessagingDestinationKey.String(c.exchange),
+			semconv.MessagingDestinationKindTopic,
+			semconv.MessagingRabbitmqRoutingKeyKey.String(request.EventType()),
+		),
+	)
+	defer span.End()
+
+	err := c.call(ctx, request, reply)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
+
+// call is an internal method which sends the request and waits for its reply
+func (c *RPCClient) call(ctx context.Context, request Event, reply any) error {
+	msg, err := NewMessage(request, c.options.serializer)
+	if err != nil {
+		return err
+	}
+
+	msg.Producer = c.options.producer
+	msg.ReplyTo = DirectReplyTo
+	correlate(ctx, msg)
+	setEnvelopeHeaders(msg)
+	InjectTraceContext(ctx, msg)
+
+	replies := make(chan *Message, 1)
+
+	c.pendingMutex.Lock()
+	c.pending[msg.ID] = replies
+	c.pendingMutex.Unlock()
+
+	defer func() {
+		c.pendingMutex.Lock()
+		delete(c.pending, msg.ID)
+		c.pendingMutex.Unlock()
+	}()
+
+	publishing := publishingFromMessage(msg)
+	publishing.DeliveryMode = amqp.Transient
+
+	c.mutex.Lock()
+	err = c.channel.PublishWithContext(ctx, c.exchange, msg.RoutingKey, false, false, publishing)
+	c.mutex.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	select {
+	case response := <-replies:
+		return decodeReply(response, reply)
+	case <-c.done:
+		return ErrRPCClientClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// dispatchReplies hands every reply to the call waiting for it. Replies to calls
+// which have already timed out are dropped.
+func (c *RPCClient) dispatchReplies(replies <-chan amqp.Delivery) {
+	defer close(c.done)
+
+	for delivery := range replies {
+		msg := messageFromDelivery(delivery)
+
+		c.pendingMutex.Lock()
+		pending, ok := c.pending[msg.CausationID]
+		c.pendingMutex.Unlock()
+
+		if ok {
+			pending <- msg
+		}
+	}
+}
+
+// Close closes the client channel
+func (c *RPCClient) Close() error {
+	return c.channel.Close()
+}
+
+// decodeReply decodes the reply of an RPC request into the given value
+func decodeReply(msg *Message, reply any) error {
+	if handlerErr, ok := msg.Headers[RPCErrorHeader]; ok {
+		return &RemoteError{Message: fmt.Sprint(handlerErr)}
+	}
+
+	serializer, err := SerializerFor(msg.ContentType)
+	if err != nil {
+		return err
+	}
+
+	return serializer.Unmarshal(msg.Body, reply)
+}
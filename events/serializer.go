@@ -0,0 +1,135 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ProtobufContentType is the content type of messages serialized with Protocol Buffers
+const ProtobufContentType = "application/x-protobuf"
+
+var (
+	// ErrUnsupportedContentType is returned when no serializer is registered for the content type of a message
+	ErrUnsupportedContentType = errors.New("unsupported content type")
+
+	// ErrNotProtoMessage is returned when trying to serialize a value which is not a protobuf message
+	// with the Protobuf serializer
+	ErrNotProtoMessage = errors.New("value is not a protobuf message")
+)
+
+// Serializer is an interface that defines how events are converted to and from message bodies.
+// Other formats (i.e. Avro) can be supported by registering a custom serializer with RegisterSerializer.
+type Serializer interface {
+	ContentType() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONSerializer is a Serializer which uses JSON. It is the default serializer.
+type JSONSerializer struct{}
+
+// ContentType returns the content type of JSON messages
+func (JSONSerializer) ContentType() string {
+	return JSONContentType
+}
+
+// Marshal serializes the given value to JSON
+func (JSONSerializer) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal deserializes the given JSON data into v
+func (JSONSerializer) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// ProtobufSerializer is a Serializer which uses Protocol Buffers. Events must be pointers
+// to generated protobuf messages.
+type ProtobufSerializer struct{}
+
+// ContentType returns the content type of Protobuf messages
+func (ProtobufSerializer) ContentType() string {
+	return ProtobufContentType
+}
+
+// Marshal serializes the given protobuf message
+func (ProtobufSerializer) Marshal(v any) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrNotProtoMessage, v)
+	}
+
+	return proto.Marshal(msg)
+}
+
+// Unmarshal deserializes the given data into the protobuf message v
+func (ProtobufSerializer) Unmarshal(data []byte, v any) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("%w: %T", ErrNotProtoMessage, v)
+	}
+
+	return proto.Unmarshal(data, msg)
+}
+
+// serializers holds the registered serializers keyed by content type
+var serializers = struct {
+	sync.RWMutex
+	byContentType map[string]Serializer
+}{
+	byContentType: map[string]Serializer{
+		JSONContentType:     JSONSerializer{},
+		ProtobufContentType: ProtobufSerializer{},
+	},
+}
+
+// RegisterSerializer registers a serializer for its content type, so that incoming messages
+// with this content type can be decoded
+func RegisterSerializer(serializer Serializer) {
+	serializers.Lock()
+	defer serializers.Unlock()
+
+	serializers.byContentType[serializer.ContentType()] = serializer
+}
+
+// SerializerFor returns the serializer registered for the given content type.
+// Messages without a content type are considered to be JSON.
+func SerializerFor(contentType string) (Serializer, error) {
+	if contentType == "" {
+		return JSONSerializer{}, nil
+	}
+
+	serializers.RLock()
+	defer serializers.RUnlock()
+
+	serializer, ok := serializers.byContentType[contentType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedContentType, contentType)
+	}
+
+	return serializer, nil
+}
+
+// decodeEvent deserializes the message body into a new T with the serializer matching the
+// message content type. Pointer event types (i.e. protobuf messages) are allocated before decoding.
+func decodeEvent[T Event](msg *Message) (T, error) {
+	var event T
+
+	serializer, err := SerializerFor(msg.ContentType)
+	if err != nil {
+		return event, err
+	}
+
+	eventType := reflect.TypeOf(event)
+	if eventType != nil && eventType.Kind() == reflect.Pointer {
+		event = reflect.New(eventType.Elem()).Interface().(T)
+		return event, serializer.Unmarshal(msg.Body, event)
+	}
+
+	return event, serializer.Unmarshal(msg.Body, &event)
+}
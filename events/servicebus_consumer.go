@@ -0,0 +1,394 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus"
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus/admin"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// defaultRuleName is the name of the rule matching every message, created along with a subscription
+const defaultRuleName = "$Default"
+
+// serviceBusReceiver is an interface implemented by both session and non-session Service Bus receivers
+type serviceBusReceiver interface {
+	ReceiveMessages(ctx context.Context, maxMessages int, options *azservicebus.ReceiveMessagesOptions) ([]*azservicebus.ReceivedMessage, error)
+	CompleteMessage(ctx context.Context, message *azservicebus.ReceivedMessage, options *azservicebus.CompleteMessageOptions) error
+	AbandonMessage(ctx context.Context, message *azservicebus.ReceivedMessage, options *azservicebus.AbandonMessageOptions) error
+	DeadLetterMessage(ctx context.Context, message *azservicebus.ReceivedMessage, options *azservicebus.DeadLetterOptions) error
+	Close(ctx context.Context) error
+}
+
+// WithSessions makes the consumer subscription session-enabled. Messages sharing the same session id
+// (the ordering key of the message) are processed sequentially, by a single worker at a time.
+// Azure Service Bus only.
+func WithSessions() ConsumerOption {
+	return func(opts *consumerOptions) {
+		opts.sessions = true
+	}
+}
+
+// AzureServiceBusConsumer is a Consumer which consumes events from an Azure Service Bus
+// subscription of a topic. Failed messages are abandoned and redelivered by Service Bus, which
+// moves them to the subscription dead-letter queue once the maximum delivery count is reached.
+// Permanent failures are dead-lettered right away.
+type AzureServiceBusConsumer struct {
+	*Router
+	client       *azservicebus.Client
+	adminClient  *admin.Client
+	topic        string
+	subscription string
+	options      consumerOptions
+}
+
+// NewAzureServiceBusConsumer creates a new Azure Service Bus consumer of the given subscription.
+// The topic and subscription are created when the consumer starts if they don't exist yet.
+func NewAzureServiceBusConsumer(client *azservicebus.Client, adminClient *admin.Client, topic, subscription string, opts ...ConsumerOption) *AzureServiceBusConsumer {
+	consumer := &AzureServiceBusConsumer{
+		Router:       NewRouter(),
+		client:       client,
+		adminClient:  adminClient,
+		topic:        topic,
+		subscription: subscription,
+	}
+
+	for _, opt := range opts {
+		opt(&consumer.options)
+	}
+
+	return consumer
+}
+
+// Start declares the subscription with one rule per registered event type and starts receiving
+// messages with the configured number of workers. It blocks until the given context is cancelled.
+func (c *AzureServiceBusConsumer) Start(ctx context.Context) error {
+	if err := c.declareTopology(ctx); err != nil {
+		return err
+	}
+
+	workers := c.options.workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	var receiver serviceBusReceiver
+
+	if !c.options.sessions {
+		var err error
+
+		receiver, err = c.client.NewReceiverForSubscription(c.topic, c.subscription, nil)
+		if err != nil {
+			return err
+		}
+
+		defer receiver.Close(context.Background())
+	}
+
+	// Stop every worker as soon as one of them fails
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var err error
+			if c.options.sessions {
+				err = c.receiveSessions(ctx)
+			} else {
+				err = c.receive(ctx, receiver)
+			}
+
+			if err != nil {
+				errs <- err
+				cancel()
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	return <-errs
+}
+
+// receive handles messages from the given receiver until the context is cancelled
+func (c *AzureServiceBusConsumer) receive(ctx context.Context, receiver serviceBusReceiver) error {
+	batchSize := c.options.prefetch
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	for {
+		messages, err := receiver.ReceiveMessages(ctx, batchSize, nil)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		for _, message := range messages {
+			c.handleMessage(detach(ctx), receiver, message)
+		}
+	}
+}
+
+// receiveSessions accepts sessions one after the other and handles their messages
+// until the session is empty or the context is cancelled
+func (c *AzureServiceBusConsumer) receiveSessions(ctx context.Context) error {
+	for {
+		session, err := c.client.AcceptNextSessionForSubscription(ctx, c.topic, c.subscription, nil)
+		if err != nil {
+			var sbErr *azservicebus.Error
+
+			switch {
+			case ctx.Err() != nil:
+				return nil
+			case errors.As(err, &sbErr) && sbErr.Code == azservicebus.CodeTimeout:
+				// No session is available at the moment
+				continue
+			default:
+				return err
+			}
+		}
+
+		err = c.drainSession(ctx, session)
+		session.Close(context.Background())
+
+		if err != nil {
+			return err
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// drainSession handles the messages of a session until no message is received for a while
+func (c *AzureServiceBusConsumer) drainSession(ctx context.Context, session *azservicebus.SessionReceiver) error {
+	for {
+		receiveCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		messages, err := session.ReceiveMessages(receiveCtx, 1, nil)
+		cancel()
+
+		if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		if len(messages) == 0 {
+			return nil
+		}
+
+		for _, message := range messages {
+			c.handleMessage(detach(ctx), session, message)
+		}
+	}
+}
+
+// handleMessage processes a single message and settles it depending on the handler result
+func (c *AzureServiceBusConsumer) handleMessage(ctx context.Context, receiver serviceBusReceiver, message *azservicebus.ReceivedMessage) {
+	msg := messageFromServiceBus(message)
+
+	observe := observeProcessing(c.options.metrics, c.subscription, msg.Type)
+
+	err := c.process(ctx, msg)
+	observe(err)
+
+	if err == nil {
+		receiver.CompleteMessage(ctx, message, nil)
+
+		return
+	}
+
+	if c.options.logger != nil {
+		c.options.logger.Error(err, map[string]string{
+			"subscription": c.subscription,
+			"message_id":   msg.ID,
+			"event_type":   msg.Type,
+		})
+	}
+
+	if IsPermanent(err) {
+		reason := err.Error()
+		receiver.DeadLetterMessage(ctx, message, &azservicebus.DeadLetterOptions{Reason: &reason})
+
+		return
+	}
+
+	receiver.AbandonMessage(ctx, message, nil)
+}
+
+// process dispatches the message to its handler inside a tracing span, recovering any panic
+func (c *AzureServiceBusConsumer) process(ctx context.Context, msg *Message) (err error) {
+	ctx = ExtractTraceContext(ctx, msg)
+	ctx = ContextWithEnvelope(ctx, msg.Envelope)
+
+	ctx, span := tracer.Start(
+		ctx,
+		c.subscription+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String("servicebus"),
+			semconv.MessagingDestinationKey.String(c.topic),
+			semconv.MessagingDestinationKindTopic,
+			semconv.MessagingOperationProcess,
+			semconv.MessagingMessageIDKey.String(msg.ID),
+			semconv.MessagingConversationIDKey.String(msg.CorrelationID),
+		),
+	)
+	defer span.End()
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%s", recovered)
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}()
+
+	return c.Dispatch(ctx, msg)
+}
+
+// declareTopology creates the topic and subscription if they don't exist yet, and replaces the
+// default rule of the subscription with one rule per registered event type.
+// The maximum delivery count of the subscription is set from the dead-letter option.
+func (c *AzureServiceBusConsumer) declareTopology(ctx context.Context) error {
+	if err := ensureTopic(ctx, c.adminClient, c.topic); err != nil {
+		return err
+	}
+
+	existing, err := c.adminClient.GetSubscription(ctx, c.topic, c.subscription, nil)
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		properties := &admin.SubscriptionProperties{
+			RequiresSession:                  &c.options.sessions,
+			DeadLetteringOnMessageExpiration: &c.options.deadLetter,
+		}
+
+		if c.options.deadLetter && c.options.maxAttempts > 0 {
+			maxDeliveryCount := int32(c.options.maxAttempts)
+			properties.MaxDeliveryCount = &maxDeliveryCount
+		}
+
+		_, err = c.adminClient.CreateSubscription(ctx, c.topic, c.subscription, &admin.CreateSubscriptionOptions{
+			Properties: properties,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	rules := map[string]bool{}
+
+	pager := c.adminClient.NewListRulesPager(c.topic, c.subscription, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, rule := range page.Rules {
+			rules[rule.Name] = true
+		}
+	}
+
+	eventTypes := append(c.EventTypes(), c.options.bindingKeys...)
+	for _, eventType := range eventTypes {
+		if rules[eventType] {
+			continue
+		}
+
+		name, subject := eventType, eventType
+
+		_, err := c.adminClient.CreateRule(ctx, c.topic, c.subscription, &admin.CreateRuleOptions{
+			Name:   &name,
+			Filter: &admin.CorrelationFilter{Subject: &subject},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// The default rule matches every message, so it is removed once our own rules exist
+	if rules[defaultRuleName] && len(eventTypes) > 0 {
+		_, err := c.adminClient.DeleteRule(ctx, c.topic, c.subscription, defaultRuleName, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close is a no-op since receivers are closed when the consumer stops. The Service Bus client
+// is shared and must be closed by its owner.
+func (c *AzureServiceBusConsumer) Close() error {
+	return nil
+}
+
+// messageFromServiceBus converts a received Service Bus message into a Message
+func messageFromServiceBus(message *azservicebus.ReceivedMessage) *Message {
+	headers := make(map[string]any, len(message.ApplicationProperties))
+	for key, value := range message.ApplicationProperties {
+		headers[key] = value
+	}
+
+	msg := &Message{
+		Envelope: Envelope{
+			ID:            message.MessageID,
+			Type:          stringValue(message.Subject),
+			CorrelationID: stringValue(message.CorrelationID),
+		},
+		ContentType: stringValue(message.ContentType),
+		RoutingKey:  stringValue(message.Subject),
+		Headers:     headers,
+		Body:        message.Body,
+	}
+
+	envelopeFromHeaders(&msg.Envelope, headers)
+
+	if occurredAt, ok := headers[OccurredAtProperty].(time.Time); ok {
+		msg.OccurredAt = occurredAt
+	} else if message.EnqueuedTime != nil {
+		msg.OccurredAt = *message.EnqueuedTime
+	}
+
+	if producer, ok := headers[ProducerProperty].(string); ok {
+		msg.Producer = producer
+	}
+
+	return msg
+}
+
+// stringValue returns the value of a string pointer, or an empty string if it is nil
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+
+	return *value
+}
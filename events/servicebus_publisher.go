@@ -0,0 +1,158 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus"
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus/admin"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Application properties used to carry the envelope metadata which has no Service Bus message property
+const (
+	OccurredAtProperty = "x-occurred-at"
+	ProducerProperty   = "x-producer"
+)
+
+// AzureServiceBusPublisher is a Publisher which publishes events to an Azure Service Bus topic
+type AzureServiceBusPublisher struct {
+	sender  *azservicebus.Sender
+	topic   string
+	options publisherOptions
+}
+
+// NewAzureServiceBusPublisher creates a new Azure Service Bus publisher sending events to the given topic.
+// The topic is created if it doesn't exist yet.
+func NewAzureServiceBusPublisher(ctx context.Context, client *azservicebus.Client, adminClient *admin.Client, topic string, opts ...PublisherOption) (*AzureServiceBusPublisher, error) {
+	if err := ensureTopic(ctx, adminClient, topic); err != nil {
+		return nil, err
+	}
+
+	sender, err := client.NewSender(topic, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	publisher := &AzureServiceBusPublisher{
+		sender: sender,
+		topic:  topic,
+	}
+
+	for _, opt := range opts {
+		opt(&publisher.options)
+	}
+
+	return publisher, nil
+}
+
+// Publish serializes the given event and sends it to the topic, using the event type as subject.
+// The ordering key of the message is used as session id, so that session-enabled subscriptions
+// process messages with the same key sequentially.
+func (p *AzureServiceBusPublisher) Publish(ctx context.Context, event Event) error {
+	ctx, span := tracer.Start(
+		ctx,
+		p.topic+" send",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String("servicebus"),
+			semconv.MessagingDestinationKey.String(p.topic),
+			semconv.MessagingDestinationKindTopic,
+		),
+	)
+	defer span.End()
+
+	err := p.publish(ctx, event, span)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	p.recordResult(err)
+
+	return err
+}
+
+// publish is an internal method which serializes and sends the event
+func (p *AzureServiceBusPublisher) publish(ctx context.Context, event Event, span trace.Span) error {
+	msg, err := NewMessage(event, p.options.serializer)
+	if err != nil {
+		return err
+	}
+
+	msg.Producer = p.options.producer
+	correlate(ctx, msg)
+	setEnvelopeHeaders(msg)
+
+	if p.options.cloudEvents {
+		if err := encodeCloudEvent(msg); err != nil {
+			return err
+		}
+	}
+
+	span.SetAttributes(
+		semconv.MessagingMessageIDKey.String(msg.ID),
+		semconv.MessagingConversationIDKey.String(msg.CorrelationID),
+	)
+
+	// Propagate the trace context to consumers through the application properties
+	InjectTraceContext(ctx, msg)
+
+	properties := make(map[string]any, len(msg.Headers)+2)
+	for key, value := range msg.Headers {
+		properties[key] = value
+	}
+
+	properties[OccurredAtProperty] = msg.OccurredAt
+	if msg.Producer != "" {
+		properties[ProducerProperty] = msg.Producer
+	}
+
+	sessionID := orderingKey(msg)
+
+	return p.sender.SendMessage(ctx, &azservicebus.Message{
+		MessageID:             &msg.ID,
+		ContentType:           &msg.ContentType,
+		CorrelationID:         &msg.CorrelationID,
+		Subject:               &msg.Type,
+		SessionID:             &sessionID,
+		ApplicationProperties: properties,
+		Body:                  msg.Body,
+	}, nil)
+}
+
+// recordResult keeps track of the publishing result in the metrics
+func (p *AzureServiceBusPublisher) recordResult(err error) {
+	if p.options.metrics == nil {
+		return
+	}
+
+	if err != nil {
+		p.options.metrics.ErrorOutgoingMessagesCounter.WithLabelValues(p.topic).Inc()
+		return
+	}
+
+	p.options.metrics.OutgoingMessagesCounter.WithLabelValues(p.topic).Inc()
+}
+
+// Close closes the publisher sender
+func (p *AzureServiceBusPublisher) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return p.sender.Close(ctx)
+}
+
+// ensureTopic creates the given topic if it doesn't exist yet
+func ensureTopic(ctx context.Context, adminClient *admin.Client, topic string) error {
+	existing, err := adminClient.GetTopic(ctx, topic, nil)
+	if err != nil || existing != nil {
+		return err
+	}
+
+	_, err = adminClient.CreateTopic(ctx, topic, nil)
+
+	return err
+}
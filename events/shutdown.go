@@ -0,0 +1,34 @@
+package events
+
+import (
+	"context"
+	"time"
+)
+
+// detachedContext is a context which keeps the values of its parent, such as the trace context,
+// but is never cancelled. Handlers run with a detached context so that in-flight messages
+// are completed and settled when a consumer is asked to stop.
+type detachedContext struct {
+	parent context.Context
+}
+
+// detach returns a context holding the values of the given context which is never cancelled
+func detach(ctx context.Context) context.Context {
+	return detachedContext{parent: ctx}
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key any) any {
+	return c.parent.Value(key)
+}
@@ -0,0 +1,57 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Upcaster is a function which converts the body of an event from one version to the next one
+type Upcaster func(body []byte) ([]byte, error)
+
+// upcasterKey identifies the upcaster of an event type from a given version
+type upcasterKey struct {
+	eventType string
+	version   int
+}
+
+// RegisterUpcaster registers an upcaster converting events of the given type from the given version
+// to the next one. Upcasters are chained, so that messages of any older version still sitting in
+// queues are converted to the latest version before reaching their handler.
+func (r *Router) RegisterUpcaster(eventType string, fromVersion int, upcaster Upcaster) {
+	r.upcasters[upcasterKey{eventType: eventType, version: fromVersion}] = upcaster
+}
+
+// upcast converts the message body to the latest version of its event type
+func (r *Router) upcast(msg *Message) error {
+	for {
+		upcaster, ok := r.upcasters[upcasterKey{eventType: msg.Type, version: msg.Version}]
+		if !ok {
+			return nil
+		}
+
+		body, err := upcaster(msg.Body)
+		if err != nil {
+			return Permanent(fmt.Errorf("failed to upcast %s event from version %d: %w", msg.Type, msg.Version, err))
+		}
+
+		msg.Body = body
+		msg.Version++
+	}
+}
+
+// UpcastJSON creates an upcaster for JSON events which modifies the decoded event fields in place
+func UpcastJSON(upcast func(fields map[string]any) error) Upcaster {
+	return func(body []byte) ([]byte, error) {
+		var fields map[string]any
+
+		if err := json.Unmarshal(body, &fields); err != nil {
+			return nil, err
+		}
+
+		if err := upcast(fields); err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(fields)
+	}
+}
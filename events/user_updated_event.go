@@ -2,6 +2,9 @@ package events
 
 import "github.com/PlayEconomy37/Play.Common/permissions"
 
+// UserUpdatedEventType is the type of the UserUpdatedEvent
+const UserUpdatedEventType = "UserUpdated"
+
 // UserUpdatedEvent is the event sent whenever an user is created or updated
 type UserUpdatedEvent struct {
 	ID          int64                   `json:"id"`
@@ -10,3 +13,12 @@ type UserUpdatedEvent struct {
 	Activated   bool                    `json:"activated"`
 	Version     int32                   `json:"version"`
 }
+
+// EventType returns the type of the event
+func (e UserUpdatedEvent) EventType() string {
+	return UserUpdatedEventType
+}
+
+func init() {
+	RegisterEventType[UserUpdatedEvent](DefaultRegistry)
+}
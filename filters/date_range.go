@@ -0,0 +1,81 @@
+package filters
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/PlayEconomy37/Play.Common/validator"
+)
+
+// dateLayout is the layout of date-only values accepted in date range parameters
+const dateLayout = "2006-01-02"
+
+// DateRange is a struct that holds a time range which can be open on either side.
+// The lower bound is inclusive and the upper bound is exclusive.
+type DateRange struct {
+	After  *time.Time
+	Before *time.Time
+}
+
+// ParseDateRange reads the <prefix>_after and <prefix>_before query string parameters (i.e. created_after
+// and created_before) into a DateRange. Values are either RFC3339 date times or dates (2006-01-02), which
+// are interpreted as midnight in the given location (UTC if nil), and bounds are converted to UTC.
+// An error is added to the validator if a value can't be parsed or if the range is empty.
+func ParseDateRange(values url.Values, prefix string, location *time.Location, v *validator.Validator) DateRange {
+	if location == nil {
+		location = time.UTC
+	}
+
+	var dateRange DateRange
+
+	afterKey, beforeKey := prefix+"_after", prefix+"_before"
+
+	dateRange.After = parseDateParam(values, afterKey, location, v)
+	dateRange.Before = parseDateParam(values, beforeKey, location, v)
+
+	if dateRange.After != nil && dateRange.Before != nil {
+		v.Check(dateRange.After.Before(*dateRange.Before), beforeKey, "must be after "+afterKey)
+	}
+
+	return dateRange
+}
+
+// parseDateParam parses the date range query string parameter with the given key, if present
+func parseDateParam(values url.Values, key string, location *time.Location, v *validator.Validator) *time.Time {
+	raw := values.Get(key)
+	if raw == "" {
+		return nil
+	}
+
+	date, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		date, err = time.ParseInLocation(dateLayout, raw, location)
+	}
+
+	if err != nil {
+		v.AddError(key, "must be an RFC3339 date time or a date in the format YYYY-MM-DD")
+		return nil
+	}
+
+	date = date.UTC()
+
+	return &date
+}
+
+// IsZero returns true if the range has no bound
+func (r DateRange) IsZero() bool {
+	return r.After == nil && r.Before == nil
+}
+
+// Apply adds the bounds of the range as conditions on the given field to the query
+func (r DateRange) Apply(q *Query, field string) *Query {
+	if r.After != nil {
+		q.Where(field, OpGreaterThanOrEqual, *r.After)
+	}
+
+	if r.Before != nil {
+		q.Where(field, OpLessThan, *r.Before)
+	}
+
+	return q
+}
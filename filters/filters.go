@@ -1,9 +1,19 @@
 package filters
 
 import (
+	"fmt"
 	"strings"
 
+	"github.com/PlayEconomy37/Play.Common/configuration"
 	"github.com/PlayEconomy37/Play.Common/validator"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Pagination bounds used when the service doesn't configure its own
+const (
+	DefaultPageSize    = 20
+	DefaultMaxPageSize = 100
+	DefaultMaxPage     = 10_000_000
 )
 
 // Filters is a truct that holds filtering parameters
@@ -12,37 +22,136 @@ type Filters struct {
 	PageSize     int
 	Sort         string
 	SortSafelist []string // Supported sort column values
+	DefaultSort  string   // Sort value used instead of an unsupported Sort value, if set
+	MaxPageSize  int      // DefaultMaxPageSize if 0
+	MaxPage      int      // DefaultMaxPage if 0
+	SkipCount    bool     // Whether the total number of records is left uncounted
+
+	Search         string         // Search term, i.e. "excalibur"
+	SearchFields   []string       // Fields matched against the search term
+	SearchStrategy SearchStrategy // How the search term is matched
+
+	Query *Query // Conditions on the fields filterable through the query string
+}
+
+// Defaults is a struct that holds the default filtering parameters of a listing endpoint
+type Defaults struct {
+	PageSize     int      // DefaultPageSize if 0
+	MaxPageSize  int      // DefaultMaxPageSize if 0
+	MaxPage      int      // DefaultMaxPage if 0
+	Sort         string   // Must be part of the safelist
+	SortSafelist []string // Supported sort column values
+
+	SearchFields   []string       // Fields matched against the search term
+	SearchStrategy SearchStrategy // How the search term is matched
+}
+
+// Options is a struct that holds the filtering configuration of a listing endpoint
+type Options struct {
+	Defaults
+	Fields []Field // Fields filterable through the query string
+}
+
+// NewDefaults creates the default filtering parameters of a listing endpoint with the given
+// sort values and the pagination bounds configured for the service
+func NewDefaults(cfg *configuration.Config, sort string, sortSafelist ...string) Defaults {
+	return Defaults{
+		PageSize:     cfg.Pagination.DefaultPageSize,
+		MaxPageSize:  cfg.Pagination.MaxPageSize,
+		MaxPage:      cfg.Pagination.MaxPage,
+		Sort:         sort,
+		SortSafelist: sortSafelist,
+	}
+}
+
+// NewFilters creates filters for the first page of records with the given defaults, ready to be
+// overridden by the values received as query parameters. Unsupported sort values fall back to
+// the default sort, so the filters are safe to use even if validation errors were ignored.
+func NewFilters(defaults Defaults) Filters {
+	if !validator.In(defaults.Sort, defaults.SortSafelist...) {
+		panic("default sort parameter is not part of the safelist: " + defaults.Sort)
+	}
+
+	pageSize := defaults.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+
+	return Filters{
+		Page:         1,
+		PageSize:     pageSize,
+		Sort:         defaults.Sort,
+		SortSafelist: defaults.SortSafelist,
+		DefaultSort:  defaults.Sort,
+		MaxPageSize:  defaults.MaxPageSize,
+		MaxPage:      defaults.MaxPage,
+
+		SearchFields:   defaults.SearchFields,
+		SearchStrategy: defaults.SearchStrategy,
+	}
 }
 
 // ValidateFilters is a helper function that validates filters received as query parameters
 func ValidateFilters(v *validator.Validator, f Filters) {
 	// Check that the page and page_size parameters contain sensible values
-	v.Check(validator.Between(f.Page, 0, 10_000_000), "page", "must be greater or equal to 0 and lower or equal to 10 million")
-	v.Check(validator.Between(f.PageSize, 0, 100), "page_size", "must be greater or equal to 0 and lower or equal to 100")
+	v.Check(validator.Between(f.Page, 0, f.maxPage()), "page", fmt.Sprintf("must be greater or equal to 0 and lower or equal to %d", f.maxPage()))
+	v.Check(validator.Between(f.PageSize, 0, f.maxPageSize()), "page_size", fmt.Sprintf("must be greater or equal to 0 and lower or equal to %d", f.maxPageSize()))
 
 	// Check that the sort parameter matches a value in the safelist
 	v.Check(validator.In(f.Sort, f.SortSafelist...), "sort", "invalid sort value")
+
+	// Check that the search term has a sensible length
+	v.Check(validator.MaxCharacters(f.Search, MaxSearchLength), "search", fmt.Sprintf("must not be more than %d characters long", MaxSearchLength))
 }
 
 // SortColumn is a helper method that checks if the client-provided `Sort` field matches one of the entries in our safelist
-// and if it does, extract the column name from the `Sort` field by stripping the leading hyphen character (if one exists)
+// and if it does, extract the column name from the `Sort` field by stripping the leading hyphen character (if one exists).
+// Unsupported values are replaced by the default sort if one is set.
 func (f Filters) SortColumn() string {
-	for _, safeValue := range f.SortSafelist {
-		if f.Sort == safeValue {
-			return strings.TrimPrefix(f.Sort, "-")
-		}
+	sort := f.effectiveSort()
+
+	if !validator.In(sort, f.SortSafelist...) {
+		// Prevent SQL injection attack.
+		// Before calling this method, we should have validated the Sort field
+		panic("unsafe sort parameter: " + f.Sort)
 	}
 
-	// Prevent SQL injection attack.
-	// Before calling this method, we should have validated the Sort field
-	panic("unsafe sort parameter: " + f.Sort)
+	return strings.TrimPrefix(sort, "-")
+}
+
+// effectiveSort returns the default sort if the `Sort` field doesn't match any entry
+// of our safelist and a default sort is set, or the `Sort` field otherwise
+func (f Filters) effectiveSort() string {
+	if f.DefaultSort != "" && !validator.In(f.Sort, f.SortSafelist...) {
+		return f.DefaultSort
+	}
+
+	return f.Sort
+}
+
+// Mongo converts the query conditions and the search term of the filters into a MongoDB filter
+func (f Filters) Mongo() primitive.M {
+	filter := primitive.M{}
+	if f.Query != nil {
+		filter = f.Query.Mongo()
+	}
+
+	return f.ApplySearch(filter)
+}
+
+// WithoutCount returns a copy of the filters which makes queries skip counting the total number
+// of records, for endpoints which don't need exact totals (i.e. infinite scroll). The metadata
+// then only reports whether more records exist after the current page.
+func (f Filters) WithoutCount() Filters {
+	f.SkipCount = true
+	return f
 }
 
 // SortDirectionMongo is a helper method that returns the sort direction (1 (ASC) or -1 (DESC))
 // depending on the prefix character of the `Sort` field
 func (f Filters) SortDirectionMongo() int8 {
 	// Descending order
-	if strings.HasPrefix(f.Sort, "-") {
+	if strings.HasPrefix(f.effectiveSort(), "-") {
 		return -1
 	}
 
@@ -54,7 +163,7 @@ func (f Filters) SortDirectionMongo() int8 {
 // depending on the prefix character of the `Sort` field
 func (f Filters) SortDirectionSQL() string {
 	// Descending order
-	if strings.HasPrefix(f.Sort, "-") {
+	if strings.HasPrefix(f.effectiveSort(), "-") {
 		return "DESC"
 	}
 
@@ -62,13 +171,45 @@ func (f Filters) SortDirectionSQL() string {
 	return "ASC"
 }
 
-// Limit is a helper method returns the number of records to be returned in the query
+// Limit is a helper method returns the number of records to be returned in the query.
+// The page size is clamped to the maximum page size, which is also used if the page size is 0.
 func (f Filters) Limit() int {
+	if f.PageSize <= 0 || f.PageSize > f.maxPageSize() {
+		return f.maxPageSize()
+	}
+
 	return f.PageSize
 }
 
 // Offset is a helper method returns the number of rows to skip before starting to
-// return records from the query
+// return records from the query. The page is clamped between 1 and the maximum page.
 func (f Filters) Offset() int {
-	return (f.Page - 1) * f.PageSize
+	page := f.Page
+	if page < 1 {
+		page = 1
+	}
+
+	if page > f.maxPage() {
+		page = f.maxPage()
+	}
+
+	return (page - 1) * f.Limit()
+}
+
+// maxPageSize returns the maximum page size of the filters
+func (f Filters) maxPageSize() int {
+	if f.MaxPageSize > 0 {
+		return f.MaxPageSize
+	}
+
+	return DefaultMaxPageSize
+}
+
+// maxPage returns the maximum page number of the filters
+func (f Filters) maxPage() int {
+	if f.MaxPage > 0 {
+		return f.MaxPage
+	}
+
+	return DefaultMaxPage
 }
@@ -1,14 +1,19 @@
 package filters
 
-import "math"
+import (
+	"math"
+	"net/url"
+	"strconv"
+)
 
 // Metadata is a struct that holds the pagination metadata
 type Metadata struct {
-	CurrentPage  int `json:"current_page,omitempty"`
-	PageSize     int `json:"page_size,omitempty"`
-	FirstPage    int `json:"first_page,omitempty"`
-	LastPage     int `json:"last_page,omitempty"`
-	TotalRecords int `json:"total_records,omitempty"`
+	CurrentPage  int  `json:"current_page,omitempty"`
+	PageSize     int  `json:"page_size,omitempty"`
+	FirstPage    int  `json:"first_page,omitempty"`
+	LastPage     int  `json:"last_page,omitempty"`
+	TotalRecords int  `json:"total_records,omitempty"`
+	HasMore      bool `json:"has_more"`
 }
 
 // CalculateMetadata calculates the appropriate pagination metadata
@@ -27,5 +32,72 @@ func CalculateMetadata(totalRecords, page, pageSize int) Metadata {
 		FirstPage:    1,
 		LastPage:     int(math.Ceil(float64(totalRecords) / float64(pageSize))),
 		TotalRecords: totalRecords,
+		HasMore:      page*pageSize < totalRecords,
 	}
 }
+
+// CalculateMetadataWithoutCount calculates the pagination metadata of a query which didn't count
+// the total number of records, given whether more records exist after the current page.
+// The last page and total records are unknown and left empty.
+func CalculateMetadataWithoutCount(page, pageSize int, hasMore bool) Metadata {
+	return Metadata{
+		CurrentPage: page,
+		PageSize:    pageSize,
+		FirstPage:   1,
+		HasMore:     hasMore,
+	}
+}
+
+// Links is a struct that holds the URLs of the current page and of the pages surrounding it
+type Links struct {
+	Self  string `json:"self"`
+	First string `json:"first,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Last  string `json:"last,omitempty"`
+}
+
+// PageLinks generates the links to the first, previous, next and last pages from the URL of the
+// current request, by replacing its page query string parameter. Other parameters (page size,
+// sort, filters...) are kept as is. Only the self link is set if there are no records, and the
+// last link is left empty if the total number of records hasn't been counted.
+func (m Metadata) PageLinks(requestURL *url.URL) Links {
+	links := Links{Self: requestURL.String()}
+
+	if m.FirstPage == 0 {
+		return links
+	}
+
+	links.First = pageURL(requestURL, m.FirstPage)
+
+	if m.LastPage > 0 {
+		links.Last = pageURL(requestURL, m.LastPage)
+	}
+
+	// Pages past the last one link back to the last page
+	if m.CurrentPage > m.FirstPage {
+		prev := m.CurrentPage - 1
+		if m.LastPage > 0 && prev > m.LastPage {
+			prev = m.LastPage
+		}
+
+		links.Prev = pageURL(requestURL, prev)
+	}
+
+	if m.HasMore {
+		links.Next = pageURL(requestURL, m.CurrentPage+1)
+	}
+
+	return links
+}
+
+// pageURL returns a copy of the given URL pointing to the given page
+func pageURL(requestURL *url.URL, page int) string {
+	query := requestURL.Query()
+	query.Set("page", strconv.Itoa(page))
+
+	u := *requestURL
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
@@ -0,0 +1,157 @@
+package filters
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PlayEconomy37/Play.Common/validator"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FieldType is a custom type which defines how the query string values of a field are parsed
+type FieldType int8
+
+// Supported field types
+const (
+	StringField   FieldType = iota // Values are used as is
+	IntField                       // Values are parsed as int64
+	FloatField                     // Values are parsed as float64
+	BoolField                      // Values are parsed as bool
+	TimeField                      // Values are parsed as RFC3339 date times
+	ObjectIDField                  // Values are parsed as MongoDB ObjectIDs
+)
+
+// Field is a struct which declares a field that can be filtered through the query string,
+// along with the operators allowed on it
+type Field struct {
+	Name      string     // Name of the query string parameter, i.e. "price"
+	Column    string     // Name of the document field or table column, defaults to Name
+	Type      FieldType  // Type of the field values, defaults to StringField
+	Operators []Operator // Operators allowed on the field, defaults to OpEqual
+}
+
+// column returns the name of the document field or table column of the field
+func (f Field) column() string {
+	if f.Column == "" {
+		return f.Name
+	}
+
+	return f.Column
+}
+
+// allows returns whether the given operator is allowed on the field
+func (f Field) allows(operator Operator) bool {
+	if len(f.Operators) == 0 {
+		return operator == OpEqual
+	}
+
+	return validator.In(operator, f.Operators...)
+}
+
+// ParseQuery parses the query string parameters of the declared fields into a Query. Parameters
+// have the form field[operator]=value (i.e. price[gte]=10&price[lte]=100&name[contains]=sword),
+// or field=value for equality, and values of the "in" operator are comma separated
+// (i.e. category[in]=weapon,armor). An error is added to the validator for every operator which
+// isn't allowed on its field and every value which can't be parsed. Other parameters are ignored.
+func ParseQuery(values url.Values, v *validator.Validator, fields ...Field) *Query {
+	query := NewQuery()
+
+	declared := make(map[string]Field, len(fields))
+	for _, field := range fields {
+		declared[field.Name] = field
+	}
+
+	// Parameters are sorted so that the conditions are always built in the same order
+	params := make([]string, 0, len(values))
+	for param := range values {
+		params = append(params, param)
+	}
+
+	sort.Strings(params)
+
+	for _, param := range params {
+		paramValues := values[param]
+		name, operator := splitParam(param)
+
+		field, ok := declared[name]
+		if !ok || len(paramValues) == 0 {
+			continue
+		}
+
+		if !field.allows(operator) {
+			v.AddError(param, "unsupported operator "+string(operator))
+			continue
+		}
+
+		value, ok := parseOperand(field, operator, paramValues[0])
+		if !ok {
+			v.AddError(param, "invalid value")
+			continue
+		}
+
+		query.Where(field.column(), operator, value)
+	}
+
+	return query
+}
+
+// splitParam splits a query string parameter into its field name and operator,
+// i.e. "price[gte]" gives "price" and "gte"
+func splitParam(param string) (string, Operator) {
+	name, rest, found := strings.Cut(param, "[")
+	if !found || !strings.HasSuffix(rest, "]") {
+		return param, OpEqual
+	}
+
+	return name, Operator(strings.TrimSuffix(rest, "]"))
+}
+
+// parseOperand parses the query string value of an operator on the given field
+func parseOperand(field Field, operator Operator, raw string) (any, bool) {
+	switch operator {
+	case OpIn:
+		parts := strings.Split(raw, ",")
+		values := make([]any, 0, len(parts))
+
+		for _, part := range parts {
+			value, ok := parseValue(field.Type, strings.TrimSpace(part))
+			if !ok {
+				return nil, false
+			}
+
+			values = append(values, value)
+		}
+
+		return values, true
+	case OpContains:
+		return raw, raw != ""
+	default:
+		return parseValue(field.Type, raw)
+	}
+}
+
+// parseValue parses a query string value according to the field type
+func parseValue(fieldType FieldType, raw string) (any, bool) {
+	switch fieldType {
+	case IntField:
+		value, err := strconv.ParseInt(raw, 10, 64)
+		return value, err == nil
+	case FloatField:
+		value, err := strconv.ParseFloat(raw, 64)
+		return value, err == nil
+	case BoolField:
+		value, err := strconv.ParseBool(raw)
+		return value, err == nil
+	case TimeField:
+		value, err := time.Parse(time.RFC3339, raw)
+		return value, err == nil
+	case ObjectIDField:
+		value, err := primitive.ObjectIDFromHex(raw)
+		return value, err == nil
+	default:
+		return raw, true
+	}
+}
@@ -0,0 +1,228 @@
+package filters
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Operator is a custom type that represents a comparison operator used in a query condition
+type Operator string
+
+// Supported query operators
+const (
+	OpEqual              Operator = "eq"
+	OpNotEqual           Operator = "ne"
+	OpGreaterThan        Operator = "gt"
+	OpGreaterThanOrEqual Operator = "gte"
+	OpLessThan           Operator = "lt"
+	OpLessThanOrEqual    Operator = "lte"
+	OpIn                 Operator = "in"
+	OpContains           Operator = "contains"
+)
+
+// ErrUnsupportedOperator is recorded by a Query when a condition uses an operator which isn't supported
+var ErrUnsupportedOperator = errors.New("unsupported query operator")
+
+// isSupported returns whether the operator is one of the supported query operators
+func (o Operator) isSupported() bool {
+	switch o {
+	case OpEqual, OpNotEqual, OpGreaterThan, OpGreaterThanOrEqual, OpLessThan, OpLessThanOrEqual, OpIn, OpContains:
+		return true
+	default:
+		return false
+	}
+}
+
+// Condition is a struct that holds a single query condition on a field
+type Condition struct {
+	Field    string
+	Operator Operator
+	Value    any
+}
+
+// Query is a typed filter builder usable by any entity. Conditions are combined with a logical AND.
+type Query struct {
+	conditions []Condition
+	err        error
+}
+
+// NewQuery creates a new empty Query
+func NewQuery() *Query {
+	return &Query{}
+}
+
+// Where adds a condition on the given field to the query. A condition with an unsupported operator
+// makes the query match nothing rather than being dropped, and its error is returned by Err.
+func (q *Query) Where(field string, operator Operator, value any) *Query {
+	if !operator.isSupported() && q.err == nil {
+		q.err = fmt.Errorf("%w: %q on %s", ErrUnsupportedOperator, operator, field)
+	}
+
+	q.conditions = append(q.conditions, Condition{Field: field, Operator: operator, Value: value})
+
+	return q
+}
+
+// Equal adds an equality condition on the given field to the query
+func (q *Query) Equal(field string, value any) *Query {
+	return q.Where(field, OpEqual, value)
+}
+
+// In adds a condition to the query which matches if the field is equal to any of the given values
+func (q *Query) In(field string, values ...any) *Query {
+	return q.Where(field, OpIn, values)
+}
+
+// Range adds a range condition on the given field to the query. Both bounds are inclusive
+// and a nil bound is ignored, so the range can be open on either side.
+func (q *Query) Range(field string, min, max any) *Query {
+	if min != nil {
+		q.Where(field, OpGreaterThanOrEqual, min)
+	}
+
+	if max != nil {
+		q.Where(field, OpLessThanOrEqual, max)
+	}
+
+	return q
+}
+
+// Contains adds a case insensitive text condition on the given field to the query.
+// An empty text is ignored.
+func (q *Query) Contains(field string, text string) *Query {
+	if text == "" {
+		return q
+	}
+
+	return q.Where(field, OpContains, text)
+}
+
+// Conditions returns the conditions of the query
+func (q *Query) Conditions() []Condition {
+	return q.conditions
+}
+
+// Err returns the error of the first condition with an unsupported operator, or nil if every
+// condition is valid
+func (q *Query) Err() error {
+	return q.err
+}
+
+// IsEmpty returns true if the query has no conditions
+func (q *Query) IsEmpty() bool {
+	return len(q.conditions) == 0
+}
+
+// Mongo converts the query into a MongoDB filter. Conditions on the same field are merged,
+// so that a lower and an upper bound on a field don't overwrite each other. The filter matches
+// nothing if a condition has an unsupported operator.
+func (q *Query) Mongo() primitive.M {
+	filter := primitive.M{}
+
+	for _, condition := range q.conditions {
+		var operator string
+		var value any = condition.Value
+
+		switch condition.Operator {
+		case OpEqual:
+			operator = "$eq"
+		case OpNotEqual:
+			operator = "$ne"
+		case OpGreaterThan:
+			operator = "$gt"
+		case OpGreaterThanOrEqual:
+			operator = "$gte"
+		case OpLessThan:
+			operator = "$lt"
+		case OpLessThanOrEqual:
+			operator = "$lte"
+		case OpIn:
+			operator = "$in"
+		case OpContains:
+			operator = "$regex"
+			value = primitive.Regex{Pattern: regexp.QuoteMeta(fmt.Sprint(condition.Value)), Options: "i"}
+		default:
+			// Match nothing rather than widening the query (see Err)
+			filter["$expr"] = false
+			continue
+		}
+
+		fieldFilter, ok := filter[condition.Field].(primitive.M)
+		if !ok {
+			fieldFilter = primitive.M{}
+			filter[condition.Field] = fieldFilter
+		}
+
+		fieldFilter[operator] = value
+	}
+
+	return filter
+}
+
+// SQL converts the query into a SQL condition for Postgres, with numbered placeholders starting
+// after the given number of existing arguments (i.e. 0 for "price >= $1 AND price <= $2"), and
+// returns the condition along with its arguments. It returns "TRUE" for an empty query, and a
+// condition with an unsupported operator is written as "FALSE".
+// Field names are written as is in the condition, so they must never come from user input.
+func (q *Query) SQL(argsOffset int) (string, []any) {
+	clauses := make([]string, 0, len(q.conditions))
+	args := []any{}
+
+	placeholder := func(value any) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(argsOffset+len(args))
+	}
+
+	for _, condition := range q.conditions {
+		var clause string
+
+		switch condition.Operator {
+		case OpEqual:
+			clause = condition.Field + " = " + placeholder(condition.Value)
+		case OpNotEqual:
+			clause = condition.Field + " <> " + placeholder(condition.Value)
+		case OpGreaterThan:
+			clause = condition.Field + " > " + placeholder(condition.Value)
+		case OpGreaterThanOrEqual:
+			clause = condition.Field + " >= " + placeholder(condition.Value)
+		case OpLessThan:
+			clause = condition.Field + " < " + placeholder(condition.Value)
+		case OpLessThanOrEqual:
+			clause = condition.Field + " <= " + placeholder(condition.Value)
+		case OpIn:
+			values, _ := condition.Value.([]any)
+			if len(values) == 0 {
+				clause = "FALSE"
+				break
+			}
+
+			placeholders := make([]string, len(values))
+			for i, value := range values {
+				placeholders[i] = placeholder(value)
+			}
+
+			clause = condition.Field + " IN (" + strings.Join(placeholders, ", ") + ")"
+		case OpContains:
+			clause = condition.Field + " ILIKE " + placeholder("%"+likeEscaper.Replace(fmt.Sprint(condition.Value))+"%")
+		default:
+			// Match nothing rather than widening the query (see Err)
+			clause = "FALSE"
+		}
+
+		clauses = append(clauses, clause)
+	}
+
+	if len(clauses) == 0 {
+		return "TRUE", args
+	}
+
+	return strings.Join(clauses, " AND "), args
+}
+
+// likeEscaper escapes the wildcard characters of LIKE patterns
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
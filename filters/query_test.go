@@ -0,0 +1,70 @@
+package filters
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestQueryMongo(t *testing.T) {
+	query := NewQuery().Range("price", 10, 100).Equal("category", "weapon")
+
+	want := primitive.M{
+		"price":    primitive.M{"$gte": 10, "$lte": 100},
+		"category": primitive.M{"$eq": "weapon"},
+	}
+
+	if got := query.Mongo(); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v; got %v", want, got)
+	}
+
+	if err := query.Err(); err != nil {
+		t.Errorf("want no error; got %v", err)
+	}
+}
+
+func TestQuerySQL(t *testing.T) {
+	query := NewQuery().Range("price", 10, 100).In("category", "weapon", "armor")
+
+	condition, args := query.SQL(1)
+
+	wantCondition := "price >= $2 AND price <= $3 AND category IN ($4, $5)"
+	if condition != wantCondition {
+		t.Errorf("want %q; got %q", wantCondition, condition)
+	}
+
+	if wantArgs := []any{10, 100, "weapon", "armor"}; !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("want %v; got %v", wantArgs, args)
+	}
+}
+
+func TestQueryUnsupportedOperator(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator Operator
+	}{
+		{name: "unknown", operator: "like"},
+		{name: "zero value", operator: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := NewQuery().Where("name", tt.operator, "sword")
+
+			if err := query.Err(); !errors.Is(err, ErrUnsupportedOperator) {
+				t.Errorf("want ErrUnsupportedOperator; got %v", err)
+			}
+
+			// The query must match nothing rather than everything
+			if got := query.Mongo(); got["$expr"] != false {
+				t.Errorf("want a filter matching nothing; got %v", got)
+			}
+
+			if condition, _ := query.SQL(0); condition != "FALSE" {
+				t.Errorf("want FALSE; got %q", condition)
+			}
+		})
+	}
+}
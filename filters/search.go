@@ -0,0 +1,85 @@
+package filters
+
+import (
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SearchStrategy is a custom type which defines how the search term of filters is matched
+type SearchStrategy int8
+
+// Supported search strategies
+const (
+	PrefixSearch SearchStrategy = iota // Case insensitive match of the start of any searchable field
+	ExactSearch                        // Exact match of any searchable field
+	TextSearch                         // Match of the collection text index, which must exist
+)
+
+// MaxSearchLength is the maximum number of characters of a search term
+const MaxSearchLength = 100
+
+// SearchMongo converts the search term of the filters into a MongoDB filter matching any of the
+// searchable fields with the search strategy. It returns an empty filter if there is no search term.
+func (f Filters) SearchMongo() primitive.M {
+	search := strings.TrimSpace(f.Search)
+	if search == "" {
+		return primitive.M{}
+	}
+
+	if f.SearchStrategy == TextSearch {
+		return primitive.M{"$text": primitive.M{"$search": search}}
+	}
+
+	if len(f.SearchFields) == 0 {
+		return primitive.M{}
+	}
+
+	var value any = search
+	if f.SearchStrategy == PrefixSearch {
+		value = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(search), Options: "i"}
+	}
+
+	conditions := make(primitive.A, 0, len(f.SearchFields))
+	for _, field := range f.SearchFields {
+		conditions = append(conditions, primitive.M{field: value})
+	}
+
+	if len(conditions) == 1 {
+		return conditions[0].(primitive.M)
+	}
+
+	return primitive.M{"$or": conditions}
+}
+
+// ApplySearch adds the search filter of the filters to the given MongoDB filter (i.e. built with
+// Query.Mongo) and returns the result. Both filters are combined with a logical AND.
+func (f Filters) ApplySearch(filter primitive.M) primitive.M {
+	search := f.SearchMongo()
+	if len(search) == 0 {
+		return filter
+	}
+
+	if len(filter) == 0 {
+		return search
+	}
+
+	for key := range search {
+		if _, exists := filter[key]; exists {
+			// Avoid overwriting conditions of the filter on the same key (i.e. "$or")
+			return primitive.M{"$and": primitive.A{filter, search}}
+		}
+	}
+
+	merged := make(primitive.M, len(filter)+len(search))
+	for key, value := range filter {
+		merged[key] = value
+	}
+
+	for key, value := range search {
+		merged[key] = value
+	}
+
+	return merged
+}
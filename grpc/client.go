@@ -0,0 +1,293 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/PlayEconomy37/Play.Common/common"
+	"github.com/PlayEconomy37/Play.Common/configuration"
+	"github.com/PlayEconomy37/Play.Common/discovery"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	defaultClientTimeout  = 10 * time.Second       // Used if the Timeout of a client is empty
+	defaultMaxAttempts    = 3                      // Used if the MaxAttempts of a client is empty
+	defaultInitialBackoff = 100 * time.Millisecond // Used if the InitialBackoff of a client is empty
+	defaultMaxBackoff     = time.Second            // Used if the MaxBackoff of a client is empty
+)
+
+// ErrUnknownService is returned when a client connection is requested for a service which isn't
+// configured in GRPC.Clients
+var ErrUnknownService = errors.New("no gRPC client configured for service")
+
+// ClientFactory is a struct which creates the client connections to the services configured in
+// GRPC.Clients, keyed by service name. Connections are created once and shared by the callers.
+type ClientFactory struct {
+	app *common.App
+
+	mu       sync.Mutex
+	conns    map[string]*grpclib.ClientConn
+	resolver *discovery.Resolver // Created on first use if Discovery.Type is set
+}
+
+// NewClientFactory creates a ClientFactory for the given application. The connections it created are
+// closed during the graceful shutdown of the application, after the calls in flight completed.
+func NewClientFactory(app *common.App) *ClientFactory {
+	factory := &ClientFactory{app: app, conns: map[string]*grpclib.ClientConn{}}
+
+	app.OnShutdown(func(ctx context.Context) error {
+		return factory.Close()
+	})
+
+	return factory
+}
+
+// Conn returns the client connection to the given service (i.e. "inventory"), created on first use from
+// its configuration with the following behaviour:
+//   - calls are traced, with trace context and baggage propagation
+//   - calls are balanced over the addresses of the target in a round-robin fashion, which are the healthy
+//     instances of the service for "discovery:///<service>" targets if Discovery.Type is set
+//   - calls carry the ID of the request which led to them in their "x-request-id" metadata
+//   - unary calls made without a deadline get the configured timeout
+//   - calls failing with Unavailable are retried with an exponential backoff
+//   - calls carry an access token of the client credentials flow if Auth.ClientId is set
+//
+// The given dial options are only used when the connection is created.
+//
+//	conn, err := clients.Conn("inventory")
+//	client := inventory.NewInventoryClient(conn)
+func (f *ClientFactory) Conn(service string, opts ...grpclib.DialOption) (*grpclib.ClientConn, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if conn, exists := f.conns[service]; exists {
+		return conn, nil
+	}
+
+	clientCfg, exists := f.app.Config.GRPC.Clients[service]
+	if !exists || clientCfg.Target == "" {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownService, service)
+	}
+
+	dialOptions, err := f.dialOptions(clientCfg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to configure the gRPC client of %s: %w", service, err)
+	}
+
+	conn, err := grpclib.Dial(clientCfg.Target, append(dialOptions, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	f.conns[service] = conn
+
+	return conn, nil
+}
+
+// Close closes the connections created by the factory
+func (f *ClientFactory) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var firstErr error
+	for service, conn := range f.conns {
+		if err := conn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+
+		delete(f.conns, service)
+	}
+
+	return firstErr
+}
+
+// dialOptions returns the dial options of a client with the given configuration
+func (f *ClientFactory) dialOptions(clientCfg configuration.GRPCClientConfig) ([]grpclib.DialOption, error) {
+	transportCredentials := insecure.NewCredentials()
+	if !clientCfg.Insecure {
+		tlsConfig, err := clientTLSConfig(clientCfg.CACertFile)
+		if err != nil {
+			return nil, err
+		}
+
+		transportCredentials = credentials.NewTLS(tlsConfig)
+	}
+
+	timeout := clientCfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultClientTimeout
+	}
+
+	dialOptions := []grpclib.DialOption{
+		grpclib.WithTransportCredentials(transportCredentials),
+		grpclib.WithDefaultServiceConfig(serviceConfig(clientCfg)),
+		grpclib.WithChainUnaryInterceptor(
+			otelgrpc.UnaryClientInterceptor(),
+			defaultDeadline(timeout),
+			propagateUnaryRequestID,
+		),
+		grpclib.WithChainStreamInterceptor(
+			otelgrpc.StreamClientInterceptor(),
+			propagateStreamRequestID,
+		),
+	}
+
+	if f.app.Config.Discovery.Type != "" {
+		if f.resolver == nil {
+			registry, err := discovery.NewRegistry(f.app.Config)
+			if err != nil {
+				return nil, err
+			}
+
+			f.resolver = discovery.NewResolver(registry, f.app.Config)
+		}
+
+		dialOptions = append(dialOptions, grpclib.WithResolvers(f.resolver.GRPCResolver()))
+	}
+
+	if f.app.Config.Auth.ClientID != "" {
+		tokenSource, err := f.app.NewTokenSource(clientCfg.Scopes...)
+		if err != nil {
+			return nil, err
+		}
+
+		dialOptions = append(dialOptions, grpclib.WithPerRPCCredentials(&tokenCredentials{
+			source:     tokenSource,
+			requireTLS: !clientCfg.Insecure,
+		}))
+	}
+
+	return dialOptions, nil
+}
+
+// clientTLSConfig returns the TLS configuration of a client trusting the given CA certificate,
+// or the system pool if it is empty
+func clientTLSConfig(caCertFile string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if caCertFile != "" {
+		caCert, err := os.ReadFile(caCertFile)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificate found in %s", caCertFile)
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// serviceConfig returns the service config balancing the calls over the addresses of the target and
+// retrying the calls of every method failing with Unavailable, which is safe since the call didn't
+// reach the service
+func serviceConfig(clientCfg configuration.GRPCClientConfig) string {
+	maxAttempts := clientCfg.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	if maxAttempts == 1 {
+		return `{"loadBalancingConfig": [{"round_robin": {}}]}`
+	}
+
+	initialBackoff := clientCfg.InitialBackoff
+	if initialBackoff <= 0 {
+		initialBackoff = defaultInitialBackoff
+	}
+
+	maxBackoff := clientCfg.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+
+	return fmt.Sprintf(`{"loadBalancingConfig": [{"round_robin": {}}], "methodConfig": [{"name": [{}], "retryPolicy": {
+		"maxAttempts": %d,
+		"initialBackoff": "%.3fs",
+		"maxBackoff": "%.3fs",
+		"backoffMultiplier": 2,
+		"retryableStatusCodes": ["UNAVAILABLE"]
+	}}]}`, maxAttempts, initialBackoff.Seconds(), maxBackoff.Seconds())
+}
+
+// defaultDeadline returns an interceptor which sets the given timeout on unary calls made without a deadline
+func defaultDeadline(timeout time.Duration) grpclib.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpclib.ClientConn, invoker grpclib.UnaryInvoker, opts ...grpclib.CallOption) error {
+		if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// propagateUnaryRequestID is an interceptor which adds the ID of the current request to unary calls
+func propagateUnaryRequestID(ctx context.Context, method string, req, reply any, cc *grpclib.ClientConn, invoker grpclib.UnaryInvoker, opts ...grpclib.CallOption) error {
+	return invoker(withRequestID(ctx), method, req, reply, cc, opts...)
+}
+
+// propagateStreamRequestID is an interceptor which adds the ID of the current request to streaming calls
+func propagateStreamRequestID(ctx context.Context, desc *grpclib.StreamDesc, cc *grpclib.ClientConn, method string, streamer grpclib.Streamer, opts ...grpclib.CallOption) (grpclib.ClientStream, error) {
+	return streamer(withRequestID(ctx), desc, cc, method, opts...)
+}
+
+// withRequestID returns a copy of the given context whose outgoing metadata holds the ID of the HTTP request
+// (set by chi's RequestID middleware) or of the gRPC call being handled, unless it already holds one
+func withRequestID(ctx context.Context) context.Context {
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(requestIDMetadataKey)) > 0 {
+		return ctx
+	}
+
+	requestID := middleware.GetReqID(ctx)
+	if requestID == "" {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if requestIDs := md.Get(requestIDMetadataKey); len(requestIDs) > 0 {
+				requestID = requestIDs[0]
+			}
+		}
+	}
+
+	if requestID == "" {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, requestIDMetadataKey, requestID)
+}
+
+// tokenCredentials is a credentials.PerRPCCredentials sending an access token of the given source
+// in the "authorization" metadata of every call
+type tokenCredentials struct {
+	source     common.TokenSource
+	requireTLS bool
+}
+
+// GetRequestMetadata returns the "authorization" metadata of a call
+func (c *tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	token, err := c.source.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"authorization": "Bearer " + token}, nil
+}
+
+// RequireTransportSecurity returns whether tokens may only be sent over TLS
+func (c *tokenCredentials) RequireTransportSecurity() bool {
+	return c.requireTLS
+}
@@ -0,0 +1,100 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/PlayEconomy37/Play.Common/common"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// healthWatchInterval is the interval at which the health of the application is checked for Watch calls
+const healthWatchInterval = 5 * time.Second
+
+// healthServer is the standard gRPC health service (grpc.health.v1.Health), which reports the application
+// as serving when every health checker registered with RegisterHealthChecker succeeds, like /readyz
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+
+	app    *common.App
+	server *grpclib.Server
+}
+
+// Check returns the health of the application. The overall health is requested with an empty service name,
+// which is the same as the health of any service of the server since they share the same dependencies.
+func (s *healthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if !s.knowsService(req.GetService()) {
+		return nil, status.Errorf(codes.NotFound, "unknown service %s", req.GetService())
+	}
+
+	return &healthpb.HealthCheckResponse{Status: s.servingStatus(ctx)}, nil
+}
+
+// Watch sends the health of the application, then every change of its health until the call is cancelled
+func (s *healthServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	if !s.knowsService(req.GetService()) {
+		return stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVICE_UNKNOWN})
+	}
+
+	ticker := time.NewTicker(healthWatchInterval)
+	defer ticker.Stop()
+
+	lastStatus := healthpb.HealthCheckResponse_UNKNOWN
+
+	for {
+		if servingStatus := s.servingStatus(stream.Context()); servingStatus != lastStatus {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: servingStatus}); err != nil {
+				return err
+			}
+
+			lastStatus = servingStatus
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// knowsService returns whether the given service name is empty or the name of a service of the server
+func (s *healthServer) knowsService(service string) bool {
+	if service == "" {
+		return true
+	}
+
+	_, exists := s.server.GetServiceInfo()[service]
+
+	return exists
+}
+
+// servingStatus runs the health checkers of the application and returns its serving status
+func (s *healthServer) servingStatus(ctx context.Context) healthpb.HealthCheckResponse_ServingStatus {
+	if len(s.app.CheckHealth(ctx)) > 0 {
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	}
+
+	return healthpb.HealthCheckResponse_SERVING
+}
+
+// registerHealthAndReflection registers the health service on the given server, along with the reflection
+// service (i.e. for grpcurl) if it is enabled in the configuration and the environment isn't production
+func registerHealthAndReflection(app *common.App, server *grpclib.Server) {
+	healthpb.RegisterHealthServer(server, &healthServer{app: app, server: server})
+
+	if app.Config.GRPC.Reflection && !strings.EqualFold(app.Config.Environment, "production") {
+		reflection.Register(server)
+	}
+}
+
+// infrastructureMethods are the services registered by NewServer, which are public
+var infrastructureMethods = []string{
+	"/" + healthpb.Health_ServiceDesc.ServiceName + "/",
+	"/grpc.reflection.v1alpha.ServerReflection/",
+}
@@ -0,0 +1,313 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PlayEconomy37/Play.Common/common"
+	"github.com/PlayEconomy37/Play.Common/logger"
+	"github.com/PlayEconomy37/Play.Common/opentelemetry"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/exp/slog"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDMetadataKey is the metadata key holding the ID of the request which led to a call,
+// the gRPC equivalent of the X-Request-Id header
+const requestIDMetadataKey = "x-request-id"
+
+// wrappedStream is a grpc.ServerStream whose context can be replaced, i.e. by a context holding the user
+type wrappedStream struct {
+	grpclib.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context of the stream
+func (s *wrappedStream) Context() context.Context {
+	return s.ctx
+}
+
+// serverMetrics is a struct that records the metrics of the calls handled by a gRPC server
+type serverMetrics struct {
+	metrics *opentelemetry.GRPCMetrics
+}
+
+// newServerMetrics creates the metrics of the gRPC server of the given application, registered
+// with the registerer of the application and using the configured duration buckets
+func newServerMetrics(app *common.App) *serverMetrics {
+	registerer := app.MetricsRegisterer
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	return &serverMetrics{
+		metrics: opentelemetry.NewGRPCMetrics(app.Config.ServiceName, registerer, app.Config.Metrics.DurationBuckets),
+	}
+}
+
+// unaryInterceptor records the metrics of unary calls
+func (m *serverMetrics) unaryInterceptor(ctx context.Context, req any, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (any, error) {
+	start := time.Now()
+	res, err := handler(ctx, req)
+	m.observe(ctx, info.FullMethod, err, time.Since(start))
+
+	return res, err
+}
+
+// streamInterceptor records the metrics of streaming calls
+func (m *serverMetrics) streamInterceptor(srv any, stream grpclib.ServerStream, info *grpclib.StreamServerInfo, handler grpclib.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, stream)
+	m.observe(stream.Context(), info.FullMethod, err, time.Since(start))
+
+	return err
+}
+
+// observe records a call of the given method which ended with the given error
+func (m *serverMetrics) observe(ctx context.Context, fullMethod string, err error, duration time.Duration) {
+	code := status.Code(err).String()
+
+	m.metrics.HandledCounter.WithLabelValues(fullMethod, code).Inc()
+	opentelemetry.ObserveWithExemplar(ctx, m.metrics.HandlingDurationHistogram.WithLabelValues(fullMethod, code), duration.Seconds())
+}
+
+// logUnaryCall returns an interceptor which logs every unary call, like the LogRequest middleware
+func logUnaryCall(app *common.App) grpclib.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (any, error) {
+		ctx, requestLogger := newCallContext(app, ctx, info.FullMethod)
+
+		start := time.Now()
+		res, err := handler(ctx, req)
+		logCall(requestLogger, info.FullMethod, err, time.Since(start))
+
+		return res, err
+	}
+}
+
+// logStreamCall returns an interceptor which logs every streaming call, like the LogRequest middleware
+func logStreamCall(app *common.App) grpclib.StreamServerInterceptor {
+	return func(srv any, stream grpclib.ServerStream, info *grpclib.StreamServerInfo, handler grpclib.StreamHandler) error {
+		ctx, requestLogger := newCallContext(app, stream.Context(), info.FullMethod)
+
+		start := time.Now()
+		err := handler(srv, &wrappedStream{ServerStream: stream, ctx: ctx})
+		logCall(requestLogger, info.FullMethod, err, time.Since(start))
+
+		return err
+	}
+}
+
+// newCallContext returns a copy of the given call context holding a sub-logger with the method, request ID
+// and baggage of the call, which handlers retrieve with LoggerFromContext. Calls entering the system get
+// the service name as origin.
+func newCallContext(app *common.App, ctx context.Context, fullMethod string) (context.Context, *logger.Logger) {
+	if opentelemetry.Origin(ctx) == "" {
+		if originCtx, err := opentelemetry.WithOrigin(ctx, app.Config.ServiceName); err == nil {
+			ctx = originCtx
+		}
+	}
+
+	attrs := []slog.Attr{slog.String(logger.MethodProperty, fullMethod)}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if requestIDs := md.Get(requestIDMetadataKey); len(requestIDs) > 0 && requestIDs[0] != "" {
+			attrs = append(attrs, slog.String(logger.RequestIDProperty, requestIDs[0]))
+		}
+	}
+
+	// Add the entries of the baggage propagated by the calling service
+	baggageAttrs := []slog.Attr{
+		slog.String(logger.UserIDProperty, opentelemetry.UserID(ctx)),
+		slog.String(logger.TenantIDProperty, opentelemetry.TenantID(ctx)),
+		slog.String(logger.OriginProperty, opentelemetry.Origin(ctx)),
+	}
+
+	for _, attr := range baggageAttrs {
+		if attr.Value() != "" {
+			attrs = append(attrs, attr)
+		}
+	}
+
+	requestLogger := app.Logger.With(attrs...)
+
+	return logger.NewContext(ctx, requestLogger), requestLogger
+}
+
+// logCall logs a call of the given method which ended with the given error. Calls failing because of
+// the server are logged as errors.
+func logCall(requestLogger *logger.Logger, fullMethod string, err error, duration time.Duration) {
+	code := status.Code(err)
+
+	properties := map[string]string{
+		"method":   fullMethod,
+		"code":     code.String(),
+		"duration": duration.String(),
+	}
+
+	switch code {
+	case codes.Unknown, codes.Internal, codes.DataLoss:
+		requestLogger.Error(err, properties)
+	default:
+		requestLogger.Info(fmt.Sprintf("%s %s", fullMethod, code), properties)
+	}
+}
+
+// recoverUnaryPanic returns an interceptor which turns panics of unary calls into Internal errors,
+// like the RecoverPanic middleware
+func recoverUnaryPanic(app *common.App) grpclib.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (res any, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = internalError(app, ctx, fmt.Errorf("%s", recovered))
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// recoverStreamPanic returns an interceptor which turns panics of streaming calls into Internal errors,
+// like the RecoverPanic middleware
+func recoverStreamPanic(app *common.App) grpclib.StreamServerInterceptor {
+	return func(srv any, stream grpclib.ServerStream, info *grpclib.StreamServerInfo, handler grpclib.StreamHandler) (err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = internalError(app, stream.Context(), fmt.Errorf("%s", recovered))
+			}
+		}()
+
+		return handler(srv, stream)
+	}
+}
+
+// internalError logs the given unexpected error and returns the Internal error sent to the client,
+// which doesn't disclose it
+func internalError(app *common.App, ctx context.Context, err error) error {
+	app.LoggerFromContext(ctx).ErrorCtx(ctx, err, nil)
+
+	return status.Error(codes.Internal, "the server encountered a problem and could not process your request")
+}
+
+// authenticateUnaryCall returns an interceptor which authenticates the user of unary calls of non-public
+// methods, like the Authenticate middleware
+func authenticateUnaryCall(app *common.App, options serverOptions) grpclib.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (any, error) {
+		if isPublicMethod(options.publicMethods, info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		ctx, err := authenticate(app, ctx, options.authenticator)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// authenticateStreamCall returns an interceptor which authenticates the user of streaming calls of
+// non-public methods, like the Authenticate middleware
+func authenticateStreamCall(app *common.App, options serverOptions) grpclib.StreamServerInterceptor {
+	return func(srv any, stream grpclib.ServerStream, info *grpclib.StreamServerInfo, handler grpclib.StreamHandler) error {
+		if isPublicMethod(options.publicMethods, info.FullMethod) {
+			return handler(srv, stream)
+		}
+
+		ctx, err := authenticate(app, stream.Context(), options.authenticator)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &wrappedStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// authenticate authenticates the user of the access token of the "authorization" metadata of the given
+// call context, and returns a copy of the context holding the user
+func authenticate(app *common.App, ctx context.Context, authenticator *common.Authenticator) (context.Context, error) {
+	var authorization string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			authorization = values[0]
+		}
+	}
+
+	if authorization == "" {
+		return ctx, status.Error(codes.Unauthenticated, common.ErrInvalidAuthenticationToken.Error())
+	}
+
+	user, err := authenticator.AuthenticateHeader(ctx, authorization)
+	if err != nil {
+		if errors.Is(err, common.ErrInvalidAuthenticationToken) {
+			return ctx, status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		return ctx, internalError(app, ctx, err)
+	}
+
+	return app.ContextWithUser(ctx, user), nil
+}
+
+// isPublicMethod returns whether the given method is one of the given public methods or services
+func isPublicMethod(publicMethods []string, fullMethod string) bool {
+	for _, publicMethod := range publicMethods {
+		if publicMethod == fullMethod || (strings.HasSuffix(publicMethod, "/") && strings.HasPrefix(fullMethod, publicMethod)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// requireUnaryPermission returns an interceptor which checks that the user of unary calls has the
+// permissions of their method, like the RequirePermission middleware
+func requireUnaryPermission(app *common.App, options serverOptions) grpclib.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (any, error) {
+		if err := checkPermissions(app, ctx, options, info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// requireStreamPermission returns an interceptor which checks that the user of streaming calls has the
+// permissions of their method, like the RequirePermission middleware
+func requireStreamPermission(app *common.App, options serverOptions) grpclib.StreamServerInterceptor {
+	return func(srv any, stream grpclib.ServerStream, info *grpclib.StreamServerInfo, handler grpclib.StreamHandler) error {
+		if err := checkPermissions(app, stream.Context(), options, info.FullMethod); err != nil {
+			return err
+		}
+
+		return handler(srv, stream)
+	}
+}
+
+// checkPermissions checks that the user of the given call context has the permissions of the given method
+func checkPermissions(app *common.App, ctx context.Context, options serverOptions, fullMethod string) error {
+	codesRequired, exists := options.permissions[fullMethod]
+	if !exists {
+		return nil
+	}
+
+	user, ok := app.UserFromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "you must be authenticated to access this resource")
+	}
+
+	permitted, err := app.HasPermissions(ctx, options.repository, user.ID, codesRequired...)
+	if err != nil {
+		return internalError(app, ctx, err)
+	}
+
+	if !permitted {
+		return status.Error(codes.PermissionDenied, "your user account doesn't have the necessary permissions to access this resource")
+	}
+
+	return nil
+}
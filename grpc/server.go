@@ -0,0 +1,157 @@
+// Package grpc provides a gRPC server with the same cross-cutting concerns as the HTTP stack of
+// common.App: panic recovery, request-scoped logging, metrics, tracing, authentication and permissions,
+// along with a factory of client connections sharing the same resiliency and tracing settings.
+package grpc
+
+import (
+	"context"
+	"net"
+
+	"github.com/PlayEconomy37/Play.Common/common"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	grpclib "google.golang.org/grpc"
+)
+
+// defaultAddress is the address the gRPC server listens on if none is configured
+const defaultAddress = ":50051"
+
+// ServerOption is a function which configures the server created by NewServer
+type ServerOption func(opts *serverOptions)
+
+// serverOptions is a struct that holds the options of the server created by NewServer
+type serverOptions struct {
+	authenticator *common.Authenticator
+	publicMethods []string
+	repository    common.AuthRepository
+	permissions   map[string][]string
+	grpcOptions   []grpclib.ServerOption
+}
+
+// WithAuthentication requires every call to carry a valid JWT access token in its "authorization" metadata
+// ("Bearer <token>"), validated with the same rules as the Authenticate middleware, except the calls of the
+// given public methods and of the health and reflection services. Public methods are full method names
+// (i.e. "/catalog.Catalog/GetItems") or service names followed by a slash (i.e. "/catalog.Catalog/")
+// for every method of the service.
+func WithAuthentication(authenticator *common.Authenticator, publicMethods ...string) ServerOption {
+	return func(opts *serverOptions) {
+		opts.authenticator = authenticator
+		opts.publicMethods = append(opts.publicMethods, publicMethods...)
+	}
+}
+
+// WithPermissions requires the authenticated user to have the given permissions to call the methods they
+// are keyed by (i.e. "/inventory.Inventory/GrantItems"), like the RequirePermission middleware
+func WithPermissions(repository common.AuthRepository, permissions map[string][]string) ServerOption {
+	return func(opts *serverOptions) {
+		opts.repository = repository
+		opts.permissions = permissions
+	}
+}
+
+// WithGRPCOptions adds options of the gRPC library to the server, i.e. transport credentials or keepalive settings
+func WithGRPCOptions(grpcOptions ...grpclib.ServerOption) ServerOption {
+	return func(opts *serverOptions) {
+		opts.grpcOptions = append(opts.grpcOptions, grpcOptions...)
+	}
+}
+
+// NewServer creates a gRPC server whose calls go through interceptors mirroring the HTTP middlewares
+// of the application, in the following order: tracing (with trace context and baggage propagation),
+// metrics, logging, panic recovery, authentication and permissions. The standard health service, which
+// reports the health checkers of the application like /readyz, and the reflection service (if GRPC.Reflection
+// is set outside of production) are registered on the returned server, along with the services of the
+// application before calling Serve.
+//
+//	server := grpc.NewServer(app, grpc.WithAuthentication(authenticator))
+//	inventory.RegisterInventoryServer(server, inventoryServer)
+func NewServer(app *common.App, opts ...ServerOption) *grpclib.Server {
+	var options serverOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	metrics := newServerMetrics(app)
+
+	unaryInterceptors := []grpclib.UnaryServerInterceptor{
+		otelgrpc.UnaryServerInterceptor(),
+		metrics.unaryInterceptor,
+		logUnaryCall(app),
+		recoverUnaryPanic(app),
+	}
+
+	streamInterceptors := []grpclib.StreamServerInterceptor{
+		otelgrpc.StreamServerInterceptor(),
+		metrics.streamInterceptor,
+		logStreamCall(app),
+		recoverStreamPanic(app),
+	}
+
+	if options.authenticator != nil {
+		options.publicMethods = append(options.publicMethods, infrastructureMethods...)
+
+		unaryInterceptors = append(unaryInterceptors, authenticateUnaryCall(app, options))
+		streamInterceptors = append(streamInterceptors, authenticateStreamCall(app, options))
+	}
+
+	if len(options.permissions) > 0 {
+		unaryInterceptors = append(unaryInterceptors, requireUnaryPermission(app, options))
+		streamInterceptors = append(streamInterceptors, requireStreamPermission(app, options))
+	}
+
+	serverOptions := append([]grpclib.ServerOption{
+		grpclib.ChainUnaryInterceptor(unaryInterceptors...),
+		grpclib.ChainStreamInterceptor(streamInterceptors...),
+	}, options.grpcOptions...)
+
+	server := grpclib.NewServer(serverOptions...)
+	registerHealthAndReflection(app, server)
+
+	return server
+}
+
+// Serve listens on the gRPC address of the configuration and serves the calls of the given server until
+// the graceful shutdown of the application, which stops the server once the in-flight calls completed or
+// when the shutdown timeout is reached. It is meant to run in its own goroutine while Serve of the
+// application serves HTTP requests, and must not be tracked by the wait group of the application.
+//
+//	go func() {
+//		if err := grpc.Serve(app, server); err != nil {
+//			app.Logger.Fatal(err, nil)
+//		}
+//	}()
+func Serve(app *common.App, server *grpclib.Server) error {
+	address := app.Config.GRPC.Address
+	if address == "" {
+		address = defaultAddress
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	app.OnShutdown(func(ctx context.Context) error {
+		stopped := make(chan struct{})
+
+		go func() {
+			server.GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			// Cancel the calls which didn't complete in time
+			server.Stop()
+		}
+
+		return nil
+	})
+
+	app.Logger.Info("Starting gRPC server", map[string]string{
+		"addr": listener.Addr().String(),
+	})
+
+	// Serve returns nil once the server has been stopped by the graceful shutdown
+	return server.Serve(listener)
+}
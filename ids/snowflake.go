@@ -0,0 +1,157 @@
+package ids
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultNodeBits is the number of bits of the node ID of snowflake IDs if none is given to WithNodeBits,
+	// which allows 1024 nodes
+	DefaultNodeBits = 10
+
+	// DefaultSequenceBits is the number of bits of the sequence of snowflake IDs if none is given to
+	// WithSequenceBits, which allows 4096 IDs per millisecond and node
+	DefaultSequenceBits = 12
+)
+
+// DefaultEpoch is the time from which the timestamp of snowflake IDs is counted if none is given to WithEpoch.
+// With the default layout, IDs can be generated for 69 years after it.
+var DefaultEpoch = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// ErrInvalidSnowflake is returned when creating a snowflake generator with an invalid layout or node ID
+var ErrInvalidSnowflake = errors.New("invalid snowflake configuration")
+
+// SnowflakeOption is a function used to configure optional behaviour of a Snowflake generator
+type SnowflakeOption func(*Snowflake)
+
+// WithEpoch sets the time from which the timestamp of the IDs is counted, which must not change
+// once IDs have been generated
+func WithEpoch(epoch time.Time) SnowflakeOption {
+	return func(s *Snowflake) {
+		s.epoch = epoch
+	}
+}
+
+// WithNodeBits sets the number of bits of the node ID, which must not change once IDs have been generated
+func WithNodeBits(bits int) SnowflakeOption {
+	return func(s *Snowflake) {
+		s.nodeBits = bits
+	}
+}
+
+// WithSequenceBits sets the number of bits of the sequence of the IDs generated within the same
+// millisecond, which must not change once IDs have been generated
+func WithSequenceBits(bits int) SnowflakeOption {
+	return func(s *Snowflake) {
+		s.sequenceBits = bits
+	}
+}
+
+// Snowflake is a struct which generates snowflake IDs: positive int64 made of the number of milliseconds
+// since the epoch, followed by the ID of the node generating them and a sequence number. IDs generated
+// by the same node are strictly increasing, and IDs generated by different nodes sort by time.
+// Every replica must use a different node ID.
+type Snowflake struct {
+	epoch        time.Time
+	nodeBits     int
+	sequenceBits int
+	nodeID       int64
+
+	mu       sync.Mutex
+	lastTime int64
+	sequence int64
+}
+
+// NewSnowflake creates a new Snowflake generator for the given node ID, which must fit in the node bits
+//
+//	generator, err := ids.NewSnowflake(ids.NodeIDFromHostname(ids.DefaultNodeBits))
+//	id := generator.Next()
+func NewSnowflake(nodeID int64, opts ...SnowflakeOption) (*Snowflake, error) {
+	generator := &Snowflake{
+		epoch:        DefaultEpoch,
+		nodeBits:     DefaultNodeBits,
+		sequenceBits: DefaultSequenceBits,
+		nodeID:       nodeID,
+	}
+
+	for _, opt := range opts {
+		opt(generator)
+	}
+
+	// Keep at least 31 bits of timestamp, which is enough for 24 days
+	if generator.nodeBits < 0 || generator.sequenceBits < 1 || generator.nodeBits+generator.sequenceBits > 32 {
+		return nil, fmt.Errorf("%w: %d node bits and %d sequence bits", ErrInvalidSnowflake, generator.nodeBits, generator.sequenceBits)
+	}
+
+	if nodeID < 0 || nodeID >= 1<<generator.nodeBits {
+		return nil, fmt.Errorf("%w: node ID %d doesn't fit in %d bits", ErrInvalidSnowflake, nodeID, generator.nodeBits)
+	}
+
+	if generator.epoch.After(time.Now()) {
+		return nil, fmt.Errorf("%w: epoch %s is in the future", ErrInvalidSnowflake, generator.epoch)
+	}
+
+	return generator, nil
+}
+
+// Next generates a new ID. When the clock goes backwards or the sequence of the current millisecond
+// is exhausted, the timestamp of the previous ID is used or moved forward, so that Next never blocks
+// nor fails.
+func (s *Snowflake) Next() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Since(s.epoch).Milliseconds()
+
+	if now > s.lastTime {
+		s.lastTime = now
+		s.sequence = 0
+	} else {
+		s.sequence = (s.sequence + 1) & (1<<s.sequenceBits - 1)
+		if s.sequence == 0 {
+			s.lastTime++
+		}
+	}
+
+	return s.lastTime<<(s.nodeBits+s.sequenceBits) | s.nodeID<<s.sequenceBits | s.sequence
+}
+
+// Time returns the time at which the given ID was generated, to the millisecond
+func (s *Snowflake) Time(id int64) time.Time {
+	return s.epoch.Add(time.Duration(id>>(s.nodeBits+s.sequenceBits)) * time.Millisecond)
+}
+
+// NodeID returns the ID of the node which generated the given ID
+func (s *Snowflake) NodeID(id int64) int64 {
+	return id >> s.sequenceBits & (1<<s.nodeBits - 1)
+}
+
+// NodeIDFromHostname derives a node ID fitting in the given number of bits from the host name. The ordinal
+// of the pods of a StatefulSet (i.e. 2 for "catalog-2") is used as is, so that replicas never collide,
+// while other host names are hashed, in which case collisions are unlikely but possible.
+func NodeIDFromHostname(bits int) int64 {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return 0
+	}
+
+	mask := int64(1)<<bits - 1
+
+	if index := strings.LastIndex(hostname, "-"); index != -1 {
+		if ordinal, err := strconv.ParseInt(hostname[index+1:], 10, 64); err == nil && ordinal >= 0 && ordinal <= mask {
+			return ordinal
+		}
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(hostname))
+
+	return int64(hash.Sum32()) & mask
+}
@@ -0,0 +1,131 @@
+package ids
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewSnowflake(t *testing.T) {
+	tests := []struct {
+		name    string
+		nodeID  int64
+		opts    []SnowflakeOption
+		wantErr error
+	}{
+		{name: "Default layout", nodeID: 1023},
+		{name: "Node ID too large", nodeID: 1024, wantErr: ErrInvalidSnowflake},
+		{name: "Negative node ID", nodeID: -1, wantErr: ErrInvalidSnowflake},
+		{name: "No sequence bits", opts: []SnowflakeOption{WithSequenceBits(0)}, wantErr: ErrInvalidSnowflake},
+		{name: "Too many bits", opts: []SnowflakeOption{WithNodeBits(16), WithSequenceBits(17)}, wantErr: ErrInvalidSnowflake},
+		{name: "Epoch in the future", opts: []SnowflakeOption{WithEpoch(time.Now().Add(time.Hour))}, wantErr: ErrInvalidSnowflake},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSnowflake(tt.nodeID, tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("want %v; got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSnowflakeRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		nodeID int64
+		opts   []SnowflakeOption
+	}{
+		{name: "Default layout", nodeID: 37},
+		{name: "Highest node ID", nodeID: 1023},
+		{name: "Custom layout", nodeID: 5, opts: []SnowflakeOption{WithNodeBits(4), WithSequenceBits(8)}},
+		{name: "No node bits", nodeID: 0, opts: []SnowflakeOption{WithNodeBits(0)}},
+		{name: "Custom epoch", nodeID: 1, opts: []SnowflakeOption{WithEpoch(time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC))}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator, err := NewSnowflake(tt.nodeID, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			before := time.Now().Truncate(time.Millisecond)
+			id := generator.Next()
+			after := time.Now()
+
+			if id <= 0 {
+				t.Errorf("want a positive ID; got %d", id)
+			}
+
+			if got := generator.NodeID(id); got != tt.nodeID {
+				t.Errorf("want node ID %d; got %d", tt.nodeID, got)
+			}
+
+			if got := generator.Time(id); got.Before(before) || got.After(after) {
+				t.Errorf("want a time between %s and %s; got %s", before, after, got)
+			}
+		})
+	}
+}
+
+func TestSnowflakeNextClockBackwards(t *testing.T) {
+	generator, err := NewSnowflake(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	previous := generator.Next()
+
+	// The clock goes back by a second
+	generator.lastTime += time.Second.Milliseconds()
+	lastTime := generator.lastTime
+
+	for i := 0; i < 100; i++ {
+		id := generator.Next()
+
+		if id <= previous {
+			t.Fatalf("want an ID greater than %d; got %d", previous, id)
+		}
+
+		if got := generator.NodeID(id); got != 3 {
+			t.Errorf("want node ID 3; got %d", got)
+		}
+
+		previous = id
+	}
+
+	if generator.lastTime != lastTime {
+		t.Errorf("want the timestamp of the previous ID %d to be kept; got %d", lastTime, generator.lastTime)
+	}
+}
+
+func TestSnowflakeNextSequenceWrap(t *testing.T) {
+	generator, err := NewSnowflake(1, WithSequenceBits(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Keep generating within the same millisecond, ahead of the clock
+	generator.lastTime = time.Since(generator.epoch).Milliseconds() + time.Minute.Milliseconds()
+	lastTime := generator.lastTime
+
+	previous := generator.lastTime<<(generator.nodeBits+generator.sequenceBits) | 1<<generator.sequenceBits
+
+	for i := 1; i <= 10; i++ {
+		id := generator.Next()
+
+		if id <= previous {
+			t.Fatalf("want an ID greater than %d; got %d", previous, id)
+		}
+
+		// The 4 IDs of a millisecond are exhausted, so the timestamp moves forward every 4 IDs
+		wantTime := generator.epoch.Add(time.Duration(lastTime+int64(i/4)) * time.Millisecond)
+		if got := generator.Time(id); !got.Equal(wantTime) {
+			t.Errorf("want time %s for ID %d; got %s", wantTime, i, got)
+		}
+
+		previous = id
+	}
+}
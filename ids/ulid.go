@@ -0,0 +1,210 @@
+// Package ids generates unique identifiers which sort in the order they were generated, for services
+// which want time-ordered IDs instead of ObjectIDs or int64 sequences: ULIDs, stored as strings,
+// and snowflake IDs, stored as int64.
+package ids
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+// crockfordAlphabet is the Crockford's Base32 alphabet used to encode ULIDs
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ulidLength is the length of the string representation of a ULID
+const ulidLength = 26
+
+// ErrInvalidULID is returned when parsing a string which isn't a valid ULID
+var ErrInvalidULID = errors.New("invalid ULID")
+
+// crockfordValues holds the value of every character of the alphabet, or 0xFF for invalid characters.
+// Lowercase letters are accepted.
+var crockfordValues = func() [256]byte {
+	var values [256]byte
+	for i := range values {
+		values[i] = 0xFF
+	}
+
+	for i := 0; i < len(crockfordAlphabet); i++ {
+		values[crockfordAlphabet[i]] = byte(i)
+		values[crockfordAlphabet[i]|0x20] = byte(i)
+	}
+
+	return values
+}()
+
+// ULID is a Universally Unique Lexicographically Sortable Identifier, made of a 48-bit timestamp in
+// milliseconds followed by 80 random bits (https://github.com/ulid/spec). Its string representation
+// (i.e. "01ARZ3NDEKTSV4RRFFQ69G5FAV") is used in JSON and in MongoDB, so that documents sort by
+// creation time on their _id.
+type ULID [16]byte
+
+// ulidEntropy is a struct which generates the random part of ULIDs. ULIDs generated within the same
+// millisecond increment the random part of the previous one, so that they keep their order.
+var ulidEntropy struct {
+	sync.Mutex
+	lastTime uint64
+	last     ULID
+}
+
+// NewULID generates a new ULID with the current time
+func NewULID() ULID {
+	return newULID(uint64(time.Now().UnixMilli()))
+}
+
+// newULID generates a new ULID with the given time in milliseconds
+func newULID(ms uint64) ULID {
+	ulidEntropy.Lock()
+	defer ulidEntropy.Unlock()
+
+	var id ULID
+
+	// Keep generating in order if the clock goes backwards or in the same millisecond, unless the
+	// random part overflows, in which case the time is moved forward
+	if ms <= ulidEntropy.lastTime {
+		id = ulidEntropy.last
+		if !increment(id[6:]) {
+			ulidEntropy.lastTime++
+			id = randomULID(ulidEntropy.lastTime)
+		}
+	} else {
+		ulidEntropy.lastTime = ms
+		id = randomULID(ms)
+	}
+
+	ulidEntropy.last = id
+
+	return id
+}
+
+// randomULID returns a ULID with the given time in milliseconds and a random part
+func randomULID(ms uint64) ULID {
+	var id ULID
+
+	putTime(&id, ms)
+
+	if _, err := rand.Read(id[6:]); err != nil {
+		panic(fmt.Sprintf("ids: unable to read random bytes: %s", err))
+	}
+
+	return id
+}
+
+// putTime writes the given time in milliseconds in the first 6 bytes of the given ULID
+func putTime(id *ULID, ms uint64) {
+	id[0] = byte(ms >> 40)
+	id[1] = byte(ms >> 32)
+	id[2] = byte(ms >> 24)
+	id[3] = byte(ms >> 16)
+	id[4] = byte(ms >> 8)
+	id[5] = byte(ms)
+}
+
+// increment increments the given big-endian number and returns false if it overflowed
+func increment(number []byte) bool {
+	for i := len(number) - 1; i >= 0; i-- {
+		number[i]++
+		if number[i] != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseULID parses the string representation of a ULID, whose letters are case-insensitive
+func ParseULID(value string) (ULID, error) {
+	var id ULID
+
+	// The first character only holds 3 bits
+	if len(value) != ulidLength || crockfordValues[value[0]] > 7 {
+		return id, fmt.Errorf("%w: %q", ErrInvalidULID, value)
+	}
+
+	var hi, lo uint64
+
+	for i := 0; i < ulidLength; i++ {
+		v := crockfordValues[value[i]]
+		if v == 0xFF {
+			return id, fmt.Errorf("%w: %q", ErrInvalidULID, value)
+		}
+
+		hi = hi<<5 | lo>>59
+		lo = lo<<5 | uint64(v)
+	}
+
+	binary.BigEndian.PutUint64(id[:8], hi)
+	binary.BigEndian.PutUint64(id[8:], lo)
+
+	return id, nil
+}
+
+// String returns the string representation of the ULID, i.e. "01ARZ3NDEKTSV4RRFFQ69G5FAV"
+func (id ULID) String() string {
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+
+	var encoded [ulidLength]byte
+	for i := ulidLength - 1; i >= 0; i-- {
+		encoded[i] = crockfordAlphabet[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+
+	return string(encoded[:])
+}
+
+// Time returns the time at which the ULID was generated, to the millisecond
+func (id ULID) Time() time.Time {
+	ms := uint64(id[0])<<40 | uint64(id[1])<<32 | uint64(id[2])<<24 | uint64(id[3])<<16 | uint64(id[4])<<8 | uint64(id[5])
+
+	return time.UnixMilli(int64(ms))
+}
+
+// IsZero returns whether the ULID is the zero value
+func (id ULID) IsZero() bool {
+	return id == ULID{}
+}
+
+// MarshalText encodes the ULID as its string representation, which is used in JSON
+func (id ULID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText decodes the string representation of a ULID
+func (id *ULID) UnmarshalText(text []byte) error {
+	parsed, err := ParseULID(string(text))
+	if err != nil {
+		return err
+	}
+
+	*id = parsed
+
+	return nil
+}
+
+// MarshalBSONValue encodes the ULID as a BSON string, which sorts like the ULID
+func (id ULID) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return bsontype.String, bsoncore.AppendString(nil, id.String()), nil
+}
+
+// UnmarshalBSONValue decodes a ULID stored as a BSON string
+func (id *ULID) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	if t != bsontype.String {
+		return fmt.Errorf("%w: cannot decode BSON %s", ErrInvalidULID, t)
+	}
+
+	value, _, ok := bsoncore.ReadString(data)
+	if !ok {
+		return fmt.Errorf("%w: malformed BSON string", ErrInvalidULID)
+	}
+
+	return id.UnmarshalText([]byte(value))
+}
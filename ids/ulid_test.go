@@ -0,0 +1,181 @@
+package ids
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestParseULID(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		want     string
+		wantTime time.Time
+		wantErr  error
+	}{
+		{name: "Spec example", value: "01ARZ3NDEKTSV4RRFFQ69G5FAV", want: "01ARZ3NDEKTSV4RRFFQ69G5FAV", wantTime: time.UnixMilli(1469922850259)},
+		{name: "Lowercase", value: "01arz3ndektsv4rrffq69g5fav", want: "01ARZ3NDEKTSV4RRFFQ69G5FAV", wantTime: time.UnixMilli(1469922850259)},
+		{name: "Zero", value: "00000000000000000000000000", want: "00000000000000000000000000", wantTime: time.UnixMilli(0)},
+		{name: "Highest", value: "7ZZZZZZZZZZZZZZZZZZZZZZZZZ", want: "7ZZZZZZZZZZZZZZZZZZZZZZZZZ", wantTime: time.UnixMilli(1<<48 - 1)},
+		{name: "Overflow", value: "8ZZZZZZZZZZZZZZZZZZZZZZZZZ", wantErr: ErrInvalidULID},
+		{name: "Too short", value: "01ARZ3NDEKTSV4RRFFQ69G5FA", wantErr: ErrInvalidULID},
+		{name: "Too long", value: "01ARZ3NDEKTSV4RRFFQ69G5FAVX", wantErr: ErrInvalidULID},
+		{name: "Excluded letter", value: "01ARZ3NDEKTSV4RRFFQ69G5FAU", wantErr: ErrInvalidULID},
+		{name: "Invalid character", value: "01ARZ3NDEKTSV4RRFFQ69G5FA-", wantErr: ErrInvalidULID},
+		{name: "Empty", value: "", wantErr: ErrInvalidULID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := ParseULID(tt.value)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("want %v; got %v", tt.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("want no error; got %v", err)
+			}
+
+			if got := id.String(); got != tt.want {
+				t.Errorf("want %s; got %s", tt.want, got)
+			}
+
+			if got := id.Time(); !got.Equal(tt.wantTime) {
+				t.Errorf("want time %s; got %s", tt.wantTime, got)
+			}
+		})
+	}
+}
+
+func TestULIDRoundTrip(t *testing.T) {
+	before := time.Now().Truncate(time.Millisecond)
+	id := NewULID()
+	after := time.Now()
+
+	if got := id.Time(); got.Before(before) || got.After(after) {
+		t.Errorf("want a time between %s and %s; got %s", before, after, got)
+	}
+
+	parsed, err := ParseULID(id.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed != id {
+		t.Errorf("want %s; got %s", id, parsed)
+	}
+
+	encoded, err := json.Marshal(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded ULID
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded != id {
+		t.Errorf("want %s after a JSON round-trip; got %s", id, decoded)
+	}
+
+	document, err := bson.Marshal(bson.M{"_id": id})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var result struct {
+		ID ULID `bson:"_id"`
+	}
+	if err := bson.Unmarshal(document, &result); err != nil {
+		t.Fatal(err)
+	}
+
+	if result.ID != id {
+		t.Errorf("want %s after a BSON round-trip; got %s", id, result.ID)
+	}
+}
+
+// restoreULIDEntropy restores the state of the ULID generator once the test completes, so that the
+// times moved forward by the test don't leak into the ULIDs generated by the following tests
+func restoreULIDEntropy(t *testing.T) {
+	ulidEntropy.Lock()
+	lastTime, last := ulidEntropy.lastTime, ulidEntropy.last
+	ulidEntropy.Unlock()
+
+	t.Cleanup(func() {
+		ulidEntropy.Lock()
+		ulidEntropy.lastTime, ulidEntropy.last = lastTime, last
+		ulidEntropy.Unlock()
+	})
+}
+
+func TestNewULIDMonotonic(t *testing.T) {
+	restoreULIDEntropy(t)
+
+	// Start after every ULID generated so far
+	ulidEntropy.Lock()
+	ms := ulidEntropy.lastTime + 1000
+	ulidEntropy.Unlock()
+
+	tests := []struct {
+		name string
+		ms   uint64
+	}{
+		{name: "New millisecond", ms: ms},
+		{name: "Same millisecond", ms: ms},
+		{name: "Same millisecond again", ms: ms},
+		{name: "Clock backwards", ms: ms - 500},
+		{name: "Next millisecond", ms: ms + 1},
+	}
+
+	var previous ULID
+
+	for _, tt := range tests {
+		id := newULID(tt.ms)
+
+		if id.String() <= previous.String() {
+			t.Errorf("%s: want a ULID greater than %s; got %s", tt.name, previous, id)
+		}
+
+		if got := uint64(id.Time().UnixMilli()); got < tt.ms {
+			t.Errorf("%s: want a time of at least %d; got %d", tt.name, tt.ms, got)
+		}
+
+		previous = id
+	}
+}
+
+func TestNewULIDRandomOverflow(t *testing.T) {
+	restoreULIDEntropy(t)
+
+	ulidEntropy.Lock()
+	ms := ulidEntropy.lastTime + 1000
+
+	// The random part of the previous ULID is exhausted
+	putTime(&ulidEntropy.last, ms)
+	for i := 6; i < len(ulidEntropy.last); i++ {
+		ulidEntropy.last[i] = 0xFF
+	}
+	ulidEntropy.lastTime = ms
+	previous := ulidEntropy.last
+	ulidEntropy.Unlock()
+
+	id := newULID(ms)
+
+	if id.String() <= previous.String() {
+		t.Errorf("want a ULID greater than %s; got %s", previous, id)
+	}
+
+	if got := uint64(id.Time().UnixMilli()); got != ms+1 {
+		t.Errorf("want the time to move forward to %d; got %d", ms+1, got)
+	}
+}
@@ -0,0 +1,215 @@
+package locks
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultLeaderTTL is the time to live of the lock of a leader if none is given to WithLeaderTTL.
+// A leader which crashes is replaced after this delay at most.
+const DefaultLeaderTTL = 15 * time.Second
+
+// ElectionOption is a function used to configure optional behaviour of an Election
+type ElectionOption func(*Election)
+
+// WithLeaderTTL sets the time to live of the lock of the leader, which is renewed every third of it.
+// Replicas which aren't the leader try to become the leader at the same interval.
+func WithLeaderTTL(ttl time.Duration) ElectionOption {
+	return func(e *Election) {
+		e.ttl = ttl
+	}
+}
+
+// WithLeadershipChanges calls the given function whenever this replica becomes the leader or steps down,
+// i.e. to keep track of the leadership in a metric
+func WithLeadershipChanges(onChange func(leader bool)) ElectionOption {
+	return func(e *Election) {
+		e.onChange = onChange
+	}
+}
+
+// WithElectionErrors calls the given function with the unexpected errors of the locker, i.e. to log them
+func WithElectionErrors(onError func(err error)) ElectionOption {
+	return func(e *Election) {
+		e.onError = onError
+	}
+}
+
+// Election is a struct which elects a single leader among the replicas of a service competing for the
+// same lock, i.e. to relay the outbox (see events.NewOutboxRelay), run scheduled jobs or apply migrations
+// from a single replica.
+// The leader keeps renewing its lock, and steps down as soon as it loses it or before it expires
+// if it can't be renewed, so that two replicas are never leaders at the same time.
+type Election struct {
+	locker   Locker
+	name     string
+	ttl      time.Duration
+	onChange func(leader bool)
+	onError  func(err error)
+
+	leader atomic.Bool
+}
+
+// NewElection creates a new Election of the lock with the given name (i.e. "outbox-relay")
+func NewElection(locker Locker, name string, opts ...ElectionOption) *Election {
+	election := &Election{
+		locker: locker,
+		name:   name,
+		ttl:    DefaultLeaderTTL,
+	}
+
+	for _, opt := range opts {
+		opt(election)
+	}
+
+	return election
+}
+
+// IsLeader returns whether this replica is currently the leader
+func (e *Election) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Campaign makes this replica compete for the leadership until the given context is cancelled, so that
+// IsLeader can be checked before doing work which must not be done by several replicas
+func (e *Election) Campaign(ctx context.Context) {
+	_ = e.RunWhenLeader(ctx, func(leaderCtx context.Context) error {
+		<-leaderCtx.Done()
+		return nil
+	})
+}
+
+// RunWhenLeader waits for this replica to become the leader and runs the given function, whose context
+// is cancelled when the replica steps down. The function is run again whenever the replica becomes the
+// leader again, until it returns on its own or the given context is cancelled. The replica steps down
+// once the function returns, and its error is returned.
+//
+//	go election.RunWhenLeader(ctx, projector.Rebuild)
+func (e *Election) RunWhenLeader(ctx context.Context, fn func(ctx context.Context) error) error {
+	for {
+		lock, err := e.acquire(ctx)
+		if err != nil {
+			// The context has been cancelled while waiting for the leadership
+			return nil
+		}
+
+		leaderCtx, stepDown := context.WithCancel(ctx)
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			e.keepLeading(leaderCtx, stepDown, lock)
+		}()
+
+		e.setLeader(true)
+
+		err = fn(leaderCtx)
+		lost := leaderCtx.Err() != nil && ctx.Err() == nil
+
+		stepDown()
+		wg.Wait()
+		e.release(lock)
+		e.setLeader(false)
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		// Compete for the leadership again if it has been lost while the function was running
+		if !lost {
+			return err
+		}
+	}
+}
+
+// acquire tries to acquire the lock every third of its time to live until it succeeds or the given
+// context is cancelled
+func (e *Election) acquire(ctx context.Context) (*Lock, error) {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		lock, err := e.locker.Acquire(ctx, e.name, e.ttl)
+		if err == nil {
+			return lock, nil
+		}
+
+		if !errors.Is(err, ErrLockNotAcquired) && ctx.Err() == nil {
+			e.reportError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// keepLeading renews the given lock every third of its time to live until the given context is cancelled.
+// The replica steps down if the lock is lost, or if it can't be renewed before it expires.
+func (e *Election) keepLeading(ctx context.Context, stepDown context.CancelFunc, lock *Lock) {
+	renewInterval := e.ttl / 3
+
+	ticker := time.NewTicker(renewInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := e.locker.Renew(ctx, lock, e.ttl)
+		if err == nil {
+			continue
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if errors.Is(err, ErrLockLost) {
+			stepDown()
+			return
+		}
+
+		e.reportError(err)
+
+		// Another replica may acquire the lock once it expires
+		if time.Until(lock.ExpiresAt) < renewInterval {
+			stepDown()
+			return
+		}
+	}
+}
+
+// release releases the given lock so that another replica can become the leader right away
+func (e *Election) release(lock *Lock) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := e.locker.Release(ctx, lock); err != nil && !errors.Is(err, ErrLockLost) {
+		e.reportError(err)
+	}
+}
+
+// setLeader records whether this replica is the leader and notifies the change
+func (e *Election) setLeader(leader bool) {
+	if e.leader.Swap(leader) != leader && e.onChange != nil {
+		e.onChange(leader)
+	}
+}
+
+// reportError reports an unexpected error of the locker
+func (e *Election) reportError(err error) {
+	if e.onError != nil {
+		e.onError(err)
+	}
+}
@@ -0,0 +1,62 @@
+package locks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"time"
+)
+
+var (
+	// ErrLockNotAcquired is returned when trying to acquire a lock
+	// which is currently held by another owner
+	ErrLockNotAcquired = errors.New("lock not acquired")
+
+	// ErrLockLost is returned when trying to renew or release a lock
+	// which has expired and may have been acquired by another owner
+	ErrLockLost = errors.New("lock lost")
+)
+
+// Lock is a struct that holds a distributed lock acquired by a Locker.
+// Token is a fencing token which is incremented every time the lock is acquired.
+// It should be passed along to the protected resource so that writes coming from
+// a previous owner whose lock expired can be rejected.
+type Lock struct {
+	Name      string
+	Owner     string
+	Token     int64
+	ExpiresAt time.Time
+}
+
+// Locker is an interface that defines a distributed lock backend
+type Locker interface {
+	// Acquire tries to acquire the lock with the given name for the given time to live.
+	// It returns ErrLockNotAcquired if the lock is held by another owner.
+	Acquire(ctx context.Context, name string, ttl time.Duration) (*Lock, error)
+
+	// Renew extends the expiry of a held lock by the given time to live.
+	// It returns ErrLockLost if the lock is no longer held by its owner.
+	Renew(ctx context.Context, lock *Lock, ttl time.Duration) error
+
+	// Release releases a held lock. It returns ErrLockLost if the lock
+	// is no longer held by its owner.
+	Release(ctx context.Context, lock *Lock) error
+}
+
+// newOwnerID generates a unique owner id for a Locker instance using
+// the host name and a random suffix
+func newOwnerID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return hostname
+	}
+
+	return hostname + "-" + hex.EncodeToString(suffix)
+}
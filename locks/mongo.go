@@ -0,0 +1,108 @@
+package locks
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LocksCollection is used as the collection name for storing locks in MongoDB
+const LocksCollection = "locks"
+
+// mongoLock is the document stored in MongoDB for every lock
+type mongoLock struct {
+	Name      string    `bson:"_id"`
+	Owner     string    `bson:"owner"`
+	Token     int64     `bson:"token"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+// MongoLocker is a Locker backed by a MongoDB collection. Lock documents are never deleted
+// so that fencing tokens keep increasing monotonically.
+type MongoLocker struct {
+	collection *mongo.Collection
+	owner      string
+}
+
+// NewMongoLocker creates a new MongoLocker which stores locks in the locks collection of the given database
+func NewMongoLocker(client *mongo.Client, database string) *MongoLocker {
+	return &MongoLocker{
+		collection: client.Database(database).Collection(LocksCollection),
+		owner:      newOwnerID(),
+	}
+}
+
+// Acquire tries to acquire the lock with the given name for the given time to live
+func (l *MongoLocker) Acquire(ctx context.Context, name string, ttl time.Duration) (*Lock, error) {
+	now := time.Now().UTC()
+
+	// Only match the lock if it has expired. If the lock doesn't exist it is created by the upsert,
+	// and if it is held by another owner the upsert fails with a duplicate key error.
+	filter := bson.M{"_id": name, "expires_at": bson.M{"$lte": now}}
+	update := bson.M{
+		"$set": bson.M{"owner": l.owner, "expires_at": now.Add(ttl)},
+		"$inc": bson.M{"token": 1},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var lock mongoLock
+
+	err := l.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&lock)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrLockNotAcquired
+		}
+
+		return nil, err
+	}
+
+	return &Lock{
+		Name:      lock.Name,
+		Owner:     lock.Owner,
+		Token:     lock.Token,
+		ExpiresAt: lock.ExpiresAt,
+	}, nil
+}
+
+// Renew extends the expiry of a held lock by the given time to live
+func (l *MongoLocker) Renew(ctx context.Context, lock *Lock, ttl time.Duration) error {
+	expiresAt := time.Now().UTC().Add(ttl)
+
+	err := l.updateHeldLock(ctx, lock, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	lock.ExpiresAt = expiresAt
+
+	return nil
+}
+
+// Release releases a held lock by expiring it immediately
+func (l *MongoLocker) Release(ctx context.Context, lock *Lock) error {
+	return l.updateHeldLock(ctx, lock, time.Time{})
+}
+
+// updateHeldLock sets the expiry of a lock if it is still held by the given owner and fencing token
+func (l *MongoLocker) updateHeldLock(ctx context.Context, lock *Lock, expiresAt time.Time) error {
+	filter := bson.M{
+		"_id":        lock.Name,
+		"owner":      lock.Owner,
+		"token":      lock.Token,
+		"expires_at": bson.M{"$gt": time.Now().UTC()},
+	}
+
+	result, err := l.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"expires_at": expiresAt}})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrLockLost
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package locks
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// acquireScript atomically creates the lock hash if it doesn't exist and increments
+// the fencing token, which is stored in a separate key that never expires
+var acquireScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return false
+end
+local token = redis.call("INCR", KEYS[2])
+redis.call("HSET", KEYS[1], "owner", ARGV[1], "token", token)
+redis.call("PEXPIRE", KEYS[1], ARGV[2])
+return token
+`)
+
+// renewScript extends the expiry of the lock if it is still held by the given owner and token
+var renewScript = redis.NewScript(`
+if redis.call("HGET", KEYS[1], "owner") == ARGV[1] and redis.call("HGET", KEYS[1], "token") == ARGV[2] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[3])
+end
+return 0
+`)
+
+// releaseScript deletes the lock if it is still held by the given owner and token
+var releaseScript = redis.NewScript(`
+if redis.call("HGET", KEYS[1], "owner") == ARGV[1] and redis.call("HGET", KEYS[1], "token") == ARGV[2] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisLocker is a Locker backed by Redis
+type RedisLocker struct {
+	client redis.UniversalClient
+	prefix string
+	owner  string
+}
+
+// NewRedisLocker creates a new RedisLocker. Keys are prefixed with the given prefix
+// (i.e. the service name) to avoid collisions between services sharing the same Redis instance.
+func NewRedisLocker(client redis.UniversalClient, prefix string) *RedisLocker {
+	return &RedisLocker{
+		client: client,
+		prefix: prefix,
+		owner:  newOwnerID(),
+	}
+}
+
+// lockKey returns the Redis key of the lock with the given name
+func (l *RedisLocker) lockKey(name string) string {
+	return l.prefix + ":locks:" + name
+}
+
+// tokenKey returns the Redis key of the fencing token of the lock with the given name
+func (l *RedisLocker) tokenKey(name string) string {
+	return l.prefix + ":locks:" + name + ":token"
+}
+
+// Acquire tries to acquire the lock with the given name for the given time to live
+func (l *RedisLocker) Acquire(ctx context.Context, name string, ttl time.Duration) (*Lock, error) {
+	expiresAt := time.Now().UTC().Add(ttl)
+
+	token, err := acquireScript.Run(
+		ctx,
+		l.client,
+		[]string{l.lockKey(name), l.tokenKey(name)},
+		l.owner,
+		ttl.Milliseconds(),
+	).Int64()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrLockNotAcquired
+		}
+
+		return nil, err
+	}
+
+	return &Lock{
+		Name:      name,
+		Owner:     l.owner,
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// Renew extends the expiry of a held lock by the given time to live
+func (l *RedisLocker) Renew(ctx context.Context, lock *Lock, ttl time.Duration) error {
+	expiresAt := time.Now().UTC().Add(ttl)
+
+	renewed, err := renewScript.Run(
+		ctx,
+		l.client,
+		[]string{l.lockKey(lock.Name)},
+		lock.Owner,
+		lock.Token,
+		ttl.Milliseconds(),
+	).Int64()
+	if err != nil {
+		return err
+	}
+
+	if renewed == 0 {
+		return ErrLockLost
+	}
+
+	lock.ExpiresAt = expiresAt
+
+	return nil
+}
+
+// Release releases a held lock
+func (l *RedisLocker) Release(ctx context.Context, lock *Lock) error {
+	released, err := releaseScript.Run(
+		ctx,
+		l.client,
+		[]string{l.lockKey(lock.Name)},
+		lock.Owner,
+		lock.Token,
+	).Int64()
+	if err != nil {
+		return err
+	}
+
+	if released == 0 {
+		return ErrLockLost
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Names of the properties added to the log entries written with a context
+const (
+	TraceIDProperty   = "trace_id"
+	SpanIDProperty    = "span_id"
+	RequestIDProperty = "request_id"
+)
+
+// Names of the properties added to the log entries written with the logger of a request
+const (
+	MethodProperty   = "method"
+	RouteProperty    = "route"
+	UserIDProperty   = "user_id"
+	TenantIDProperty = "tenant_id"
+	OriginProperty   = "origin"
+)
+
+// loggerContextKey is the key of the logger in a context
+type loggerContextKey struct{}
+
+// NewContext returns a copy of the given context which holds the given logger,
+// i.e. a sub-logger with the properties of the current request
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, l)
+}
+
+// FromContext returns the logger held by the given context, or nil if it doesn't hold any
+func FromContext(ctx context.Context) *Logger {
+	l, _ := ctx.Value(loggerContextKey{}).(*Logger)
+	return l
+}
+
+// InfoCtx is a helper method for writing log entries at the INFO level, which are correlated
+// with the trace and request of the given context
+func (l *Logger) InfoCtx(ctx context.Context, message string, properties map[string]string) {
+	l.Info(message, contextProperties(ctx, properties))
+}
+
+// WarningCtx is a helper method for writing log entries at the WARNING level, which are correlated
+// with the trace and request of the given context
+func (l *Logger) WarningCtx(ctx context.Context, message string, properties map[string]string) {
+	l.Warning(message, contextProperties(ctx, properties))
+}
+
+// ErrorCtx is a helper method for writing log entries at the ERROR level, which are correlated
+// with the trace and request of the given context
+func (l *Logger) ErrorCtx(ctx context.Context, err error, properties map[string]string) {
+	l.Error(err, contextProperties(ctx, properties))
+}
+
+// contextProperties returns a copy of the given properties with the trace ID and span ID of the span
+// of the context, and the request ID set by chi's RequestID middleware, so that log entries can be
+// joined to traces. Properties which are already set are kept.
+func contextProperties(ctx context.Context, properties map[string]string) map[string]string {
+	correlation := map[string]string{}
+
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		correlation[TraceIDProperty] = spanContext.TraceID().String()
+		correlation[SpanIDProperty] = spanContext.SpanID().String()
+	}
+
+	if requestID := middleware.GetReqID(ctx); requestID != "" {
+		correlation[RequestIDProperty] = requestID
+	}
+
+	if len(correlation) == 0 {
+		return properties
+	}
+
+	for key, value := range properties {
+		correlation[key] = value
+	}
+
+	return correlation
+}
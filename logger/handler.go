@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"encoding/json"
+	"io"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// JSONHandler is a slog handler which writes log entries as JSON lines with the level, time, message,
+// properties (typed attributes) and, for entries at the ERROR and FATAL levels, the stack trace:
+//
+//	{"level":"INFO","time":"2022-10-02T15:04:05Z","message":"Starting server","properties":{"addr":":8080"}}
+type JSONHandler struct {
+	output io.Writer
+	mutex  *sync.Mutex
+	attrs  []slog.Attr
+}
+
+// NewJSONHandler returns a new JSONHandler writing log entries to the given output destination
+func NewJSONHandler(output io.Writer) *JSONHandler {
+	return &JSONHandler{output: output, mutex: &sync.Mutex{}}
+}
+
+// Enabled returns true, since the minimum severity level is enforced by the Logger
+func (h *JSONHandler) Enabled(slog.Level) bool {
+	return true
+}
+
+// With returns a new JSONHandler which adds the given attributes to the properties of every log entry
+func (h *JSONHandler) With(attrs []slog.Attr) slog.Handler {
+	withAttrs := make([]slog.Attr, 0, len(h.attrs)+len(attrs))
+	withAttrs = append(withAttrs, h.attrs...)
+	withAttrs = append(withAttrs, attrs...)
+
+	return &JSONHandler{output: h.output, mutex: h.mutex, attrs: withAttrs}
+}
+
+// Handle writes the log entry of the given record
+func (h *JSONHandler) Handle(record slog.Record) error {
+	log := h.entry(record)
+
+	// Lock the mutex so that no two writes to the output destination can happen
+	// concurrently. If we don't do this, it's possible that the text for two or more
+	// log entries will be intermingled in the output.
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	_, err := h.output.Write(append(log, '\n'))
+
+	return err
+}
+
+// entry returns the JSON log entry of the given record, without trailing newline
+func (h *JSONHandler) entry(record slog.Record) []byte {
+	level := levelFromSlog(record.Level())
+
+	aux := struct {
+		Level      string         `json:"level"`
+		Time       string         `json:"time"`
+		Message    string         `json:"message"`
+		Properties map[string]any `json:"properties,omitempty"`
+		Trace      string         `json:"trace,omitempty"`
+	}{
+		Level:   level.String(),
+		Time:    record.Time().UTC().Format(time.RFC3339),
+		Message: record.Message(),
+	}
+
+	if len(h.attrs) > 0 || record.NumAttrs() > 0 {
+		aux.Properties = make(map[string]any, len(h.attrs)+record.NumAttrs())
+
+		for _, attr := range h.attrs {
+			addProperty(aux.Properties, attr)
+		}
+
+		record.Attrs(func(attr slog.Attr) {
+			addProperty(aux.Properties, attr)
+		})
+	}
+
+	// Include a stack trace for entries at the ERROR and FATAL levels
+	if level >= LevelError {
+		aux.Trace = string(debug.Stack())
+	}
+
+	// Convert the anonymous struct into JSON
+	log, err := json.Marshal(aux)
+	if err != nil {
+		log = []byte(LevelError.String() + ": unable to marshal log message: " + err.Error())
+	}
+
+	return log
+}
+
+// addProperty adds an attribute to the properties of a log entry. Errors are written with their message
+// and durations in a human-friendly format, since they don't have a meaningful JSON representation.
+func addProperty(properties map[string]any, attr slog.Attr) {
+	if attr.Key() == "" {
+		return
+	}
+
+	switch value := attr.Value().(type) {
+	case error:
+		properties[attr.Key()] = value.Error()
+	case time.Duration:
+		properties[attr.Key()] = value.String()
+	default:
+		properties[attr.Key()] = value
+	}
+}
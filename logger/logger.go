@@ -1,12 +1,14 @@
 package logger
 
 import (
-	"encoding/json"
+	"fmt"
 	"io"
 	"os"
-	"runtime/debug"
-	"sync"
+	"strings"
+	"sync/atomic"
 	"time"
+
+	"golang.org/x/exp/slog"
 )
 
 // Level is a custom type that represents the severity level for a log entry
@@ -15,16 +17,19 @@ type Level int8
 // Initialize constants which represent a specific severity level. We use the iota
 // keyword as a shortcut to assign successive integer values to the constants.
 const (
-	LevelInfo    Level = iota // Has the value 0
-	LevelWarning              // Has the value 1
-	LevelError                // Has the value 2
-	LevelFatal                // Has the value 3
-	LevelOff                  // Has the value 4
+	LevelDebug   Level = iota - 1 // Has the value -1
+	LevelInfo                     // Has the value 0
+	LevelWarning                  // Has the value 1
+	LevelError                    // Has the value 2
+	LevelFatal                    // Has the value 3
+	LevelOff                      // Has the value 4
 )
 
 // String returns a human-friendly string for the severity level
 func (l Level) String() string {
 	switch l {
+	case LevelDebug:
+		return "DEBUG"
 	case LevelInfo:
 		return "INFO"
 	case LevelWarning:
@@ -33,105 +38,185 @@ func (l Level) String() string {
 		return "ERROR"
 	case LevelFatal:
 		return "FATAL"
+	case LevelOff:
+		return "OFF"
 	default:
 		return ""
 	}
 }
 
-// Logger is a struct that defines a custom Logger type. This holds the output destination that the
-// log entries will be written to, the minimum severity level that log entries will be written for,
-// plus a mutex for coordinating the writes.
+// ParseLevel returns the severity level with the given name, regardless of case (i.e. "debug")
+func ParseLevel(name string) (Level, error) {
+	for level := LevelDebug; level <= LevelOff; level++ {
+		if strings.EqualFold(name, level.String()) {
+			return level, nil
+		}
+	}
+
+	return LevelInfo, fmt.Errorf("unknown log level %q", name)
+}
+
+// slogFatalLevel is the slog level of log entries at the FATAL level, which slog doesn't name
+const slogFatalLevel = slog.ErrorLevel + 4
+
+// slogLevel returns the slog level corresponding to the severity level
+func (l Level) slogLevel() slog.Level {
+	switch l {
+	case LevelDebug:
+		return slog.DebugLevel
+	case LevelInfo:
+		return slog.InfoLevel
+	case LevelWarning:
+		return slog.WarnLevel
+	case LevelError:
+		return slog.ErrorLevel
+	default:
+		return slogFatalLevel
+	}
+}
+
+// levelFromSlog returns the severity level corresponding to a slog level
+func levelFromSlog(level slog.Level) Level {
+	switch {
+	case level >= slogFatalLevel:
+		return LevelFatal
+	case level >= slog.ErrorLevel:
+		return LevelError
+	case level >= slog.WarnLevel:
+		return LevelWarning
+	case level >= slog.InfoLevel:
+		return LevelInfo
+	default:
+		return LevelDebug
+	}
+}
+
+// Logger is a struct that defines a custom Logger type. This holds the slog handler that the
+// log entries will be written with and the minimum severity level that log entries will be
+// written for, which can be changed at runtime. Properties of log entries are typed slog attributes.
 type Logger struct {
-	output   io.Writer
-	minLevel Level
-	mutex    sync.Mutex
+	handler  slog.Handler
+	minLevel *atomic.Int32 // Shared with the sub-loggers
 }
 
-// New returns a new Logger instance which writes log entries at or above a minimum severity
-// level to a specific output destination
+// New returns a new Logger instance which writes JSON log entries at or above a minimum severity
+// level to a specific output destination, with the values of the DefaultRedactedKeys and
+// obvious secrets (i.e. JWTs in query strings) masked
 func New(output io.Writer, minLevel Level) *Logger {
+	return NewWithHandler(NewRedactingHandler(NewJSONHandler(output), NewRedactor(DefaultRedactedKeys)), minLevel)
+}
+
+// NewWithHandler returns a new Logger instance which writes log entries at or above a minimum
+// severity level with the given slog handler, so that services can swap the logging backend
+// (i.e. slog.NewTextHandler(os.Stdout) during development). Sensitive data is only masked if
+// the handler is wrapped in a RedactingHandler.
+func NewWithHandler(handler slog.Handler, minLevel Level) *Logger {
+	logger := &Logger{
+		handler:  handler,
+		minLevel: &atomic.Int32{},
+	}
+
+	logger.minLevel.Store(int32(minLevel))
+
+	return logger
+}
+
+// Level returns the minimum severity level that log entries are written for
+func (l *Logger) Level() Level {
+	return Level(l.minLevel.Load())
+}
+
+// SetLevel changes the minimum severity level that log entries are written for, i.e. to turn
+// on debug logging without restarting the service. Sub-loggers created with With are changed too.
+func (l *Logger) SetLevel(level Level) {
+	l.minLevel.Store(int32(level))
+}
+
+// Handler returns the slog handler of the logger
+func (l *Logger) Handler() slog.Handler {
+	return l.handler
+}
+
+// With returns a sub-logger which adds the given attributes to every log entry,
+// i.e. logger.With(slog.String("consumer", "inventory"), slog.Int("worker", 2))
+func (l *Logger) With(attrs ...slog.Attr) *Logger {
 	return &Logger{
-		output:   output,
-		minLevel: minLevel,
+		handler:  l.handler.With(attrs),
+		minLevel: l.minLevel,
+	}
+}
+
+// Enabled returns whether log entries at the given level are written
+func (l *Logger) Enabled(level Level) bool {
+	return level >= l.Level() && l.handler.Enabled(level.slogLevel())
+}
+
+// Log writes a log entry at the given level with typed attributes,
+// i.e. logger.Log(logger.LevelInfo, "Item granted", slog.Int("quantity", 3))
+func (l *Logger) Log(level Level, message string, attrs ...slog.Attr) {
+	// If the severity level of the log entry is below the minimum severity for the
+	// logger, then return with no further action
+	if !l.Enabled(level) {
+		return
 	}
+
+	record := slog.NewRecord(time.Now(), level.slogLevel(), message, 0)
+	record.AddAttrs(attrs...)
+
+	l.handler.Handle(record)
+}
+
+// Debug is a helper method for writing log entries at the DEBUG level. Notice
+// that it accepts a map as the second parameter which can contain any arbitrary
+// 'properties' that you want to appear in the log entry.
+func (l *Logger) Debug(message string, properties map[string]string) {
+	l.Log(LevelDebug, message, propertiesAttrs(properties)...)
 }
 
 // Info is a helper method for writing log entries at the INFO level. Notice
 // that it accepts a map as the second parameter which can contain any arbitrary
 // 'properties' that you want to appear in the log entry.
 func (l *Logger) Info(message string, properties map[string]string) {
-	l.print(LevelInfo, message, properties)
+	l.Log(LevelInfo, message, propertiesAttrs(properties)...)
 }
 
 // Warning is a helper method for writing log entries at the WARNING level. Notice
 // that it accepts a map as the second parameter which can contain any arbitrary
 // 'properties' that you want to appear in the log entry.
 func (l *Logger) Warning(message string, properties map[string]string) {
-	l.print(LevelWarning, message, properties)
+	l.Log(LevelWarning, message, propertiesAttrs(properties)...)
 }
 
 // Error is a helper method for writing log entries at the ERROR level. Notice
 // that it accepts a map as the second parameter which can contain any arbitrary
 // 'properties' that you want to appear in the log entry.
 func (l *Logger) Error(err error, properties map[string]string) {
-	l.print(LevelError, err.Error(), properties)
+	l.Log(LevelError, err.Error(), propertiesAttrs(properties)...)
 }
 
 // Fatal is a helper method for writing log entries at the FATAL level. Notice
 // that it accepts a map as the second parameter which can contain any arbitrary
 // 'properties' that you want to appear in the log entry.
 func (l *Logger) Fatal(err error, properties map[string]string) {
-	l.print(LevelError, err.Error(), properties)
+	l.Log(LevelError, err.Error(), propertiesAttrs(properties)...)
 	os.Exit(1)
 }
 
-// print is an internal method for writing the log entry
-func (l *Logger) print(level Level, message string, properties map[string]string) (int, error) {
-	// If the severity level of the log entry is below the minimum severity for the
-	// logger, then return with no further action
-	if level < l.minLevel {
-		return 0, nil
+// propertiesAttrs converts the properties of a log entry into string attributes
+func propertiesAttrs(properties map[string]string) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(properties))
+	for key, value := range properties {
+		attrs = append(attrs, slog.String(key, value))
 	}
 
-	aux := struct {
-		Level      string            `json:"level"`
-		Time       string            `json:"time"`
-		Message    string            `json:"message"`
-		Properties map[string]string `json:"properties,omitempty"`
-		Trace      string            `json:"trace,omitempty"`
-	}{
-		Level:      level.String(),
-		Time:       time.Now().UTC().Format(time.RFC3339),
-		Message:    message,
-		Properties: properties,
-	}
-
-	// Include a stack trace for entries at the ERROR and FATAL levels
-	if level >= LevelError {
-		aux.Trace = string(debug.Stack())
-	}
-
-	// Declare a log variable for holding the actual log entry
-	var log []byte
-
-	// Convert the anonymous struct into JSON and store it in the "log" variable
-	log, err := json.Marshal(aux)
-	if err != nil {
-		log = []byte(LevelError.String() + ": unable to marshal log message: " + err.Error())
-	}
-
-	// Lock the mutex so that no two writes to the output destination can happen
-	// concurrently. If we don't do this, it's possible that the text for two or more
-	// log entries will be intermingled in the output.
-	l.mutex.Lock()
-	defer l.mutex.Unlock()
-
-	return l.output.Write(append(log, '\n'))
+	return attrs
 }
 
 // Write is implemented on our Logger type so that it satisfies the
 // io.Writer interface. This writes a log entry at the ERROR level with no additional
 // properties.
 func (l *Logger) Write(message []byte) (n int, err error) {
-	return l.print(LevelError, string(message), nil)
+	l.Log(LevelError, string(message))
+
+	return len(message), nil
 }
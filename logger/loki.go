@@ -0,0 +1,171 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+const (
+	lokiBatchSize     = 100             // Entries pushed as soon as this many are pending
+	lokiFlushInterval = time.Second     // Pending entries pushed at least this often
+	lokiPushTimeout   = 5 * time.Second // Timeout of a push request
+)
+
+// LokiHandler is a slog handler which pushes JSON log entries to a Grafana Loki stream with the given
+// labels. Entries are pushed in batches by a background goroutine, so the handler must be closed
+// to push the pending entries when the service stops.
+type LokiHandler struct {
+	client *lokiClient // Shared with the handlers created with With
+	json   *JSONHandler
+}
+
+// lokiClient is a struct that holds the entries waiting to be pushed to Loki
+type lokiClient struct {
+	url        string
+	labels     map[string]string
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	entries [][2]string // Timestamp in nanoseconds and line
+
+	push      chan struct{}
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewLokiHandler returns a new LokiHandler pushing log entries to the given push URL
+// (i.e. "http://loki:3100/loki/api/v1/push") with the given stream labels
+func NewLokiHandler(pushURL string, labels map[string]string) (*LokiHandler, error) {
+	if pushURL == "" {
+		return nil, errors.New("missing Loki push URL")
+	}
+
+	client := &lokiClient{
+		url:        pushURL,
+		labels:     labels,
+		httpClient: &http.Client{Timeout: lokiPushTimeout},
+		push:       make(chan struct{}, 1),
+		done:       make(chan struct{}),
+	}
+
+	client.wg.Add(1)
+	go client.run()
+
+	return &LokiHandler{client: client, json: NewJSONHandler(io.Discard)}, nil
+}
+
+// Enabled returns true, since the minimum severity level is enforced by the Logger and the sinks
+func (h *LokiHandler) Enabled(slog.Level) bool {
+	return true
+}
+
+// With returns a new LokiHandler which adds the given attributes to the properties of every log entry
+func (h *LokiHandler) With(attrs []slog.Attr) slog.Handler {
+	return &LokiHandler{client: h.client, json: h.json.With(attrs).(*JSONHandler)}
+}
+
+// Handle adds the JSON log entry of the given record to the entries waiting to be pushed
+func (h *LokiHandler) Handle(record slog.Record) error {
+	entry := [2]string{strconv.FormatInt(record.Time().UnixNano(), 10), string(h.json.entry(record))}
+
+	h.client.mu.Lock()
+	h.client.entries = append(h.client.entries, entry)
+	full := len(h.client.entries) >= lokiBatchSize
+	h.client.mu.Unlock()
+
+	if full {
+		select {
+		case h.client.push <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Close pushes the pending entries and stops the background goroutine
+func (h *LokiHandler) Close() error {
+	h.client.closeOnce.Do(func() {
+		close(h.client.done)
+	})
+
+	h.client.wg.Wait()
+
+	return nil
+}
+
+// run pushes the pending entries periodically, when a batch is full and when the handler is closed
+func (c *lokiClient) run() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(lokiFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-c.push:
+		case <-c.done:
+			c.flush()
+			return
+		}
+
+		c.flush()
+	}
+}
+
+// flush pushes the pending entries to Loki. Since they can't be logged, failures are written to stderr.
+func (c *lokiClient) flush() {
+	c.mu.Lock()
+	entries := c.entries
+	c.entries = nil
+	c.mu.Unlock()
+
+	if len(entries) == 0 {
+		return
+	}
+
+	if err := c.send(entries); err != nil {
+		fmt.Fprintf(os.Stderr, "unable to push %d log entries to Loki: %v\n", len(entries), err)
+	}
+}
+
+// send pushes the given entries to Loki in a single stream
+func (c *lokiClient) send(entries [][2]string) error {
+	type stream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+
+	body, err := json.Marshal(struct {
+		Streams []stream `json:"streams"`
+	}{
+		Streams: []stream{{Stream: c.labels, Values: entries}},
+	})
+	if err != nil {
+		return err
+	}
+
+	res, err := c.httpClient.Post(c.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package logger
+
+import (
+	"io"
+	"regexp"
+	"strings"
+
+	"golang.org/x/exp/slog"
+)
+
+// RedactedValue replaces the redacted values in log entries
+const RedactedValue = "[REDACTED]"
+
+// DefaultRedactedKeys are the property keys whose values are redacted by default
+var DefaultRedactedKeys = []string{
+	"password",
+	"passwd",
+	"secret",
+	"token",
+	"api_key",
+	"apikey",
+	"authorization",
+	"cookie",
+	"email",
+}
+
+// secretPatterns match obvious secrets wherever they appear in messages and property values.
+// Their first and second groups, if any, are kept before and after the redacted value.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),                               // JWT
+	regexp.MustCompile(`(?i)\b((?:Bearer|Basic)\s+)[A-Za-z0-9._~+/=-]{8,}`),                                  // Authorization header value
+	regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`),                                                               // AWS access key ID
+	regexp.MustCompile(`\bSG\.[A-Za-z0-9_-]{16,}\.[A-Za-z0-9_-]{16,}`),                                       // SendGrid API key
+	regexp.MustCompile(`\b(?:sk|pk|rk)_(?:live|test)_[A-Za-z0-9]{16,}`),                                      // Stripe API key
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}`),                                                       // GitHub token
+	regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`),                                                     // Slack token
+	regexp.MustCompile(`(?i)\b((?:mongodb(?:\+srv)?|postgres(?:ql)?|amqps?|redis)://[^:/@\s]+:)[^@/\s]+(@)`), // Credentials in a connection string
+}
+
+// Redactor is a struct that masks sensitive data before it is written to the logs: the values of properties
+// whose key contains one of the redacted keys (i.e. "Password" or "refresh_token" for "password" and "token"),
+// the values of key=value pairs with these keys in messages and values (i.e. query strings of URLs)
+// and obvious secrets such as JWTs and API keys
+type Redactor struct {
+	keys  []string
+	pairs *regexp.Regexp
+}
+
+// NewRedactor returns a new Redactor masking the values of the given keys, which are matched regardless of
+// case, underscores and dashes
+func NewRedactor(keys []string) *Redactor {
+	redactor := &Redactor{}
+
+	quoted := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+
+		redactor.keys = append(redactor.keys, normalizeKey(key))
+		quoted = append(quoted, regexp.QuoteMeta(key))
+	}
+
+	if len(quoted) > 0 {
+		redactor.pairs = regexp.MustCompile(`(?i)([\w.-]*(?:` + strings.Join(quoted, "|") + `)[\w.-]*=)[^&;,\s"]+`)
+	}
+
+	return redactor
+}
+
+// RedactKey returns whether the values of the property with the given key are redacted
+func (r *Redactor) RedactKey(key string) bool {
+	key = normalizeKey(key)
+
+	for _, redactedKey := range r.keys {
+		if strings.Contains(key, redactedKey) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Redact returns the given value with the values of key=value pairs with redacted keys and the obvious secrets masked
+func (r *Redactor) Redact(value string) string {
+	if r.pairs != nil {
+		value = r.pairs.ReplaceAllString(value, "${1}"+RedactedValue)
+	}
+
+	for _, pattern := range secretPatterns {
+		value = pattern.ReplaceAllString(value, "${1}"+RedactedValue+"${2}")
+	}
+
+	return value
+}
+
+// RedactAttr returns the given attribute with its value masked if it has a redacted key, or with the
+// sensitive data of its value masked if it is a string or an error
+func (r *Redactor) RedactAttr(attr slog.Attr) slog.Attr {
+	if r.RedactKey(attr.Key()) {
+		return slog.String(attr.Key(), RedactedValue)
+	}
+
+	switch value := attr.Value().(type) {
+	case string:
+		if redacted := r.Redact(value); redacted != value {
+			return slog.String(attr.Key(), redacted)
+		}
+	case error:
+		if redacted := r.Redact(value.Error()); redacted != value.Error() {
+			return slog.String(attr.Key(), redacted)
+		}
+	}
+
+	return attr
+}
+
+// normalizeKey returns the given key in lower case without underscores, dashes and dots
+func normalizeKey(key string) string {
+	return strings.NewReplacer("_", "", "-", "", ".", "").Replace(strings.ToLower(key))
+}
+
+// RedactingHandler is a slog handler which masks sensitive data in the messages and attributes
+// of log entries before passing them to the wrapped handler
+type RedactingHandler struct {
+	handler  slog.Handler
+	redactor *Redactor
+}
+
+// NewRedactingHandler returns a new RedactingHandler passing log entries to the given handler once
+// they have been redacted by the given redactor
+func NewRedactingHandler(handler slog.Handler, redactor *Redactor) *RedactingHandler {
+	return &RedactingHandler{handler: handler, redactor: redactor}
+}
+
+// Enabled returns whether the wrapped handler writes log entries at the given level
+func (h *RedactingHandler) Enabled(level slog.Level) bool {
+	return h.handler.Enabled(level)
+}
+
+// With returns a new RedactingHandler whose wrapped handler adds the given attributes, once redacted,
+// to every log entry
+func (h *RedactingHandler) With(attrs []slog.Attr) slog.Handler {
+	return &RedactingHandler{handler: h.handler.With(h.redactAttrs(attrs)), redactor: h.redactor}
+}
+
+// Handle passes a copy of the record with its message and attributes redacted to the wrapped handler
+func (h *RedactingHandler) Handle(record slog.Record) error {
+	redacted := slog.NewRecord(record.Time(), record.Level(), h.redactor.Redact(record.Message()), 0)
+
+	attrs := make([]slog.Attr, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) {
+		attrs = append(attrs, attr)
+	})
+
+	redacted.AddAttrs(h.redactAttrs(attrs)...)
+
+	return h.handler.Handle(redacted)
+}
+
+// Close closes the wrapped handler if it holds resources
+func (h *RedactingHandler) Close() error {
+	if closer, ok := h.handler.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// redactAttrs returns a redacted copy of the given attributes
+func (h *RedactingHandler) redactAttrs(attrs []slog.Attr) []slog.Attr {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = h.redactor.RedactAttr(attr)
+	}
+
+	return redacted
+}
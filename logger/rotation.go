@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/PlayEconomy37/Play.Common/configuration"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// rotatingFile is an output destination which writes to a file rotated by size and, optionally,
+// at a fixed interval. Rotated files are renamed with their rotation time (i.e. audit-2022-10-02T00-00-00.000.log)
+// and removed once there are more than the maximum number of backups or they are older than the maximum age.
+type rotatingFile struct {
+	*lumberjack.Logger
+
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// newRotatingFile opens the file of the given sink configuration with its rotation settings
+func newRotatingFile(sinkCfg configuration.LogSinkConfig) *rotatingFile {
+	file := &rotatingFile{
+		Logger: &lumberjack.Logger{
+			Filename:   sinkCfg.Path,
+			MaxSize:    sinkCfg.MaxSize,
+			MaxBackups: sinkCfg.MaxBackups,
+			MaxAge:     sinkCfg.MaxAge,
+			Compress:   sinkCfg.Compress,
+		},
+		done: make(chan struct{}),
+	}
+
+	if sinkCfg.RotateEvery > 0 {
+		file.wg.Add(1)
+		go file.rotateEvery(sinkCfg.RotateEvery)
+	}
+
+	return file
+}
+
+// rotateEvery rotates the file at every multiple of the given interval since the epoch
+// until the file is closed
+func (f *rotatingFile) rotateEvery(interval time.Duration) {
+	defer f.wg.Done()
+
+	for {
+		now := time.Now()
+		timer := time.NewTimer(now.Truncate(interval).Add(interval).Sub(now))
+
+		select {
+		case <-timer.C:
+			// Since they can't be logged, failures are written to stderr
+			if err := f.Rotate(); err != nil {
+				fmt.Fprintf(os.Stderr, "unable to rotate log file %s: %v\n", f.Filename, err)
+			}
+		case <-f.done:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// Close stops the periodic rotation and closes the file
+func (f *rotatingFile) Close() error {
+	f.closeOnce.Do(func() {
+		close(f.done)
+	})
+
+	f.wg.Wait()
+
+	return f.Logger.Close()
+}
@@ -0,0 +1,265 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/PlayEconomy37/Play.Common/configuration"
+	"golang.org/x/exp/slog"
+)
+
+// Log sinks which can be selected in the configuration
+const (
+	StdoutSink = "Stdout"
+	StderrSink = "Stderr"
+	FileSink   = "File"
+	SyslogSink = "Syslog"
+	LokiSink   = "Loki"
+)
+
+// Formats of the log entries written by stream sinks (stdout, stderr and files)
+const (
+	JSONFormat = "JSON"
+	TextFormat = "Text"
+)
+
+var (
+	// ErrUnsupportedSink is returned when a configured log sink is not supported
+	ErrUnsupportedSink = errors.New("unsupported log sink")
+
+	// ErrUnsupportedFormat is returned when the configured format of a log sink is not supported
+	ErrUnsupportedFormat = errors.New("unsupported log format")
+)
+
+// Sink is a struct that holds a slog handler along with the minimum severity level of
+// the log entries it receives
+type Sink struct {
+	Handler  slog.Handler
+	MinLevel Level
+}
+
+// MultiHandler is a slog handler which passes log entries to several sinks, each receiving
+// the entries at or above its own minimum severity level (i.e. every entry to stdout and
+// errors only to durable storage)
+type MultiHandler struct {
+	sinks []Sink
+}
+
+// NewMultiHandler returns a new MultiHandler passing log entries to the given sinks
+func NewMultiHandler(sinks ...Sink) *MultiHandler {
+	return &MultiHandler{sinks: sinks}
+}
+
+// Enabled returns whether at least one sink receives log entries at the given level
+func (h *MultiHandler) Enabled(level slog.Level) bool {
+	for _, sink := range h.sinks {
+		if levelFromSlog(level) >= sink.MinLevel && sink.Handler.Enabled(level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// With returns a new MultiHandler whose sinks add the given attributes to every log entry
+func (h *MultiHandler) With(attrs []slog.Attr) slog.Handler {
+	sinks := make([]Sink, len(h.sinks))
+	for i, sink := range h.sinks {
+		sinks[i] = Sink{Handler: sink.Handler.With(attrs), MinLevel: sink.MinLevel}
+	}
+
+	return &MultiHandler{sinks: sinks}
+}
+
+// Handle passes the record to every sink whose minimum severity level it reaches.
+// Every sink receives it even if one fails, and the first error is returned.
+func (h *MultiHandler) Handle(record slog.Record) error {
+	var firstErr error
+
+	for _, sink := range h.sinks {
+		if levelFromSlog(record.Level()) < sink.MinLevel || !sink.Handler.Enabled(record.Level()) {
+			continue
+		}
+
+		if err := sink.Handler.Handle(record); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// Close closes the sinks which hold resources (files, connections, pending entries)
+func (h *MultiHandler) Close() error {
+	var firstErr error
+
+	for _, sink := range h.sinks {
+		if closer, ok := sink.Handler.(io.Closer); ok {
+			if err := closer.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	return firstErr
+}
+
+// NewFromConfig returns a new Logger instance writing log entries to the sinks declared in the
+// configuration, or JSON log entries to stdout if there are none, once sensitive data has been
+// masked with the redacted keys of the configuration. The Logger must be closed
+// when the service stops so that files are closed and pending entries are pushed.
+func NewFromConfig(cfg *configuration.Config) (*Logger, error) {
+	loggingCfg := cfg.Logging
+
+	minLevel := LevelInfo
+	if loggingCfg.Level != "" {
+		level, err := ParseLevel(loggingCfg.Level)
+		if err != nil {
+			return nil, err
+		}
+
+		minLevel = level
+	}
+
+	sinksCfg := loggingCfg.Sinks
+	if len(sinksCfg) == 0 {
+		sinksCfg = []configuration.LogSinkConfig{{Type: StdoutSink}}
+	}
+
+	handler := &MultiHandler{}
+
+	for _, sinkCfg := range sinksCfg {
+		sink, err := newSink(cfg, sinkCfg)
+		if err != nil {
+			handler.Close()
+			return nil, fmt.Errorf("log sink %s: %w", sinkCfg.Type, err)
+		}
+
+		handler.sinks = append(handler.sinks, sink)
+	}
+
+	if loggingCfg.DisableRedaction {
+		return NewWithHandler(handler, minLevel), nil
+	}
+
+	redactedKeys := loggingCfg.RedactedKeys
+	if len(redactedKeys) == 0 {
+		redactedKeys = DefaultRedactedKeys
+	}
+
+	return NewWithHandler(NewRedactingHandler(handler, NewRedactor(redactedKeys)), minLevel), nil
+}
+
+// newSink creates the sink declared in the given sink configuration. A sink without a level of its own
+// receives every entry let through by the Logger, so that its level can still be lowered with SetLevel.
+func newSink(cfg *configuration.Config, sinkCfg configuration.LogSinkConfig) (Sink, error) {
+	sink := Sink{MinLevel: LevelDebug}
+
+	if sinkCfg.Level != "" {
+		level, err := ParseLevel(sinkCfg.Level)
+		if err != nil {
+			return Sink{}, err
+		}
+
+		sink.MinLevel = level
+	}
+
+	var err error
+
+	switch sinkType := sinkCfg.Type; {
+	case sinkType == "" || strings.EqualFold(sinkType, StdoutSink):
+		sink.Handler, err = newStreamHandler(os.Stdout, sinkCfg.Format)
+	case strings.EqualFold(sinkType, StderrSink):
+		sink.Handler, err = newStreamHandler(os.Stderr, sinkCfg.Format)
+	case strings.EqualFold(sinkType, FileSink):
+		sink.Handler, err = newFileHandler(sinkCfg)
+	case strings.EqualFold(sinkType, SyslogSink):
+		tag := sinkCfg.Tag
+		if tag == "" {
+			tag = cfg.ServiceName
+		}
+
+		sink.Handler, err = NewSyslogHandler(sinkCfg.Address, tag)
+	case strings.EqualFold(sinkType, LokiSink):
+		labels := map[string]string{"service_name": cfg.ServiceName}
+		for name, value := range sinkCfg.Labels {
+			labels[name] = value
+		}
+
+		sink.Handler, err = NewLokiHandler(sinkCfg.Address, labels)
+	default:
+		err = fmt.Errorf("%w: %s", ErrUnsupportedSink, sinkType)
+	}
+
+	return sink, err
+}
+
+// newStreamHandler creates the handler writing log entries to the given output destination in the given format
+func newStreamHandler(output io.Writer, format string) (slog.Handler, error) {
+	switch {
+	case format == "" || strings.EqualFold(format, JSONFormat):
+		return NewJSONHandler(output), nil
+	case strings.EqualFold(format, TextFormat):
+		// Let debug entries through, since the minimum severity level is enforced by the Logger and the sinks
+		return slog.HandlerOptions{Level: slog.DebugLevel}.NewTextHandler(output), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+}
+
+// newFileHandler creates the handler appending log entries to the configured file,
+// which is rotated if a maximum size or a rotation interval is configured
+func newFileHandler(sinkCfg configuration.LogSinkConfig) (slog.Handler, error) {
+	if sinkCfg.Path == "" {
+		return nil, errors.New("missing file path")
+	}
+
+	var file io.WriteCloser
+
+	if sinkCfg.MaxSize > 0 || sinkCfg.RotateEvery > 0 {
+		file = newRotatingFile(sinkCfg)
+	} else {
+		var err error
+
+		file, err = os.OpenFile(sinkCfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	handler, err := newStreamHandler(file, sinkCfg.Format)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return closingHandler{Handler: handler, closer: file}, nil
+}
+
+// closingHandler is a slog handler which closes the output destination of the wrapped handler when it is closed
+type closingHandler struct {
+	slog.Handler
+	closer io.Closer
+}
+
+// With returns a new closingHandler wrapping the wrapped handler with the given attributes
+func (h closingHandler) With(attrs []slog.Attr) slog.Handler {
+	return closingHandler{Handler: h.Handler.With(attrs), closer: h.closer}
+}
+
+// Close closes the output destination of the handler
+func (h closingHandler) Close() error {
+	return h.closer.Close()
+}
+
+// Close closes the handler of the logger if it holds resources, i.e. the sinks created by NewFromConfig
+func (l *Logger) Close() error {
+	if closer, ok := l.handler.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PlayEconomy37/Play.Common/configuration"
+)
+
+func TestNewFromConfigSetLevel(t *testing.T) {
+	tests := []struct {
+		name      string
+		sinkLevel string
+		want      bool
+	}{
+		{name: "Sink without level", want: true},
+		{name: "Sink with its own level", sinkLevel: "info", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "service.log")
+
+			cfg := &configuration.Config{}
+			cfg.Logging.Sinks = []configuration.LogSinkConfig{{Type: FileSink, Path: path, Level: tt.sinkLevel}}
+
+			logger, err := NewFromConfig(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger.Debug("before", nil)
+			logger.SetLevel(LevelDebug)
+			logger.Debug("after", nil)
+
+			if err := logger.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if strings.Contains(string(content), "before") {
+				t.Errorf("want no debug entry before SetLevel; got %s", content)
+			}
+
+			if got := strings.Contains(string(content), "after"); got != tt.want {
+				t.Errorf("want debug entry written after SetLevel to be %v; got %s", tt.want, content)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+//go:build !windows && !plan9
+
+package logger
+
+import (
+	"io"
+	"log/syslog"
+	"net/url"
+	"strings"
+
+	"golang.org/x/exp/slog"
+)
+
+// syslogHandler is a slog handler which sends log entries to a syslog server as JSON messages,
+// with the syslog severity matching their level
+type syslogHandler struct {
+	writer *syslog.Writer
+	json   *JSONHandler
+}
+
+// NewSyslogHandler returns a new slog handler sending log entries with the given tag to the syslog server
+// at the given address (i.e. "udp://syslog:514" or "tcp://syslog:601"), or to the local daemon if empty
+func NewSyslogHandler(address, tag string) (slog.Handler, error) {
+	network, raddr := "", ""
+
+	if address != "" {
+		network, raddr = "udp", address
+
+		if strings.Contains(address, "://") {
+			addressURL, err := url.Parse(address)
+			if err != nil {
+				return nil, err
+			}
+
+			network, raddr = addressURL.Scheme, addressURL.Host
+		}
+	}
+
+	writer, err := syslog.Dial(network, raddr, syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return &syslogHandler{writer: writer, json: NewJSONHandler(io.Discard)}, nil
+}
+
+// Enabled returns true, since the minimum severity level is enforced by the Logger and the sinks
+func (h *syslogHandler) Enabled(slog.Level) bool {
+	return true
+}
+
+// With returns a new syslogHandler which adds the given attributes to the properties of every log entry
+func (h *syslogHandler) With(attrs []slog.Attr) slog.Handler {
+	return &syslogHandler{writer: h.writer, json: h.json.With(attrs).(*JSONHandler)}
+}
+
+// Handle sends the JSON log entry of the given record with the syslog severity matching its level
+func (h *syslogHandler) Handle(record slog.Record) error {
+	message := string(h.json.entry(record))
+
+	switch levelFromSlog(record.Level()) {
+	case LevelDebug:
+		return h.writer.Debug(message)
+	case LevelInfo:
+		return h.writer.Info(message)
+	case LevelWarning:
+		return h.writer.Warning(message)
+	case LevelError:
+		return h.writer.Err(message)
+	default:
+		return h.writer.Crit(message)
+	}
+}
+
+// Close closes the connection to the syslog server
+func (h *syslogHandler) Close() error {
+	return h.writer.Close()
+}
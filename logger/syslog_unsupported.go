@@ -0,0 +1,14 @@
+//go:build windows || plan9
+
+package logger
+
+import (
+	"errors"
+
+	"golang.org/x/exp/slog"
+)
+
+// NewSyslogHandler returns an error, since syslog is not supported on this platform
+func NewSyslogHandler(address, tag string) (slog.Handler, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
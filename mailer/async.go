@@ -0,0 +1,242 @@
+package mailer
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"time"
+
+	"github.com/PlayEconomy37/Play.Common/events"
+	"github.com/PlayEconomy37/Play.Common/opentelemetry"
+)
+
+// SendEmailRequestedEventType is the type of the event used to queue emails through the message broker
+const SendEmailRequestedEventType = "SendEmailRequested"
+
+// Default configuration of the asynchronous mailer
+const (
+	DefaultQueueSize    = 100
+	DefaultDrainTimeout = 30 * time.Second
+)
+
+// DefaultAsyncRetryPolicy is the retry policy of the asynchronous mailer, which can afford
+// waiting longer than the mailer since no request is waiting for the email
+var DefaultAsyncRetryPolicy = RetryPolicy{
+	MaxAttempts:  5,
+	InitialDelay: time.Second,
+	MaxDelay:     time.Minute,
+	Multiplier:   2,
+}
+
+// ErrQueueFull is returned when queueing an email while the in-memory queue is full
+var ErrQueueFull = errors.New("mail queue is full")
+
+// Job is a struct that holds an email waiting to be sent. When jobs are queued through the
+// message broker, the template data is serialized to JSON, so templates receive a map.
+type Job struct {
+	Message
+}
+
+// EventType returns the type of the event used to queue the job through the message broker
+func (j Job) EventType() string {
+	return SendEmailRequestedEventType
+}
+
+// FailureHandler is a function called when an email could not be sent after all retries
+type FailureHandler func(ctx context.Context, job Job, err error)
+
+// AsyncOption is a function used to configure optional behaviour of an asynchronous mailer
+type AsyncOption func(*asyncOptions)
+
+// asyncOptions is a struct that holds the optional configuration of an asynchronous mailer
+type asyncOptions struct {
+	queueSize      int
+	publisher      events.Publisher
+	retryPolicy    RetryPolicy
+	drainTimeout   time.Duration
+	failureHandler FailureHandler
+	metrics        *opentelemetry.MailerMetrics
+	rateLimiter    *rateLimiter
+}
+
+// WithQueueSize sets the number of emails which can wait in the in-memory queue
+func WithQueueSize(size int) AsyncOption {
+	return func(opts *asyncOptions) {
+		opts.queueSize = size
+	}
+}
+
+// WithPublisher queues emails through the message broker instead of the in-memory queue.
+// They are sent by the consumer on which HandleJobs has been called.
+func WithPublisher(publisher events.Publisher) AsyncOption {
+	return func(opts *asyncOptions) {
+		opts.publisher = publisher
+	}
+}
+
+// WithAsyncRetryPolicy sets the policy used to retry queued emails which could not be delivered
+func WithAsyncRetryPolicy(policy RetryPolicy) AsyncOption {
+	return func(opts *asyncOptions) {
+		opts.retryPolicy = policy
+	}
+}
+
+// WithDrainTimeout sets the time given to send the emails left in the in-memory queue on shutdown
+func WithDrainTimeout(timeout time.Duration) AsyncOption {
+	return func(opts *asyncOptions) {
+		opts.drainTimeout = timeout
+	}
+}
+
+// WithFailureHandler sets the function called when an email could not be sent after all retries
+func WithFailureHandler(handler FailureHandler) AsyncOption {
+	return func(opts *asyncOptions) {
+		opts.failureHandler = handler
+	}
+}
+
+// WithMailerMetrics makes the mailer keep track of queued emails in the given metrics,
+// instead of the metrics of the mailer
+func WithMailerMetrics(metrics *opentelemetry.MailerMetrics) AsyncOption {
+	return func(opts *asyncOptions) {
+		opts.metrics = metrics
+	}
+}
+
+// AsyncMailer is a struct which queues emails so that they are sent in the background
+// with retries, instead of blocking the request which triggered them
+type AsyncMailer struct {
+	mailer     Mailer
+	fileSystem embed.FS
+	jobs       chan Job
+	options    asyncOptions
+}
+
+// NewAsyncMailer creates a new asynchronous mailer sending emails with the given mailer and the templates
+// of the given file system. Emails are queued in memory unless a publisher is provided.
+func NewAsyncMailer(mailer Mailer, fileSystem embed.FS, opts ...AsyncOption) *AsyncMailer {
+	options := asyncOptions{
+		queueSize:    DefaultQueueSize,
+		retryPolicy:  DefaultAsyncRetryPolicy,
+		drainTimeout: DefaultDrainTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.metrics == nil {
+		options.metrics = mailer.metrics
+	}
+
+	return &AsyncMailer{
+		mailer:     mailer,
+		fileSystem: fileSystem,
+		jobs:       make(chan Job, options.queueSize),
+		options:    options,
+	}
+}
+
+// Send queues an email to the given recipient. It returns ErrQueueFull instead of blocking
+// when the in-memory queue is full.
+func (m *AsyncMailer) Send(ctx context.Context, recipient string, templateFile string, data any) error {
+	return m.SendMessage(ctx, Message{To: []string{recipient}, TemplateFile: templateFile, Data: data})
+}
+
+// SendMessage queues the given message. It returns ErrQueueFull instead of blocking
+// when the in-memory queue is full.
+func (m *AsyncMailer) SendMessage(ctx context.Context, msg Message) error {
+	job := Job{Message: msg}
+
+	if m.options.publisher != nil {
+		if err := m.options.publisher.Publish(ctx, job); err != nil {
+			return err
+		}
+	} else {
+		select {
+		case m.jobs <- job:
+		default:
+			return ErrQueueFull
+		}
+	}
+
+	if m.options.metrics != nil {
+		m.options.metrics.QueuedEmailsCounter.WithLabelValues(msg.TemplateFile).Inc()
+	}
+
+	return nil
+}
+
+// Run sends the emails of the in-memory queue until the given context is cancelled, then sends the
+// emails left in the queue within the drain timeout. It is meant to be run with App.Background.
+func (m *AsyncMailer) Run(ctx context.Context) {
+	for {
+		select {
+		case job := <-m.jobs:
+			m.Process(ctx, job)
+		case <-ctx.Done():
+			m.drain()
+			return
+		}
+	}
+}
+
+// drain sends the emails left in the in-memory queue
+func (m *AsyncMailer) drain() {
+	ctx, cancel := context.WithTimeout(context.Background(), m.options.drainTimeout)
+	defer cancel()
+
+	for {
+		select {
+		case job := <-m.jobs:
+			m.Process(ctx, job)
+		default:
+			return
+		}
+	}
+}
+
+// Process sends the emails of the given job, retrying them according to the retry policy until
+// the context is cancelled. Emails already sent are not sent again when retrying. The failure handler is called when the emails could not be sent, or were not sent
+// because of the rate limit.
+func (m *AsyncMailer) Process(ctx context.Context, job Job) (err error) {
+	ctx, span := startSpan(ctx, job.Message)
+	observe := m.mailer.observeSend(job.Message)
+
+	defer func() {
+		observe(err)
+		endSpan(span, err)
+	}()
+
+	if m.options.rateLimiter != nil {
+		err = m.options.rateLimiter.allow(ctx, &job.Message)
+	}
+
+	if err == nil {
+		var emails []Email
+
+		emails, err = m.mailer.render(m.fileSystem, job.Message)
+		if err == nil {
+			_, err = m.mailer.deliver(ctx, emails, m.options.retryPolicy)
+		}
+	}
+
+	if err != nil && m.options.failureHandler != nil {
+		m.options.failureHandler(ctx, job, err)
+	}
+
+	return err
+}
+
+// HandleJobs registers a handler sending the emails queued through the message broker.
+// Emails which could not be sent are not retried by the consumer since they have already
+// been retried by the mailer.
+func HandleJobs(registrar events.HandlerRegistrar, mailer *AsyncMailer) {
+	events.Handle(registrar, func(ctx context.Context, job Job) error {
+		if err := mailer.Process(ctx, job); err != nil {
+			return events.Permanent(err)
+		}
+
+		return nil
+	})
+}
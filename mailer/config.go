@@ -0,0 +1,74 @@
+package mailer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/PlayEconomy37/Play.Common/configuration"
+)
+
+// Email providers which can be selected in the configuration
+const (
+	SMTPProvider     = "SMTP"
+	SendGridProvider = "SendGrid"
+	SESProvider      = "SES"
+)
+
+// ErrUnsupportedProvider is returned when the configured email provider is not supported
+var ErrUnsupportedProvider = errors.New("unsupported email provider")
+
+// NewFromConfig creates a new Mailer delivering emails with the provider selected in the configuration
+// (SMTP by default). The sender email address is taken from the SMTP section for every provider.
+// The mailer keeps track of sent emails in metrics named after the service.
+func NewFromConfig(cfg *configuration.Config, opts ...Option) (Mailer, error) {
+	transport, err := newSenderFromConfig(cfg)
+	if err != nil {
+		return Mailer{}, err
+	}
+
+	opts = append([]Option{WithMetrics(mailerMetrics(cfg.ServiceName))}, opts...)
+
+	return NewWithSender(transport, cfg.SMTP.Sender, opts...), nil
+}
+
+// newSenderFromConfig creates the Sender of the provider selected in the configuration
+func newSenderFromConfig(cfg *configuration.Config) (Sender, error) {
+	switch provider := cfg.Mail.Provider; {
+	case provider == "" || strings.EqualFold(provider, SMTPProvider):
+		smtpCfg := cfg.SMTP
+
+		encryption, err := ParseEncryption(smtpCfg.Encryption)
+		if err != nil {
+			return nil, err
+		}
+
+		authentication, err := ParseAuthentication(smtpCfg.Authentication)
+		if err != nil {
+			return nil, err
+		}
+
+		configOpts := []SMTPOption{WithEncryption(encryption), WithAuthentication(authentication)}
+		if smtpCfg.InsecureSkipVerify {
+			configOpts = append(configOpts, WithInsecureSkipVerify())
+		}
+
+		if dkimCfg := smtpCfg.DKIM; dkimCfg.Domain != "" {
+			privateKey, err := LoadDKIMPrivateKey(dkimCfg.PrivateKey, dkimCfg.PrivateKeyFile)
+			if err != nil {
+				return nil, err
+			}
+
+			configOpts = append(configOpts, WithDKIM(dkimCfg.Domain, dkimCfg.Selector, privateKey))
+		}
+
+		return NewSMTPSender(smtpCfg.Host, smtpCfg.Port, smtpCfg.Username, smtpCfg.Password, configOpts...), nil
+	case strings.EqualFold(provider, SendGridProvider):
+		return NewSendGridSender(cfg.Mail.SendGrid.APIKey), nil
+	case strings.EqualFold(provider, SESProvider):
+		sesCfg := cfg.Mail.SES
+		return NewSESSender(sesCfg.Region, sesCfg.AccessKeyID, sesCfg.SecretAccessKey, sesCfg.SessionToken), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedProvider, provider)
+	}
+}
@@ -0,0 +1,65 @@
+package mailer
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+
+	"github.com/toorop/go-dkim"
+)
+
+// ErrInvalidDKIMKey is returned when the DKIM private key is not a PEM encoded RSA key
+var ErrInvalidDKIMKey = errors.New("invalid DKIM private key")
+
+// dkimSignedHeaders are the headers covered by the DKIM signature
+var dkimSignedHeaders = []string{"from", "to", "cc", "reply-to", "subject", "date", "message-id", "mime-version", "content-type"}
+
+// WithDKIM signs outgoing emails with DKIM, with the given domain, selector and PEM encoded RSA private key
+// (PKCS1 or PKCS8), so that emails sent through the SMTP server pass DMARC. The public key must be
+// published in the <selector>._domainkey.<domain> TXT record.
+func WithDKIM(domain, selector string, privateKey []byte) SMTPOption {
+	return func(s *SMTPSender) {
+		options := dkim.NewSigOptions()
+		options.Domain = domain
+		options.Selector = selector
+		options.PrivateKey = privateKey
+		options.Canonicalization = "relaxed/relaxed"
+		options.Headers = dkimSignedHeaders
+
+		s.dkim = &options
+	}
+}
+
+// LoadDKIMPrivateKey returns the given PEM encoded private key, or reads it from the given file
+// when no key is given, and checks that it is an RSA private key
+func LoadDKIMPrivateKey(privateKey, privateKeyFile string) ([]byte, error) {
+	key := []byte(privateKey)
+
+	if privateKey == "" {
+		var err error
+
+		key, err = os.ReadFile(privateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	block, _ := pem.Decode(key)
+	if block == nil {
+		return nil, ErrInvalidDKIMKey
+	}
+
+	if _, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	if parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		if _, ok := parsed.(*rsa.PrivateKey); ok {
+			return key, nil
+		}
+	}
+
+	return nil, ErrInvalidDKIMKey
+}
@@ -0,0 +1,137 @@
+package mailer
+
+import (
+	"fmt"
+	"html/template"
+	"io/fs"
+	"path"
+	"time"
+
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// DefaultLocale is the locale of the templates used when no template exists for the recipient locale
+const DefaultLocale = "en"
+
+// WithDefaultLocale sets the locale of the templates used when no template exists for the recipient locale
+func WithDefaultLocale(locale string) Option {
+	return func(m *Mailer) {
+		m.defaultLocale = locale
+	}
+}
+
+// dateLayouts holds the layouts of dates per language, and per region when it differs
+var dateLayouts = map[string]string{
+	"en-US": "01/02/2006",
+	"en":    "02/01/2006",
+	"fr":    "02/01/2006",
+	"es":    "02/01/2006",
+	"it":    "02/01/2006",
+	"pt":    "02/01/2006",
+	"de":    "02.01.2006",
+	"ja":    "2006/01/02",
+	"zh":    "2006/01/02",
+}
+
+// timeLayouts holds the layouts of times per language, and per region when it differs
+var timeLayouts = map[string]string{
+	"en-US": "3:04 PM",
+}
+
+// symbolFirstLanguages are the languages which write the currency symbol before the amount
+var symbolFirstLanguages = map[string]bool{"en": true, "ja": true, "zh": true, "ko": true}
+
+// templatePaths returns the paths of the template file to try, in order, for the given locale:
+// the locale directory (emails/fr-CA/...), its language directory (emails/fr/...), the default
+// locale directory and the templates directory itself
+func templatePaths(file, locale, defaultLocale string) []string {
+	paths := []string{}
+
+	for _, candidate := range []string{locale, languageOf(locale), defaultLocale} {
+		if candidate != "" {
+			paths = append(paths, path.Join(TemplatesDir, candidate, file))
+		}
+	}
+
+	return append(paths, path.Join(TemplatesDir, file))
+}
+
+// resolveTemplatePath returns the path of the first template file which exists for the given locale
+func resolveTemplatePath(fileSystem fs.FS, file, locale, defaultLocale string) string {
+	paths := templatePaths(file, locale, defaultLocale)
+
+	for _, filePath := range paths {
+		if _, err := fs.Stat(fileSystem, filePath); err == nil {
+			return filePath
+		}
+	}
+
+	// Let the parser report the missing template
+	return paths[len(paths)-1]
+}
+
+// templateFuncs returns the functions available in email templates, formatting values for the given locale:
+//
+//	{{ formatDate .CreatedAt }}          01/31/2023 (en-US), 31/01/2023 (fr)
+//	{{ formatDateTime .CreatedAt }}      01/31/2023 3:04 PM (en-US), 31/01/2023 15:04 (fr)
+//	{{ formatNumber .Quantity }}         1,234 (en), 1 234 (fr)
+//	{{ formatCurrency .Price "EUR" }}    €1,234.50 (en), 1 234,50 € (fr)
+//
+// Amounts in a currency which is not an ISO 4217 code (i.e. gil) are followed by the currency name.
+func templateFuncs(locale string) template.FuncMap {
+	tag := language.Make(locale)
+	printer := message.NewPrinter(tag)
+
+	dateLayout := localeValue(dateLayouts, locale, "2006-01-02")
+	timeLayout := localeValue(timeLayouts, locale, "15:04")
+
+	return template.FuncMap{
+		"formatDate": func(t time.Time) string {
+			return t.Format(dateLayout)
+		},
+		"formatDateTime": func(t time.Time) string {
+			return t.Format(dateLayout + " " + timeLayout)
+		},
+		"formatNumber": func(value any) string {
+			return printer.Sprint(number.Decimal(value))
+		},
+		"formatCurrency": func(amount float64, code string) string {
+			unit, err := currency.ParseISO(code)
+			if err != nil {
+				return printer.Sprintf("%v %s", number.Decimal(amount, number.Scale(2)), code)
+			}
+
+			scale, _ := currency.Standard.Rounding(unit)
+			formatted := printer.Sprint(number.Decimal(amount, number.Scale(scale)))
+			symbol := printer.Sprint(currency.NarrowSymbol(unit))
+
+			if symbolFirstLanguages[languageOf(locale)] {
+				return symbol + formatted
+			}
+
+			return fmt.Sprintf("%s %s", formatted, symbol)
+		},
+	}
+}
+
+// localeValue returns the value of the given locale, or of its language, or the default value
+func localeValue(values map[string]string, locale, defaultValue string) string {
+	if value, ok := values[locale]; ok {
+		return value
+	}
+
+	if value, ok := values[languageOf(locale)]; ok {
+		return value
+	}
+
+	return defaultValue
+}
+
+// languageOf returns the language of the given locale (i.e. fr for fr-CA)
+func languageOf(locale string) string {
+	base, _ := language.Make(locale).Base()
+	return base.String()
+}
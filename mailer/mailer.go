@@ -2,56 +2,144 @@ package mailer
 
 import (
 	"bytes"
+	"context"
 	"embed"
 	"html/template"
 	"time"
 
-	mail "github.com/xhit/go-simple-mail/v2"
+	"github.com/PlayEconomy37/Play.Common/logger"
+	"github.com/PlayEconomy37/Play.Common/opentelemetry"
+	"github.com/google/uuid"
 )
 
-// Mailer is a struct that holds an SMTP server and a sender email address
+// Mailer is a struct that holds the sender delivering our emails and a sender email address
 type Mailer struct {
-	server *mail.SMTPServer
-	sender string
+	transport Sender
+	sender    string
+	templates *templateCache
+	metrics   *opentelemetry.MailerMetrics
+	logger    *logger.Logger
+
+	defaultLocale string
+	retryPolicy   RetryPolicy
 }
 
-// New crates a new Mailer instance
-func New(host string, port int, username, password, sender string) Mailer {
-	// Create email server
-	server := mail.NewSMTPClient()
-	server.Host = host
-	server.Port = port
-	server.Username = username
-	server.Password = password
-	server.KeepAlive = false
-	server.ConnectTimeout = 10 * time.Second
-	server.SendTimeout = 10 * time.Second
-
-	return Mailer{server: server, sender: sender}
+// New crates a new Mailer instance sending emails through the given SMTP server.
+// The SMTP server is configured with the WithSMTPOptions option.
+func New(host string, port int, username, password, sender string, opts ...Option) Mailer {
+	return NewWithSender(NewSMTPSender(host, port, username, password), sender, opts...)
+}
+
+// NewWithSender creates a new Mailer instance delivering emails with the given Sender
+// (i.e. an HTTP API based provider) from the given sender email address
+func NewWithSender(transport Sender, sender string, opts ...Option) Mailer {
+	mailer := Mailer{transport: transport, sender: sender, templates: newTemplateCache(), defaultLocale: DefaultLocale, retryPolicy: DefaultRetryPolicy}
+
+	for _, opt := range opts {
+		opt(&mailer)
+	}
+
+	return mailer
 }
 
 // Send takes the recipient email address as the first parameter, the name of the file
 // containing the templates, and any dynamic data for the templates as an any parameter.
 func (m Mailer) Send(recipient string, fileSystem embed.FS, templateFile string, data any) error {
-	// Connect to mail server
-	client, err := m.server.Connect()
+	return m.SendContext(context.Background(), recipient, fileSystem, templateFile, data)
+}
+
+// SendContext is like Send but stops retrying once the given context is cancelled or its
+// deadline is exceeded. The email is traced as a child span of the context span.
+func (m Mailer) SendContext(ctx context.Context, recipient string, fileSystem embed.FS, templateFile string, data any) error {
+	return m.SendMessageContext(ctx, fileSystem, Message{To: []string{recipient}, TemplateFile: templateFile, Data: data})
+}
+
+// SendMessage sends the given message, whose templates are parsed from the given file system,
+// along with its attachments. Messages with per-recipient data are sent as one email per
+// recipient, delivered together (i.e. over the same SMTP connection).
+func (m Mailer) SendMessage(fileSystem embed.FS, msg Message) error {
+	return m.SendMessageContext(context.Background(), fileSystem, msg)
+}
+
+// SendMessageContext is like SendMessage but stops retrying once the given context is cancelled
+// or its deadline is exceeded. The message is traced as a child span of the context span.
+func (m Mailer) SendMessageContext(ctx context.Context, fileSystem embed.FS, msg Message) (err error) {
+	ctx, span := startSpan(ctx, msg)
+	observe := m.observeSend(msg)
+
+	defer func() {
+		observe(err)
+		endSpan(span, err)
+	}()
+
+	emails, err := m.render(fileSystem, msg)
 	if err != nil {
 		return err
 	}
 
-	// Use the `ParseFS()` method to parse the required template file from the embedded
-	// file system
-	tmpl, err := template.New("email").ParseFS(fileSystem, "emails/"+templateFile)
+	// Retry sending every email according to the retry policy before aborting and returning the final error
+	_, err = m.deliver(ctx, emails, m.retryPolicy)
+
+	return err
+}
+
+// render renders the templates of the message into the emails to send: a single email
+// for all recipients, or one email per recipient when the message has per-recipient data
+func (m Mailer) render(fileSystem embed.FS, msg Message) ([]Email, error) {
+	// Get the required template file in the locale of the message from the cache, which
+	// parses it from the embedded file system the first time
+	locale := msg.Locale
+	if locale == "" {
+		locale = m.defaultLocale
+	}
+
+	tmpl, err := m.templates.get(fileSystem, msg.TemplateFile, locale, m.defaultLocale)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if len(msg.RecipientData) == 0 {
+		email, err := m.renderEmail(tmpl, msg, msg.To, msg.CC, msg.BCC, msg.Data)
+		if err != nil {
+			return nil, err
+		}
+
+		return []Email{email}, nil
+	}
+
+	emails := make([]Email, 0, len(msg.To))
+
+	for i, recipient := range msg.To {
+		data, ok := msg.RecipientData[recipient]
+		if !ok {
+			data = msg.Data
+		}
+
+		// The CC and BCC recipients only get the email of the first recipient
+		var cc, bcc []string
+		if i == 0 {
+			cc, bcc = msg.CC, msg.BCC
+		}
+
+		email, err := m.renderEmail(tmpl, msg, []string{recipient}, cc, bcc, data)
+		if err != nil {
+			return nil, err
+		}
+
+		emails = append(emails, email)
 	}
 
+	return emails, nil
+}
+
+// renderEmail renders the templates with the given data into a new email sent to the given recipients
+func (m Mailer) renderEmail(tmpl *template.Template, msg Message, to, cc, bcc []string, data any) (Email, error) {
 	// Execute the named template "subject", passing in the dynamic data and storing the
 	// result in a bytes.Buffer variable.
 	subject := new(bytes.Buffer)
-	err = tmpl.ExecuteTemplate(subject, "subject", data)
+	err := tmpl.ExecuteTemplate(subject, "subject", data)
 	if err != nil {
-		return err
+		return Email{}, err
 	}
 
 	// Follow the same pattern to execute the "plainBody" template and store the result
@@ -59,36 +147,66 @@ func (m Mailer) Send(recipient string, fileSystem embed.FS, templateFile string,
 	plainBody := new(bytes.Buffer)
 	err = tmpl.ExecuteTemplate(plainBody, "plainBody", data)
 	if err != nil {
-		return err
+		return Email{}, err
 	}
 
 	// And likewise with the "htmlBody" template.
 	htmlBody := new(bytes.Buffer)
 	err = tmpl.ExecuteTemplate(htmlBody, "htmlBody", data)
 	if err != nil {
-		return err
+		return Email{}, err
 	}
 
-	// Setup email message
-	email := mail.NewMSG()
-	email.SetFrom(m.sender)
-	email.AddTo(recipient)
-	email.SetSubject(subject.String())
-	email.SetBody(mail.TextPlain, plainBody.String())
-	email.AddAlternative(mail.TextHTML, htmlBody.String())
-
-	// Try sending the email up to three times before aborting and returning the final
-	// error. We sleep for 500 milliseconds between each attempt.
-	for i := 1; i <= 3; i++ {
-		err = email.Send(client)
-		// If everything worked, return nil
+	return Email{
+		ID:          uuid.NewString(),
+		Template:    msg.TemplateFile,
+		From:        m.sender,
+		To:          to,
+		CC:          cc,
+		BCC:         bcc,
+		ReplyTo:     msg.ReplyTo,
+		Subject:     subject.String(),
+		PlainBody:   plainBody.String(),
+		HTMLBody:    htmlBody.String(),
+		Attachments: msg.Attachments,
+	}, nil
+}
+
+// deliver delivers the emails with the sender, retrying every email which failed according to the given
+// policy. It returns the number of emails delivered, which are the first ones, since it stops at the
+// first email which could not be delivered.
+func (m Mailer) deliver(ctx context.Context, emails []Email, policy RetryPolicy) (int, error) {
+	delivered, attempt := 0, 1
+
+	for {
+		sent, err := m.transport.Deliver(ctx, emails[delivered:])
+		m.recordDelivered(emails[delivered : delivered+sent])
+
+		if sent > 0 {
+			// The email which failed gets its own attempts
+			delivered += sent
+			attempt = 1
+		}
+
+		// If everything worked, return the number of delivered emails
 		if nil == err {
-			return nil
+			return delivered, nil
 		}
 
-		// If it didn't work, sleep for a short time and retry.
-		time.Sleep(500 * time.Millisecond)
-	}
+		m.recordFailure(emails[delivered], attempt, err)
 
-	return err
+		if attempt >= policy.MaxAttempts || !policy.retryable(err) {
+			return delivered, err
+		}
+
+		// If it didn't work, wait for a while and retry.
+		select {
+		case <-time.After(policy.Delay(attempt)):
+		case <-ctx.Done():
+			return delivered, err
+		}
+
+		attempt++
+		m.recordRetry(emails[delivered])
+	}
 }
@@ -0,0 +1,77 @@
+package mailer
+
+import (
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+)
+
+// Message is a struct that holds an email to send, rendered from the templates of the given file
+// in the locale of the recipients (i.e. emails/fr/activation.tmpl), or in the default locale.
+// When per-recipient data is provided, every recipient of To gets its own email rendered with its
+// data (or with Data if it has none), and the CC and BCC recipients are added to the first one only,
+// so that they receive the message once.
+type Message struct {
+	To            []string       `json:"to"`
+	CC            []string       `json:"cc,omitempty"`
+	BCC           []string       `json:"bcc,omitempty"`
+	ReplyTo       string         `json:"reply_to,omitempty"`
+	TemplateFile  string         `json:"template_file"`
+	Locale        string         `json:"locale,omitempty"` // i.e. fr or fr-CA
+	Data          any            `json:"data"`
+	RecipientData map[string]any `json:"recipient_data,omitempty"` // Keyed by recipient address
+	Attachments   []Attachment   `json:"attachments,omitempty"`
+}
+
+// Attachment is a struct that holds a file attached to an email. Inline attachments (i.e. images)
+// are displayed in the HTML body, which references them by name (i.e. <img src="cid:logo.png">).
+type Attachment struct {
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Data        []byte `json:"data"`
+	Inline      bool   `json:"inline,omitempty"`
+}
+
+// NewAttachment reads the attachment with the given name from the given reader.
+// Its content type is inferred from the file extension, or from its content otherwise.
+func NewAttachment(name string, reader io.Reader) (Attachment, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return Attachment{}, err
+	}
+
+	return Attachment{Name: name, ContentType: detectContentType(name, data), Data: data}, nil
+}
+
+// NewAttachmentFromFS reads the attachment from the file with the given path in the given
+// file system (i.e. an embed.FS). It is named after the file.
+func NewAttachmentFromFS(fileSystem fs.FS, filePath string) (Attachment, error) {
+	data, err := fs.ReadFile(fileSystem, filePath)
+	if err != nil {
+		return Attachment{}, err
+	}
+
+	name := path.Base(filePath)
+
+	return Attachment{Name: name, ContentType: detectContentType(name, data), Data: data}, nil
+}
+
+// NewInlineAttachment reads an inline attachment (i.e. an image displayed in the HTML body)
+// from the given reader
+func NewInlineAttachment(name string, reader io.Reader) (Attachment, error) {
+	attachment, err := NewAttachment(name, reader)
+	attachment.Inline = true
+
+	return attachment, err
+}
+
+// detectContentType returns the content type of a file from its extension or its content
+func detectContentType(name string, data []byte) string {
+	if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+		return contentType
+	}
+
+	return http.DetectContentType(data)
+}
@@ -0,0 +1,100 @@
+package mailer
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/PlayEconomy37/Play.Common/logger"
+	"github.com/PlayEconomy37/Play.Common/opentelemetry"
+)
+
+// Option is a function used to configure optional behaviour of a mailer
+type Option func(*Mailer)
+
+// WithMetrics makes the mailer keep track of sent, failed and retried emails in the given metrics
+func WithMetrics(metrics *opentelemetry.MailerMetrics) Option {
+	return func(m *Mailer) {
+		m.metrics = metrics
+	}
+}
+
+// WithLogger makes the mailer log delivered and failed emails with the given logger
+func WithLogger(logger *logger.Logger) Option {
+	return func(m *Mailer) {
+		m.logger = logger
+	}
+}
+
+// metricsByAppName holds the mailer metrics created for each application, since
+// Prometheus metrics can only be registered once per process
+var metricsByAppName = struct {
+	sync.Mutex
+	metrics map[string]*opentelemetry.MailerMetrics
+}{metrics: map[string]*opentelemetry.MailerMetrics{}}
+
+// mailerMetrics returns the mailer metrics of the given application, creating them if needed
+func mailerMetrics(appName string) *opentelemetry.MailerMetrics {
+	metricsByAppName.Lock()
+	defer metricsByAppName.Unlock()
+
+	metrics, ok := metricsByAppName.metrics[appName]
+	if !ok {
+		metrics = opentelemetry.CreateMailerMetrics(appName)
+		metricsByAppName.metrics[appName] = metrics
+	}
+
+	return metrics
+}
+
+// observeSend returns a function which records the duration of sending the message
+// and whether it failed once it has been sent
+func (m Mailer) observeSend(msg Message) func(err error) {
+	if m.metrics == nil {
+		return func(error) {}
+	}
+
+	started := time.Now()
+
+	return func(err error) {
+		m.metrics.SendDurationHistogram.WithLabelValues(msg.TemplateFile).Observe(time.Since(started).Seconds())
+
+		if err != nil {
+			m.metrics.FailedEmailsCounter.WithLabelValues(msg.TemplateFile).Inc()
+		}
+	}
+}
+
+// recordDelivered keeps track of delivered emails in the metrics and logs
+func (m Mailer) recordDelivered(emails []Email) {
+	for _, email := range emails {
+		if m.metrics != nil {
+			m.metrics.SentEmailsCounter.WithLabelValues(email.Template).Inc()
+		}
+
+		if m.logger != nil {
+			m.logger.Info("Email sent", map[string]string{
+				"message_id": email.ID,
+				"template":   email.Template,
+			})
+		}
+	}
+}
+
+// recordFailure logs a failed delivery attempt of the given email
+func (m Mailer) recordFailure(email Email, attempt int, err error) {
+	if m.logger != nil {
+		m.logger.Error(err, map[string]string{
+			"message_id": email.ID,
+			"template":   email.Template,
+			"attempt":    strconv.Itoa(attempt),
+		})
+	}
+}
+
+// recordRetry keeps track of a new delivery attempt of the given email in the metrics
+func (m Mailer) recordRetry(email Email) {
+	if m.metrics != nil {
+		m.metrics.RetriedEmailsCounter.WithLabelValues(email.Template).Inc()
+	}
+}
@@ -0,0 +1,96 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// ErrRecipientCooldown is returned when every recipient of an email has already received
+// an email during the recipient cooldown
+var ErrRecipientCooldown = errors.New("recipient is in cooldown")
+
+// WithRateLimit limits the number of recipients the asynchronous mailer sends emails to per minute,
+// and the time before a recipient can receive another email. Recipients in cooldown are removed
+// from the emails, so that a bug or an abuse scenario can't get our sender blacklisted.
+// A zero value disables the corresponding limit.
+func WithRateLimit(perMinute int, recipientCooldown time.Duration) AsyncOption {
+	return func(opts *asyncOptions) {
+		opts.rateLimiter = newRateLimiter(perMinute, recipientCooldown)
+	}
+}
+
+// rateLimiter is a struct which limits the emails sent by the asynchronous mailer
+type rateLimiter struct {
+	limiter  *rate.Limiter
+	cooldown time.Duration
+
+	mutex    sync.Mutex
+	lastSent map[string]time.Time // Keyed by lowercase recipient address
+}
+
+// newRateLimiter creates a new rate limiter
+func newRateLimiter(perMinute int, cooldown time.Duration) *rateLimiter {
+	limiter := &rateLimiter{cooldown: cooldown, lastSent: map[string]time.Time{}}
+
+	if perMinute > 0 {
+		limiter.limiter = rate.NewLimiter(rate.Limit(float64(perMinute)/60), perMinute)
+	}
+
+	return limiter
+}
+
+// allow removes the recipients of the message which are in cooldown and waits until the remaining
+// recipients can be sent an email within the per-minute cap
+func (l *rateLimiter) allow(ctx context.Context, msg *Message) error {
+	if l.cooldown > 0 {
+		msg.To = l.filterCooldown(msg.To)
+		if len(msg.To) == 0 {
+			return ErrRecipientCooldown
+		}
+	}
+
+	if l.limiter == nil {
+		return nil
+	}
+
+	recipients := len(msg.To) + len(msg.CC) + len(msg.BCC)
+	if recipients > l.limiter.Burst() {
+		recipients = l.limiter.Burst()
+	}
+
+	return l.limiter.WaitN(ctx, recipients)
+}
+
+// filterCooldown returns the recipients which are not in cooldown and starts their cooldown
+func (l *rateLimiter) filterCooldown(recipients []string) []string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	allowed := make([]string, 0, len(recipients))
+
+	for _, recipient := range recipients {
+		key := strings.ToLower(recipient)
+
+		if lastSent, ok := l.lastSent[key]; ok && now.Sub(lastSent) < l.cooldown {
+			continue
+		}
+
+		l.lastSent[key] = now
+		allowed = append(allowed, recipient)
+	}
+
+	// Forget the recipients whose cooldown is over, so that the map doesn't grow forever
+	for key, lastSent := range l.lastSent {
+		if now.Sub(lastSent) >= l.cooldown {
+			delete(l.lastSent, key)
+		}
+	}
+
+	return allowed
+}
@@ -0,0 +1,101 @@
+package mailer
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// Recorder is a Sender which stores the rendered emails in memory instead of delivering them,
+// so that tests can assert on the emails sent by a service:
+//
+//	recorder := mailer.NewRecorder()
+//	m := mailer.NewWithSender(recorder, "no-reply@playeconomy.com")
+type Recorder struct {
+	mutex  sync.Mutex
+	emails []Email
+	err    error
+}
+
+// NewRecorder creates a new empty Recorder
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Deliver stores the emails, or fails with the error set with SetError
+func (r *Recorder) Deliver(ctx context.Context, emails []Email) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	r.emails = append(r.emails, emails...)
+
+	return len(emails), nil
+}
+
+// SetError makes every following delivery fail with the given error, until it is called with nil
+func (r *Recorder) SetError(err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.err = err
+}
+
+// Messages returns the recorded emails in the order in which they were sent
+func (r *Recorder) Messages() []Email {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]Email(nil), r.emails...)
+}
+
+// LastMessage returns the last recorded email, if any
+func (r *Recorder) LastMessage() (Email, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if len(r.emails) == 0 {
+		return Email{}, false
+	}
+
+	return r.emails[len(r.emails)-1], true
+}
+
+// MessagesTo returns the recorded emails sent to the given address, whether as a To, CC or BCC recipient.
+// Addresses are compared case insensitively.
+func (r *Recorder) MessagesTo(address string) []Email {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	emails := []Email{}
+
+	for _, email := range r.emails {
+		if containsAddress(email.To, address) || containsAddress(email.CC, address) || containsAddress(email.BCC, address) {
+			emails = append(emails, email)
+		}
+	}
+
+	return emails
+}
+
+// Reset removes every recorded email
+func (r *Recorder) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.emails = nil
+}
+
+// containsAddress returns whether the given addresses contain the address
+func containsAddress(addresses []string, address string) bool {
+	for _, candidate := range addresses {
+		if strings.EqualFold(candidate, address) {
+			return true
+		}
+	}
+
+	return false
+}
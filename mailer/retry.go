@@ -0,0 +1,88 @@
+package mailer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/textproto"
+	"time"
+)
+
+// APIError is an error returned by the HTTP API of an email provider
+type APIError struct {
+	Provider   string
+	StatusCode int
+	Message    string
+}
+
+// Error returns the message of the error
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s: unexpected status %d: %s", e.Provider, e.StatusCode, e.Message)
+}
+
+// RetryPolicy is a struct that defines how emails which could not be delivered are retried
+type RetryPolicy struct {
+	MaxAttempts  int                  // Maximum number of attempts, including the first one
+	InitialDelay time.Duration        // Delay before the first retry
+	MaxDelay     time.Duration        // Upper bound of the delay between two attempts
+	Multiplier   float64              // Factor by which the delay grows after every attempt (1 for a constant delay)
+	Retryable    func(err error) bool // Whether an error is worth retrying, IsRetryable if nil
+}
+
+// DefaultRetryPolicy is the retry policy of the mailer: three attempts, 500 milliseconds apart
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:  3,
+	InitialDelay: 500 * time.Millisecond,
+	MaxDelay:     500 * time.Millisecond,
+	Multiplier:   1,
+}
+
+// WithRetryPolicy sets the policy used to retry emails which could not be delivered
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(m *Mailer) {
+		m.retryPolicy = policy
+	}
+}
+
+// Delay returns the delay to wait before the next attempt, given the number of attempts made so far
+func (p RetryPolicy) Delay(attempts int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(p.InitialDelay) * math.Pow(multiplier, float64(attempts-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		return p.MaxDelay
+	}
+
+	return time.Duration(delay)
+}
+
+// retryable returns whether the error is worth retrying according to the policy
+func (p RetryPolicy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+
+	return IsRetryable(err)
+}
+
+// IsRetryable returns whether delivering an email again may succeed after the given error.
+// SMTP 5xx replies are permanent failures (i.e. unknown mailbox) while 4xx replies are transient
+// (i.e. greylisting). Likewise, provider API client errors are permanent, except for rate limiting.
+// Any other error (i.e. a network error) is considered transient.
+func IsRetryable(err error) bool {
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) {
+		return smtpErr.Code < 500
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= http.StatusInternalServerError || apiErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	return true
+}
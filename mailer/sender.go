@@ -0,0 +1,27 @@
+package mailer
+
+import "context"
+
+// Sender is an interface that defines how rendered emails are delivered (i.e. through an SMTP
+// server or the HTTP API of an email provider)
+type Sender interface {
+	// Deliver delivers the emails in order and returns the number of emails delivered
+	// before the first failure
+	Deliver(ctx context.Context, emails []Email) (int, error)
+}
+
+// Email is a struct that holds a rendered email ready to be delivered.
+// The ID is unique and used as the Message-ID of the email when the provider allows it.
+type Email struct {
+	ID          string
+	Template    string
+	From        string
+	To          []string
+	CC          []string
+	BCC         []string
+	ReplyTo     string
+	Subject     string
+	PlainBody   string
+	HTMLBody    string
+	Attachments []Attachment
+}
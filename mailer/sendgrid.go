@@ -0,0 +1,165 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+)
+
+// SendGridAPIURL is the URL of the SendGrid API used to send emails
+const SendGridAPIURL = "https://api.sendgrid.com/v3/mail/send"
+
+// SendGridSender is a Sender which delivers emails through the SendGrid HTTP API
+type SendGridSender struct {
+	apiKey string
+	url    string
+	client *http.Client
+}
+
+// NewSendGridSender creates a new SendGrid sender authenticated with the given API key
+func NewSendGridSender(apiKey string) *SendGridSender {
+	return &SendGridSender{
+		apiKey: apiKey,
+		url:    SendGridAPIURL,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// sendGridAddress is a struct that holds an email address in a SendGrid request
+type sendGridAddress struct {
+	Email string `json:"email"`
+}
+
+// sendGridPersonalization is a struct that holds the recipients of an email in a SendGrid request
+type sendGridPersonalization struct {
+	To  []sendGridAddress `json:"to"`
+	CC  []sendGridAddress `json:"cc,omitempty"`
+	BCC []sendGridAddress `json:"bcc,omitempty"`
+}
+
+// sendGridContent is a struct that holds a body of an email in a SendGrid request
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// sendGridAttachment is a struct that holds an attachment in a SendGrid request
+type sendGridAttachment struct {
+	Content     []byte `json:"content"` // Base64 encoded by encoding/json
+	Type        string `json:"type,omitempty"`
+	Filename    string `json:"filename"`
+	Disposition string `json:"disposition"`
+	ContentID   string `json:"content_id,omitempty"`
+}
+
+// sendGridRequest is a struct that holds the body of a SendGrid send request
+type sendGridRequest struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	ReplyTo          *sendGridAddress          `json:"reply_to,omitempty"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+	Attachments      []sendGridAttachment      `json:"attachments,omitempty"`
+	CustomArgs       map[string]string         `json:"custom_args,omitempty"`
+}
+
+// Deliver sends the emails with one request each
+func (s *SendGridSender) Deliver(ctx context.Context, emails []Email) (int, error) {
+	for sent, email := range emails {
+		if err := s.send(ctx, email); err != nil {
+			return sent, err
+		}
+	}
+
+	return len(emails), nil
+}
+
+// send sends a single email
+func (s *SendGridSender) send(ctx context.Context, email Email) error {
+	body, err := json.Marshal(newSendGridRequest(email))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusMultipleChoices {
+		message, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return &APIError{Provider: "sendgrid", StatusCode: res.StatusCode, Message: string(message)}
+	}
+
+	return nil
+}
+
+// newSendGridRequest converts an email into a SendGrid send request
+func newSendGridRequest(email Email) sendGridRequest {
+	req := sendGridRequest{
+		Personalizations: []sendGridPersonalization{{
+			To:  sendGridAddresses(email.To),
+			CC:  sendGridAddresses(email.CC),
+			BCC: sendGridAddresses(email.BCC),
+		}},
+		From:    sendGridAddress{Email: email.From},
+		Subject: email.Subject,
+		Content: []sendGridContent{
+			{Type: "text/plain", Value: email.PlainBody},
+			{Type: "text/html", Value: email.HTMLBody},
+		},
+	}
+
+	// SendGrid does not allow setting the Message-ID, so our id is attached to its events instead
+	if email.ID != "" {
+		req.CustomArgs = map[string]string{"message_id": email.ID}
+	}
+
+	if email.ReplyTo != "" {
+		req.ReplyTo = &sendGridAddress{Email: email.ReplyTo}
+	}
+
+	for _, attachment := range email.Attachments {
+		sendGridAttachment := sendGridAttachment{
+			Content:     attachment.Data,
+			Type:        attachment.ContentType,
+			Filename:    attachment.Name,
+			Disposition: "attachment",
+		}
+
+		if attachment.Inline {
+			sendGridAttachment.Disposition = "inline"
+			sendGridAttachment.ContentID = attachment.Name
+		}
+
+		req.Attachments = append(req.Attachments, sendGridAttachment)
+	}
+
+	return req
+}
+
+// sendGridAddresses converts email addresses into SendGrid addresses
+func sendGridAddresses(emails []string) []sendGridAddress {
+	if len(emails) == 0 {
+		return nil
+	}
+
+	addresses := make([]sendGridAddress, len(emails))
+	for i, email := range emails {
+		addresses[i] = sendGridAddress{Email: email}
+	}
+
+	return addresses
+}
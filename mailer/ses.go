@@ -0,0 +1,116 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/PlayEconomy37/Play.Common/internal/awssig"
+)
+
+// sesPath is the path of the SES v2 API endpoint used to send emails
+const sesPath = "/v2/email/outbound-emails"
+
+// SESSender is a Sender which delivers emails through the AWS SES v2 HTTP API.
+// Emails are sent as raw MIME messages, so that attachments are supported.
+type SESSender struct {
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+	url             string
+	client          *http.Client
+}
+
+// NewSESSender creates a new SES sender for the given AWS region, authenticated with the given credentials.
+// The session token is only needed with temporary credentials and can be empty.
+func NewSESSender(region, accessKeyID, secretAccessKey, sessionToken string) *SESSender {
+	return &SESSender{
+		region:          region,
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+		sessionToken:    sessionToken,
+		url:             fmt.Sprintf("https://email.%s.amazonaws.com%s", region, sesPath),
+		client:          &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// sesRequest is a struct that holds the body of an SES send request
+type sesRequest struct {
+	FromEmailAddress string `json:"FromEmailAddress"`
+	Destination      struct {
+		ToAddresses  []string `json:"ToAddresses,omitempty"`
+		CcAddresses  []string `json:"CcAddresses,omitempty"`
+		BccAddresses []string `json:"BccAddresses,omitempty"`
+	} `json:"Destination"`
+	Content struct {
+		Raw struct {
+			Data []byte `json:"Data"` // Base64 encoded by encoding/json
+		} `json:"Raw"`
+	} `json:"Content"`
+}
+
+// Deliver sends the emails with one request each
+func (s *SESSender) Deliver(ctx context.Context, emails []Email) (int, error) {
+	for sent, email := range emails {
+		if err := s.send(ctx, email); err != nil {
+			return sent, err
+		}
+	}
+
+	return len(emails), nil
+}
+
+// send sends a single email
+func (s *SESSender) send(ctx context.Context, email Email) error {
+	smtpEmail := newSMTPEmail(email)
+	if err := smtpEmail.GetError(); err != nil {
+		return err
+	}
+
+	var sesReq sesRequest
+	sesReq.FromEmailAddress = email.From
+	sesReq.Destination.ToAddresses = email.To
+	sesReq.Destination.CcAddresses = email.CC
+	sesReq.Destination.BccAddresses = email.BCC
+	sesReq.Content.Raw.Data = []byte(smtpEmail.GetMessage())
+
+	body, err := json.Marshal(sesReq)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	s.sign(req, body, time.Now().UTC())
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusMultipleChoices {
+		message, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return &APIError{Provider: "ses", StatusCode: res.StatusCode, Message: string(message)}
+	}
+
+	return nil
+}
+
+// sign signs the request with AWS Signature Version 4
+func (s *SESSender) sign(req *http.Request, body []byte, now time.Time) {
+	awssig.Sign(req, body, awssig.Credentials{
+		AccessKeyID:     s.accessKeyID,
+		SecretAccessKey: s.secretAccessKey,
+		SessionToken:    s.sessionToken,
+	}, s.region, "ses", now)
+}
@@ -0,0 +1,231 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/toorop/go-dkim"
+	mail "github.com/xhit/go-simple-mail/v2"
+)
+
+// Encryption is a custom type which defines how the connection to the SMTP server is encrypted
+type Encryption string
+
+// Supported encryption modes
+const (
+	EncryptionNone     Encryption = "none"
+	EncryptionSTARTTLS Encryption = "starttls"
+	EncryptionTLS      Encryption = "tls" // Implicit TLS, usually on port 465
+)
+
+// Authentication is a custom type which defines how we authenticate with the SMTP server
+type Authentication string
+
+// Supported authentication types
+const (
+	AuthPlain   Authentication = "plain"
+	AuthLogin   Authentication = "login"
+	AuthCRAMMD5 Authentication = "cram-md5"
+	AuthNone    Authentication = "none"
+)
+
+var (
+	// ErrUnsupportedEncryption is returned when the configured SMTP encryption mode is not supported
+	ErrUnsupportedEncryption = errors.New("unsupported SMTP encryption")
+
+	// ErrUnsupportedAuthentication is returned when the configured SMTP authentication type is not supported
+	ErrUnsupportedAuthentication = errors.New("unsupported SMTP authentication")
+)
+
+// encryptions maps our encryption modes to the ones of the mail library
+var encryptions = map[Encryption]mail.Encryption{
+	EncryptionNone:     mail.EncryptionNone,
+	EncryptionSTARTTLS: mail.EncryptionSTARTTLS,
+	EncryptionTLS:      mail.EncryptionSSLTLS,
+}
+
+// authentications maps our authentication types to the ones of the mail library
+var authentications = map[Authentication]mail.AuthType{
+	AuthPlain:   mail.AuthPlain,
+	AuthLogin:   mail.AuthLogin,
+	AuthCRAMMD5: mail.AuthCRAMMD5,
+	AuthNone:    mail.AuthNone,
+}
+
+// SMTPOption is a function used to configure optional behaviour of an SMTP sender
+type SMTPOption func(*SMTPSender)
+
+// WithSMTPOptions configures the SMTP sender of a mailer created with New
+func WithSMTPOptions(opts ...SMTPOption) Option {
+	return func(m *Mailer) {
+		if sender, ok := m.transport.(*SMTPSender); ok {
+			for _, opt := range opts {
+				opt(sender)
+			}
+		}
+	}
+}
+
+// WithEncryption sets the encryption used to connect to the SMTP server (none by default)
+func WithEncryption(encryption Encryption) SMTPOption {
+	return func(s *SMTPSender) {
+		s.server.Encryption = encryptions[encryption]
+	}
+}
+
+// WithAuthentication sets the authentication type of the SMTP server (plain by default)
+func WithAuthentication(authentication Authentication) SMTPOption {
+	return func(s *SMTPSender) {
+		if authType, ok := authentications[authentication]; ok {
+			s.server.Authentication = authType
+		}
+	}
+}
+
+// WithInsecureSkipVerify disables the verification of the SMTP server certificate.
+// It must only be used in development, i.e. with a local relay using a self-signed certificate.
+func WithInsecureSkipVerify() SMTPOption {
+	return func(s *SMTPSender) {
+		s.server.TLSConfig = &tls.Config{ServerName: s.server.Host, InsecureSkipVerify: true}
+	}
+}
+
+// ParseEncryption returns the encryption mode with the given name (case insensitive).
+// An empty name means no encryption.
+func ParseEncryption(name string) (Encryption, error) {
+	if name == "" {
+		return EncryptionNone, nil
+	}
+
+	encryption := Encryption(strings.ToLower(name))
+	if _, ok := encryptions[encryption]; !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedEncryption, name)
+	}
+
+	return encryption, nil
+}
+
+// ParseAuthentication returns the authentication type with the given name (case insensitive).
+// An empty name means plain authentication.
+func ParseAuthentication(name string) (Authentication, error) {
+	if name == "" {
+		return AuthPlain, nil
+	}
+
+	authentication := Authentication(strings.ToLower(name))
+	if _, ok := authentications[authentication]; !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedAuthentication, name)
+	}
+
+	return authentication, nil
+}
+
+// SMTPSender is a Sender which delivers emails through an SMTP server
+type SMTPSender struct {
+	server *mail.SMTPServer
+	dkim   *dkim.SigOptions
+}
+
+// NewSMTPSender creates a new SMTP sender
+func NewSMTPSender(host string, port int, username, password string, opts ...SMTPOption) *SMTPSender {
+	// Create email server
+	server := mail.NewSMTPClient()
+	server.Host = host
+	server.Port = port
+	server.Username = username
+	server.Password = password
+	server.KeepAlive = false
+	server.ConnectTimeout = 10 * time.Second
+	server.SendTimeout = 10 * time.Second
+
+	sender := &SMTPSender{server: server}
+
+	for _, opt := range opts {
+		opt(sender)
+	}
+
+	return sender
+}
+
+// Deliver sends the emails over a single connection to the SMTP server
+func (s *SMTPSender) Deliver(ctx context.Context, emails []Email) (int, error) {
+	client, err := s.connect()
+	if err != nil {
+		return 0, err
+	}
+
+	for sent, email := range emails {
+		if err := ctx.Err(); err != nil {
+			client.Quit()
+			return sent, err
+		}
+
+		smtpEmail := newSMTPEmail(email)
+		if s.dkim != nil {
+			smtpEmail.SetDkim(*s.dkim)
+		}
+
+		if err := smtpEmail.Send(client); err != nil {
+			// The connection may be unusable after a failure
+			client.Close()
+			return sent, err
+		}
+	}
+
+	client.Quit()
+
+	return len(emails), nil
+}
+
+// connect opens a connection to the SMTP server which is kept alive between emails
+func (s *SMTPSender) connect() (*mail.SMTPClient, error) {
+	client, err := s.server.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	client.KeepAlive = true
+
+	return client, nil
+}
+
+// newSMTPEmail converts an email into an email of the mail library
+func newSMTPEmail(email Email) *mail.Email {
+	smtpEmail := mail.NewMSG()
+	smtpEmail.SetFrom(email.From)
+	smtpEmail.AddTo(email.To...)
+	smtpEmail.SetSubject(email.Subject)
+	smtpEmail.SetBody(mail.TextPlain, email.PlainBody)
+	smtpEmail.AddAlternative(mail.TextHTML, email.HTMLBody)
+
+	if email.ID != "" {
+		smtpEmail.AddHeader("Message-ID", fmt.Sprintf("<%s@%s>", email.ID, addressDomain(email.From)))
+	}
+
+	if len(email.CC) > 0 {
+		smtpEmail.AddCc(email.CC...)
+	}
+
+	if len(email.BCC) > 0 {
+		smtpEmail.AddBcc(email.BCC...)
+	}
+
+	if email.ReplyTo != "" {
+		smtpEmail.SetReplyTo(email.ReplyTo)
+	}
+
+	for _, attachment := range email.Attachments {
+		smtpEmail.Attach(&mail.File{
+			Name:     attachment.Name,
+			MimeType: attachment.ContentType,
+			Data:     attachment.Data,
+			Inline:   attachment.Inline,
+		})
+	}
+
+	return smtpEmail
+}
@@ -0,0 +1,92 @@
+package mailer
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"sync"
+)
+
+// TemplatesDir is the directory of the embedded file system holding the email templates
+const TemplatesDir = "emails"
+
+// requiredBlocks are the templates every email template file must define
+var requiredBlocks = []string{"subject", "plainBody", "htmlBody"}
+
+// ErrInvalidTemplate is returned when an email template file does not define every required block
+var ErrInvalidTemplate = errors.New("invalid email template")
+
+// templateKey is the key of a parsed template file in the cache
+type templateKey struct {
+	fileSystem embed.FS
+	file       string
+	locale     string
+}
+
+// templateCache is a struct which holds the parsed email template files, so that
+// they are only parsed once
+type templateCache struct {
+	sync.RWMutex
+	templates map[templateKey]*template.Template
+}
+
+// newTemplateCache creates a new empty template cache
+func newTemplateCache() *templateCache {
+	return &templateCache{templates: map[templateKey]*template.Template{}}
+}
+
+// get returns the parsed template file of the given file system for the given locale, parsing it if needed
+func (c *templateCache) get(fileSystem embed.FS, file, locale, defaultLocale string) (*template.Template, error) {
+	key := templateKey{fileSystem: fileSystem, file: file, locale: locale}
+
+	c.RLock()
+	tmpl, ok := c.templates[key]
+	c.RUnlock()
+
+	if ok {
+		return tmpl, nil
+	}
+
+	tmpl, err := parseTemplate(fileSystem, resolveTemplatePath(fileSystem, file, locale, defaultLocale), locale)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Lock()
+	c.templates[key] = tmpl
+	c.Unlock()
+
+	return tmpl, nil
+}
+
+// parseTemplate parses the template file with the given path from the embedded file system,
+// with the template functions of the given locale
+func parseTemplate(fileSystem fs.FS, filePath, locale string) (*template.Template, error) {
+	return template.New("email").Funcs(templateFuncs(locale)).ParseFS(fileSystem, filePath)
+}
+
+// ValidateTemplates parses every email template file of the given file system, localized ones
+// included, and checks that they define the subject, plainBody and htmlBody templates. It is meant to be called at startup
+// so that broken templates are detected before sending emails.
+func ValidateTemplates(fileSystem fs.FS) error {
+	return fs.WalkDir(fileSystem, TemplatesDir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		tmpl, err := parseTemplate(fileSystem, filePath, DefaultLocale)
+		if err != nil {
+			return err
+		}
+
+		for _, block := range requiredBlocks {
+			if tmpl.Lookup(block) == nil {
+				return fmt.Errorf("%w: %s does not define %q", ErrInvalidTemplate, filePath, block)
+			}
+		}
+
+		return nil
+	})
+}
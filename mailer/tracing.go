@@ -0,0 +1,71 @@
+package mailer
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the name of the tracer used to instrument our mailer
+const tracerName = "github.com/PlayEconomy37/Play.Common/mailer"
+
+// tracer is the Opentelemetry tracer used by the mailer
+var tracer = otel.Tracer(tracerName)
+
+// startSpan starts a span for sending the given message. Only the domains of the recipients
+// are recorded, so that traces do not contain email addresses.
+func startSpan(ctx context.Context, msg Message) (context.Context, trace.Span) {
+	return tracer.Start(
+		ctx,
+		"mailer send",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("email.template", msg.TemplateFile),
+			attribute.StringSlice("email.recipient_domains", recipientDomains(msg)),
+			attribute.Int("email.recipients", len(msg.To)+len(msg.CC)+len(msg.BCC)),
+		),
+	)
+}
+
+// endSpan records the error, if any, on the span and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// recipientDomains returns the sorted distinct domains of the recipients of the message
+func recipientDomains(msg Message) []string {
+	seen := map[string]bool{}
+	domains := []string{}
+
+	for _, recipients := range [][]string{msg.To, msg.CC, msg.BCC} {
+		for _, recipient := range recipients {
+			domain := addressDomain(recipient)
+
+			if !seen[domain] {
+				seen[domain] = true
+				domains = append(domains, domain)
+			}
+		}
+	}
+
+	sort.Strings(domains)
+
+	return domains
+}
+
+// addressDomain returns the lowercase domain of the given email address
+// (i.e. "Jane <jane@example.com>" has the example.com domain)
+func addressDomain(address string) string {
+	domain := address[strings.LastIndex(address, "@")+1:]
+	return strings.ToLower(strings.TrimRight(domain, "> "))
+}
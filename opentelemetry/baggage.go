@@ -0,0 +1,81 @@
+package opentelemetry
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// Keys of the baggage entries set by the helpers below
+const (
+	UserIDBaggageKey   = "user_id"
+	TenantIDBaggageKey = "tenant_id"
+	OriginBaggageKey   = "origin" // Service where the request entered the system
+)
+
+// SetBaggage returns a copy of the given context whose baggage holds the given entry. Baggage is
+// propagated with the trace context to the services called over HTTP (with NewTransport) and to the
+// consumers of published messages. Values can't contain spaces, commas, semicolons or backslashes.
+func SetBaggage(ctx context.Context, key, value string) (context.Context, error) {
+	member, err := baggage.NewMember(key, value)
+	if err != nil {
+		return ctx, err
+	}
+
+	bag, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		return ctx, err
+	}
+
+	return baggage.ContextWithBaggage(ctx, bag), nil
+}
+
+// GetBaggage returns the value of the baggage entry with the given key, or an empty string if there is none
+func GetBaggage(ctx context.Context, key string) string {
+	return baggage.FromContext(ctx).Member(key).Value()
+}
+
+// WithUserID returns a copy of the given context whose baggage holds the ID of the user making the request
+func WithUserID(ctx context.Context, userID string) (context.Context, error) {
+	return SetBaggage(ctx, UserIDBaggageKey, userID)
+}
+
+// UserID returns the ID of the user making the request held by the baggage of the given context
+func UserID(ctx context.Context) string {
+	return GetBaggage(ctx, UserIDBaggageKey)
+}
+
+// WithTenantID returns a copy of the given context whose baggage holds the ID of the tenant of the request
+func WithTenantID(ctx context.Context, tenantID string) (context.Context, error) {
+	return SetBaggage(ctx, TenantIDBaggageKey, tenantID)
+}
+
+// TenantID returns the ID of the tenant of the request held by the baggage of the given context
+func TenantID(ctx context.Context) string {
+	return GetBaggage(ctx, TenantIDBaggageKey)
+}
+
+// WithOrigin returns a copy of the given context whose baggage holds the origin of the request
+func WithOrigin(ctx context.Context, origin string) (context.Context, error) {
+	return SetBaggage(ctx, OriginBaggageKey, origin)
+}
+
+// Origin returns the origin of the request held by the baggage of the given context
+func Origin(ctx context.Context) string {
+	return GetBaggage(ctx, OriginBaggageKey)
+}
+
+// NewTransport returns an HTTP transport which creates a client span for every request and propagates
+// the trace context and baggage of the request context to the called service, using the given
+// transport (http.DefaultTransport if nil) to send requests
+//
+//	client := &http.Client{Transport: opentelemetry.NewTransport(nil), Timeout: 10 * time.Second}
+func NewTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return otelhttp.NewTransport(base)
+}
@@ -0,0 +1,32 @@
+package opentelemetry
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ObserveWithExemplar observes the given value with the ID of the trace of the context as exemplar,
+// so that dashboards can link an observation to its trace. The value is observed without exemplar
+// if the context holds no sampled span, since unsampled traces can't be looked up.
+func ObserveWithExemplar(ctx context.Context, observer prometheus.Observer, value float64) {
+	spanContext := trace.SpanContextFromContext(ctx)
+
+	exemplarObserver, ok := observer.(prometheus.ExemplarObserver)
+	if !ok || !spanContext.IsSampled() {
+		observer.Observe(value)
+		return
+	}
+
+	exemplarObserver.ObserveWithExemplar(value, prometheus.Labels{"trace_id": spanContext.TraceID().String()})
+}
+
+// MetricsHandler returns the handler serving the metrics of the given gatherer to Prometheus.
+// Exemplars are only exposed in the OpenMetrics format, which Prometheus negotiates when
+// exemplar storage is enabled (--enable-feature=exemplar-storage).
+func MetricsHandler(gatherer prometheus.Gatherer) http.Handler {
+	return promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{EnableOpenMetrics: true})
+}
@@ -0,0 +1,41 @@
+package opentelemetry
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// GRPCMetrics is a struct that holds some prometheus metrics
+// regarding the calls handled by a gRPC server
+type GRPCMetrics struct {
+	HandledCounter            *prometheus.CounterVec
+	HandlingDurationHistogram *prometheus.HistogramVec
+}
+
+// NewGRPCMetrics creates a counter and a histogram used to keep track of the calls handled by the gRPC
+// server of our application (i.e. the service name), registered with the given registerer. Calls are
+// labelled with their full method (i.e. "/inventory.Inventory/GrantItems") and status code, and their
+// durations are observed in seconds with the given buckets (prometheus.DefBuckets if empty). Creating
+// the metrics of an application several times returns the collectors which have already been registered.
+func NewGRPCMetrics(appName string, registerer prometheus.Registerer, buckets []float64) *GRPCMetrics {
+	if len(buckets) == 0 {
+		buckets = prometheus.DefBuckets
+	}
+
+	handledCounter := registerCollector(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: fmt.Sprintf("%s_grpc_server_handled_total", metricName(appName)),
+		Help: "Total gRPC calls handled by the server",
+	}, []string{"method", "code"}))
+
+	handlingDurationHistogram := registerCollector(registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    fmt.Sprintf("%s_grpc_server_handling_seconds", metricName(appName)),
+		Help:    "Duration of the gRPC calls handled by the server in seconds",
+		Buckets: buckets,
+	}, []string{"method", "code"}))
+
+	return &GRPCMetrics{
+		HandledCounter:            handledCounter,
+		HandlingDurationHistogram: handlingDurationHistogram,
+	}
+}
@@ -2,9 +2,19 @@ package opentelemetry
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// MaxRouteLabels is the maximum number of distinct routes for which HTTP metrics are labelled.
+// Requests of further routes are counted under OtherRoute to keep the cardinality of the metrics bounded.
+const MaxRouteLabels = 200
+
+// Labels of the requests which can't be labelled with their route
+const (
+	UnmatchedRoute = "unmatched" // Requests which didn't match any route
+	OtherRoute     = "other"     // Requests of routes beyond MaxRouteLabels
 )
 
 // HTTPMetrics is a struct that holds some prometheus metrics
@@ -12,33 +22,90 @@ import (
 type HTTPMetrics struct {
 	TotalRequestsCounter       *prometheus.CounterVec
 	TotalResponsesCounter      *prometheus.CounterVec
-	TotalProcessingTimeCounter *prometheus.HistogramVec
+	TotalProcessingTimeCounter *prometheus.HistogramVec // Kept for existing dashboards, use RequestDurationHistogram instead
+	RequestDurationHistogram   *prometheus.HistogramVec
+
+	routesMu sync.RWMutex
+	routes   map[string]struct{}
 }
 
 // CreateHTTPMetrics creates counters and histograms used to keep
-// track of HTTP metrics in our application
+// track of HTTP metrics in our application, registered with the default Prometheus registry
 func CreateHTTPMetrics(appName string) *HTTPMetrics {
+	return NewHTTPMetrics(appName, prometheus.DefaultRegisterer, nil)
+}
+
+// NewHTTPMetrics creates counters and histograms used to keep track of HTTP metrics in our
+// application, registered with the given registerer. Request durations are observed in seconds
+// with the given buckets (prometheus.DefBuckets if empty). Creating the metrics of an application
+// several times returns the collectors which have already been registered.
+func NewHTTPMetrics(appName string, registerer prometheus.Registerer, buckets []float64) *HTTPMetrics {
+	if len(buckets) == 0 {
+		buckets = prometheus.DefBuckets
+	}
+
 	// Create total HTTP requests counter
-	totalRequestsCounter := promauto.NewCounterVec(prometheus.CounterOpts{
+	totalRequestsCounter := registerCollector(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name: fmt.Sprintf("%s_total_requests_received", appName),
 		Help: "Total HTTP requests received",
-	}, []string{"method", "url"})
+	}, []string{"method", "url"}))
 
 	// Create HTTP response counter
-	totalResponsesCounter := promauto.NewCounterVec(prometheus.CounterOpts{
+	totalResponsesCounter := registerCollector(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name: fmt.Sprintf("%s_total_responses_sent", appName),
 		Help: "Total HTTP responses sent",
-	}, []string{"method", "url", "statusCode"})
+	}, []string{"method", "url", "statusCode"}))
 
 	// Create HTTP requests duration histogram
-	totalProcessingTimeCounter := promauto.NewHistogramVec(prometheus.HistogramOpts{
+	totalProcessingTimeCounter := registerCollector(registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
 		Name: fmt.Sprintf("%s_total_processing_time_microseconds", appName),
 		Help: "Total processing time of HTTP requests in microseconds",
-	}, []string{"method", "url"})
+	}, []string{"method", "url"}))
+
+	// Create HTTP requests duration histogram in seconds, the unit expected by standard dashboards
+	requestDurationHistogram := registerCollector(registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    fmt.Sprintf("%s_http_request_duration_seconds", appName),
+		Help:    "Duration of HTTP requests in seconds",
+		Buckets: buckets,
+	}, []string{"method", "url", "statusCode"}))
 
 	return &HTTPMetrics{
 		TotalRequestsCounter:       totalRequestsCounter,
 		TotalResponsesCounter:      totalResponsesCounter,
 		TotalProcessingTimeCounter: totalProcessingTimeCounter,
+		RequestDurationHistogram:   requestDurationHistogram,
+		routes:                     map[string]struct{}{},
+	}
+}
+
+// RouteLabel returns the "url" label of the requests matching the given route pattern (i.e. "/items/{id}").
+// Requests which didn't match any route are labelled UnmatchedRoute and, once MaxRouteLabels routes
+// have been seen, requests of new routes are labelled OtherRoute.
+func (m *HTTPMetrics) RouteLabel(pattern string) string {
+	if pattern == "" {
+		return UnmatchedRoute
+	}
+
+	m.routesMu.RLock()
+	_, exists := m.routes[pattern]
+	m.routesMu.RUnlock()
+
+	if exists {
+		return pattern
 	}
+
+	m.routesMu.Lock()
+	defer m.routesMu.Unlock()
+
+	if _, exists := m.routes[pattern]; exists {
+		return pattern
+	}
+
+	if len(m.routes) >= MaxRouteLabels {
+		return OtherRoute
+	}
+
+	m.routes[pattern] = struct{}{}
+
+	return pattern
 }
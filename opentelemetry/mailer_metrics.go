@@ -0,0 +1,55 @@
+package opentelemetry
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MailerMetrics is a struct that holds some prometheus metrics
+// regarding the emails sent by our application
+type MailerMetrics struct {
+	QueuedEmailsCounter  *prometheus.CounterVec
+	SentEmailsCounter    *prometheus.CounterVec
+	FailedEmailsCounter  *prometheus.CounterVec
+	RetriedEmailsCounter *prometheus.CounterVec
+
+	SendDurationHistogram *prometheus.HistogramVec
+}
+
+// CreateMailerMetrics creates counters used to keep
+// track of sent emails in our application
+func CreateMailerMetrics(appName string) *MailerMetrics {
+	queuedEmailsCounter := registerCollector(prometheus.DefaultRegisterer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: fmt.Sprintf("%s_queued_emails_total", appName),
+		Help: "The total number of emails queued to be sent asynchronously",
+	}, []string{"template"}))
+
+	sentEmailsCounter := registerCollector(prometheus.DefaultRegisterer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: fmt.Sprintf("%s_sent_emails_total", appName),
+		Help: "The total number of emails delivered",
+	}, []string{"template"}))
+
+	failedEmailsCounter := registerCollector(prometheus.DefaultRegisterer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: fmt.Sprintf("%s_failed_emails_total", appName),
+		Help: "The total number of emails which could not be sent after all retries",
+	}, []string{"template"}))
+
+	retriedEmailsCounter := registerCollector(prometheus.DefaultRegisterer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: fmt.Sprintf("%s_retried_emails_total", appName),
+		Help: "The total number of attempts to deliver emails which had already failed",
+	}, []string{"template"}))
+
+	sendDurationHistogram := registerCollector(prometheus.DefaultRegisterer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: fmt.Sprintf("%s_email_send_duration_seconds", appName),
+		Help: "The time taken to render and deliver emails, retries included",
+	}, []string{"template"}))
+
+	return &MailerMetrics{
+		QueuedEmailsCounter:   queuedEmailsCounter,
+		SentEmailsCounter:     sentEmailsCounter,
+		FailedEmailsCounter:   failedEmailsCounter,
+		RetriedEmailsCounter:  retriedEmailsCounter,
+		SendDurationHistogram: sendDurationHistogram,
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
 // MessageBrokerMetrics is a struct that holds some prometheus metrics
@@ -13,29 +12,61 @@ type MessageBrokerMetrics struct {
 	IncomingMessagesCounter *prometheus.CounterVec
 	SuccessMessagesCounter  *prometheus.CounterVec
 	ErrorMessagesCounter    *prometheus.CounterVec
+
+	ProcessingDurationHistogram *prometheus.HistogramVec
+
+	DuplicateMessagesCounter *prometheus.CounterVec
+
+	OutgoingMessagesCounter      *prometheus.CounterVec
+	ErrorOutgoingMessagesCounter *prometheus.CounterVec
 }
 
 // CreateMessageBrokerMetrics creates counters used to keep
 // track of message broker metrics in our application
 func CreateMessageBrokerMetrics(appName string) *MessageBrokerMetrics {
-	incomingMessagesCounter := promauto.NewCounterVec(prometheus.CounterOpts{
+	incomingMessagesCounter := registerCollector(prometheus.DefaultRegisterer, prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name: fmt.Sprintf("%s_incoming_messages_total", appName),
 		Help: "The total number of incoming messages",
-	}, []string{"queue"})
+	}, []string{"queue", "event_type"}))
 
-	successMessagesCounter := promauto.NewCounterVec(prometheus.CounterOpts{
+	successMessagesCounter := registerCollector(prometheus.DefaultRegisterer, prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name: fmt.Sprintf("%s_success_incoming_messages_total", appName),
 		Help: "The total number of success incoming success messages",
-	}, []string{"queue"})
+	}, []string{"queue", "event_type"}))
 
-	errorMessagesCounter := promauto.NewCounterVec(prometheus.CounterOpts{
+	errorMessagesCounter := registerCollector(prometheus.DefaultRegisterer, prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name: fmt.Sprintf("%s_error_incoming_message_total", appName),
 		Help: "The total number of error incoming success messages",
-	}, []string{"queue"})
+	}, []string{"queue", "event_type"}))
+
+	processingDurationHistogram := registerCollector(prometheus.DefaultRegisterer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    fmt.Sprintf("%s_message_processing_duration_seconds", appName),
+		Help:    "The time taken to handle incoming messages in seconds",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"queue", "event_type"}))
+
+	duplicateMessagesCounter := registerCollector(prometheus.DefaultRegisterer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: fmt.Sprintf("%s_duplicate_incoming_messages_total", appName),
+		Help: "The total number of incoming messages skipped because they were already processed",
+	}, []string{"consumer"}))
+
+	outgoingMessagesCounter := registerCollector(prometheus.DefaultRegisterer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: fmt.Sprintf("%s_outgoing_messages_total", appName),
+		Help: "The total number of messages published",
+	}, []string{"exchange"}))
+
+	errorOutgoingMessagesCounter := registerCollector(prometheus.DefaultRegisterer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: fmt.Sprintf("%s_error_outgoing_messages_total", appName),
+		Help: "The total number of messages which failed to be published",
+	}, []string{"exchange"}))
 
 	return &MessageBrokerMetrics{
-		IncomingMessagesCounter: incomingMessagesCounter,
-		SuccessMessagesCounter:  successMessagesCounter,
-		ErrorMessagesCounter:    errorMessagesCounter,
+		IncomingMessagesCounter:      incomingMessagesCounter,
+		SuccessMessagesCounter:       successMessagesCounter,
+		ErrorMessagesCounter:         errorMessagesCounter,
+		ProcessingDurationHistogram:  processingDurationHistogram,
+		DuplicateMessagesCounter:     duplicateMessagesCounter,
+		OutgoingMessagesCounter:      outgoingMessagesCounter,
+		ErrorOutgoingMessagesCounter: errorOutgoingMessagesCounter,
 	}
 }
@@ -0,0 +1,66 @@
+package opentelemetry
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// MongoCommandMetrics is a struct that holds some prometheus metrics
+// regarding the MongoDB commands run by our repositories
+type MongoCommandMetrics struct {
+	CommandDurationHistogram *prometheus.HistogramVec
+}
+
+// CreateMongoCommandMetrics creates the histogram used to keep track of
+// the duration of MongoDB commands in our application
+func CreateMongoCommandMetrics(dbName string) *MongoCommandMetrics {
+	commandDurationHistogram := registerCollector(prometheus.DefaultRegisterer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:   mongoNamespace,
+		Subsystem:   "commands",
+		Name:        "duration_seconds",
+		Help:        "The time taken to run MongoDB commands, including the round trip to the server.",
+		ConstLabels: prometheus.Labels{"db_name": dbName},
+	}, []string{"command", "status"}))
+
+	return &MongoCommandMetrics{CommandDurationHistogram: commandDurationHistogram}
+}
+
+// CommandMonitor returns the command monitor that must be set on the mongo client options in order
+// to observe the duration of commands, calling the given monitor (i.e. otelmongo's) as well.
+// Durations hold the ID of the trace of the command as exemplar.
+func (m *MongoCommandMetrics) CommandMonitor(next *event.CommandMonitor) *event.CommandMonitor {
+	if next == nil {
+		next = &event.CommandMonitor{}
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			if next.Started != nil {
+				next.Started(ctx, evt)
+			}
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			m.observe(ctx, evt.CommandFinishedEvent, "succeeded")
+
+			if next.Succeeded != nil {
+				next.Succeeded(ctx, evt)
+			}
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			m.observe(ctx, evt.CommandFinishedEvent, "failed")
+
+			if next.Failed != nil {
+				next.Failed(ctx, evt)
+			}
+		},
+	}
+}
+
+// observe records the duration of a finished command
+func (m *MongoCommandMetrics) observe(ctx context.Context, evt event.CommandFinishedEvent, status string) {
+	duration := time.Duration(evt.DurationNanos).Seconds()
+	ObserveWithExemplar(ctx, m.CommandDurationHistogram.WithLabelValues(evt.CommandName, status), duration)
+}
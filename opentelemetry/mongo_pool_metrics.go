@@ -0,0 +1,235 @@
+package opentelemetry
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+const mongoNamespace = "go_mongo_stats"
+
+// MongoPoolCollector is a struct that implements the prometheus.Collector interface.
+// It keeps track of the MongoDB connection pool through the events emitted by the driver's pool monitor.
+type MongoPoolCollector struct {
+	mutex sync.Mutex
+
+	// Connection pool state per server address
+	open              map[string]int64
+	inUse             map[string]int64
+	waitQueueTimeouts map[string]int64
+	checkoutFailures  map[string]int64
+
+	// Start times of the pending connection checkouts
+	pendingCheckouts map[checkoutKey]time.Time
+
+	// Descriptions of exported metrics
+	openDesc              *prometheus.Desc
+	inUseDesc             *prometheus.Desc
+	idleDesc              *prometheus.Desc
+	waitQueueTimeoutsDesc *prometheus.Desc
+	checkoutFailuresDesc  *prometheus.Desc
+	checkoutDuration      *prometheus.HistogramVec
+}
+
+// checkoutKey identifies a connection checkout. The driver emits the events of a checkout from the
+// goroutine checking out the connection, and in v1.10 they carry no ID matching the end of a checkout
+// with its start, so the goroutine identifies the checkout among the pending ones of the address.
+type checkoutKey struct {
+	address   string
+	goroutine uint64
+}
+
+// NewMongoPoolCollector creates a new MongoPoolCollector
+func NewMongoPoolCollector(dbName string) *MongoPoolCollector {
+	labels := prometheus.Labels{"db_name": dbName}
+	variableLabels := []string{"address"}
+
+	return &MongoPoolCollector{
+		open:              map[string]int64{},
+		inUse:             map[string]int64{},
+		waitQueueTimeouts: map[string]int64{},
+		checkoutFailures:  map[string]int64{},
+		pendingCheckouts:  map[checkoutKey]time.Time{},
+		openDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(mongoNamespace, subsystem, "open"),
+			"The number of established connections both in use and idle.",
+			variableLabels,
+			labels,
+		),
+		inUseDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(mongoNamespace, subsystem, "in_use"),
+			"The number of connections currently checked out of the pool.",
+			variableLabels,
+			labels,
+		),
+		idleDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(mongoNamespace, subsystem, "idle"),
+			"The number of idle connections.",
+			variableLabels,
+			labels,
+		),
+		waitQueueTimeoutsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(mongoNamespace, subsystem, "wait_queue_timeouts"),
+			"The total number of connection checkouts that timed out in the wait queue.",
+			variableLabels,
+			labels,
+		),
+		checkoutFailuresDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(mongoNamespace, subsystem, "checkout_failures"),
+			"The total number of failed connection checkouts.",
+			variableLabels,
+			labels,
+		),
+		checkoutDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   mongoNamespace,
+			Subsystem:   subsystem,
+			Name:        "checkout_duration_seconds",
+			Help:        "The time taken to check out a connection from the pool.",
+			ConstLabels: labels,
+		}, variableLabels),
+	}
+}
+
+// PoolMonitor returns the pool monitor that must be set on the mongo client options
+// in order to feed the collector
+func (c *MongoPoolCollector) PoolMonitor() *event.PoolMonitor {
+	return &event.PoolMonitor{Event: c.handlePoolEvent}
+}
+
+// handlePoolEvent updates the pool state with the given driver event
+func (c *MongoPoolCollector) handlePoolEvent(evt *event.PoolEvent) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	address := evt.Address
+
+	switch evt.Type {
+	case event.ConnectionCreated:
+		c.open[address]++
+	case event.ConnectionClosed:
+		decrement(c.open, address)
+	case event.GetStarted:
+		c.pendingCheckouts[checkoutKey{address: address, goroutine: goroutineID()}] = time.Now()
+	case event.GetSucceeded:
+		c.inUse[address]++
+		c.observeCheckout(address)
+	case event.GetFailed:
+		c.checkoutFailures[address]++
+		if evt.Reason == event.ReasonTimedOut {
+			c.waitQueueTimeouts[address]++
+		}
+		c.observeCheckout(address)
+	case event.ConnectionReturned:
+		decrement(c.inUse, address)
+	case event.PoolClosedEvent:
+		delete(c.open, address)
+		delete(c.inUse, address)
+
+		for key := range c.pendingCheckouts {
+			if key.address == address {
+				delete(c.pendingCheckouts, key)
+			}
+		}
+	}
+}
+
+// observeCheckout records the duration of the checkout of the current goroutine for the given address
+func (c *MongoPoolCollector) observeCheckout(address string) {
+	key := checkoutKey{address: address, goroutine: goroutineID()}
+
+	start, exists := c.pendingCheckouts[key]
+	if !exists {
+		return
+	}
+
+	c.checkoutDuration.WithLabelValues(address).Observe(time.Since(start).Seconds())
+	delete(c.pendingCheckouts, key)
+}
+
+// decrement decrements the gauge of the given address unless it has no entry, which happens for the
+// connections closed or returned after the pool of the address was closed
+func decrement(gauge map[string]int64, address string) {
+	if _, exists := gauge[address]; exists {
+		gauge[address]--
+	}
+}
+
+// goroutineID returns the ID of the current goroutine, read from the header of its stack trace
+// (i.e. "goroutine 18 [running]:")
+func goroutineID() uint64 {
+	var buf [64]byte
+	stack := buf[:runtime.Stack(buf[:], false)]
+
+	stack = bytes.TrimPrefix(stack, []byte("goroutine "))
+	if end := bytes.IndexByte(stack, ' '); end != -1 {
+		stack = stack[:end]
+	}
+
+	id, _ := strconv.ParseUint(string(stack), 10, 64)
+
+	return id
+}
+
+// Describe implements the prometheus.Collector interface.
+func (c *MongoPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.openDesc
+	ch <- c.inUseDesc
+	ch <- c.idleDesc
+	ch <- c.waitQueueTimeoutsDesc
+	ch <- c.checkoutFailuresDesc
+	c.checkoutDuration.Describe(ch)
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *MongoPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for address, open := range c.open {
+		inUse := c.inUse[address]
+
+		ch <- prometheus.MustNewConstMetric(
+			c.openDesc,
+			prometheus.GaugeValue,
+			float64(open),
+			address,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.inUseDesc,
+			prometheus.GaugeValue,
+			float64(inUse),
+			address,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.idleDesc,
+			prometheus.GaugeValue,
+			float64(open-inUse),
+			address,
+		)
+	}
+
+	for address, timeouts := range c.waitQueueTimeouts {
+		ch <- prometheus.MustNewConstMetric(
+			c.waitQueueTimeoutsDesc,
+			prometheus.CounterValue,
+			float64(timeouts),
+			address,
+		)
+	}
+
+	for address, failures := range c.checkoutFailures {
+		ch <- prometheus.MustNewConstMetric(
+			c.checkoutFailuresDesc,
+			prometheus.CounterValue,
+			float64(failures),
+			address,
+		)
+	}
+
+	c.checkoutDuration.Collect(ch)
+}
@@ -0,0 +1,113 @@
+package opentelemetry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+func TestMongoPoolCollectorPoolClosed(t *testing.T) {
+	c := NewMongoPoolCollector("test")
+	monitor := c.PoolMonitor()
+
+	for _, eventType := range []string{
+		event.ConnectionCreated,
+		event.ConnectionCreated,
+		event.GetSucceeded,
+		event.PoolClosedEvent,
+		event.ConnectionReturned,
+		event.ConnectionClosed,
+		event.ConnectionClosed,
+	} {
+		monitor.Event(&event.PoolEvent{Type: eventType, Address: "localhost:27017"})
+	}
+
+	if open, exists := c.open["localhost:27017"]; exists {
+		t.Errorf("want no open connections entry; got %d", open)
+	}
+
+	if inUse, exists := c.inUse["localhost:27017"]; exists {
+		t.Errorf("want no in use connections entry; got %d", inUse)
+	}
+}
+
+func TestMongoPoolCollectorUnknownAddress(t *testing.T) {
+	c := NewMongoPoolCollector("test")
+	monitor := c.PoolMonitor()
+
+	monitor.Event(&event.PoolEvent{Type: event.ConnectionReturned, Address: "localhost:27017"})
+	monitor.Event(&event.PoolEvent{Type: event.ConnectionClosed, Address: "localhost:27017"})
+
+	if open, exists := c.open["localhost:27017"]; exists {
+		t.Errorf("want no open connections entry; got %d", open)
+	}
+
+	if inUse, exists := c.inUse["localhost:27017"]; exists {
+		t.Errorf("want no in use connections entry; got %d", inUse)
+	}
+}
+
+func TestMongoPoolCollectorCheckoutDuration(t *testing.T) {
+	c := NewMongoPoolCollector("test")
+	monitor := c.PoolMonitor()
+
+	const address = "localhost:27017"
+
+	started := make(chan struct{})
+	quickDone := make(chan struct{})
+	slowDone := make(chan struct{})
+
+	// A slow checkout waits in the queue while a quick one starts and succeeds
+	go func() {
+		defer close(slowDone)
+
+		monitor.Event(&event.PoolEvent{Type: event.GetStarted, Address: address})
+		close(started)
+
+		time.Sleep(20 * time.Millisecond)
+		<-quickDone
+		time.Sleep(20 * time.Millisecond)
+
+		monitor.Event(&event.PoolEvent{Type: event.GetFailed, Address: address, Reason: event.ReasonTimedOut})
+	}()
+
+	go func() {
+		defer close(quickDone)
+
+		<-started
+		time.Sleep(20 * time.Millisecond)
+
+		monitor.Event(&event.PoolEvent{Type: event.GetStarted, Address: address})
+		monitor.Event(&event.PoolEvent{Type: event.GetSucceeded, Address: address, ConnectionID: 1})
+	}()
+
+	<-slowDone
+
+	var metric dto.Metric
+	if err := c.checkoutDuration.WithLabelValues(address).(prometheus.Histogram).Write(&metric); err != nil {
+		t.Fatal(err)
+	}
+
+	histogram := metric.GetHistogram()
+	if got := histogram.GetSampleCount(); got != 2 {
+		t.Fatalf("want 2 checkouts; got %d", got)
+	}
+
+	// Each checkout is matched with its own start: the quick one is below 10ms, and the slow one lasts 40ms at least
+	for _, bucket := range histogram.GetBucket() {
+		if bucket.GetUpperBound() == 0.01 && bucket.GetCumulativeCount() != 1 {
+			t.Errorf("want 1 checkout within 10ms; got %d", bucket.GetCumulativeCount())
+		}
+	}
+
+	if got := histogram.GetSampleSum(); got < 0.04 {
+		t.Errorf("want the checkouts to last 40ms at least; got %v", got)
+	}
+
+	if len(c.pendingCheckouts) != 0 {
+		t.Errorf("want no pending checkout; got %d", len(c.pendingCheckouts))
+	}
+}
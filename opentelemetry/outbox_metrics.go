@@ -0,0 +1,54 @@
+package opentelemetry
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// OutboxMetrics is a struct that holds some prometheus metrics
+// regarding the relay of our transactional outbox
+type OutboxMetrics struct {
+	PendingMessagesGauge   prometheus.Gauge
+	LagGauge               prometheus.Gauge
+	RelayedMessagesCounter prometheus.Counter
+	ErrorMessagesCounter   prometheus.Counter
+	LeaderGauge            prometheus.Gauge
+}
+
+// CreateOutboxMetrics creates gauges and counters used to keep
+// track of the outbox relay in our application
+func CreateOutboxMetrics(appName string) *OutboxMetrics {
+	pendingMessagesGauge := registerCollector(prometheus.DefaultRegisterer, prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: fmt.Sprintf("%s_outbox_pending_messages", appName),
+		Help: "The number of outbox messages waiting to be relayed",
+	}))
+
+	lagGauge := registerCollector(prometheus.DefaultRegisterer, prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: fmt.Sprintf("%s_outbox_lag_seconds", appName),
+		Help: "The age of the oldest outbox message waiting to be relayed",
+	}))
+
+	relayedMessagesCounter := registerCollector(prometheus.DefaultRegisterer, prometheus.NewCounter(prometheus.CounterOpts{
+		Name: fmt.Sprintf("%s_outbox_relayed_messages_total", appName),
+		Help: "The total number of outbox messages relayed to the message broker",
+	}))
+
+	errorMessagesCounter := registerCollector(prometheus.DefaultRegisterer, prometheus.NewCounter(prometheus.CounterOpts{
+		Name: fmt.Sprintf("%s_outbox_error_messages_total", appName),
+		Help: "The total number of outbox messages which failed to be relayed",
+	}))
+
+	leaderGauge := registerCollector(prometheus.DefaultRegisterer, prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: fmt.Sprintf("%s_outbox_relay_leader", appName),
+		Help: "Whether this replica is currently the outbox relay leader (1) or not (0)",
+	}))
+
+	return &OutboxMetrics{
+		PendingMessagesGauge:   pendingMessagesGauge,
+		LagGauge:               lagGauge,
+		RelayedMessagesCounter: relayedMessagesCounter,
+		ErrorMessagesCounter:   errorMessagesCounter,
+		LeaderGauge:            leaderGauge,
+	}
+}
@@ -0,0 +1,27 @@
+package opentelemetry
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// registerCollector registers the given collector with the registerer and returns it. If an equal
+// collector has already been registered (i.e. when metrics are created twice), the existing collector
+// is returned instead, so that metrics can be created as many times as needed. It panics if the
+// collector conflicts with a different collector.
+func registerCollector[C prometheus.Collector](registerer prometheus.Registerer, collector C) C {
+	err := registerer.Register(collector)
+	if err == nil {
+		return collector
+	}
+
+	alreadyRegisteredErr := prometheus.AlreadyRegisteredError{}
+	if errors.As(err, &alreadyRegisteredErr) {
+		if existing, ok := alreadyRegisteredErr.ExistingCollector.(C); ok {
+			return existing
+		}
+	}
+
+	panic(err)
+}
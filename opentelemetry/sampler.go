@@ -0,0 +1,130 @@
+package opentelemetry
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PlayEconomy37/Play.Common/configuration"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newSampler creates the sampler selected in the configuration. Traces are sampled with the configured
+// ratio unless their parent span was sampled by another service, in which case its decision is kept.
+// Spans of the configured routes are always sampled, and spans of unsampled traces are recorded
+// (but not exported) if errors must always be sampled.
+func newSampler(cfg *configuration.Config) sdktrace.Sampler {
+	tracingCfg := cfg.Tracing
+
+	ratio := tracingCfg.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+
+	sampler := sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))
+
+	if len(tracingCfg.SampledRoutes) > 0 {
+		sampler = routeSampler{routes: tracingCfg.SampledRoutes, fallback: sampler}
+	}
+
+	if tracingCfg.SampleErrors {
+		sampler = recordingSampler{fallback: sampler}
+	}
+
+	return sampler
+}
+
+// routeSampler is a sampler which always samples spans of the given routes and leaves
+// the decision to the fallback sampler for other spans
+type routeSampler struct {
+	routes   []string
+	fallback sdktrace.Sampler
+}
+
+// ShouldSample samples the span if its name, HTTP route or HTTP target matches one of the routes.
+// Routes ending with "*" match every path starting with them, i.e. "/purchase/*".
+func (s routeSampler) ShouldSample(params sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	candidates := []string{params.Name}
+	for _, attr := range params.Attributes {
+		if attr.Key == semconv.HTTPRouteKey || attr.Key == semconv.HTTPTargetKey {
+			candidates = append(candidates, attr.Value.AsString())
+		}
+	}
+
+	for _, route := range s.routes {
+		for _, candidate := range candidates {
+			if matchRoute(route, candidate) {
+				return sdktrace.SamplingResult{
+					Decision:   sdktrace.RecordAndSample,
+					Tracestate: trace.SpanContextFromContext(params.ParentContext).TraceState(),
+				}
+			}
+		}
+	}
+
+	return s.fallback.ShouldSample(params)
+}
+
+// Description returns information describing the sampler
+func (s routeSampler) Description() string {
+	return fmt.Sprintf("RouteSampler{%s,%s}", strings.Join(s.routes, ","), s.fallback.Description())
+}
+
+// matchRoute returns whether the given value matches a route, which can end with a "*" wildcard
+func matchRoute(route, value string) bool {
+	if strings.HasSuffix(route, "*") {
+		return strings.HasPrefix(value, strings.TrimSuffix(route, "*"))
+	}
+
+	return route == value
+}
+
+// recordingSampler is a sampler which records the spans dropped by the fallback sampler,
+// so that errorSpanProcessor can export them if they end with an error
+type recordingSampler struct {
+	fallback sdktrace.Sampler
+}
+
+// ShouldSample records the span instead of dropping it if the fallback sampler doesn't sample it
+func (s recordingSampler) ShouldSample(params sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	result := s.fallback.ShouldSample(params)
+	if result.Decision == sdktrace.Drop {
+		result.Decision = sdktrace.RecordOnly
+	}
+
+	return result
+}
+
+// Description returns information describing the sampler
+func (s recordingSampler) Description() string {
+	return fmt.Sprintf("RecordingSampler{%s}", s.fallback.Description())
+}
+
+// errorSpanProcessor is a span processor which passes sampled spans to the wrapped processor,
+// along with the recorded spans of unsampled traces which ended with an error
+type errorSpanProcessor struct {
+	sdktrace.SpanProcessor
+}
+
+// OnEnd passes the span to the wrapped processor if it is sampled or if it ended with an error
+func (p errorSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	switch {
+	case s.SpanContext().IsSampled():
+		p.SpanProcessor.OnEnd(s)
+	case s.Status().Code == codes.Error:
+		p.SpanProcessor.OnEnd(sampledSpan{s})
+	}
+}
+
+// sampledSpan is a recorded span which is flagged as sampled so that it gets exported
+type sampledSpan struct {
+	sdktrace.ReadOnlySpan
+}
+
+// SpanContext returns the span context of the span with the sampled flag set
+func (s sampledSpan) SpanContext() trace.SpanContext {
+	spanContext := s.ReadOnlySpan.SpanContext()
+	return spanContext.WithTraceFlags(spanContext.TraceFlags().WithSampled(true))
+}
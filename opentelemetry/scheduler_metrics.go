@@ -0,0 +1,47 @@
+package opentelemetry
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// SchedulerMetrics is a struct that holds some prometheus metrics
+// regarding the jobs run by the scheduler of our application
+type SchedulerMetrics struct {
+	RunsCounter          *prometheus.CounterVec
+	RunDurationHistogram *prometheus.HistogramVec
+	LastSuccessGauge     *prometheus.GaugeVec
+}
+
+// NewSchedulerMetrics creates the metrics used to keep track of the jobs run by the scheduler of our
+// application (i.e. the service name), registered with the given registerer. Runs are labelled with
+// the job name and their result (success, error, panic or skipped), and their durations are observed
+// in seconds with the given buckets (prometheus.DefBuckets if empty).
+func NewSchedulerMetrics(appName string, registerer prometheus.Registerer, buckets []float64) *SchedulerMetrics {
+	if len(buckets) == 0 {
+		buckets = prometheus.DefBuckets
+	}
+
+	runsCounter := registerCollector(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: fmt.Sprintf("%s_scheduler_job_runs_total", metricName(appName)),
+		Help: "Total runs of the scheduled jobs, skipped runs included",
+	}, []string{"job", "result"}))
+
+	runDurationHistogram := registerCollector(registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    fmt.Sprintf("%s_scheduler_job_duration_seconds", metricName(appName)),
+		Help:    "Duration of the runs of the scheduled jobs in seconds",
+		Buckets: buckets,
+	}, []string{"job"}))
+
+	lastSuccessGauge := registerCollector(registerer, prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: fmt.Sprintf("%s_scheduler_job_last_success_timestamp_seconds", metricName(appName)),
+		Help: "Unix time of the last successful run of the scheduled jobs",
+	}, []string{"job"}))
+
+	return &SchedulerMetrics{
+		RunsCounter:          runsCounter,
+		RunDurationHistogram: runDurationHistogram,
+		LastSuccessGauge:     lastSuccessGauge,
+	}
+}
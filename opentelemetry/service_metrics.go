@@ -0,0 +1,133 @@
+package opentelemetry
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Unit is a custom type which holds the unit of a metric, appended to its name
+type Unit string
+
+// Units of metrics, following the Prometheus naming conventions
+const (
+	UnitNone    Unit = ""
+	UnitSeconds Unit = "seconds"
+	UnitBytes   Unit = "bytes"
+	UnitRatio   Unit = "ratio"
+)
+
+// MetricOpts is a struct that holds the declaration of a business metric
+type MetricOpts struct {
+	Name    string    // i.e. "items_purchased", without the service prefix and the unit suffix
+	Help    string    // Description of the metric
+	Unit    Unit      // Appended to the name, i.e. UnitSeconds
+	Labels  []string  // Names of the labels of the metric
+	Buckets []float64 // Histograms only, prometheus.DefBuckets if empty
+}
+
+// ServiceMetrics is a struct used by services to declare their business metrics with a consistent
+// naming convention: <service>_<name>_<unit>, with a "_total" suffix for counters
+// (i.e. "inventory_items_granted_total"). Metrics are registered the first time they are declared
+// and the same collector is returned when they are declared again, so they can be declared where
+// they are used.
+type ServiceMetrics struct {
+	prefix     string
+	registerer prometheus.Registerer
+
+	mu         sync.Mutex
+	collectors map[string]prometheus.Collector
+}
+
+// NewServiceMetrics creates a new ServiceMetrics for the given service, whose metrics
+// are registered with the given registerer (prometheus.DefaultRegisterer if nil)
+func NewServiceMetrics(serviceName string, registerer prometheus.Registerer) *ServiceMetrics {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	return &ServiceMetrics{
+		prefix:     metricName(serviceName),
+		registerer: registerer,
+		collectors: map[string]prometheus.Collector{},
+	}
+}
+
+// Counter returns the counter with the given declaration, registering it if needed
+func (m *ServiceMetrics) Counter(opts MetricOpts) *prometheus.CounterVec {
+	name := m.name(opts) + "_total"
+
+	return declare(m, name, func() *prometheus.CounterVec {
+		return prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: opts.Help}, opts.Labels)
+	})
+}
+
+// Gauge returns the gauge with the given declaration, registering it if needed
+func (m *ServiceMetrics) Gauge(opts MetricOpts) *prometheus.GaugeVec {
+	name := m.name(opts)
+
+	return declare(m, name, func() *prometheus.GaugeVec {
+		return prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name, Help: opts.Help}, opts.Labels)
+	})
+}
+
+// Histogram returns the histogram with the given declaration, registering it if needed
+func (m *ServiceMetrics) Histogram(opts MetricOpts) *prometheus.HistogramVec {
+	name := m.name(opts)
+
+	buckets := opts.Buckets
+	if len(buckets) == 0 {
+		buckets = prometheus.DefBuckets
+	}
+
+	return declare(m, name, func() *prometheus.HistogramVec {
+		return prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: name, Help: opts.Help, Buckets: buckets}, opts.Labels)
+	})
+}
+
+// name returns the full name of the metric with the given declaration
+func (m *ServiceMetrics) name(opts MetricOpts) string {
+	name := m.prefix + "_" + metricName(opts.Name)
+	if opts.Unit != UnitNone && !strings.HasSuffix(name, "_"+string(opts.Unit)) {
+		name += "_" + string(opts.Unit)
+	}
+
+	return name
+}
+
+// declare returns the collector with the given name, creating and registering it if needed.
+// It panics if a metric of another type has already been declared with the same name.
+func declare[C prometheus.Collector](m *ServiceMetrics, name string, create func() C) C {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if collector, exists := m.collectors[name]; exists {
+		typed, ok := collector.(C)
+		if !ok {
+			panic(fmt.Sprintf("opentelemetry: metric %q has already been declared as a %T", name, collector))
+		}
+
+		return typed
+	}
+
+	collector := registerCollector(m.registerer, create())
+	m.collectors[name] = collector
+
+	return collector
+}
+
+// metricName converts a name into a valid Prometheus metric name, i.e. "Play.Catalog" gives "play_catalog"
+func metricName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		default:
+			return '_'
+		}
+	}, name)
+}
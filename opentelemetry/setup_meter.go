@@ -0,0 +1,118 @@
+package opentelemetry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/PlayEconomy37/Play.Common/configuration"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/metric/global"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"google.golang.org/grpc/credentials"
+)
+
+// PrometheusExporter is the default metric exporter, which only exposes metrics to Prometheus.
+// The OTLP exporters also push metrics to an OTLP endpoint.
+const PrometheusExporter = "Prometheus"
+
+// SetupMeter sets up the Opentelemetry metrics SDK, used by instrumented libraries (i.e. otelhttp and
+// otelsql) and services through the global meter provider. Metrics share the resource of the traces
+// and are exposed with a Prometheus collector registered with the given registerer (prometheus.DefaultRegisterer
+// if nil), next to the existing Prometheus metrics. They are also pushed to the configured endpoint
+// with the OTLP exporters.
+func SetupMeter(cfg *configuration.Config, registerer prometheus.Registerer) (*sdkmetric.MeterProvider, error) {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	prometheusExporter := otelprometheus.New()
+
+	opts := []sdkmetric.Option{
+		sdkmetric.WithResource(NewResource(cfg)),
+		sdkmetric.WithReader(prometheusExporter),
+	}
+
+	pushExporter, err := newMetricExporter(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if pushExporter != nil {
+		readerOpts := []sdkmetric.PeriodicReaderOption{}
+		if interval := cfg.Metrics.ExportInterval; interval > 0 {
+			readerOpts = append(readerOpts, sdkmetric.WithInterval(interval))
+		}
+
+		opts = append(opts, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(pushExporter, readerOpts...)))
+	}
+
+	meterProvider := sdkmetric.NewMeterProvider(opts...)
+
+	if err := registerer.Register(prometheusExporter.Collector); err != nil {
+		return nil, err
+	}
+
+	global.SetMeterProvider(meterProvider)
+
+	return meterProvider, nil
+}
+
+// newMetricExporter creates the exporter pushing metrics to the OTLP endpoint selected in the configuration.
+// It returns a nil exporter if metrics are only exposed to Prometheus. The headers and TLS settings
+// of the tracing configuration are used, since traces and metrics are usually sent to the same backend.
+func newMetricExporter(cfg *configuration.Config) (sdkmetric.Exporter, error) {
+	metricsCfg, tracingCfg := cfg.Metrics, cfg.Tracing
+	endpoint, path, insecure := splitEndpoint(metricsCfg.Endpoint)
+	insecure = insecure || tracingCfg.Insecure
+
+	switch exporter := metricsCfg.Exporter; {
+	case exporter == "" || strings.EqualFold(exporter, PrometheusExporter):
+		return nil, nil
+	case strings.EqualFold(exporter, OTLPExporter):
+		opts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithHeaders(tracingCfg.Headers)}
+		if endpoint != "" {
+			opts = append(opts, otlpmetricgrpc.WithEndpoint(endpoint))
+		}
+
+		if insecure {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		} else {
+			tlsConfig, err := exporterTLSConfig(tracingCfg.CACertFile)
+			if err != nil {
+				return nil, err
+			}
+
+			opts = append(opts, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+		}
+
+		return otlpmetricgrpc.New(context.Background(), opts...)
+	case strings.EqualFold(exporter, OTLPHTTPExporter):
+		opts := []otlpmetrichttp.Option{otlpmetrichttp.WithHeaders(tracingCfg.Headers)}
+		if endpoint != "" {
+			opts = append(opts, otlpmetrichttp.WithEndpoint(endpoint))
+		}
+
+		if path != "" {
+			opts = append(opts, otlpmetrichttp.WithURLPath(path))
+		}
+
+		if insecure {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		} else {
+			tlsConfig, err := exporterTLSConfig(tracingCfg.CACertFile)
+			if err != nil {
+				return nil, err
+			}
+
+			opts = append(opts, otlpmetrichttp.WithTLSClientConfig(tlsConfig))
+		}
+
+		return otlpmetrichttp.New(context.Background(), opts...)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedExporter, exporter)
+	}
+}
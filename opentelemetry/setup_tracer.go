@@ -1,10 +1,9 @@
 package opentelemetry
 
 import (
-	"log"
-
+	"github.com/PlayEconomy37/Play.Common/configuration"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
@@ -12,41 +11,64 @@ import (
 	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
 )
 
-// SetupTracer sets up Opentelemetry with a Jaeger exporter or an in-memory exporter (when running tests)
-func SetupTracer(isTest bool) *sdktrace.TracerProvider {
-	var tracerProvider *sdktrace.TracerProvider
+// SetupTracer sets up Opentelemetry with the exporter selected in the configuration
+// or an in-memory exporter (when running tests)
+func SetupTracer(cfg *configuration.Config, isTest bool) (*sdktrace.TracerProvider, error) {
+	opts := []sdktrace.TracerProviderOption{sdktrace.WithResource(NewResource(cfg))}
 
 	if isTest {
-		// Setup in-memory exporter
-		inMemoryExporter := tracetest.NewInMemoryExporter()
-
-		tracerProvider = sdktrace.NewTracerProvider(
+		// Setup in-memory exporter which receives every span
+		opts = append(
+			opts,
 			sdktrace.WithSampler(sdktrace.AlwaysSample()),
-			sdktrace.WithSyncer(inMemoryExporter),
-			sdktrace.WithResource(resource.NewWithAttributes(
-				semconv.SchemaURL,
-				semconv.ServiceNameKey.String("catalog"),
-			)),
+			sdktrace.WithSyncer(tracetest.NewInMemoryExporter()),
 		)
 	} else {
-		// Setup Jaeger
-		jaegerExporter, err := jaeger.New(jaeger.WithCollectorEndpoint(jaeger.WithEndpoint("http://localhost:14268/api/traces")))
+		// Setup configured exporter. Spans are still created but not exported if tracing is disabled.
+		exporter, err := newSpanExporter(cfg)
 		if err != nil {
-			log.Fatalf("could not initialize Jaeger exporter: %v", err)
+			return nil, err
 		}
 
-		tracerProvider = sdktrace.NewTracerProvider(
-			sdktrace.WithSampler(sdktrace.AlwaysSample()),
-			sdktrace.WithBatcher(jaegerExporter),
-			sdktrace.WithResource(resource.NewWithAttributes(
-				semconv.SchemaURL,
-				semconv.ServiceNameKey.String("catalog"),
-			)),
-		)
+		opts = append(opts, sdktrace.WithSampler(newSampler(cfg)))
+
+		if exporter != nil {
+			var processor sdktrace.SpanProcessor = sdktrace.NewBatchSpanProcessor(exporter)
+			if cfg.Tracing.SampleErrors {
+				processor = errorSpanProcessor{processor}
+			}
+
+			opts = append(opts, sdktrace.WithSpanProcessor(processor))
+		}
 	}
 
+	tracerProvider := sdktrace.NewTracerProvider(opts...)
+
 	otel.SetTracerProvider(tracerProvider)
 	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
 
-	return tracerProvider
+	return tracerProvider, nil
+}
+
+// NewResource creates the resource describing the service in telemetry data, from the service name,
+// version and environment and the extra resource attributes of the configuration
+func NewResource(cfg *configuration.Config) *resource.Resource {
+	attributes := make([]attribute.KeyValue, 0, len(cfg.Tracing.ResourceAttributes)+3)
+
+	// Extra attributes come first so that they can't override the attributes below
+	for key, value := range cfg.Tracing.ResourceAttributes {
+		attributes = append(attributes, attribute.String(key, value))
+	}
+
+	attributes = append(attributes, semconv.ServiceNameKey.String(cfg.ServiceName))
+
+	if cfg.ServiceVersion != "" {
+		attributes = append(attributes, semconv.ServiceVersionKey.String(cfg.ServiceVersion))
+	}
+
+	if cfg.Environment != "" {
+		attributes = append(attributes, semconv.DeploymentEnvironmentKey.String(cfg.Environment))
+	}
+
+	return resource.NewWithAttributes(semconv.SchemaURL, attributes...)
 }
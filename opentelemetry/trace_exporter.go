@@ -0,0 +1,140 @@
+package opentelemetry
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/PlayEconomy37/Play.Common/configuration"
+	"go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/credentials"
+)
+
+// Trace exporters which can be selected in the configuration
+const (
+	JaegerExporter   = "Jaeger"
+	OTLPExporter     = "OTLP" // OTLP over gRPC
+	OTLPHTTPExporter = "OTLPHttp"
+	StdoutExporter   = "Stdout"
+	NoneExporter     = "None"
+)
+
+// defaultJaegerEndpoint is the collector endpoint used by the Jaeger exporter if none is configured
+const defaultJaegerEndpoint = "http://localhost:14268/api/traces"
+
+// ErrUnsupportedExporter is returned when the configured trace exporter is not supported
+var ErrUnsupportedExporter = errors.New("unsupported trace exporter")
+
+// newSpanExporter creates the span exporter selected in the configuration (Jaeger by default).
+// It returns a nil exporter if tracing is disabled.
+func newSpanExporter(cfg *configuration.Config) (sdktrace.SpanExporter, error) {
+	tracingCfg := cfg.Tracing
+
+	switch exporter := tracingCfg.Exporter; {
+	case exporter == "" || strings.EqualFold(exporter, JaegerExporter):
+		endpoint := tracingCfg.Endpoint
+		if endpoint == "" {
+			endpoint = defaultJaegerEndpoint
+		}
+
+		return jaeger.New(jaeger.WithCollectorEndpoint(jaeger.WithEndpoint(endpoint)))
+	case strings.EqualFold(exporter, OTLPExporter):
+		endpoint, _, insecure := splitEndpoint(tracingCfg.Endpoint)
+
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithHeaders(tracingCfg.Headers)}
+		if endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(endpoint))
+		}
+
+		if insecure || tracingCfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		} else {
+			tlsConfig, err := exporterTLSConfig(tracingCfg.CACertFile)
+			if err != nil {
+				return nil, err
+			}
+
+			opts = append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+		}
+
+		return otlptracegrpc.New(context.Background(), opts...)
+	case strings.EqualFold(exporter, OTLPHTTPExporter):
+		endpoint, path, insecure := splitEndpoint(tracingCfg.Endpoint)
+
+		opts := []otlptracehttp.Option{otlptracehttp.WithHeaders(tracingCfg.Headers)}
+		if endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(endpoint))
+		}
+
+		if path != "" {
+			opts = append(opts, otlptracehttp.WithURLPath(path))
+		}
+
+		if insecure || tracingCfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		} else {
+			tlsConfig, err := exporterTLSConfig(tracingCfg.CACertFile)
+			if err != nil {
+				return nil, err
+			}
+
+			opts = append(opts, otlptracehttp.WithTLSClientConfig(tlsConfig))
+		}
+
+		return otlptracehttp.New(context.Background(), opts...)
+	case strings.EqualFold(exporter, StdoutExporter):
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case strings.EqualFold(exporter, NoneExporter):
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedExporter, exporter)
+	}
+}
+
+// splitEndpoint splits an OTLP endpoint given as a URL (i.e. "https://api.honeycomb.io/v1/traces")
+// into its host, path and whether it is insecure. Endpoints without a scheme are returned as is.
+func splitEndpoint(endpoint string) (string, string, bool) {
+	if !strings.Contains(endpoint, "://") {
+		return endpoint, "", false
+	}
+
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return endpoint, "", false
+	}
+
+	return endpointURL.Host, strings.TrimSuffix(endpointURL.Path, "/"), endpointURL.Scheme == "http"
+}
+
+// exporterTLSConfig creates the TLS configuration used to connect to the tracing backend.
+// The given CA certificate is trusted in place of the system pool if provided.
+func exporterTLSConfig(caCertFile string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if caCertFile == "" {
+		return tlsConfig, nil
+	}
+
+	caCert, err := os.ReadFile(caCertFile)
+	if err != nil {
+		return nil, err
+	}
+
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("no certificate found in %s", caCertFile)
+	}
+
+	tlsConfig.RootCAs = certPool
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,69 @@
+package sanitize
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/text/unicode/norm"
+)
+
+// TrimSpace removes leading and trailing white space
+func TrimSpace(value string) string {
+	return strings.TrimSpace(value)
+}
+
+// NormalizeWhitespace trims a string and replaces every sequence of white space characters
+// (including new lines and tabs) by a single space
+func NormalizeWhitespace(value string) string {
+	return strings.Join(strings.FieldsFunc(value, unicode.IsSpace), " ")
+}
+
+// ToLower returns the string with all Unicode letters mapped to their lower case.
+// It is used to normalize email addresses before storing and comparing them.
+func ToLower(value string) string {
+	return strings.ToLower(value)
+}
+
+// NFC returns the Unicode NFC normalization of a string, so that strings which look identical
+// (i.e. "é" written with one or two code points) are stored identically
+func NFC(value string) string {
+	return norm.NFC.String(value)
+}
+
+// StripHTML removes HTML tags, comments, scripts and styles from a string and returns its
+// text content, with HTML entities decoded
+func StripHTML(value string) string {
+	if !strings.ContainsAny(value, "<&") {
+		return value
+	}
+
+	var builder strings.Builder
+
+	tokenizer := html.NewTokenizer(strings.NewReader(value))
+	skipping := atom.Atom(0)
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			// The tokenizer returns an error token once the whole string has been read
+			return builder.String()
+		case html.TextToken:
+			if skipping == 0 {
+				builder.Write(tokenizer.Text())
+			}
+		case html.StartTagToken:
+			// The content of scripts and styles isn't text
+			if name, _ := tokenizer.TagName(); skipping == 0 {
+				if tag := atom.Lookup(name); tag == atom.Script || tag == atom.Style {
+					skipping = tag
+				}
+			}
+		case html.EndTagToken:
+			if name, _ := tokenizer.TagName(); atom.Lookup(name) == skipping {
+				skipping = 0
+			}
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package sanitize
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Tag is the name of the struct tag holding the sanitizers of a field
+const Tag = "sanitize"
+
+// sanitizers holds the sanitizers which can be used in sanitize tags
+var sanitizers = map[string]func(string) string{
+	"trim":       TrimSpace,
+	"whitespace": NormalizeWhitespace,
+	"lower":      ToLower,
+	"html":       StripHTML,
+	"nfc":        NFC,
+}
+
+// Struct applies the sanitizers declared in the `sanitize` tag of the string fields of the given
+// pointer to struct, in order, i.e. `sanitize:"html,whitespace"` or `sanitize:"trim,lower"`.
+// Pointers to strings, slices of strings and nested structs are sanitized as well.
+// It panics if a tag refers to an unknown sanitizer.
+func Struct(s any) {
+	value := reflect.ValueOf(s)
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("sanitize: Struct called with %T, expected a pointer to struct", s))
+	}
+
+	sanitizeStruct(value.Elem())
+}
+
+// sanitizeStruct applies the sanitizers of the fields of an addressable struct value
+func sanitizeStruct(value reflect.Value) {
+	t := value.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		tag := f.Tag.Get(Tag)
+		if tag == "-" {
+			continue
+		}
+
+		sanitizeValue(value.Field(i), parseTag(f, tag))
+	}
+}
+
+// sanitizeValue applies the given sanitizers to a string value, or to the strings held by a
+// pointer or slice, and sanitizes nested structs
+func sanitizeValue(value reflect.Value, fns []func(string) string) {
+	switch value.Kind() {
+	case reflect.String:
+		if len(fns) > 0 {
+			value.SetString(apply(value.String(), fns))
+		}
+	case reflect.Pointer:
+		if !value.IsNil() {
+			sanitizeValue(value.Elem(), fns)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			sanitizeValue(value.Index(i), fns)
+		}
+	case reflect.Struct:
+		sanitizeStruct(value)
+	}
+}
+
+// parseTag returns the sanitizers of a sanitize tag
+func parseTag(f reflect.StructField, tag string) []func(string) string {
+	if tag == "" {
+		return nil
+	}
+
+	names := strings.Split(tag, ",")
+	fns := make([]func(string) string, 0, len(names))
+
+	for _, name := range names {
+		fn, ok := sanitizers[strings.TrimSpace(name)]
+		if !ok {
+			panic(fmt.Sprintf("sanitize: unknown sanitizer %q on field %s", name, f.Name))
+		}
+
+		fns = append(fns, fn)
+	}
+
+	return fns
+}
+
+// apply applies the given sanitizers to a string, in order
+func apply(value string, fns []func(string) string) string {
+	for _, fn := range fns {
+		value = fn(value)
+	}
+
+	return value
+}
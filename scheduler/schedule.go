@@ -0,0 +1,260 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSchedule is returned when a schedule expression can't be parsed
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// Schedule is an interface that defines when a job runs
+type Schedule interface {
+	// Next returns the next time the job runs after the given time
+	Next(after time.Time) time.Time
+}
+
+// intervalSchedule is a Schedule running a job at a fixed interval
+type intervalSchedule struct {
+	interval time.Duration
+}
+
+// Every returns a Schedule running a job at the given interval, rounded to the second (one second at least)
+func Every(interval time.Duration) Schedule {
+	if interval < time.Second {
+		interval = time.Second
+	}
+
+	return intervalSchedule{interval: interval.Round(time.Second)}
+}
+
+// Next returns the given time plus the interval, truncated to the second
+func (s intervalSchedule) Next(after time.Time) time.Time {
+	return after.Add(s.interval).Truncate(time.Second)
+}
+
+// cronSchedule is a Schedule running a job at the times matching a cron expression. Every field is a set
+// of bits, bit n being set if the value n matches.
+type cronSchedule struct {
+	minutes, hours, daysOfMonth, months, daysOfWeek uint64
+
+	// Whether a day matches when either its day of month or day of week does, which is the case
+	// when both fields are restricted, like in cron
+	eitherDay bool
+}
+
+// cronField is the range of values of a field of a cron expression, along with the names of its values
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField     = cronField{name: "minute", min: 0, max: 59}
+	hourField       = cronField{name: "hour", min: 0, max: 23}
+	dayOfMonthField = cronField{name: "day of month", min: 1, max: 31}
+	monthField      = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Sunday is both 0 and 7
+	dayOfWeekField = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// descriptors are the predefined cron expressions
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a schedule expression, which is either a standard cron expression with five fields
+// (minute, hour, day of month, month and day of week, i.e. "*/15 2-4 * * MON-FRI"), a predefined
+// expression (i.e. "@daily") or an interval (i.e. "@every 30s"). Cron expressions are evaluated in
+// the location of the scheduler.
+func Parse(expression string) (Schedule, error) {
+	expression = strings.TrimSpace(expression)
+
+	if interval, found := cutPrefix(expression, "@every "); found {
+		duration, err := time.ParseDuration(strings.TrimSpace(interval))
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("%w: %q has an invalid interval", ErrInvalidSchedule, expression)
+		}
+
+		return Every(duration), nil
+	}
+
+	if descriptor, exists := descriptors[strings.ToLower(expression)]; exists {
+		expression = descriptor
+	}
+
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: %q should have 5 fields", ErrInvalidSchedule, expression)
+	}
+
+	var (
+		schedule cronSchedule
+		err      error
+	)
+
+	if schedule.minutes, err = parseField(fields[0], minuteField); err != nil {
+		return nil, err
+	}
+
+	if schedule.hours, err = parseField(fields[1], hourField); err != nil {
+		return nil, err
+	}
+
+	if schedule.daysOfMonth, err = parseField(fields[2], dayOfMonthField); err != nil {
+		return nil, err
+	}
+
+	if schedule.months, err = parseField(fields[3], monthField); err != nil {
+		return nil, err
+	}
+
+	if schedule.daysOfWeek, err = parseField(fields[4], dayOfWeekField); err != nil {
+		return nil, err
+	}
+
+	// Sunday can be written 7
+	if schedule.daysOfWeek&(1<<7) != 0 {
+		schedule.daysOfWeek |= 1
+	}
+
+	schedule.eitherDay = !strings.HasPrefix(fields[2], "*") && !strings.HasPrefix(fields[4], "*")
+
+	return schedule, nil
+}
+
+// MustParse is like Parse but panics if the expression can't be parsed. It simplifies the registration
+// of jobs with constant expressions.
+func MustParse(expression string) Schedule {
+	schedule, err := Parse(expression)
+	if err != nil {
+		panic(err)
+	}
+
+	return schedule
+}
+
+// parseField parses a field of a cron expression made of comma separated values, ranges (i.e. "1-5")
+// and steps (i.e. "*/10" or "0-30/5"), and returns the matching values as a set of bits
+func parseField(value string, field cronField) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(value, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return 0, fmt.Errorf("%w: invalid step %q in %s field", ErrInvalidSchedule, part, field.name)
+			}
+		}
+
+		start, end := field.min, field.max
+
+		if rangePart != "*" {
+			startPart, endPart, isRange := strings.Cut(rangePart, "-")
+
+			var err error
+			if start, err = field.parseValue(startPart); err != nil {
+				return 0, err
+			}
+
+			end = start
+
+			if isRange {
+				if end, err = field.parseValue(endPart); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				// "5/10" means from 5 to the maximum every 10
+				end = field.max
+			}
+
+			if end < start {
+				return 0, fmt.Errorf("%w: invalid range %q in %s field", ErrInvalidSchedule, part, field.name)
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+// parseValue parses a value of the field, which is a number or a name (i.e. "MON")
+func (f cronField) parseValue(value string) (int, error) {
+	if named, exists := f.names[strings.ToLower(value)]; exists {
+		return named, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number < f.min || number > f.max {
+		return 0, fmt.Errorf("%w: invalid value %q in %s field", ErrInvalidSchedule, value, f.name)
+	}
+
+	return number, nil
+}
+
+// Next returns the first time matching the expression after the given time, or the zero time if there is
+// none within five years (i.e. "0 0 30 2 *")
+func (s cronSchedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case s.months&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case s.hours&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case s.minutes&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// matchesDay returns whether the day of the given time matches the day of month and day of week fields
+func (s cronSchedule) matchesDay(t time.Time) bool {
+	dayOfMonth := s.daysOfMonth&(1<<uint(t.Day())) != 0
+	dayOfWeek := s.daysOfWeek&(1<<uint(t.Weekday())) != 0
+
+	if s.eitherDay {
+		return dayOfMonth || dayOfWeek
+	}
+
+	return dayOfMonth && dayOfWeek
+}
+
+// cutPrefix returns the given string without the given prefix (compared case-insensitively) and whether
+// it had the prefix
+func cutPrefix(s, prefix string) (string, bool) {
+	if len(s) < len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) {
+		return s, false
+	}
+
+	return s[len(prefix):], true
+}
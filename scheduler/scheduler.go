@@ -0,0 +1,464 @@
+// Package scheduler runs recurring jobs on cron expressions or intervals, with panic recovery, tracing,
+// metrics, overlap prevention and optionally a single run across the replicas of a service.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/PlayEconomy37/Play.Common/locks"
+	"github.com/PlayEconomy37/Play.Common/logger"
+	"github.com/PlayEconomy37/Play.Common/opentelemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the name of the tracer used to instrument the runs of the jobs
+const tracerName = "github.com/PlayEconomy37/Play.Common/scheduler"
+
+// tracer is the Opentelemetry tracer creating a span for every run of a job
+var tracer = otel.Tracer(tracerName)
+
+const (
+	// DrainTimeout is the time given to running jobs to complete once the scheduler is stopped,
+	// before their context is cancelled, if none is given to WithDrainTimeout
+	DrainTimeout = 30 * time.Second
+
+	// DefaultLockTTL is the time to live of the lock of a job if none is given to WithLock, unless the job
+	// runs more often than every two minutes, in which case it is half the time between two runs
+	DefaultLockTTL = time.Minute
+)
+
+// Results of the runs of a job, used as metric label
+const (
+	resultSuccess = "success"
+	resultError   = "error"
+	resultPanic   = "panic"
+	resultSkipped = "skipped"
+)
+
+var (
+	// ErrDuplicateJob is returned when adding a job whose name is already used
+	ErrDuplicateJob = errors.New("duplicate job")
+
+	// ErrSchedulerStarted is returned when adding a job once the scheduler is running
+	ErrSchedulerStarted = errors.New("scheduler already started")
+
+	// ErrInvalidLockTTL is returned when adding a job whose lock would outlive the time between two runs
+	ErrInvalidLockTTL = errors.New("lock TTL not shorter than the time between two runs")
+)
+
+// lockTTLCheckedRuns is the number of upcoming runs whose spacing is compared to the lock TTL of a job
+// when it is added, so that cron expressions whose runs aren't evenly spaced are checked too
+const lockTTLCheckedRuns = 100
+
+// Job is a function run by the scheduler. Its context is cancelled when it times out,
+// when it loses its lock or when it doesn't complete within the drain timeout of the shutdown.
+type Job func(ctx context.Context) error
+
+// Option is a function used to configure optional behaviour of a Scheduler
+type Option func(*Scheduler)
+
+// WithLogger makes the scheduler log failing and skipped runs with the given logger
+func WithLogger(logger *logger.Logger) Option {
+	return func(s *Scheduler) {
+		s.logger = logger
+	}
+}
+
+// WithMetrics makes the scheduler keep track of the runs of its jobs in the given metrics
+func WithMetrics(metrics *opentelemetry.SchedulerMetrics) Option {
+	return func(s *Scheduler) {
+		s.metrics = metrics
+	}
+}
+
+// WithLocation sets the location in which cron expressions are evaluated (time.Local by default)
+func WithLocation(location *time.Location) Option {
+	return func(s *Scheduler) {
+		s.location = location
+	}
+}
+
+// WithDrainTimeout sets the time given to running jobs to complete once the scheduler is stopped
+// (DrainTimeout by default)
+func WithDrainTimeout(timeout time.Duration) Option {
+	return func(s *Scheduler) {
+		s.drainTimeout = timeout
+	}
+}
+
+// JobOption is a function used to configure optional behaviour of a job
+type JobOption func(*job)
+
+// WithTimeout cancels the context of the runs of the job after the given timeout
+func WithTimeout(timeout time.Duration) JobOption {
+	return func(j *job) {
+		j.timeout = timeout
+	}
+}
+
+// WithLock makes a single replica of the service run the job at every scheduled time, the one acquiring
+// the lock named after the job. The lock is held for the given time to live at least (DefaultLockTTL if
+// empty), which should be longer than the clock skew between replicas and must be shorter than the time
+// between two runs, so that a quick run doesn't keep the lock until the next one. It is renewed while
+// the job runs longer than that.
+func WithLock(locker locks.Locker, ttl time.Duration) JobOption {
+	return func(j *job) {
+		j.locker = locker
+		j.lockTTL = ttl
+	}
+}
+
+// WhenLeader makes the job run only on the replica which is the leader of the given election, whose
+// campaign is run separately (i.e. with Campaign). It suits jobs whose runs shouldn't depend on which
+// replica fires first, unlike WithLock.
+func WhenLeader(election *locks.Election) JobOption {
+	return func(j *job) {
+		j.election = election
+	}
+}
+
+// job is a struct that holds a job registered with a Scheduler
+type job struct {
+	name     string
+	schedule Schedule
+	fn       Job
+	timeout  time.Duration
+	locker   locks.Locker
+	lockTTL  time.Duration
+	election *locks.Election
+	running  atomic.Bool
+}
+
+// Scheduler is a struct which runs registered jobs at the times of their schedule. A run is skipped
+// when the previous run of the same job is still in progress, so that runs of a job never overlap.
+type Scheduler struct {
+	logger       *logger.Logger
+	metrics      *opentelemetry.SchedulerMetrics
+	location     *time.Location
+	drainTimeout time.Duration
+
+	mu      sync.Mutex
+	jobs    []*job
+	names   map[string]bool
+	started bool
+}
+
+// New creates a new Scheduler. Jobs are added before calling Run.
+func New(opts ...Option) *Scheduler {
+	s := &Scheduler{
+		location:     time.Local,
+		drainTimeout: DrainTimeout,
+		names:        map[string]bool{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Add registers a job running at the times of the given schedule expression (see Parse)
+//
+//	err := s.Add("purge-expired-tokens", "@every 1h", tokens.DeleteExpired, scheduler.WithLock(locker, time.Minute))
+func (s *Scheduler) Add(name, expression string, fn Job, opts ...JobOption) error {
+	schedule, err := Parse(expression)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", name, err)
+	}
+
+	return s.Schedule(name, schedule, fn, opts...)
+}
+
+// Schedule registers a job running at the times of the given schedule. It returns ErrInvalidLockTTL
+// if the job has a lock whose given time to live isn't shorter than the time between two of its runs.
+func (s *Scheduler) Schedule(name string, schedule Schedule, fn Job, opts ...JobOption) error {
+	j := &job{name: name, schedule: schedule, fn: fn}
+	for _, opt := range opts {
+		opt(j)
+	}
+
+	if j.locker != nil {
+		interval := s.shortestInterval(schedule)
+
+		switch {
+		case j.lockTTL <= 0:
+			j.lockTTL = DefaultLockTTL
+			if interval > 0 && interval <= 2*DefaultLockTTL {
+				j.lockTTL = interval / 2
+			}
+		case interval > 0 && j.lockTTL >= interval:
+			return fmt.Errorf("%w: job %s has a lock TTL of %s and runs every %s", ErrInvalidLockTTL, name, j.lockTTL, interval)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return ErrSchedulerStarted
+	}
+
+	if s.names[name] {
+		return fmt.Errorf("%w: %s", ErrDuplicateJob, name)
+	}
+
+	s.names[name] = true
+	s.jobs = append(s.jobs, j)
+
+	return nil
+}
+
+// Run runs the registered jobs at the times of their schedule until the given context is cancelled,
+// then waits for the running jobs to complete
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	s.started = true
+	jobs := s.jobs
+	s.mu.Unlock()
+
+	// Running jobs outlive the given context for the drain timeout
+	jobsCtx, cancelJobs := context.WithCancel(context.Background())
+	defer cancelJobs()
+
+	var loops, runs sync.WaitGroup
+
+	for _, j := range jobs {
+		loops.Add(1)
+
+		go func(j *job) {
+			defer loops.Done()
+			s.loop(ctx, jobsCtx, j, &runs)
+		}(j)
+	}
+
+	loops.Wait()
+
+	drained := make(chan struct{})
+	go func() {
+		runs.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-time.After(s.drainTimeout):
+		s.logWarning("Scheduled jobs did not complete before the drain timeout", nil)
+		cancelJobs()
+		<-drained
+	}
+}
+
+// shortestInterval returns the shortest time between two of the upcoming runs of the given schedule,
+// or 0 if it doesn't run twice
+func (s *Scheduler) shortestInterval(schedule Schedule) time.Duration {
+	var shortest time.Duration
+
+	previous := schedule.Next(time.Now().In(s.location))
+
+	for i := 0; i < lockTTLCheckedRuns && !previous.IsZero(); i++ {
+		next := schedule.Next(previous)
+		if next.IsZero() {
+			break
+		}
+
+		if interval := next.Sub(previous); shortest == 0 || interval < shortest {
+			shortest = interval
+		}
+
+		previous = next
+	}
+
+	return shortest
+}
+
+// loop triggers the runs of the given job at the times of its schedule until the given context is cancelled
+func (s *Scheduler) loop(ctx, jobsCtx context.Context, j *job, runs *sync.WaitGroup) {
+	for {
+		next := j.schedule.Next(time.Now().In(s.location))
+		if next.IsZero() {
+			s.logWarning("Scheduled job will never run", map[string]string{"job": j.name})
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		// Only the leader runs the job
+		if j.election != nil && !j.election.IsLeader() {
+			continue
+		}
+
+		// Skip the run if the previous one is still in progress
+		if !j.running.CompareAndSwap(false, true) {
+			s.record(j.name, resultSkipped)
+			s.logWarning("Scheduled job skipped since its previous run is still in progress", map[string]string{"job": j.name})
+
+			continue
+		}
+
+		runs.Add(1)
+
+		go func() {
+			defer runs.Done()
+			defer j.running.Store(false)
+
+			s.run(jobsCtx, j)
+		}()
+	}
+}
+
+// run runs the given job once, unless another replica holds its lock
+func (s *Scheduler) run(ctx context.Context, j *job) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if j.locker != nil {
+		lock, err := j.locker.Acquire(ctx, "scheduler:"+j.name, j.lockTTL)
+		if err != nil {
+			if errors.Is(err, locks.ErrLockNotAcquired) {
+				// Another replica runs the job
+				return
+			}
+
+			s.record(j.name, resultError)
+			s.logError(ctx, fmt.Errorf("unable to acquire the lock of job %s: %w", j.name, err), j.name)
+
+			return
+		}
+
+		start := time.Now()
+		stopRenewing := s.renewLock(ctx, cancel, j, lock)
+
+		defer func() {
+			stopRenewing()
+
+			// Keep the lock until it expires if the job was quick, so that replicas whose clock is
+			// slightly late don't run the job again. The lock TTL being shorter than the time between
+			// two runs, it expires before the next run.
+			if time.Since(start) >= j.lockTTL {
+				releaseCtx, cancelRelease := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancelRelease()
+
+				j.locker.Release(releaseCtx, lock)
+			}
+		}()
+	}
+
+	if j.timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, j.timeout)
+		defer cancelTimeout()
+	}
+
+	ctx, span := tracer.Start(
+		ctx,
+		j.name+" run",
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attribute.String("job.name", j.name)),
+	)
+	defer span.End()
+
+	start := time.Now()
+	result := resultSuccess
+
+	err := func() (err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				result = resultPanic
+				err = fmt.Errorf("%s", recovered)
+			}
+		}()
+
+		return j.fn(ctx)
+	}()
+
+	if err != nil {
+		if result != resultPanic {
+			result = resultError
+		}
+
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		s.logError(ctx, err, j.name)
+	}
+
+	s.record(j.name, result)
+
+	if s.metrics != nil {
+		opentelemetry.ObserveWithExemplar(ctx, s.metrics.RunDurationHistogram.WithLabelValues(j.name), time.Since(start).Seconds())
+
+		if err == nil {
+			s.metrics.LastSuccessGauge.WithLabelValues(j.name).SetToCurrentTime()
+		}
+	}
+}
+
+// renewLock renews the given lock of the given job while it runs, and cancels the run if the lock is lost.
+// It returns a function stopping the renewal.
+func (s *Scheduler) renewLock(ctx context.Context, cancel context.CancelFunc, j *job, lock *locks.Lock) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(j.lockTTL / 2)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			if err := j.locker.Renew(ctx, lock, j.lockTTL); err != nil {
+				s.logError(ctx, fmt.Errorf("lost the lock of job %s: %w", j.name, err), j.name)
+				cancel()
+
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// record counts a run of the given job with the given result
+func (s *Scheduler) record(name, result string) {
+	if s.metrics != nil {
+		s.metrics.RunsCounter.WithLabelValues(name, result).Inc()
+	}
+}
+
+// logError logs the given error of a run of the given job if the scheduler has a logger
+func (s *Scheduler) logError(ctx context.Context, err error, name string) {
+	if s.logger != nil {
+		s.logger.ErrorCtx(ctx, err, map[string]string{"job": name})
+	}
+}
+
+// logWarning logs the given message if the scheduler has a logger
+func (s *Scheduler) logWarning(message string, properties map[string]string) {
+	if s.logger != nil {
+		s.logger.Warning(message, properties)
+	}
+}
@@ -0,0 +1,367 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/PlayEconomy37/Play.Common/locks"
+	"github.com/PlayEconomy37/Play.Common/opentelemetry"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// tickSchedule is a Schedule running a job at a fixed interval below one second, unlike Every
+type tickSchedule time.Duration
+
+// Next returns the given time plus the interval
+func (s tickSchedule) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(s))
+}
+
+// fakeLocker is an in-memory locks.Locker recording its calls
+type fakeLocker struct {
+	mu         sync.Mutex
+	acquireErr error
+	renewErr   error
+	acquired   []string
+	releases   int
+}
+
+func (l *fakeLocker) Acquire(ctx context.Context, name string, ttl time.Duration) (*locks.Lock, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.acquireErr != nil {
+		return nil, l.acquireErr
+	}
+
+	l.acquired = append(l.acquired, name)
+
+	return &locks.Lock{Name: name, Owner: "test", Token: int64(len(l.acquired)), ExpiresAt: time.Now().Add(ttl)}, nil
+}
+
+func (l *fakeLocker) Renew(ctx context.Context, lock *locks.Lock, ttl time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.renewErr
+}
+
+func (l *fakeLocker) Release(ctx context.Context, lock *locks.Lock) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.releases++
+
+	return nil
+}
+
+// newTestScheduler creates a scheduler keeping track of the runs of its jobs in its own metrics registry
+func newTestScheduler(t *testing.T, opts ...Option) (*Scheduler, *opentelemetry.SchedulerMetrics) {
+	t.Helper()
+
+	metrics := opentelemetry.NewSchedulerMetrics("test", prometheus.NewRegistry(), nil)
+
+	return New(append([]Option{WithMetrics(metrics)}, opts...)...), metrics
+}
+
+// runScheduler runs the scheduler in the background and returns a function stopping it, which waits
+// for Run to return
+func runScheduler(s *Scheduler) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		s.Run(ctx)
+	}()
+
+	return func() {
+		cancel()
+		<-stopped
+	}
+}
+
+// waitFor waits for the given condition to be true, failing the test after a second
+func waitFor(t *testing.T, description string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", description)
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSchedulerSkipsOverlappingRuns(t *testing.T) {
+	s, metrics := newTestScheduler(t)
+
+	var runs atomic.Int32
+	release := make(chan struct{})
+
+	err := s.Schedule("slow", tickSchedule(10*time.Millisecond), func(ctx context.Context) error {
+		runs.Add(1)
+		<-release
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stop := runScheduler(s)
+
+	waitFor(t, "a skipped run", func() bool {
+		return testutil.ToFloat64(metrics.RunsCounter.WithLabelValues("slow", resultSkipped)) > 0
+	})
+
+	if got := runs.Load(); got != 1 {
+		t.Errorf("want 1 run while the first one is in progress; got %d", got)
+	}
+
+	close(release)
+	stop()
+
+	if got := testutil.ToFloat64(metrics.RunsCounter.WithLabelValues("slow", resultSuccess)); got < 1 {
+		t.Errorf("want at least 1 successful run; got %v", got)
+	}
+}
+
+func TestSchedulerRecoversPanics(t *testing.T) {
+	s, metrics := newTestScheduler(t)
+
+	var runs atomic.Int32
+
+	err := s.Schedule("panicking", tickSchedule(10*time.Millisecond), func(ctx context.Context) error {
+		runs.Add(1)
+		panic("boom")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stop := runScheduler(s)
+
+	// The scheduler keeps running the job after it panicked
+	waitFor(t, "a second run", func() bool {
+		return runs.Load() >= 2
+	})
+
+	stop()
+
+	if got := testutil.ToFloat64(metrics.RunsCounter.WithLabelValues("panicking", resultPanic)); got < 2 {
+		t.Errorf("want at least 2 panicking runs; got %v", got)
+	}
+}
+
+func TestSchedulerDrainTimeout(t *testing.T) {
+	s, _ := newTestScheduler(t, WithDrainTimeout(50*time.Millisecond))
+
+	started := make(chan struct{})
+	var once sync.Once
+	var cancelled atomic.Bool
+
+	err := s.Schedule("stuck", tickSchedule(10*time.Millisecond), func(ctx context.Context) error {
+		once.Do(func() { close(started) })
+
+		<-ctx.Done()
+		cancelled.Store(true)
+
+		return ctx.Err()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stop := runScheduler(s)
+	<-started
+
+	begin := time.Now()
+	stop()
+
+	if elapsed := time.Since(begin); elapsed < 50*time.Millisecond || elapsed > time.Second {
+		t.Errorf("want Run to return after the drain timeout; got %s", elapsed)
+	}
+
+	if !cancelled.Load() {
+		t.Error("want the context of the running job to be cancelled after the drain timeout")
+	}
+}
+
+func TestSchedulerDrainCompletesRunningJobs(t *testing.T) {
+	s, metrics := newTestScheduler(t)
+
+	started := make(chan struct{})
+	var once sync.Once
+
+	err := s.Schedule("quick", tickSchedule(10*time.Millisecond), func(ctx context.Context) error {
+		once.Do(func() { close(started) })
+
+		select {
+		case <-time.After(20 * time.Millisecond):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stop := runScheduler(s)
+	<-started
+	stop()
+
+	if got := testutil.ToFloat64(metrics.RunsCounter.WithLabelValues("quick", resultError)); got != 0 {
+		t.Errorf("want the running job to complete without error; got %v errors", got)
+	}
+}
+
+func TestSchedulerWithLock(t *testing.T) {
+	s, metrics := newTestScheduler(t)
+	locker := &fakeLocker{}
+
+	err := s.Schedule("locked", tickSchedule(50*time.Millisecond), func(ctx context.Context) error {
+		return nil
+	}, WithLock(locker, 20*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stop := runScheduler(s)
+
+	waitFor(t, "a successful run", func() bool {
+		return testutil.ToFloat64(metrics.RunsCounter.WithLabelValues("locked", resultSuccess)) > 0
+	})
+
+	stop()
+
+	locker.mu.Lock()
+	defer locker.mu.Unlock()
+
+	if len(locker.acquired) == 0 || locker.acquired[0] != "scheduler:locked" {
+		t.Errorf("want the lock scheduler:locked to be acquired; got %v", locker.acquired)
+	}
+
+	// Quick runs keep the lock until it expires
+	if locker.releases != 0 {
+		t.Errorf("want the lock of quick runs to be kept; got %d releases", locker.releases)
+	}
+}
+
+func TestSchedulerWithLockHeldByAnotherReplica(t *testing.T) {
+	s, metrics := newTestScheduler(t)
+	locker := &fakeLocker{acquireErr: locks.ErrLockNotAcquired}
+
+	var runs atomic.Int32
+
+	err := s.Schedule("locked", tickSchedule(10*time.Millisecond), func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	}, WithLock(locker, 5*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stop := runScheduler(s)
+	time.Sleep(50 * time.Millisecond)
+	stop()
+
+	if got := runs.Load(); got != 0 {
+		t.Errorf("want no run while another replica holds the lock; got %d", got)
+	}
+
+	if got := testutil.ToFloat64(metrics.RunsCounter.WithLabelValues("locked", resultError)); got != 0 {
+		t.Errorf("want no error while another replica holds the lock; got %v", got)
+	}
+}
+
+func TestSchedulerWithLockLost(t *testing.T) {
+	s, _ := newTestScheduler(t)
+	locker := &fakeLocker{renewErr: locks.ErrLockLost}
+
+	cancelled := make(chan struct{})
+	var once sync.Once
+
+	err := s.Schedule("long", tickSchedule(50*time.Millisecond), func(ctx context.Context) error {
+		<-ctx.Done()
+		once.Do(func() { close(cancelled) })
+
+		return ctx.Err()
+	}, WithLock(locker, 20*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stop := runScheduler(s)
+	defer stop()
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("want the run to be cancelled once its lock is lost")
+	}
+}
+
+func TestScheduleLockTTL(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule Schedule
+		ttl      time.Duration
+		wantErr  error
+	}{
+		{name: "Shorter than the interval", schedule: Every(time.Hour), ttl: time.Minute},
+		{name: "Default TTL", schedule: MustParse("0 3 * * *")},
+		{name: "Equal to the interval", schedule: Every(time.Minute), ttl: time.Minute, wantErr: ErrInvalidLockTTL},
+		{name: "Longer than the interval", schedule: Every(30 * time.Second), ttl: time.Minute, wantErr: ErrInvalidLockTTL},
+		{name: "Longer than the shortest cron interval", schedule: MustParse("0,5 3 * * *"), ttl: 10 * time.Minute, wantErr: ErrInvalidLockTTL},
+		{name: "Never running twice", schedule: MustParse("0 0 30 2 *"), ttl: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New()
+
+			err := s.Schedule("locked", tt.schedule, func(ctx context.Context) error { return nil }, WithLock(&fakeLocker{}, tt.ttl))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("want %v; got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScheduleDefaultLockTTL(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule Schedule
+		want     time.Duration
+	}{
+		{name: "Every minute", schedule: Every(time.Minute), want: 30 * time.Second},
+		{name: "Every minute cron", schedule: MustParse("* * * * *"), want: 30 * time.Second},
+		{name: "Every 10 seconds", schedule: Every(10 * time.Second), want: 5 * time.Second},
+		{name: "Every 2 minutes", schedule: Every(2 * time.Minute), want: DefaultLockTTL},
+		{name: "Daily", schedule: MustParse("0 3 * * *"), want: DefaultLockTTL},
+		{name: "Never running twice", schedule: MustParse("0 0 30 2 *"), want: DefaultLockTTL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New()
+
+			err := s.Schedule("locked", tt.schedule, func(ctx context.Context) error { return nil }, WithLock(&fakeLocker{}, 0))
+			if err != nil {
+				t.Fatalf("want no error; got %v", err)
+			}
+
+			if got := s.jobs[0].lockTTL; got != tt.want {
+				t.Errorf("want a lock TTL of %s; got %s", tt.want, got)
+			}
+		})
+	}
+}
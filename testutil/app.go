@@ -0,0 +1,268 @@
+// Package testutil helps testing the HTTP handlers of services built on common.App: NewTestApp creates an
+// application writing its logs in memory, recording its spans and signing access tokens, requests are
+// built fluently and sent to a handler, and responses are checked with assertions.
+//
+//	app := testutil.NewTestApp(t)
+//	router := routes(app.App, app.Users, app.PublicKey)
+//
+//	app.NewRequest(http.MethodPost, "/items").
+//		AsUser(1, "catalog:write").
+//		WithJSON(map[string]any{"name": "Potion", "price": 5}).
+//		Send(router).
+//		AssertStatus(http.StatusCreated).
+//		AssertJSONPath("item.name", "Potion")
+package testutil
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PlayEconomy37/Play.Common/common"
+	"github.com/PlayEconomy37/Play.Common/configuration"
+	"github.com/PlayEconomy37/Play.Common/database"
+	"github.com/PlayEconomy37/Play.Common/logger"
+	"github.com/pascaldekloe/jwt"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const (
+	// TestAuthority is the authority of the configuration of test applications, which issues their tokens
+	TestAuthority = "http://localhost:5000"
+
+	// TestAudience is the audience of the tokens signed by test applications
+	TestAudience = "http://localhost:3000"
+)
+
+// TestApp is a struct wrapping a common.App created for tests, along with what the tests need to
+// check its behaviour
+type TestApp struct {
+	*common.App
+
+	Users     *UserRepository      // Users authenticated by the tokens of the test application
+	PublicKey string               // Given to App.Authenticate to check the tokens of the test application
+	Registry  *prometheus.Registry // Holds the metrics registered by the application
+
+	t          testing.TB
+	privateKey *rsa.PrivateKey
+	logs       *logBuffer
+	spans      *tracetest.SpanRecorder
+
+	tracerProvider *sdktrace.TracerProvider
+}
+
+// AppOption is a function used to configure a TestApp
+type AppOption func(*TestApp)
+
+// WithConfig replaces the stub configuration of the test application, i.e. to test the rate limiter.
+// Auth.Issuer and Auth.Audiences should be left empty for the tokens of the test application to be valid.
+func WithConfig(cfg *configuration.Config) AppOption {
+	return func(app *TestApp) {
+		app.Config = cfg
+	}
+}
+
+// WithLogLevel sets the minimum level of the log entries kept in memory, logger.LevelInfo by default
+func WithLogLevel(level logger.Level) AppOption {
+	return func(app *TestApp) {
+		app.Logger.SetLevel(level)
+	}
+}
+
+// WithGlobalTracer makes the tracer provider of the test application the global one until the test
+// completes, so that the spans of the Tracing middleware and of instrumented clients are recorded too.
+// Tests using it must not run in parallel.
+func WithGlobalTracer() AppOption {
+	return func(app *TestApp) {
+		previous := otel.GetTracerProvider()
+		otel.SetTracerProvider(app.tracerProvider)
+
+		app.t.Cleanup(func() {
+			otel.SetTracerProvider(previous)
+		})
+	}
+}
+
+// NewTestApp creates an application for tests with a stub configuration, a logger writing in memory,
+// a tracer recording its spans and its own metrics registry. Its logs are written in the output of
+// the test if it fails.
+func NewTestApp(t testing.TB, opts ...AppOption) *TestApp {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate the RSA key of the test application: %s", err)
+	}
+
+	publicKey, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatalf("unable to encode the RSA public key of the test application: %s", err)
+	}
+
+	logs := &logBuffer{}
+	spans := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	registry := prometheus.NewRegistry()
+
+	cfg := &configuration.Config{
+		Address:     "localhost:0",
+		ServiceName: "test",
+		Environment: "test",
+		Authority:   TestAuthority,
+	}
+
+	app := &TestApp{
+		App: &common.App{
+			Config:            cfg,
+			Logger:            logger.New(logs, logger.LevelInfo),
+			Tracer:            tracerProvider.Tracer("test"),
+			MetricsRegisterer: registry,
+		},
+		Users:      NewUserRepository(),
+		PublicKey:  base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})),
+		Registry:   registry,
+		t:          t,
+		privateKey: privateKey,
+		logs:       logs,
+		spans:      spans,
+
+		tracerProvider: tracerProvider,
+	}
+
+	for _, opt := range opts {
+		opt(app)
+	}
+
+	t.Cleanup(func() {
+		if t.Failed() && logs.Len() > 0 {
+			t.Logf("logs of the test application:\n%s", logs.String())
+		}
+
+		_ = tracerProvider.Shutdown(context.Background())
+	})
+
+	return app
+}
+
+// Logs returns the log entries written by the application so far, one JSON object per line
+func (app *TestApp) Logs() string {
+	return app.logs.String()
+}
+
+// Spans returns the spans ended by the tracer of the application so far, along with the spans of the
+// Tracing middleware if WithGlobalTracer is given
+func (app *TestApp) Spans() []sdktrace.ReadOnlySpan {
+	return app.spans.Ended()
+}
+
+// Token returns an access token of the given user signed by the test application, which is valid
+// for an hour. The claims can be altered with the given functions, i.e. to test expired tokens.
+func (app *TestApp) Token(userID int64, opts ...func(claims *jwt.Claims)) string {
+	app.t.Helper()
+
+	now := time.Now()
+
+	var claims jwt.Claims
+	claims.Subject = strconv.FormatInt(userID, 10)
+	claims.Issuer = app.Config.Authority
+	claims.Audiences = []string{TestAudience}
+	claims.Issued = jwt.NewNumericTime(now)
+	claims.NotBefore = jwt.NewNumericTime(now)
+	claims.Expires = jwt.NewNumericTime(now.Add(time.Hour))
+
+	for _, opt := range opts {
+		opt(&claims)
+	}
+
+	token, err := claims.RSASign(jwt.RS256, app.privateKey)
+	if err != nil {
+		app.t.Fatalf("unable to sign the access token: %s", err)
+	}
+
+	return string(token)
+}
+
+// UserRepository is an in-memory common.AuthRepository holding the users of a test application
+type UserRepository struct {
+	mu    sync.RWMutex
+	users map[int64]database.User
+}
+
+// Compile-time check that UserRepository can be given to the authentication middlewares
+var _ common.AuthRepository = (*UserRepository)(nil)
+
+// NewUserRepository creates a new empty UserRepository
+func NewUserRepository() *UserRepository {
+	return &UserRepository{users: map[int64]database.User{}}
+}
+
+// Add adds an activated user with the given ID and permissions, replacing any user with the same ID
+func (r *UserRepository) Add(userID int64, permissions ...string) database.User {
+	user := database.User{ID: userID, Permissions: permissions, Activated: true, Version: 1}
+
+	r.Put(user)
+
+	return user
+}
+
+// Put adds the given user, replacing any user with the same ID
+func (r *UserRepository) Put(user database.User) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.users[user.ID] = user
+}
+
+// GetByID returns the user with the given ID, or database.ErrRecordNotFound if there isn't any
+func (r *UserRepository) GetByID(ctx context.Context, id int64) (database.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, exists := r.users[id]
+	if !exists {
+		return database.User{}, database.ErrRecordNotFound
+	}
+
+	return user, nil
+}
+
+// logBuffer is a bytes.Buffer which can be written by several goroutines
+type logBuffer struct {
+	mu     sync.Mutex
+	buffer bytes.Buffer
+}
+
+// Write appends the given log entry to the buffer
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buffer.Write(p)
+}
+
+// String returns the content of the buffer
+func (b *logBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buffer.String()
+}
+
+// Len returns the length of the content of the buffer
+func (b *logBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buffer.Len()
+}
@@ -0,0 +1,128 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// RequestBuilder is a struct which builds an HTTP request fluently and sends it to a handler
+type RequestBuilder struct {
+	t      testing.TB
+	app    *TestApp
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	body   []byte
+}
+
+// NewRequest starts building a request with the given method and path, i.e. "/items?page=2"
+func NewRequest(t testing.TB, method, path string) *RequestBuilder {
+	return &RequestBuilder{
+		t:      t,
+		method: method,
+		path:   path,
+		query:  url.Values{},
+		header: http.Header{},
+	}
+}
+
+// NewRequest starts building a request with the given method and path, which can be sent on behalf
+// of the users of the application
+func (app *TestApp) NewRequest(method, path string) *RequestBuilder {
+	builder := NewRequest(app.t, method, path)
+	builder.app = app
+
+	return builder
+}
+
+// WithHeader sets a header of the request
+func (b *RequestBuilder) WithHeader(key, value string) *RequestBuilder {
+	b.header.Set(key, value)
+	return b
+}
+
+// WithQuery adds a parameter to the query string of the request
+func (b *RequestBuilder) WithQuery(key, value string) *RequestBuilder {
+	b.query.Add(key, value)
+	return b
+}
+
+// WithToken sends the given access token in the Authorization header of the request
+func (b *RequestBuilder) WithToken(token string) *RequestBuilder {
+	return b.WithHeader("Authorization", "Bearer "+token)
+}
+
+// AsUser sends the request on behalf of the user with the given ID, which is added to the users of
+// the application with the given permissions
+func (b *RequestBuilder) AsUser(userID int64, permissions ...string) *RequestBuilder {
+	b.t.Helper()
+
+	if b.app == nil {
+		b.t.Fatal("AsUser requires a request built by TestApp.NewRequest")
+	}
+
+	b.app.Users.Add(userID, permissions...)
+
+	return b.WithToken(b.app.Token(userID))
+}
+
+// WithJSON sends the given value encoded as JSON in the body of the request
+func (b *RequestBuilder) WithJSON(value any) *RequestBuilder {
+	b.t.Helper()
+
+	body, err := json.Marshal(value)
+	if err != nil {
+		b.t.Fatalf("unable to encode the body of the request: %s", err)
+	}
+
+	return b.WithBody(body, "application/json")
+}
+
+// WithBody sends the given body with the given content type, i.e. to send malformed JSON
+func (b *RequestBuilder) WithBody(body []byte, contentType string) *RequestBuilder {
+	b.body = body
+	return b.WithHeader("Content-Type", contentType)
+}
+
+// Build returns the request
+func (b *RequestBuilder) Build() *http.Request {
+	b.t.Helper()
+
+	var body io.Reader
+	if b.body != nil {
+		body = bytes.NewReader(b.body)
+	}
+
+	r := httptest.NewRequest(b.method, b.path, body)
+
+	if len(b.query) > 0 {
+		query := r.URL.Query()
+		for key, values := range b.query {
+			query[key] = append(query[key], values...)
+		}
+
+		r.URL.RawQuery = query.Encode()
+	}
+
+	for key, values := range b.header {
+		r.Header[key] = values
+	}
+
+	return r
+}
+
+// Send sends the request to the given handler, i.e. the router of the service, and returns its response
+func (b *RequestBuilder) Send(handler http.Handler) *Response {
+	b.t.Helper()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, b.Build())
+
+	return &Response{t: b.t, Recorder: recorder}
+}
@@ -0,0 +1,178 @@
+package testutil
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Response is a struct wrapping the response to a request, whose assertions report their failures
+// to the test and can be chained
+type Response struct {
+	t        testing.TB
+	Recorder *httptest.ResponseRecorder
+
+	decoded bool
+	body    any
+}
+
+// Status returns the status code of the response
+func (r *Response) Status() int {
+	return r.Recorder.Code
+}
+
+// Body returns the body of the response
+func (r *Response) Body() string {
+	return r.Recorder.Body.String()
+}
+
+// DecodeJSON decodes the JSON body of the response into the given target, i.e. the envelope of
+// the response
+func (r *Response) DecodeJSON(target any) *Response {
+	r.t.Helper()
+
+	if err := json.Unmarshal(r.Recorder.Body.Bytes(), target); err != nil {
+		r.t.Fatalf("unable to decode the body of the response: %s\nbody: %s", err, r.Body())
+	}
+
+	return r
+}
+
+// JSONPath returns the value found at the given path of the JSON body of the response, whose keys
+// and array indexes are separated by dots (i.e. "items.0.name"), and whether it exists. Numbers are
+// returned as float64, objects as map[string]any and arrays as []any.
+func (r *Response) JSONPath(path string) (any, bool) {
+	r.t.Helper()
+
+	if !r.decoded {
+		r.DecodeJSON(&r.body)
+		r.decoded = true
+	}
+
+	return lookupJSONPath(r.body, path)
+}
+
+// AssertStatus checks that the response has the given status code
+func (r *Response) AssertStatus(want int) *Response {
+	r.t.Helper()
+
+	if r.Status() != want {
+		r.t.Errorf("want status %d; got %d\nbody: %s", want, r.Status(), r.Body())
+	}
+
+	return r
+}
+
+// AssertHeader checks that the response has the given header value
+func (r *Response) AssertHeader(key, want string) *Response {
+	r.t.Helper()
+
+	if got := r.Recorder.Header().Get(key); got != want {
+		r.t.Errorf("want header %s %q; got %q", key, want, got)
+	}
+
+	return r
+}
+
+// AssertJSONPath checks that the value at the given path of the JSON body (see JSONPath) is equal
+// to the given value once encoded as JSON, so that i.e. int values match JSON numbers
+func (r *Response) AssertJSONPath(path string, want any) *Response {
+	r.t.Helper()
+
+	got, exists := r.JSONPath(path)
+	if !exists {
+		r.t.Errorf("want %s in the body; got nothing\nbody: %s", path, r.Body())
+		return r
+	}
+
+	encoded, err := json.Marshal(want)
+	if err != nil {
+		r.t.Fatalf("unable to encode the expected value of %s: %s", path, err)
+	}
+
+	var normalized any
+	_ = json.Unmarshal(encoded, &normalized)
+
+	if !reflect.DeepEqual(got, normalized) {
+		r.t.Errorf("want %s to be %s; got %s", path, encoded, mustMarshal(got))
+	}
+
+	return r
+}
+
+// AssertJSONPathExists checks that the JSON body has a value at the given path (see JSONPath)
+func (r *Response) AssertJSONPathExists(path string) *Response {
+	r.t.Helper()
+
+	if _, exists := r.JSONPath(path); !exists {
+		r.t.Errorf("want %s in the body; got nothing\nbody: %s", path, r.Body())
+	}
+
+	return r
+}
+
+// AssertError checks that the response has the given status code and an error envelope with the given
+// message, i.e. {"error": "The requested resource could not be found"}
+func (r *Response) AssertError(status int, message string) *Response {
+	r.t.Helper()
+
+	return r.AssertStatus(status).AssertJSONPath("error", message)
+}
+
+// AssertValidationErrors checks that the response is a 422 Unprocessable Entity whose error envelope
+// has an error for every given field, i.e. {"error": {"name": "must be provided"}}
+func (r *Response) AssertValidationErrors(fields ...string) *Response {
+	r.t.Helper()
+
+	r.AssertStatus(http.StatusUnprocessableEntity)
+
+	for _, field := range fields {
+		r.AssertJSONPathExists("error." + field)
+	}
+
+	return r
+}
+
+// lookupJSONPath returns the value found at the given dot-separated path of the given decoded JSON value
+func lookupJSONPath(value any, path string) (any, bool) {
+	if path == "" {
+		return value, true
+	}
+
+	for _, segment := range strings.Split(path, ".") {
+		switch current := value.(type) {
+		case map[string]any:
+			next, exists := current[segment]
+			if !exists {
+				return nil, false
+			}
+
+			value = next
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(current) {
+				return nil, false
+			}
+
+			value = current[index]
+		default:
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+// mustMarshal encodes the given decoded JSON value for failure messages
+func mustMarshal(value any) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err.Error()
+	}
+
+	return string(encoded)
+}
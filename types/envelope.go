@@ -1,5 +1,22 @@
 package types
 
+import (
+	"net/url"
+
+	"github.com/PlayEconomy37/Play.Common/filters"
+)
+
 // Envelope is a map that wraps whatever data we want to send back to the client
 // as a JSON response
 type Envelope map[string]any
+
+// NewListEnvelope creates an envelope for a page of records, holding the records under the
+// given key along with the pagination metadata and the links to the surrounding pages,
+// generated from the URL of the current request
+func NewListEnvelope(key string, records any, metadata filters.Metadata, requestURL *url.URL) Envelope {
+	return Envelope{
+		key:        records,
+		"metadata": metadata,
+		"links":    metadata.PageLinks(requestURL),
+	}
+}
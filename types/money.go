@@ -0,0 +1,310 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/PlayEconomy37/Play.Common/validator"
+)
+
+// Currency is an ISO 4217 like currency code, i.e. "GIL"
+type Currency string
+
+// Gil is the in-game currency of Play Economy
+const Gil Currency = "GIL"
+
+var (
+	// ErrCurrencyMismatch is returned when combining amounts of different currencies
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+
+	// ErrUnknownCurrency is returned when using a currency which hasn't been registered
+	ErrUnknownCurrency = errors.New("unknown currency")
+
+	// ErrMoneyOverflow is returned when the result of an operation doesn't fit in an amount
+	ErrMoneyOverflow = errors.New("amount overflow")
+
+	// ErrInvalidMoney is returned when parsing an amount which isn't a valid decimal number
+	// or has more decimal places than its currency
+	ErrInvalidMoney = errors.New("invalid amount")
+)
+
+// currencies holds the number of decimal places of the minor units of the known currencies
+var currencies = struct {
+	sync.RWMutex
+	decimals map[Currency]int
+}{decimals: map[Currency]int{
+	Gil:   2,
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"JPY": 0,
+}}
+
+func init() {
+	validator.RegisterCatalog(validator.DefaultLocale, validator.Catalog{
+		"invalid_currency": "must be an amount in {currency}",
+	})
+}
+
+// RegisterCurrency registers a currency whose minor units have the given number of decimal places
+// (i.e. 2 for cents), so that amounts of it can be parsed and formatted
+func RegisterCurrency(currency Currency, decimals int) {
+	currencies.Lock()
+	defer currencies.Unlock()
+
+	currencies.decimals[currency] = decimals
+}
+
+// Decimals returns the number of decimal places of the minor units of the currency,
+// and whether the currency is known
+func (c Currency) Decimals() (int, bool) {
+	currencies.RLock()
+	defer currencies.RUnlock()
+
+	decimals, exists := currencies.decimals[c]
+
+	return decimals, exists
+}
+
+// Money is a value type holding an amount of money as an integer number of minor units (i.e. cents),
+// so that amounts are added and compared without the rounding errors of floating-point numbers.
+// Amounts are stored in MongoDB as their minor units, which keeps them sortable, and are sent in JSON
+// as decimal strings along with their currency, i.e. {"amount": "12.34", "currency": "GIL"}.
+type Money struct {
+	Amount   int64    `bson:"amount"` // In minor units
+	Currency Currency `bson:"currency"`
+}
+
+// NewMoney creates an amount of money of the given number of minor units (i.e. 1234 for 12.34)
+func NewMoney(amount int64, currency Currency) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// ParseMoney parses a decimal amount of the given currency (i.e. "12.34"), which must not have more
+// decimal places than the minor units of the currency
+func ParseMoney(amount string, currency Currency) (Money, error) {
+	decimals, exists := currency.Decimals()
+	if !exists {
+		return Money{}, fmt.Errorf("%w: %s", ErrUnknownCurrency, currency)
+	}
+
+	value := strings.TrimSpace(amount)
+
+	// A single sign is allowed, which is kept when parsing the minor units so that the lowest amount fits
+	sign := ""
+	if strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+") {
+		sign, value = value[:1], value[1:]
+	}
+
+	integerPart, fractionPart, _ := strings.Cut(value, ".")
+	if integerPart == "" || len(fractionPart) > decimals || !isDigits(integerPart) || !isDigits(fractionPart) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, amount)
+	}
+
+	minorUnits, err := strconv.ParseInt(sign+integerPart+fractionPart+strings.Repeat("0", decimals-len(fractionPart)), 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, amount)
+	}
+
+	return Money{Amount: minorUnits, Currency: currency}, nil
+}
+
+// MoneyFromFloat converts a floating-point amount of the given currency (i.e. 12.34) into Money, rounding
+// it to the nearest minor unit. It eases the migration of the amounts which were stored as float64.
+func MoneyFromFloat(amount float64, currency Currency) (Money, error) {
+	decimals, exists := currency.Decimals()
+	if !exists {
+		return Money{}, fmt.Errorf("%w: %s", ErrUnknownCurrency, currency)
+	}
+
+	minorUnits := math.Round(amount * math.Pow10(decimals))
+	if math.IsNaN(minorUnits) || minorUnits >= math.MaxInt64 || minorUnits < math.MinInt64 {
+		return Money{}, fmt.Errorf("%w: %v", ErrInvalidMoney, amount)
+	}
+
+	return Money{Amount: int64(minorUnits), Currency: currency}, nil
+}
+
+// isDigits returns whether the given string only contains ASCII digits
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsZero returns whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsPositive returns whether the amount is greater than zero
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+// IsNegative returns whether the amount is lower than zero
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Add returns the sum of the two amounts, which must be of the same currency
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.checkCurrency(other); err != nil {
+		return Money{}, err
+	}
+
+	sum := m.Amount + other.Amount
+	if (other.Amount > 0 && sum < m.Amount) || (other.Amount < 0 && sum > m.Amount) {
+		return Money{}, ErrMoneyOverflow
+	}
+
+	return Money{Amount: sum, Currency: m.Currency}, nil
+}
+
+// Sub returns the difference of the two amounts, which must be of the same currency
+func (m Money) Sub(other Money) (Money, error) {
+	if other.Amount == math.MinInt64 {
+		return Money{}, ErrMoneyOverflow
+	}
+
+	return m.Add(Money{Amount: -other.Amount, Currency: other.Currency})
+}
+
+// Mul returns the amount multiplied by the given quantity, i.e. the total price of several items
+func (m Money) Mul(quantity int64) (Money, error) {
+	if m.Amount == 0 || quantity == 0 {
+		return Money{Currency: m.Currency}, nil
+	}
+
+	product := m.Amount * quantity
+	if product/quantity != m.Amount || (m.Amount == -1 && quantity == math.MinInt64) || (quantity == -1 && m.Amount == math.MinInt64) {
+		return Money{}, ErrMoneyOverflow
+	}
+
+	return Money{Amount: product, Currency: m.Currency}, nil
+}
+
+// Neg returns the opposite of the amount
+func (m Money) Neg() Money {
+	return Money{Amount: -m.Amount, Currency: m.Currency}
+}
+
+// Cmp compares the two amounts, which must be of the same currency, and returns -1 if the amount
+// is lower than the other, 0 if they are equal and 1 if it is greater
+func (m Money) Cmp(other Money) (int, error) {
+	if err := m.checkCurrency(other); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case m.Amount < other.Amount:
+		return -1, nil
+	case m.Amount > other.Amount:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// Equal returns whether the two amounts are equal and of the same currency
+func (m Money) Equal(other Money) bool {
+	return m == other
+}
+
+// checkCurrency returns ErrCurrencyMismatch if the other amount isn't of the same currency
+func (m Money) checkCurrency(other Money) error {
+	if m.Currency != other.Currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+
+	return nil
+}
+
+// Decimal returns the amount as a decimal number with the decimal places of its currency (i.e. "12.34"),
+// or as a number of minor units if the currency is unknown
+func (m Money) Decimal() string {
+	decimals, exists := m.Currency.Decimals()
+	if !exists || decimals == 0 {
+		return strconv.FormatInt(m.Amount, 10)
+	}
+
+	digits := strconv.FormatInt(m.Amount, 10)
+
+	sign := ""
+	if m.Amount < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-decimals] + "." + digits[len(digits)-decimals:]
+}
+
+// String returns the amount followed by its currency, i.e. "12.34 GIL"
+func (m Money) String() string {
+	return m.Decimal() + " " + string(m.Currency)
+}
+
+// moneyJSON is the JSON representation of Money
+type moneyJSON struct {
+	Amount   json.Number `json:"amount"`
+	Currency Currency    `json:"currency"`
+}
+
+// MarshalJSON encodes the amount as a decimal string along with its currency
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string   `json:"amount"`
+		Currency Currency `json:"currency"`
+	}{Amount: m.Decimal(), Currency: m.Currency})
+}
+
+// UnmarshalJSON decodes an amount given as a decimal string or number along with its currency
+// (i.e. {"amount": "12.34", "currency": "GIL"}), without going through floating-point numbers
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var value moneyJSON
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+
+	parsed, err := ParseMoney(value.Amount.String(), value.Currency)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+
+	return nil
+}
+
+// ValidateMoney checks that the given amount is in the given currency and isn't negative, and adds
+// an error under the given key to the validator otherwise
+//
+//	types.ValidateMoney(v, "price", input.Price, types.Gil)
+//	v.Check(input.Price.IsPositive(), "price", "must be greater than 0")
+func ValidateMoney(v *validator.Validator, key string, m Money, currency Currency) {
+	if m.Currency != currency {
+		v.AddLocalizedError(key, "invalid_currency", validator.Params{"currency": string(currency)})
+		return
+	}
+
+	if m.IsNegative() {
+		v.AddLocalizedError(key, "negative", nil)
+	}
+}
@@ -0,0 +1,199 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  string
+		want    int64
+		wantErr error
+	}{
+		{name: "Integer", amount: "12", want: 1200},
+		{name: "Decimal", amount: "12.34", want: 1234},
+		{name: "One decimal place", amount: "12.3", want: 1230},
+		{name: "Trailing dot", amount: "12.", want: 1200},
+		{name: "Spaces", amount: " 12.34 ", want: 1234},
+		{name: "Plus sign", amount: "+12.34", want: 1234},
+		{name: "Minus sign", amount: "-12.34", want: -1234},
+		{name: "Negative zero", amount: "-0", want: 0},
+		{name: "Highest amount", amount: "92233720368547758.07", want: math.MaxInt64},
+		{name: "Lowest amount", amount: "-92233720368547758.08", want: math.MinInt64},
+		{name: "Too many decimal places", amount: "12.345", wantErr: ErrInvalidMoney},
+		{name: "Overflow", amount: "92233720368547758.08", wantErr: ErrInvalidMoney},
+		{name: "Negative overflow", amount: "-92233720368547758.09", wantErr: ErrInvalidMoney},
+		{name: "Plus sign only", amount: "+", wantErr: ErrInvalidMoney},
+		{name: "Minus sign only", amount: "-", wantErr: ErrInvalidMoney},
+		{name: "Several signs", amount: "-+5", wantErr: ErrInvalidMoney},
+		{name: "Dot only", amount: ".", wantErr: ErrInvalidMoney},
+		{name: "Sign and dot only", amount: "-.", wantErr: ErrInvalidMoney},
+		{name: "No integer part", amount: ".5", wantErr: ErrInvalidMoney},
+		{name: "Empty", amount: "", wantErr: ErrInvalidMoney},
+		{name: "Several dots", amount: "1.2.3", wantErr: ErrInvalidMoney},
+		{name: "Letters", amount: "12a", wantErr: ErrInvalidMoney},
+		{name: "Exponent", amount: "1e2", wantErr: ErrInvalidMoney},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMoney(tt.amount, Gil)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("want %v; got %v", tt.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("want no error; got %v", err)
+			}
+
+			if got != NewMoney(tt.want, Gil) {
+				t.Errorf("want %v; got %v", NewMoney(tt.want, Gil), got)
+			}
+		})
+	}
+}
+
+func TestParseMoneyUnknownCurrency(t *testing.T) {
+	_, err := ParseMoney("12.34", "XXX")
+	if !errors.Is(err, ErrUnknownCurrency) {
+		t.Errorf("want %v; got %v", ErrUnknownCurrency, err)
+	}
+}
+
+func TestMoneyDecimal(t *testing.T) {
+	tests := []struct {
+		name  string
+		money Money
+		want  string
+	}{
+		{name: "Zero", money: NewMoney(0, Gil), want: "0.00"},
+		{name: "One minor unit", money: NewMoney(1, Gil), want: "0.01"},
+		{name: "Below one major unit", money: NewMoney(99, Gil), want: "0.99"},
+		{name: "One major unit", money: NewMoney(100, Gil), want: "1.00"},
+		{name: "Positive", money: NewMoney(1234, Gil), want: "12.34"},
+		{name: "Negative minor unit", money: NewMoney(-1, Gil), want: "-0.01"},
+		{name: "Negative below one major unit", money: NewMoney(-99, Gil), want: "-0.99"},
+		{name: "Negative", money: NewMoney(-1234, Gil), want: "-12.34"},
+		{name: "Lowest amount", money: NewMoney(math.MinInt64, Gil), want: "-92233720368547758.08"},
+		{name: "No decimal places", money: NewMoney(-1234, "JPY"), want: "-1234"},
+		{name: "Unknown currency", money: NewMoney(1234, "XXX"), want: "1234"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.money.Decimal(); got != tt.want {
+				t.Errorf("want %q; got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	tests := []struct {
+		name    string
+		op      func() (Money, error)
+		want    int64
+		wantErr error
+	}{
+		{name: "Add", op: func() (Money, error) { return NewMoney(1, Gil).Add(NewMoney(2, Gil)) }, want: 3},
+		{name: "Add overflow", op: func() (Money, error) { return NewMoney(math.MaxInt64, Gil).Add(NewMoney(1, Gil)) }, wantErr: ErrMoneyOverflow},
+		{name: "Add negative overflow", op: func() (Money, error) { return NewMoney(math.MinInt64, Gil).Add(NewMoney(-1, Gil)) }, wantErr: ErrMoneyOverflow},
+		{name: "Add currency mismatch", op: func() (Money, error) { return NewMoney(1, Gil).Add(NewMoney(1, "USD")) }, wantErr: ErrCurrencyMismatch},
+		{name: "Sub", op: func() (Money, error) { return NewMoney(1, Gil).Sub(NewMoney(3, Gil)) }, want: -2},
+		{name: "Sub to lowest amount", op: func() (Money, error) { return NewMoney(-1, Gil).Sub(NewMoney(math.MaxInt64, Gil)) }, want: math.MinInt64},
+		{name: "Sub lowest amount", op: func() (Money, error) { return NewMoney(0, Gil).Sub(NewMoney(math.MinInt64, Gil)) }, wantErr: ErrMoneyOverflow},
+		{name: "Sub lowest amount from negative", op: func() (Money, error) { return NewMoney(-1, Gil).Sub(NewMoney(math.MinInt64, Gil)) }, wantErr: ErrMoneyOverflow},
+		{name: "Sub overflow", op: func() (Money, error) { return NewMoney(math.MinInt64, Gil).Sub(NewMoney(1, Gil)) }, wantErr: ErrMoneyOverflow},
+		{name: "Mul", op: func() (Money, error) { return NewMoney(250, Gil).Mul(3) }, want: 750},
+		{name: "Mul by zero", op: func() (Money, error) { return NewMoney(math.MinInt64, Gil).Mul(0) }, want: 0},
+		{name: "Mul by one", op: func() (Money, error) { return NewMoney(math.MinInt64, Gil).Mul(1) }, want: math.MinInt64},
+		{name: "Mul lowest amount by minus one", op: func() (Money, error) { return NewMoney(math.MinInt64, Gil).Mul(-1) }, wantErr: ErrMoneyOverflow},
+		{name: "Mul minus one by lowest quantity", op: func() (Money, error) { return NewMoney(-1, Gil).Mul(math.MinInt64) }, wantErr: ErrMoneyOverflow},
+		{name: "Mul lowest amount by two", op: func() (Money, error) { return NewMoney(math.MinInt64, Gil).Mul(2) }, wantErr: ErrMoneyOverflow},
+		{name: "Mul overflow", op: func() (Money, error) { return NewMoney(math.MaxInt64/2+1, Gil).Mul(2) }, wantErr: ErrMoneyOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.op()
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("want %v; got %v", tt.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("want no error; got %v", err)
+			}
+
+			if got != NewMoney(tt.want, Gil) {
+				t.Errorf("want %v; got %v", NewMoney(tt.want, Gil), got)
+			}
+		})
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    Money
+		wantErr error
+	}{
+		{name: "String", data: `{"amount": "12.34", "currency": "GIL"}`, want: NewMoney(1234, Gil)},
+		{name: "Number", data: `{"amount": 12.34, "currency": "GIL"}`, want: NewMoney(1234, Gil)},
+		{name: "Negative string", data: `{"amount": "-0.05", "currency": "GIL"}`, want: NewMoney(-5, Gil)},
+		{name: "Negative number", data: `{"amount": -0.05, "currency": "GIL"}`, want: NewMoney(-5, Gil)},
+		{name: "Integer number", data: `{"amount": 12, "currency": "JPY"}`, want: NewMoney(12, "JPY")},
+		{name: "Too many decimal places", data: `{"amount": 12.345, "currency": "GIL"}`, wantErr: ErrInvalidMoney},
+		{name: "Unknown currency", data: `{"amount": "12.34", "currency": "XXX"}`, wantErr: ErrUnknownCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Money
+
+			err := json.Unmarshal([]byte(tt.data), &got)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("want %v; got %v", tt.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("want no error; got %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("want %v; got %v", tt.want, got)
+			}
+
+			// The decoded amount is encoded back as a decimal string, which decodes to the same amount
+			encoded, err := json.Marshal(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var decoded Money
+			if err := json.Unmarshal(encoded, &decoded); err != nil {
+				t.Fatal(err)
+			}
+
+			if decoded != tt.want {
+				t.Errorf("want %v after a round-trip; got %v from %s", tt.want, decoded, encoded)
+			}
+		})
+	}
+}
@@ -7,3 +7,9 @@ type MongoEntity[K, T any] interface {
 	GetVersion() int32
 	SetVersion(version int32) T
 }
+
+// IDSetter is an interface implemented by entities whose ID can be generated by our generic MongoDB
+// repository on Create (i.e. a ULID or a snowflake ID), instead of being set by the caller
+type IDSetter[K, T any] interface {
+	SetID(id K) T
+}
@@ -16,3 +16,11 @@ type MongoRepository[K any, T MongoEntity[K, T]] interface {
 	Update(ctx context.Context, entity T) error
 	Delete(ctx context.Context, id K) error
 }
+
+// BulkMongoRepository is a generic MongoDB repository interface which also supports bulk operations.
+// It is separate from MongoRepository so that existing implementations and mocks keep satisfying it.
+type BulkMongoRepository[K any, T MongoEntity[K, T]] interface {
+	MongoRepository[K, T]
+	DeleteByFilter(ctx context.Context, filter primitive.M, allowEmptyFilter bool) (int64, error)
+	UpdateByFilter(ctx context.Context, filter primitive.M, update primitive.M, allowEmptyFilter bool) (int64, error)
+}
@@ -0,0 +1,12 @@
+package types
+
+import "time"
+
+// Timestamps is an interface implemented by entities which keep track of when they were created
+// and last updated. Our generic MongoDB repository uses it to stamp these fields automatically.
+// Bulk updates can't call SetUpdatedAt, so they set the "updatedAt" field (database.UpdatedAtField),
+// which should be the bson name of the update time.
+type Timestamps[T any] interface {
+	SetCreatedAt(createdAt time.Time) T
+	SetUpdatedAt(updatedAt time.Time) T
+}
@@ -0,0 +1,166 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// conditionCompiler compiles a conditional rule with the given parameter for fields of the given struct type
+type conditionCompiler func(param string, parent reflect.Type) (func(parent reflect.Value) bool, error)
+
+// conditionalRules holds the rules which make a field required depending on other fields of its struct:
+//
+//	required_if=payout_method bank       required if payout_method equals "bank"
+//	required_unless=payout_method card   required unless payout_method equals "card"
+//	required_with=street city            required if street or city is provided
+//
+// Fields are referred to by their JSON or Go name. Fields with a conditional rule are optional
+// when the condition isn't met, so their other rules only run when they are provided.
+var conditionalRules = map[string]conditionCompiler{
+	"required_if":     compileRequiredIf,
+	"required_unless": compileRequiredUnless,
+	"required_with":   compileRequiredWith,
+}
+
+// compileCondition compiles a conditional rule and adds its condition to the conditions of the field
+func compileCondition(field *structField, name, param string, parent reflect.Type) error {
+	if parent == nil {
+		return fmt.Errorf("%w: %s can only be used on struct fields", ErrInvalidRule, name)
+	}
+
+	condition, err := conditionalRules[name](param, parent)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %s", ErrInvalidRule, name, err)
+	}
+
+	// The field is required as soon as one of its conditions is met
+	if previous := field.requiredWhen; previous != nil {
+		field.requiredWhen = func(parent reflect.Value) bool {
+			return previous(parent) || condition(parent)
+		}
+	} else {
+		field.requiredWhen = condition
+	}
+
+	field.omitEmpty = true
+
+	return nil
+}
+
+// compileRequiredIf compiles the "required_if" rule, whose parameter is a field name and a value
+func compileRequiredIf(param string, parent reflect.Type) (func(parent reflect.Value) bool, error) {
+	return compileEquals(param, parent)
+}
+
+// compileRequiredUnless compiles the "required_unless" rule, whose parameter is a field name and a value
+func compileRequiredUnless(param string, parent reflect.Type) (func(parent reflect.Value) bool, error) {
+	equals, err := compileEquals(param, parent)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(parent reflect.Value) bool {
+		return !equals(parent)
+	}, nil
+}
+
+// compileRequiredWith compiles the "required_with" rule, whose parameter is a list of field names
+func compileRequiredWith(param string, parent reflect.Type) (func(parent reflect.Value) bool, error) {
+	names := strings.Fields(param)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("expected at least one field name")
+	}
+
+	indexes := make([]int, 0, len(names))
+
+	for _, name := range names {
+		index, ok := fieldIndex(parent, name)
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+
+		indexes = append(indexes, index)
+	}
+
+	return func(parent reflect.Value) bool {
+		for _, index := range indexes {
+			if !isBlank(parent.Field(index)) {
+				return true
+			}
+		}
+
+		return false
+	}, nil
+}
+
+// compileEquals compiles a condition checking that the field of the parameter equals its value,
+// i.e. "payout_method bank"
+func compileEquals(param string, parent reflect.Type) (func(parent reflect.Value) bool, error) {
+	name, expected, ok := strings.Cut(strings.TrimSpace(param), " ")
+	if !ok {
+		return nil, fmt.Errorf("expected a field name and a value, got %q", param)
+	}
+
+	index, ok := fieldIndex(parent, name)
+	if !ok {
+		return nil, fmt.Errorf("unknown field %q", name)
+	}
+
+	expected = strings.TrimSpace(expected)
+
+	return func(parent reflect.Value) bool {
+		value := reflect.Indirect(parent.Field(index))
+		return value.IsValid() && fmt.Sprint(value.Interface()) == expected
+	}, nil
+}
+
+// fieldIndex returns the index of the exported field of the given struct type with the given JSON or Go name
+func fieldIndex(t reflect.Type, name string) (int, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.IsExported() && (jsonName(f) == name || f.Name == name) {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// isBlank returns whether a value is a nil pointer, a zero value or a blank string
+func isBlank(value reflect.Value) bool {
+	value = reflect.Indirect(value)
+	if !value.IsValid() {
+		return true
+	}
+
+	if value.Kind() == reflect.String {
+		return !NotBlank(value.String())
+	}
+
+	return value.IsZero()
+}
+
+// RequiredIf adds an error under the given key if the condition is met and the value is blank,
+// i.e. v.RequiredIf(req.PayoutMethod == "bank", "bank_account", req.BankAccount)
+func (v *Validator) RequiredIf(condition bool, key string, value any) {
+	if condition && isBlank(reflect.ValueOf(value)) {
+		v.AddLocalizedError(key, "required", nil)
+	}
+}
+
+// RequiredUnless adds an error under the given key if the condition isn't met and the value is blank
+func (v *Validator) RequiredUnless(condition bool, key string, value any) {
+	v.RequiredIf(!condition, key, value)
+}
+
+// RequiredWith adds an error under the given key if the value is blank while one of the other values is provided,
+// i.e. v.RequiredWith("city", req.City, req.Street, req.ZipCode)
+func (v *Validator) RequiredWith(key string, value any, others ...any) {
+	for _, other := range others {
+		if !isBlank(reflect.ValueOf(other)) {
+			v.RequiredIf(true, key, value)
+			return
+		}
+	}
+}
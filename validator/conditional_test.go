@@ -0,0 +1,174 @@
+package validator
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testPayout struct {
+	Method      string  `json:"payout_method" validate:"required,oneof=bank card wallet"`
+	BankAccount string  `json:"bank_account" validate:"required_if=payout_method bank,min=8"`
+	CardNumber  *string `json:"card_number" validate:"required_unless=Method bank"`
+	Street      string  `json:"street"`
+	ZipCode     *string `json:"zip_code"`
+	City        string  `json:"city" validate:"required_with=street zip_code,max=20"`
+	Wallet      string  `json:"wallet" validate:"required_if=payout_method wallet,required_with=street"`
+}
+
+func TestStructConditionalRules(t *testing.T) {
+	card := "4111111111111111"
+	emptyZipCode := ""
+	zipCode := "75001"
+
+	tests := []struct {
+		name      string
+		payout    testPayout
+		wantCodes map[string]string
+	}{
+		{
+			name:      "Required if condition met",
+			payout:    testPayout{Method: "bank"},
+			wantCodes: map[string]string{"bank_account": "required"},
+		},
+		{
+			name:      "Required if condition met and other rules failing",
+			payout:    testPayout{Method: "bank", BankAccount: "123"},
+			wantCodes: map[string]string{"bank_account": "too_short"},
+		},
+		{
+			name:   "Required if condition not met",
+			payout: testPayout{Method: "card", CardNumber: &card},
+		},
+		{
+			name:      "Required if condition not met and other rules failing",
+			payout:    testPayout{Method: "card", CardNumber: &card, BankAccount: "123"},
+			wantCodes: map[string]string{"bank_account": "too_short"},
+		},
+		{
+			name:      "Required unless condition not met",
+			payout:    testPayout{Method: "card"},
+			wantCodes: map[string]string{"card_number": "required"},
+		},
+		{
+			name:   "Required unless condition met",
+			payout: testPayout{Method: "bank", BankAccount: "12345678"},
+		},
+		{
+			name:      "Required with other field provided",
+			payout:    testPayout{Method: "card", CardNumber: &card, Street: "Sector 7"},
+			wantCodes: map[string]string{"city": "required", "wallet": "required"},
+		},
+		{
+			name:      "Required with other pointer field provided",
+			payout:    testPayout{Method: "card", CardNumber: &card, ZipCode: &zipCode},
+			wantCodes: map[string]string{"city": "required"},
+		},
+		{
+			name:   "Required with other pointer field blank",
+			payout: testPayout{Method: "card", CardNumber: &card, Street: " ", ZipCode: &emptyZipCode},
+		},
+		{
+			name:   "Required with field provided",
+			payout: testPayout{Method: "card", CardNumber: &card, ZipCode: &zipCode, City: "Midgar"},
+		},
+		{
+			name:      "Several conditions",
+			payout:    testPayout{Method: "wallet", CardNumber: &card},
+			wantCodes: map[string]string{"wallet": "required"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := New()
+			v.Struct(tt.payout)
+
+			if len(tt.wantCodes) == 0 {
+				if v.HasErrors() {
+					t.Errorf("want no errors; got %v", v.Errors)
+				}
+				return
+			}
+
+			if !reflect.DeepEqual(v.Codes, tt.wantCodes) {
+				t.Errorf("want codes %v; got %v", tt.wantCodes, v.Codes)
+			}
+		})
+	}
+}
+
+func TestInvalidConditionalRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+	}{
+		{
+			name: "Required if without value",
+			value: struct {
+				Kind   string
+				Number string `validate:"required_if=Kind"`
+			}{},
+		},
+		{
+			name: "Required if with unknown field",
+			value: struct {
+				Number string `validate:"required_if=kind bank"`
+			}{},
+		},
+		{
+			name: "Required unless with unknown field",
+			value: struct {
+				Number string `validate:"required_unless=kind bank"`
+			}{},
+		},
+		{
+			name: "Required with without fields",
+			value: struct {
+				City string `validate:"required_with="`
+			}{},
+		},
+		{
+			name: "Required with unknown field",
+			value: struct {
+				City string `validate:"required_with=street"`
+			}{},
+		},
+		{
+			name: "Conditional rule after dive",
+			value: struct {
+				Kind string
+				Tags []string `validate:"dive,required_if=Kind bank"`
+			}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := recoverError(t, func() {
+				New().Struct(tt.value)
+			})
+
+			if !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("want panic with %v; got %v", ErrInvalidRule, err)
+			}
+		})
+	}
+}
+
+func TestRequiredHelpers(t *testing.T) {
+	var nilAccount *string
+
+	v := New()
+	v.RequiredIf(true, "bank_account", nilAccount)
+	v.RequiredIf(false, "iban", "")
+	v.RequiredUnless(false, "card_number", " ")
+	v.RequiredWith("city", "", "", nil, "Sector 7")
+	v.RequiredWith("zip_code", "", "", nil)
+
+	want := map[string]string{"bank_account": "required", "card_number": "required", "city": "required"}
+
+	if !reflect.DeepEqual(v.Codes, want) {
+		t.Errorf("want codes %v; got %v", want, v.Codes)
+	}
+}
@@ -0,0 +1,150 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// AsyncCheck is a function which checks a value against an external system (i.e. a repository)
+// and returns whether it is valid. Errors are reserved for failures of the external system.
+type AsyncCheck func(ctx context.Context) (bool, error)
+
+// asyncRules holds the asynchronous rules registered by services, keyed by name
+var asyncRules = struct {
+	sync.RWMutex
+	checks map[string]func(ctx context.Context, value any) (bool, error)
+}{checks: map[string]func(ctx context.Context, value any) (bool, error){}}
+
+// RegisterAsync registers an asynchronous rule which can then be used with ValidatorContext.AsyncVar,
+// i.e. validator.RegisterAsync("emailAvailable", "is already taken", repository.IsEmailAvailable).
+// The message is added to the default catalog under the rule name, so that it can be translated.
+// Rules are meant to be registered on startup and RegisterAsync panics if the name is already taken.
+func RegisterAsync[T any](name, message string, fn func(ctx context.Context, value T) (bool, error)) {
+	asyncRules.Lock()
+	defer asyncRules.Unlock()
+
+	if _, exists := asyncRules.checks[name]; exists {
+		panic(fmt.Sprintf("validator: async rule %q is already registered", name))
+	}
+
+	asyncRules.checks[name] = func(ctx context.Context, value any) (bool, error) {
+		typed, ok := value.(T)
+		if !ok {
+			return false, fmt.Errorf("%w: %s cannot be used on %T", ErrInvalidRule, name, value)
+		}
+
+		return fn(ctx, typed)
+	}
+
+	RegisterCatalog(DefaultLocale, Catalog{name: message})
+}
+
+// asyncCheck is a struct which holds an asynchronous check waiting to be run
+type asyncCheck struct {
+	key     string
+	code    string
+	message string // Id of the message in the catalogs if code is empty
+	check   AsyncCheck
+}
+
+// ValidatorContext is a Validator which can also run asynchronous checks, such as uniqueness
+// checks backed by a repository. Checks are queued with CheckAsync and AsyncVar and run
+// concurrently by Wait, which adds their errors to the same errors map.
+type ValidatorContext struct {
+	*Validator
+	ctx     context.Context
+	timeout time.Duration
+	checks  []asyncCheck
+}
+
+// NewWithContext creates a new ValidatorContext whose asynchronous checks run with the given context.
+// If the timeout is positive, checks which haven't completed after it are cancelled.
+func NewWithContext(ctx context.Context, timeout time.Duration) *ValidatorContext {
+	return &ValidatorContext{Validator: New(), ctx: ctx, timeout: timeout}
+}
+
+// CheckAsync queues an asynchronous check which adds the given error message and code under
+// the given key if it fails
+func (v *ValidatorContext) CheckAsync(key, code, message string, check AsyncCheck) {
+	v.checks = append(v.checks, asyncCheck{key: key, code: code, message: message, check: check})
+}
+
+// AsyncVar queues the registered asynchronous rule with the given name against the given value,
+// adding an error under the given key if it fails. It panics if no rule has been registered
+// with the given name.
+func (v *ValidatorContext) AsyncVar(key string, value any, name string) {
+	asyncRules.RLock()
+	fn, ok := asyncRules.checks[name]
+	asyncRules.RUnlock()
+
+	if !ok {
+		panic(fmt.Errorf("validator: %w: %q", ErrUnknownRule, name))
+	}
+
+	v.checks = append(v.checks, asyncCheck{key: key, message: name, check: func(ctx context.Context) (bool, error) {
+		return fn(ctx, value)
+	}})
+}
+
+// Wait runs the queued asynchronous checks concurrently and adds the errors of the failed ones to the map.
+// Checks of keys which already have an error are skipped, so that external systems are only queried
+// for values which are otherwise valid. It returns an error if a check couldn't be completed.
+func (v *ValidatorContext) Wait() error {
+	ctx := v.ctx
+	if v.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, v.timeout)
+		defer cancel()
+	}
+
+	checks := make([]asyncCheck, 0, len(v.checks))
+	for _, check := range v.checks {
+		if _, exists := v.Errors[check.key]; !exists {
+			checks = append(checks, check)
+		}
+	}
+
+	v.checks = nil
+
+	results := make([]bool, len(checks))
+	errs := make([]error, len(checks))
+
+	var wg sync.WaitGroup
+
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check asyncCheck) {
+			defer wg.Done()
+
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					errs[i] = fmt.Errorf("%s", recovered)
+				}
+			}()
+
+			results[i], errs[i] = check.check(ctx)
+		}(i, check)
+	}
+
+	wg.Wait()
+
+	for i, check := range checks {
+		if errs[i] != nil {
+			return fmt.Errorf("validator: async check of %s failed: %w", check.key, errs[i])
+		}
+
+		if results[i] {
+			continue
+		}
+
+		if check.code == "" {
+			v.AddLocalizedError(check.key, check.message, nil)
+		} else {
+			v.AddErrorWithCode(check.key, check.code, check.message)
+		}
+	}
+
+	return nil
+}
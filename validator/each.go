@@ -0,0 +1,30 @@
+package validator
+
+import "fmt"
+
+// Each validates every element of a slice with its own Validator and merges the errors under
+// the key of the element, i.e. "items[2].price". Errors of the element itself can be added
+// under an empty key, i.e. "items[2]".
+//
+//	validator.Each(v, "items", req.Items, func(i int, item Item, iv *validator.Validator) {
+//		iv.Check(item.Quantity > 0, "quantity", "must be greater than 0")
+//	})
+func Each[T any](v *Validator, key string, values []T, fn func(i int, value T, ev *Validator)) {
+	for i, value := range values {
+		ev := New()
+		fn(i, value, ev)
+
+		v.Merge(IndexKey(key, i), ev)
+	}
+}
+
+// EachEntry validates every entry of a map with its own Validator and merges the errors under
+// the key of the entry, i.e. "prices[gold]"
+func EachEntry[K comparable, T any](v *Validator, key string, values map[K]T, fn func(k K, value T, ev *Validator)) {
+	for k, value := range values {
+		ev := New()
+		fn(k, value, ev)
+
+		v.Merge(key+"["+fmt.Sprint(k)+"]", ev)
+	}
+}
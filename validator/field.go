@@ -0,0 +1,201 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FieldValidator is a struct used to chain validation rules on a single field, recording their
+// errors under the field key. Only the first failing rule of a field is reported.
+//
+//	v.Field("name", req.Name).Required().MaxLen(50)
+//	v.Field("price", req.Price).Between(0, 10000).MaxDecimals(2)
+type FieldValidator struct {
+	validator *Validator
+	key       string
+	value     any
+	skip      bool
+}
+
+// Field returns a FieldValidator for the given value, whose errors are recorded under the given key
+func (v *Validator) Field(key string, value any) *FieldValidator {
+	return &FieldValidator{validator: v, key: key, value: value}
+}
+
+// apply runs the rules of the given tag against the field value, unless the field already has an error
+func (f *FieldValidator) apply(tag string) *FieldValidator {
+	if f.skip {
+		return f
+	}
+
+	if _, exists := f.validator.Errors[f.key]; exists {
+		return f
+	}
+
+	f.validator.Var(f.key, f.value, tag)
+
+	return f
+}
+
+// Optional skips the following rules if the value is blank
+func (f *FieldValidator) Optional() *FieldValidator {
+	if isBlank(reflect.ValueOf(f.value)) {
+		f.skip = true
+	}
+
+	return f
+}
+
+// Required checks that the value is provided, i.e. not blank for strings and non-nil for pointers
+func (f *FieldValidator) Required() *FieldValidator {
+	return f.apply("required")
+}
+
+// RequiredIf checks that the value is provided if the condition is met, and skips the following
+// rules if the condition isn't met and the value is blank
+func (f *FieldValidator) RequiredIf(condition bool) *FieldValidator {
+	if condition {
+		return f.Required()
+	}
+
+	return f.Optional()
+}
+
+// MinLen checks that a string has at least n characters, or that a slice or map has at least n items
+func (f *FieldValidator) MinLen(n int) *FieldValidator {
+	return f.length("min", n)
+}
+
+// MaxLen checks that a string has at most n characters, or that a slice or map has at most n items
+func (f *FieldValidator) MaxLen(n int) *FieldValidator {
+	return f.length("max", n)
+}
+
+// Len checks that a string has exactly n characters, or that a slice or map has exactly n items
+func (f *FieldValidator) Len(n int) *FieldValidator {
+	return f.length("len", n)
+}
+
+// length applies a bound rule which must check the length of the value
+func (f *FieldValidator) length(rule string, n int) *FieldValidator {
+	if f.value != nil && !hasLength(indirectType(reflect.TypeOf(f.value))) {
+		panic(fmt.Errorf("validator: %w: %s length cannot be checked on %T", ErrInvalidRule, rule, f.value))
+	}
+
+	return f.apply(rule + "=" + strconv.Itoa(n))
+}
+
+// Min checks that a number is greater or equal to min
+func (f *FieldValidator) Min(min float64) *FieldValidator {
+	return f.number("min", min)
+}
+
+// Max checks that a number is lower or equal to max
+func (f *FieldValidator) Max(max float64) *FieldValidator {
+	return f.number("max", max)
+}
+
+// Between checks that a number is greater or equal to min and lower or equal to max
+func (f *FieldValidator) Between(min, max float64) *FieldValidator {
+	return f.Min(min).Max(max)
+}
+
+// number applies a bound rule which must check the value of a number
+func (f *FieldValidator) number(rule string, bound float64) *FieldValidator {
+	if f.value != nil && !isNumber(indirectType(reflect.TypeOf(f.value))) {
+		panic(fmt.Errorf("validator: %w: %s cannot be checked on %T", ErrInvalidRule, rule, f.value))
+	}
+
+	return f.apply(rule + "=" + strconv.FormatFloat(bound, 'f', -1, 64))
+}
+
+// Positive checks that a number is greater than 0
+func (f *FieldValidator) Positive() *FieldValidator {
+	return f.apply("positive")
+}
+
+// NonNegative checks that a number is greater or equal to 0
+func (f *FieldValidator) NonNegative() *FieldValidator {
+	return f.apply("nonnegative")
+}
+
+// MultipleOf checks that a number is a multiple of the given step
+func (f *FieldValidator) MultipleOf(step float64) *FieldValidator {
+	return f.apply("multipleof=" + strconv.FormatFloat(step, 'f', -1, 64))
+}
+
+// MaxDecimals checks that a number has at most the given number of decimal places
+func (f *FieldValidator) MaxDecimals(places int) *FieldValidator {
+	return f.apply("decimals=" + strconv.Itoa(places))
+}
+
+// OneOf checks that the value is one of the given values
+func (f *FieldValidator) OneOf(values ...any) *FieldValidator {
+	safelist := make([]string, len(values))
+	for i, value := range values {
+		safelist[i] = fmt.Sprint(value)
+	}
+
+	return f.apply("oneof=" + strings.Join(safelist, " "))
+}
+
+// Email checks that a string is an email address
+func (f *FieldValidator) Email() *FieldValidator {
+	return f.apply("email")
+}
+
+// URL checks that a string is an absolute URL
+func (f *FieldValidator) URL() *FieldValidator {
+	return f.apply("url")
+}
+
+// UUID checks that a string is a UUID in its canonical form
+func (f *FieldValidator) UUID() *FieldValidator {
+	return f.apply("uuid")
+}
+
+// ObjectID checks that a string is the hex representation of a MongoDB ObjectID
+func (f *FieldValidator) ObjectID() *FieldValidator {
+	return f.apply("objectid")
+}
+
+// Rule applies the built-in or registered rule with the given name and optional parameter,
+// i.e. Rule("gilAmount") or Rule("itemCode", "3")
+func (f *FieldValidator) Rule(name string, param ...string) *FieldValidator {
+	if len(param) > 0 {
+		return f.apply(name + "=" + param[0])
+	}
+
+	return f.apply(name)
+}
+
+// Matches checks that a string matches the given regular expression, adding the given message otherwise
+func (f *FieldValidator) Matches(regex *regexp.Regexp, message string) *FieldValidator {
+	value := reflect.Indirect(reflect.ValueOf(f.value))
+	if !value.IsValid() {
+		return f
+	}
+
+	if value.Kind() != reflect.String {
+		panic(fmt.Errorf("validator: %w: Matches cannot be checked on %T", ErrInvalidRule, f.value))
+	}
+
+	return f.Check(Matches(value.String(), regex), message)
+}
+
+// Check adds the given message if a custom validation check is not 'ok'
+func (f *FieldValidator) Check(ok bool, message string) *FieldValidator {
+	return f.CheckWithCode(ok, DefaultCode, message)
+}
+
+// CheckWithCode adds the given message and code if a custom validation check is not 'ok'
+func (f *FieldValidator) CheckWithCode(ok bool, code, message string) *FieldValidator {
+	if !f.skip {
+		f.validator.CheckWithCode(ok, f.key, code, message)
+	}
+
+	return f
+}
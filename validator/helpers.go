@@ -1,17 +1,43 @@
 package validator
 
 import (
+	"encoding/json"
+	"math"
 	"net/url"
+	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"golang.org/x/exp/constraints"
 )
 
 // EmailRegex is a regular expression used for sanity checking the format of email addresses
 var EmailRegex = regexp.MustCompile("^[a-zA-Z0-9.!#$%&'*+\\/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$")
 
+// UUIDRegex is a regular expression matching UUIDs in their canonical form
+var UUIDRegex = regexp.MustCompile("^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$")
+
+// E164Regex is a regular expression matching phone numbers in the E.164 format (i.e. +14155552671)
+var E164Regex = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+// AlphanumericRegex is a regular expression matching strings made of ASCII letters and digits only
+var AlphanumericRegex = regexp.MustCompile("^[a-zA-Z0-9]+$")
+
+// SlugRegex is a regular expression matching lowercase slugs (i.e. "iron-sword")
+var SlugRegex = regexp.MustCompile("^[a-z0-9]+(?:-[a-z0-9]+)*$")
+
+// iso8601Layouts are the layouts of the ISO 8601 dates and date times accepted by IsISO8601
+var iso8601Layouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"}
+
+// Number is a constraint that permits any integer or floating-point type
+type Number interface {
+	constraints.Integer | constraints.Float
+}
+
 // NotBlank returns true if string is not an empty string
 func NotBlank(value string) bool {
 	return strings.TrimSpace(value) != ""
@@ -99,3 +125,107 @@ func IsURL(value string) bool {
 
 	return u.Scheme != "" && u.Host != ""
 }
+
+// IsUUID returns true if input is a UUID in its canonical form
+func IsUUID(value string) bool {
+	return UUIDRegex.MatchString(value)
+}
+
+// IsObjectIDHex returns true if input is the hex representation of a MongoDB ObjectID
+func IsObjectIDHex(value string) bool {
+	return primitive.IsValidObjectID(value)
+}
+
+// IsE164Phone returns true if input is a phone number in the E.164 format
+func IsE164Phone(value string) bool {
+	return E164Regex.MatchString(value)
+}
+
+// IsISO8601 returns true if input is an ISO 8601 date (2006-01-02) or date time
+// (2006-01-02T15:04:05Z07:00, with an optional time zone and fraction of seconds)
+func IsISO8601(value string) bool {
+	_, ok := parseISO8601(value)
+	return ok
+}
+
+// IsDateBefore returns true if input is an ISO 8601 date or date time before the given time
+func IsDateBefore(value string, limit time.Time) bool {
+	date, ok := parseISO8601(value)
+	return ok && date.Before(limit)
+}
+
+// IsDateAfter returns true if input is an ISO 8601 date or date time after the given time
+func IsDateAfter(value string, limit time.Time) bool {
+	date, ok := parseISO8601(value)
+	return ok && date.After(limit)
+}
+
+// IsAlphanumeric returns true if input only contains ASCII letters and digits
+func IsAlphanumeric(value string) bool {
+	return AlphanumericRegex.MatchString(value)
+}
+
+// IsSlug returns true if input is made of lowercase letters and digits separated by single dashes
+func IsSlug(value string) bool {
+	return SlugRegex.MatchString(value)
+}
+
+// IsJSON returns true if input is a valid JSON document
+func IsJSON(value string) bool {
+	return json.Valid([]byte(value))
+}
+
+// parseISO8601 parses an ISO 8601 date or date time. Values without time zone are in UTC.
+func parseISO8601(value string) (time.Time, bool) {
+	for _, layout := range iso8601Layouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// Positive returns true if a number is greater than 0
+func Positive[T Number](value T) bool {
+	return value > 0
+}
+
+// NonNegative returns true if a number is greater or equal to 0
+func NonNegative[T Number](value T) bool {
+	return value >= 0
+}
+
+// MultipleOf returns true if a number is a multiple of the given step (i.e. 0.5).
+// Floating-point numbers are compared with a small tolerance to absorb rounding errors.
+func MultipleOf[T Number](value, step T) bool {
+	if step == 0 {
+		return false
+	}
+
+	quotient := float64(value) / float64(step)
+
+	return math.Abs(quotient-math.Round(quotient)) < 1e-9
+}
+
+// MaxDecimalPlaces returns true if a number has at most the given number of decimal places
+// (i.e. 12.34 has 2 decimal places), as written in its shortest decimal representation
+func MaxDecimalPlaces[T Number](value T, places int) bool {
+	return decimalPlaces(float64(value), reflect.TypeOf(value).Bits()) <= places
+}
+
+// decimalPlaces returns the number of decimal places of a number of the given bit size
+func decimalPlaces(value float64, bitSize int) int {
+	if bitSize != 32 {
+		bitSize = 64
+	}
+
+	formatted := strconv.FormatFloat(value, 'f', -1, bitSize)
+
+	_, decimals, found := strings.Cut(formatted, ".")
+	if !found {
+		return 0
+	}
+
+	return len(decimals)
+}
@@ -0,0 +1,166 @@
+package validator
+
+import (
+	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
+)
+
+// DefaultLocale is the locale of the messages recorded in the errors map
+const DefaultLocale = "en"
+
+// Catalog is a map of message templates keyed by message id, i.e. "too_short": "must be at least {min} characters long".
+// Templates refer to the parameters of the failed rule between braces. Messages added without an id
+// (i.e. with Check) are looked up by their English text instead.
+type Catalog map[string]string
+
+// Params is a map holding the parameters of a message, i.e. {"min": "3"}
+type Params map[string]string
+
+// localizedMessage is a struct which holds the id and parameters of a message added to the errors map
+type localizedMessage struct {
+	id     string
+	params Params
+}
+
+// catalogs holds the registered message catalogs, keyed by locale
+var catalogs = struct {
+	sync.RWMutex
+	locales map[string]Catalog
+}{locales: map[string]Catalog{
+	DefaultLocale: {
+		"required":          "must be provided",
+		"too_short":         "must be at least {min} characters long",
+		"too_long":          "must not be more than {max} characters long",
+		"wrong_length":      "must be exactly {len} characters long",
+		"too_few_items":     "must contain at least {min} items",
+		"too_many_items":    "must not contain more than {max} items",
+		"wrong_item_count":  "must contain exactly {len} items",
+		"too_small":         "must be greater or equal to {min}",
+		"too_large":         "must be lower or equal to {max}",
+		"invalid_email":     "must be a valid email address",
+		"invalid_url":       "must be a valid URL",
+		"not_allowed":       "must be one of {values}",
+		"invalid_uuid":      "must be a valid UUID",
+		"invalid_object_id": "must be a valid object id",
+		"invalid_phone":     "must be a valid phone number in E.164 format (i.e. +14155552671)",
+		"invalid_date":      "must be a valid ISO 8601 date",
+		"not_alphanumeric":  "must only contain letters and digits",
+		"invalid_slug":      "must only contain lowercase letters, digits and dashes",
+		"invalid_json":      "must be valid JSON",
+		"not_positive":      "must be greater than 0",
+		"negative":          "must be greater or equal to 0",
+		"not_multiple":      "must be a multiple of {multipleof}",
+		"too_many_decimals": "must not have more than {decimals} decimal places",
+	},
+}}
+
+// RegisterCatalog adds the messages of the given catalog to the catalog of the given locale,
+// i.e. "fr" or "fr-CA". Registering messages for the default locale overrides the built-in
+// English messages.
+func RegisterCatalog(locale string, catalog Catalog) {
+	catalogs.Lock()
+	defer catalogs.Unlock()
+
+	existing, ok := catalogs.locales[locale]
+	if !ok {
+		existing = Catalog{}
+		catalogs.locales[locale] = existing
+	}
+
+	for id, template := range catalog {
+		existing[id] = template
+	}
+}
+
+// AddLocalizedError adds the message of the default catalog with the given id to the map, with the id
+// as error code (so long as no entry already exists for the given key). The message can later be
+// rendered in other locales with Translate.
+func (v *Validator) AddLocalizedError(key, id string, params Params) {
+	if _, exists := v.Errors[key]; exists {
+		return
+	}
+
+	v.AddErrorWithCode(key, id, lookupMessage(DefaultLocale, id, params))
+
+	if v.messages == nil {
+		v.messages = map[string]localizedMessage{}
+	}
+
+	v.messages[key] = localizedMessage{id: id, params: params}
+}
+
+// Translate returns a copy of the errors map with the messages rendered for the given locale
+// (i.e. "fr-CA"), falling back to its language ("fr") and then to the recorded message
+func (v Validator) Translate(locale string) map[string]string {
+	errors := make(map[string]string, len(v.Errors))
+
+	for key, message := range v.Errors {
+		if localized, ok := v.messages[key]; ok {
+			errors[key] = translate(locale, localized.id, localized.params, message)
+		} else {
+			errors[key] = translate(locale, message, nil, message)
+		}
+	}
+
+	return errors
+}
+
+// MatchLocale returns the registered locale which best matches the given Accept-Language
+// header value, or DefaultLocale if none matches
+func MatchLocale(acceptLanguage string) string {
+	catalogs.RLock()
+	defer catalogs.RUnlock()
+
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil {
+		return DefaultLocale
+	}
+
+	for _, tag := range tags {
+		locale := tag.String()
+		if _, ok := catalogs.locales[locale]; ok {
+			return locale
+		}
+
+		base, _ := tag.Base()
+		if _, ok := catalogs.locales[base.String()]; ok {
+			return base.String()
+		}
+	}
+
+	return DefaultLocale
+}
+
+// translate renders the message with the given id in the given locale, or returns the fallback
+// message if no catalog of the locale or of its language holds the message
+func translate(locale, id string, params Params, fallback string) string {
+	catalogs.RLock()
+	defer catalogs.RUnlock()
+
+	lang, _, _ := strings.Cut(locale, "-")
+
+	for _, candidate := range []string{locale, lang} {
+		if template, ok := catalogs.locales[candidate][id]; ok {
+			return render(template, params)
+		}
+	}
+
+	return fallback
+}
+
+// lookupMessage renders the message with the given id in the given locale, or returns the id
+// if the message doesn't exist
+func lookupMessage(locale, id string, params Params) string {
+	return translate(locale, id, params, id)
+}
+
+// render replaces the parameters of a message template by their value
+func render(template string, params Params) string {
+	for name, value := range params {
+		template = strings.ReplaceAll(template, "{"+name+"}", value)
+	}
+
+	return template
+}
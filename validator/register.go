@@ -0,0 +1,196 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// customRules holds the rules registered by services, keyed by name
+var customRules = struct {
+	sync.RWMutex
+	compilers map[string]ruleCompiler
+}{compilers: map[string]ruleCompiler{}}
+
+// Register registers a custom rule which can then be used in validation tags, with Var and FieldValidator.Rule,
+// i.e. validator.Register("gilAmount", "must be a valid gil amount", isGilAmount).
+// The rule function receives the field value and the rule parameter (i.e. "3" for "itemCode=3").
+// The message is added to the default catalog under the rule name, so that it can be translated,
+// and may contain a {param} placeholder which is replaced by the rule parameter.
+// The rule can only be used on fields whose type is assignable or has the same kind as T.
+// Rules are meant to be registered on startup and Register panics if the name is already taken.
+func Register[T any](name, message string, fn func(value T, param string) bool) {
+	target := reflect.TypeOf((*T)(nil)).Elem()
+
+	compiler := func(param string, t reflect.Type) (compiledRule, error) {
+		if !t.AssignableTo(target) && !(t.Kind() == target.Kind() && t.ConvertibleTo(target)) {
+			return compiledRule{}, fmt.Errorf("%w: %s cannot be used on %s", ErrInvalidRule, name, t)
+		}
+
+		return compiledRule{
+			check: func(value reflect.Value) bool {
+				return fn(value.Convert(target).Interface().(T), param)
+			},
+			message: name,
+			params:  Params{"param": param},
+		}, nil
+	}
+
+	customRules.Lock()
+	defer customRules.Unlock()
+
+	_, conditional := conditionalRules[name]
+	if _, exists := builtinRules[name]; exists || conditional || name == "omitempty" {
+		panic(fmt.Sprintf("validator: rule %q is a built-in rule", name))
+	}
+
+	if _, exists := customRules.compilers[name]; exists {
+		panic(fmt.Sprintf("validator: rule %q is already registered", name))
+	}
+
+	customRules.compilers[name] = compiler
+	RegisterCatalog(DefaultLocale, Catalog{name: message})
+
+	// Types compiled before the rule was registered must be compiled again
+	clearCache(&structCache)
+	clearCache(&varCache)
+}
+
+// clearCache removes every entry of the given cache
+func clearCache(cache *sync.Map) {
+	cache.Range(func(key, _ any) bool {
+		cache.Delete(key)
+		return true
+	})
+}
+
+// lookupRule returns the compiler of the built-in or custom rule with the given name
+func lookupRule(name string) (ruleCompiler, bool) {
+	if compiler, ok := builtinRules[name]; ok {
+		return compiler, true
+	}
+
+	customRules.RLock()
+	defer customRules.RUnlock()
+
+	compiler, ok := customRules.compilers[name]
+
+	return compiler, ok
+}
+
+// Compile checks the validation tags of the given structs (or pointers to structs) and of their
+// nested structs, and returns an error if a tag is malformed or refers to an unknown rule.
+// It is meant to be called on startup, once custom rules are registered, so that broken tags
+// are caught before serving requests.
+func Compile(structs ...any) error {
+	visited := map[reflect.Type]bool{}
+
+	for _, s := range structs {
+		t := indirectType(reflect.TypeOf(s))
+		if t == nil || t.Kind() != reflect.Struct {
+			return fmt.Errorf("validator: Compile called with %T, expected a struct", s)
+		}
+
+		if err := compileNested(t, visited); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MustCompile is like Compile but panics if a validation tag is invalid
+func MustCompile(structs ...any) {
+	if err := Compile(structs...); err != nil {
+		panic(err)
+	}
+}
+
+// compileNested compiles the fields of the given struct type and of its nested structs
+func compileNested(t reflect.Type, visited map[reflect.Type]bool) error {
+	if visited[t] {
+		return nil
+	}
+
+	visited[t] = true
+
+	fields, err := structFields(t)
+	if err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		fieldType := indirectType(t.Field(field.index).Type)
+
+		if field.nested {
+			if err := compileNested(fieldType, visited); err != nil {
+				return err
+			}
+		}
+
+		if field.elem != nil && field.elem.nested {
+			if err := compileNested(indirectType(fieldType.Elem()), visited); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// varCacheKey is the key of the rules compiled by Var
+type varCacheKey struct {
+	t   reflect.Type
+	tag string
+}
+
+// varCache caches the rules compiled by Var per value type and tag
+var varCache sync.Map // map[varCacheKey]structFieldsResult
+
+// Var validates a single value against the rules of the given tag, i.e. "required,gilAmount",
+// and adds an error under the given key if one of them fails. It panics if the tag is malformed
+// or refers to an unknown rule.
+func (v *Validator) Var(key string, value any, tag string) {
+	if value == nil {
+		if hasRule(tag, "required") && !hasRule(tag, "omitempty") {
+			v.AddLocalizedError(key, "required", nil)
+		}
+
+		return
+	}
+
+	t := reflect.TypeOf(value)
+	cacheKey := varCacheKey{t: t, tag: tag}
+
+	cached, ok := varCache.Load(cacheKey)
+	if !ok {
+		field := structField{}
+		err := compileField(&field, t, tag, nil)
+
+		cached = structFieldsResult{fields: []structField{field}, err: err}
+		varCache.Store(cacheKey, cached)
+	}
+
+	result := cached.(structFieldsResult)
+	if result.err != nil {
+		panic(fmt.Errorf("validator: %w", result.err))
+	}
+
+	field := result.fields[0]
+	field.key = key
+
+	v.checkField(field, reflect.ValueOf(value), reflect.Value{})
+}
+
+// hasRule returns whether the given tag contains the rule with the given name
+func hasRule(tag, name string) bool {
+	for _, part := range strings.Split(tag, ",") {
+		ruleName, _, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if ruleName == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,270 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrUnknownRule is returned when a validation tag refers to a rule which doesn't exist
+var ErrUnknownRule = errors.New("unknown validation rule")
+
+// ErrInvalidRule is returned when a validation rule is used with an invalid parameter or field type
+var ErrInvalidRule = errors.New("invalid validation rule")
+
+// compiledRule is a struct which holds a validation rule ready to be run against field values
+type compiledRule struct {
+	name    string
+	check   func(value reflect.Value) bool
+	message string // Id of the message in the catalogs
+	params  Params
+}
+
+// ruleCompiler compiles a rule with the given parameter for fields of the given type
+type ruleCompiler func(param string, t reflect.Type) (compiledRule, error)
+
+// builtinRules holds the rules which can be used in validation tags
+var builtinRules = map[string]ruleCompiler{
+	"required": compileRequired,
+	"min":      compileMin,
+	"max":      compileMax,
+	"len":      compileLen,
+	"oneof":    compileOneOf,
+	"email":    stringRule("email", "invalid_email", IsEmail),
+	"url":      stringRule("url", "invalid_url", IsURL),
+	"uuid":     stringRule("uuid", "invalid_uuid", IsUUID),
+	"objectid": stringRule("objectid", "invalid_object_id", IsObjectIDHex),
+	"e164":     stringRule("e164", "invalid_phone", IsE164Phone),
+	"iso8601":  stringRule("iso8601", "invalid_date", IsISO8601),
+	"alphanum": stringRule("alphanum", "not_alphanumeric", IsAlphanumeric),
+	"slug":     stringRule("slug", "invalid_slug", IsSlug),
+	"json":     stringRule("json", "invalid_json", IsJSON),
+
+	"positive":    compilePositive,
+	"nonnegative": compileNonNegative,
+	"multipleof":  compileMultipleOf,
+	"decimals":    compileDecimals,
+}
+
+// compileRule compiles the rule with the given name and parameter for fields of the given type
+func compileRule(name, param string, t reflect.Type) (compiledRule, error) {
+	compiler, ok := lookupRule(name)
+	if !ok {
+		return compiledRule{}, fmt.Errorf("%w: %q", ErrUnknownRule, name)
+	}
+
+	rule, err := compiler(param, t)
+	if err != nil {
+		return compiledRule{}, err
+	}
+
+	rule.name = name
+
+	return rule, nil
+}
+
+// compileRequired compiles the "required" rule, which fails for zero values and blank strings
+func compileRequired(_ string, t reflect.Type) (compiledRule, error) {
+	check := func(value reflect.Value) bool {
+		return !value.IsZero()
+	}
+
+	if t.Kind() == reflect.String {
+		check = func(value reflect.Value) bool {
+			return NotBlank(value.String())
+		}
+	}
+
+	return compiledRule{check: check, message: "required"}, nil
+}
+
+// compileMin compiles the "min" rule, which checks the length of strings, slices and maps
+// or the value of numbers
+func compileMin(param string, t reflect.Type) (compiledRule, error) {
+	return compileBound(param, t, "min", func(n, bound float64) bool { return n >= bound }, boundMessages{
+		length: "too_short",
+		items:  "too_few_items",
+		number: "too_small",
+	})
+}
+
+// compileMax compiles the "max" rule, which checks the length of strings, slices and maps
+// or the value of numbers
+func compileMax(param string, t reflect.Type) (compiledRule, error) {
+	return compileBound(param, t, "max", func(n, bound float64) bool { return n <= bound }, boundMessages{
+		length: "too_long",
+		items:  "too_many_items",
+		number: "too_large",
+	})
+}
+
+// compileLen compiles the "len" rule, which checks the exact length of strings, slices and maps
+func compileLen(param string, t reflect.Type) (compiledRule, error) {
+	if !hasLength(t) {
+		return compiledRule{}, fmt.Errorf("%w: len cannot be used on %s", ErrInvalidRule, t)
+	}
+
+	return compileBound(param, t, "len", func(n, bound float64) bool { return n == bound }, boundMessages{
+		length: "wrong_length",
+		items:  "wrong_item_count",
+	})
+}
+
+// boundMessages is a struct which holds the message ids of a bound rule per kind of field
+type boundMessages struct {
+	length string
+	items  string
+	number string
+}
+
+// compileBound compiles a rule comparing the length or value of a field with the given parameter
+func compileBound(param string, t reflect.Type, name string, compare func(n, bound float64) bool, messages boundMessages) (compiledRule, error) {
+	bound, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return compiledRule{}, fmt.Errorf("%w: %s requires a numeric parameter, got %q", ErrInvalidRule, name, param)
+	}
+
+	params := Params{name: param}
+
+	switch {
+	case t.Kind() == reflect.String:
+		return compiledRule{
+			check:   func(value reflect.Value) bool { return compare(float64(utf8.RuneCountInString(value.String())), bound) },
+			message: messages.length,
+			params:  params,
+		}, nil
+	case hasLength(t):
+		return compiledRule{
+			check:   func(value reflect.Value) bool { return compare(float64(value.Len()), bound) },
+			message: messages.items,
+			params:  params,
+		}, nil
+	case isNumber(t):
+		return compiledRule{
+			check:   func(value reflect.Value) bool { return compare(numberValue(value), bound) },
+			message: messages.number,
+			params:  params,
+		}, nil
+	default:
+		return compiledRule{}, fmt.Errorf("%w: %s cannot be used on %s", ErrInvalidRule, name, t)
+	}
+}
+
+// stringRule returns the compiler of a rule which checks string fields with the given function
+func stringRule(name, message string, fn func(value string) bool) ruleCompiler {
+	return func(_ string, t reflect.Type) (compiledRule, error) {
+		if t.Kind() != reflect.String {
+			return compiledRule{}, fmt.Errorf("%w: %s cannot be used on %s", ErrInvalidRule, name, t)
+		}
+
+		return compiledRule{
+			check:   func(value reflect.Value) bool { return fn(value.String()) },
+			message: message,
+		}, nil
+	}
+}
+
+// compileOneOf compiles the "oneof" rule, which checks that a value is one of the
+// space separated values of the parameter, i.e. `validate:"oneof=asc desc"`
+func compileOneOf(param string, t reflect.Type) (compiledRule, error) {
+	safelist := strings.Fields(param)
+	if len(safelist) == 0 {
+		return compiledRule{}, fmt.Errorf("%w: oneof requires at least one value", ErrInvalidRule)
+	}
+
+	if t.Kind() != reflect.String && !isNumber(t) {
+		return compiledRule{}, fmt.Errorf("%w: oneof cannot be used on %s", ErrInvalidRule, t)
+	}
+
+	return compiledRule{
+		check:   func(value reflect.Value) bool { return In(fmt.Sprint(value.Interface()), safelist...) },
+		message: "not_allowed",
+		params:  Params{"values": strings.Join(safelist, ", ")},
+	}, nil
+}
+
+// numberRule returns a rule which checks numeric fields with the given function
+func numberRule(name, message string, params Params, t reflect.Type, fn func(value float64) bool) (compiledRule, error) {
+	if !isNumber(t) {
+		return compiledRule{}, fmt.Errorf("%w: %s cannot be used on %s", ErrInvalidRule, name, t)
+	}
+
+	return compiledRule{
+		check:   func(value reflect.Value) bool { return fn(numberValue(value)) },
+		message: message,
+		params:  params,
+	}, nil
+}
+
+// compilePositive compiles the "positive" rule, which checks that a number is greater than 0
+func compilePositive(_ string, t reflect.Type) (compiledRule, error) {
+	return numberRule("positive", "not_positive", nil, t, Positive[float64])
+}
+
+// compileNonNegative compiles the "nonnegative" rule, which checks that a number is greater or equal to 0
+func compileNonNegative(_ string, t reflect.Type) (compiledRule, error) {
+	return numberRule("nonnegative", "negative", nil, t, NonNegative[float64])
+}
+
+// compileMultipleOf compiles the "multipleof" rule, which checks that a number is a multiple
+// of the parameter, i.e. `validate:"multipleof=0.5"`
+func compileMultipleOf(param string, t reflect.Type) (compiledRule, error) {
+	step, err := strconv.ParseFloat(param, 64)
+	if err != nil || step == 0 {
+		return compiledRule{}, fmt.Errorf("%w: multipleof requires a non-zero numeric parameter, got %q", ErrInvalidRule, param)
+	}
+
+	return numberRule("multipleof", "not_multiple", Params{"multipleof": param}, t, func(value float64) bool {
+		return MultipleOf(value, step)
+	})
+}
+
+// compileDecimals compiles the "decimals" rule, which checks that a number has at most
+// the number of decimal places of the parameter, i.e. `validate:"decimals=2"`
+func compileDecimals(param string, t reflect.Type) (compiledRule, error) {
+	places, err := strconv.Atoi(param)
+	if err != nil || places < 0 {
+		return compiledRule{}, fmt.Errorf("%w: decimals requires a positive integer parameter, got %q", ErrInvalidRule, param)
+	}
+
+	return numberRule("decimals", "too_many_decimals", Params{"decimals": param}, t, func(value float64) bool {
+		return decimalPlaces(value, t.Bits()) <= places
+	})
+}
+
+// hasLength returns whether values of the given type have a length
+func hasLength(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	default:
+		return false
+	}
+}
+
+// isNumber returns whether the given type is a numeric type
+func isNumber(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// numberValue returns the value of a numeric field as a float64
+func numberValue(value reflect.Value) float64 {
+	switch {
+	case value.CanInt():
+		return float64(value.Int())
+	case value.CanUint():
+		return float64(value.Uint())
+	default:
+		return value.Float()
+	}
+}
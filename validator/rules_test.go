@@ -0,0 +1,206 @@
+package validator
+
+import (
+	"errors"
+	"testing"
+)
+
+// recoverError runs the given function and returns the error it panicked with, or nil if it didn't panic
+func recoverError(t *testing.T, fn func()) (err error) {
+	t.Helper()
+
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+
+		var ok bool
+		if err, ok = recovered.(error); !ok {
+			t.Fatalf("want panic with an error; got %v", recovered)
+		}
+	}()
+
+	fn()
+
+	return nil
+}
+
+func TestBuiltinRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		tag      string
+		value    any
+		wantCode string
+	}{
+		{name: "Required string", tag: "required", value: "sword"},
+		{name: "Required blank string", tag: "required", value: "  ", wantCode: "required"},
+		{name: "Required number", tag: "required", value: 3},
+		{name: "Required zero number", tag: "required", value: 0, wantCode: "required"},
+		{name: "Required slice", tag: "required", value: []string{"a"}},
+		{name: "Required nil slice", tag: "required", value: []string(nil), wantCode: "required"},
+
+		{name: "Min string", tag: "min=3", value: "épée"},
+		{name: "Min string too short", tag: "min=3", value: "ab", wantCode: "too_short"},
+		{name: "Min slice", tag: "min=2", value: []int{1, 2}},
+		{name: "Min slice too few items", tag: "min=2", value: []int{1}, wantCode: "too_few_items"},
+		{name: "Min map too few items", tag: "min=1", value: map[string]int{}, wantCode: "too_few_items"},
+		{name: "Min number", tag: "min=10", value: 10},
+		{name: "Min number too small", tag: "min=10", value: 9.5, wantCode: "too_small"},
+		{name: "Min unsigned number too small", tag: "min=10", value: uint8(9), wantCode: "too_small"},
+
+		{name: "Max string", tag: "max=3", value: "abc"},
+		{name: "Max string too long", tag: "max=3", value: "abcd", wantCode: "too_long"},
+		{name: "Max slice too many items", tag: "max=1", value: []int{1, 2}, wantCode: "too_many_items"},
+		{name: "Max number", tag: "max=10", value: int64(10)},
+		{name: "Max number too large", tag: "max=10", value: int64(11), wantCode: "too_large"},
+
+		{name: "Len string", tag: "len=2", value: "ab"},
+		{name: "Len string wrong length", tag: "len=2", value: "abc", wantCode: "wrong_length"},
+		{name: "Len array", tag: "len=2", value: [2]int{}},
+		{name: "Len slice wrong item count", tag: "len=2", value: []int{1}, wantCode: "wrong_item_count"},
+
+		{name: "Oneof string", tag: "oneof=asc desc", value: "desc"},
+		{name: "Oneof string not allowed", tag: "oneof=asc desc", value: "up", wantCode: "not_allowed"},
+		{name: "Oneof number", tag: "oneof=1 2 3", value: 2},
+		{name: "Oneof number not allowed", tag: "oneof=1 2 3", value: 4, wantCode: "not_allowed"},
+
+		{name: "Email", tag: "email", value: "cloud@midgar.com"},
+		{name: "Invalid email", tag: "email", value: "cloud@", wantCode: "invalid_email"},
+		{name: "URL", tag: "url", value: "https://midgar.com/shop"},
+		{name: "Invalid URL", tag: "url", value: "midgar.com", wantCode: "invalid_url"},
+		{name: "UUID", tag: "uuid", value: "3fa85f64-5717-4562-b3fc-2c963f66afa6"},
+		{name: "Invalid UUID", tag: "uuid", value: "3fa85f64", wantCode: "invalid_uuid"},
+		{name: "Object id", tag: "objectid", value: "507f1f77bcf86cd799439011"},
+		{name: "Invalid object id", tag: "objectid", value: "507f1f77", wantCode: "invalid_object_id"},
+		{name: "E.164 phone", tag: "e164", value: "+14155552671"},
+		{name: "Invalid E.164 phone", tag: "e164", value: "4155552671", wantCode: "invalid_phone"},
+		{name: "ISO 8601 date", tag: "iso8601", value: "2022-10-02"},
+		{name: "ISO 8601 date time", tag: "iso8601", value: "2022-10-02T10:00:00+02:00"},
+		{name: "Invalid ISO 8601 date", tag: "iso8601", value: "02/10/2022", wantCode: "invalid_date"},
+		{name: "Alphanumeric", tag: "alphanum", value: "Potion2"},
+		{name: "Not alphanumeric", tag: "alphanum", value: "Potion 2", wantCode: "not_alphanumeric"},
+		{name: "Slug", tag: "slug", value: "iron-sword"},
+		{name: "Invalid slug", tag: "slug", value: "Iron--sword", wantCode: "invalid_slug"},
+		{name: "JSON", tag: "json", value: `{"name":"sword"}`},
+		{name: "Invalid JSON", tag: "json", value: `{"name":`, wantCode: "invalid_json"},
+
+		{name: "Positive", tag: "positive", value: 1},
+		{name: "Not positive", tag: "positive", value: 0, wantCode: "not_positive"},
+		{name: "Nonnegative", tag: "nonnegative", value: 0},
+		{name: "Negative", tag: "nonnegative", value: -0.5, wantCode: "negative"},
+		{name: "Multiple of", tag: "multipleof=0.5", value: 2.5},
+		{name: "Not a multiple", tag: "multipleof=0.5", value: 2.3, wantCode: "not_multiple"},
+		{name: "Decimals", tag: "decimals=2", value: 12.34},
+		{name: "Decimals of float32", tag: "decimals=2", value: float32(0.1)},
+		{name: "Decimals of integer", tag: "decimals=0", value: 12},
+		{name: "Too many decimals", tag: "decimals=2", value: 12.345, wantCode: "too_many_decimals"},
+
+		{name: "Omitempty skips zero value", tag: "omitempty,min=3", value: ""},
+		{name: "Omitempty checks provided value", tag: "omitempty,min=3", value: "ab", wantCode: "too_short"},
+		{name: "First failing rule", tag: "required,min=3,email", value: "ab", wantCode: "too_short"},
+		{name: "Required runs first", tag: "min=3,required", value: "", wantCode: "required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := New()
+			v.Var("field", tt.value, tt.tag)
+
+			if tt.wantCode == "" {
+				if v.HasErrors() {
+					t.Errorf("want no errors; got %v", v.Errors)
+				}
+				return
+			}
+
+			if got := v.Codes["field"]; got != tt.wantCode {
+				t.Errorf("want code %q; got %q", tt.wantCode, got)
+			}
+
+			// Every builtin rule has a message in the default catalog
+			if got := v.Errors["field"]; got == "" || got == tt.wantCode {
+				t.Errorf("want the message of %q; got %q", tt.wantCode, got)
+			}
+		})
+	}
+}
+
+func TestBuiltinRuleMessages(t *testing.T) {
+	tests := []struct {
+		tag   string
+		value any
+		want  string
+	}{
+		{tag: "min=3", value: "ab", want: "must be at least 3 characters long"},
+		{tag: "max=2", value: []int{1, 2, 3}, want: "must not contain more than 2 items"},
+		{tag: "len=4", value: "ab", want: "must be exactly 4 characters long"},
+		{tag: "min=10", value: 5, want: "must be greater or equal to 10"},
+		{tag: "oneof=asc desc", value: "up", want: "must be one of asc, desc"},
+		{tag: "multipleof=0.5", value: 0.3, want: "must be a multiple of 0.5"},
+		{tag: "decimals=2", value: 0.123, want: "must not have more than 2 decimal places"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			v := New()
+			v.Var("field", tt.value, tt.tag)
+
+			if got := v.Errors["field"]; got != tt.want {
+				t.Errorf("want %q; got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestInvalidRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		tag     string
+		value   any
+		wantErr error
+	}{
+		{name: "Unknown rule", tag: "required,colour", value: "red", wantErr: ErrUnknownRule},
+		{name: "Min without parameter", tag: "min", value: "ab", wantErr: ErrInvalidRule},
+		{name: "Min with non-numeric parameter", tag: "min=three", value: "ab", wantErr: ErrInvalidRule},
+		{name: "Max with non-numeric parameter", tag: "max=x", value: 3, wantErr: ErrInvalidRule},
+		{name: "Len with non-numeric parameter", tag: "len=x", value: "ab", wantErr: ErrInvalidRule},
+		{name: "Oneof without values", tag: "oneof=", value: "asc", wantErr: ErrInvalidRule},
+		{name: "Multipleof with zero parameter", tag: "multipleof=0", value: 1.5, wantErr: ErrInvalidRule},
+		{name: "Multipleof with non-numeric parameter", tag: "multipleof=half", value: 1.5, wantErr: ErrInvalidRule},
+		{name: "Decimals with negative parameter", tag: "decimals=-1", value: 1.5, wantErr: ErrInvalidRule},
+		{name: "Decimals with non-integer parameter", tag: "decimals=1.5", value: 1.5, wantErr: ErrInvalidRule},
+
+		{name: "Min on bool", tag: "min=1", value: true, wantErr: ErrInvalidRule},
+		{name: "Max on struct", tag: "max=1", value: struct{}{}, wantErr: ErrInvalidRule},
+		{name: "Len on number", tag: "len=2", value: 12, wantErr: ErrInvalidRule},
+		{name: "Oneof on bool", tag: "oneof=true", value: true, wantErr: ErrInvalidRule},
+		{name: "Email on number", tag: "email", value: 3, wantErr: ErrInvalidRule},
+		{name: "URL on slice", tag: "url", value: []string{"https://midgar.com"}, wantErr: ErrInvalidRule},
+		{name: "UUID on number", tag: "uuid", value: 3, wantErr: ErrInvalidRule},
+		{name: "Object id on bytes", tag: "objectid", value: []byte("507f1f77bcf86cd799439011"), wantErr: ErrInvalidRule},
+		{name: "E.164 phone on number", tag: "e164", value: 14155552671, wantErr: ErrInvalidRule},
+		{name: "ISO 8601 on number", tag: "iso8601", value: 2022, wantErr: ErrInvalidRule},
+		{name: "Alphanumeric on number", tag: "alphanum", value: 3, wantErr: ErrInvalidRule},
+		{name: "Slug on number", tag: "slug", value: 3, wantErr: ErrInvalidRule},
+		{name: "JSON on map", tag: "json", value: map[string]int{}, wantErr: ErrInvalidRule},
+		{name: "Positive on string", tag: "positive", value: "1", wantErr: ErrInvalidRule},
+		{name: "Nonnegative on string", tag: "nonnegative", value: "1", wantErr: ErrInvalidRule},
+		{name: "Multipleof on string", tag: "multipleof=2", value: "4", wantErr: ErrInvalidRule},
+		{name: "Decimals on string", tag: "decimals=2", value: "1.5", wantErr: ErrInvalidRule},
+		{name: "Dive on string", tag: "dive,required", value: "abc", wantErr: ErrInvalidRule},
+		{name: "Conditional rule outside of a struct", tag: "required_if=kind bank", value: "abc", wantErr: ErrInvalidRule},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := recoverError(t, func() {
+				New().Var("field", tt.value, tt.tag)
+			})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("want panic with %v; got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
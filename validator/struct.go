@@ -0,0 +1,264 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Tag is the name of the struct tag holding the validation rules of a field
+const Tag = "validate"
+
+// structField is a struct which holds the compiled validation rules of a struct field
+type structField struct {
+	index     int
+	key       string
+	pointer   bool
+	omitEmpty bool
+	nested    bool
+	rules     []compiledRule
+
+	// Condition under which the field is required, set by conditional rules (i.e. required_if)
+	requiredWhen func(parent reflect.Value) bool
+
+	// Rules of the elements of slices, arrays and maps, declared after "dive" in the tag
+	elem *structField
+}
+
+// structCache caches the compiled fields of every validated struct type
+var structCache sync.Map // map[reflect.Type]structFieldsResult
+
+// structFieldsResult is the result of compiling the fields of a struct type
+type structFieldsResult struct {
+	fields []structField
+	err    error
+}
+
+// Struct validates the fields of the given struct (or pointer to struct) against the rules
+// declared in their `validate` tag, i.e. `validate:"required,min=3,max=50"`. Errors are added
+// under the JSON name of the fields and only the first failing rule of a field is reported.
+// Nested structs are validated as well, with their errors keyed by path (i.e. "address.city").
+// Rules declared after "dive" apply to the elements of slices and maps, i.e. `validate:"max=10,dive,required"`,
+// with their errors keyed by index (i.e. "items[2]" or "items[2].price" for structs).
+// It panics if a tag is malformed or refers to an unknown rule.
+func (v *Validator) Struct(s any) {
+	value := reflect.Indirect(reflect.ValueOf(s))
+	if value.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validator: Struct called with %T, expected a struct", s))
+	}
+
+	fields, err := structFields(value.Type())
+	if err != nil {
+		panic(err)
+	}
+
+	for _, field := range fields {
+		v.checkField(field, value.Field(field.index), value)
+	}
+}
+
+// nestedStruct validates a nested struct and merges its errors under the given key
+func (v *Validator) nestedStruct(key string, value reflect.Value) {
+	child := New()
+	child.Struct(value.Interface())
+
+	v.Merge(key, child)
+}
+
+// checkField runs the rules of a struct field against its value. The parent is the struct holding
+// the field, which conditional rules depend on.
+func (v *Validator) checkField(field structField, value, parent reflect.Value) {
+	if field.requiredWhen != nil && field.requiredWhen(parent) && isBlank(value) {
+		v.AddLocalizedError(field.key, "required", nil)
+		return
+	}
+
+	rules := field.rules
+
+	if field.pointer {
+		if value.IsNil() {
+			if !field.omitEmpty && len(field.rules) > 0 && field.rules[0].name == "required" {
+				v.AddLocalizedError(field.key, field.rules[0].message, nil)
+			}
+
+			return
+		}
+
+		value = value.Elem()
+
+		// Non-nil pointers are provided, even if they point to a zero value
+		if len(rules) > 0 && rules[0].name == "required" {
+			rules = rules[1:]
+		}
+	}
+
+	if field.omitEmpty && value.IsZero() {
+		return
+	}
+
+	for _, rule := range rules {
+		if !rule.check(value) {
+			v.AddLocalizedError(field.key, rule.message, rule.params)
+			return
+		}
+	}
+
+	if field.nested {
+		v.nestedStruct(field.key, value)
+	}
+
+	if field.elem != nil {
+		v.checkElems(*field.elem, field.key, value)
+	}
+}
+
+// checkElems runs the element rules of a slice, array or map field against its elements, adding
+// errors under indexed keys (i.e. "items[2]" or "prices[gold]")
+func (v *Validator) checkElems(elem structField, key string, value reflect.Value) {
+	if value.Kind() == reflect.Map {
+		iter := value.MapRange()
+		for iter.Next() {
+			elem.key = key + "[" + fmt.Sprint(iter.Key().Interface()) + "]"
+			v.checkField(elem, iter.Value(), reflect.Value{})
+		}
+
+		return
+	}
+
+	for i := 0; i < value.Len(); i++ {
+		elem.key = IndexKey(key, i)
+		v.checkField(elem, value.Index(i), reflect.Value{})
+	}
+}
+
+// structFields returns the compiled fields of the given struct type
+func structFields(t reflect.Type) ([]structField, error) {
+	if cached, ok := structCache.Load(t); ok {
+		result := cached.(structFieldsResult)
+		return result.fields, result.err
+	}
+
+	fields, err := compileStruct(t)
+	structCache.Store(t, structFieldsResult{fields: fields, err: err})
+
+	return fields, err
+}
+
+// compileStruct parses the validation tags of the exported fields of a struct type
+func compileStruct(t reflect.Type) ([]structField, error) {
+	var fields []structField
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag := f.Tag.Get(Tag)
+		if tag == "-" || !f.IsExported() {
+			continue
+		}
+
+		field := structField{index: i, key: jsonName(f)}
+
+		// Fields of embedded structs are encoded at the same level as the fields of the parent
+		if f.Anonymous && field.key == f.Name && indirectType(f.Type).Kind() == reflect.Struct {
+			field.key = ""
+		}
+
+		if err := compileField(&field, f.Type, tag, t); err != nil {
+			return nil, fmt.Errorf("validator: field %s of %s: %w", f.Name, t, err)
+		}
+
+		if tag == "" && !field.nested {
+			continue
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// compileField compiles the rules of the given tag for a field of the given type. The parent is
+// the type of the struct holding the field, or nil when validating a single value.
+func compileField(field *structField, t reflect.Type, tag string, parent reflect.Type) error {
+	if t.Kind() == reflect.Pointer {
+		field.pointer = true
+		t = t.Elem()
+	}
+
+	// Nested structs are compiled when validated, which supports recursive types
+	field.nested = t.Kind() == reflect.Struct
+
+	if tag == "" {
+		return nil
+	}
+
+	parts := strings.Split(tag, ",")
+
+	for i, part := range parts {
+		name, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+
+		if name == "dive" {
+			return compileElem(field, t, strings.Join(parts[i+1:], ","))
+		}
+
+		if name == "omitempty" {
+			field.omitEmpty = true
+			continue
+		}
+
+		if _, ok := conditionalRules[name]; ok {
+			if err := compileCondition(field, name, param, parent); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		rule, err := compileRule(name, param, t)
+		if err != nil {
+			return err
+		}
+
+		// The required rule always runs first so that nil pointers can be reported
+		if name == "required" {
+			field.rules = append([]compiledRule{rule}, field.rules...)
+		} else {
+			field.rules = append(field.rules, rule)
+		}
+	}
+
+	return nil
+}
+
+// compileElem compiles the rules of the given tag for the elements of a slice, array or map field
+func compileElem(field *structField, t reflect.Type, tag string) error {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+	default:
+		return fmt.Errorf("%w: dive cannot be used on %s", ErrInvalidRule, t)
+	}
+
+	field.elem = &structField{}
+
+	return compileField(field.elem, t.Elem(), tag, nil)
+}
+
+// indirectType returns the element type of pointer types and the given type otherwise
+func indirectType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Pointer {
+		return t.Elem()
+	}
+
+	return t
+}
+
+// jsonName returns the name of a struct field once encoded in JSON
+func jsonName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return f.Name
+	}
+
+	return name
+}
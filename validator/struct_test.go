@@ -0,0 +1,304 @@
+package validator
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testAddress struct {
+	Street string `json:"street"`
+	City   string `json:"city" validate:"required,max=20"`
+}
+
+type testItem struct {
+	Name  string  `json:"name" validate:"required"`
+	Price float64 `json:"price" validate:"positive,decimals=2"`
+}
+
+// TestTimestamps is exported since the fields of unexported embedded structs aren't validated
+type TestTimestamps struct {
+	CreatedAt string `json:"created_at" validate:"omitempty,iso8601"`
+}
+
+type testOrder struct {
+	TestTimestamps
+
+	Name     string             `json:"name" validate:"required,min=3"`
+	Nickname string             `json:"nickname,omitempty" validate:"omitempty,alphanum"`
+	Quantity *int               `json:"quantity" validate:"required,min=1"`
+	Discount *float64           `json:"discount" validate:"omitempty,max=50"`
+	Address  testAddress        `json:"addr"`
+	Billing  *testAddress       `json:"billing"`
+	Tags     []string           `json:"tags" validate:"max=3,dive,required,slug"`
+	Items    []testItem         `json:"items" validate:"min=1,dive"`
+	Gifts    []*testItem        `json:"gifts" validate:"dive,required"`
+	Prices   map[string]float64 `json:"prices" validate:"dive,positive"`
+	Internal string             `json:"-" validate:"required"`
+	Ignored  string             `json:"ignored" validate:"-"`
+	private  string             `validate:"required"`
+}
+
+// validOrder returns an order passing every rule of testOrder
+func validOrder() testOrder {
+	quantity := 1
+
+	return testOrder{
+		Name:     "Potions",
+		Quantity: &quantity,
+		Address:  testAddress{City: "Midgar"},
+		Tags:     []string{"healing", "consumable"},
+		Items:    []testItem{{Name: "Potion", Price: 1.5}},
+		Prices:   map[string]float64{"gold": 10},
+		Internal: "internal",
+	}
+}
+
+func TestStruct(t *testing.T) {
+	zero := 0
+	zeroDiscount := 0.0
+	tooHighDiscount := 60.0
+
+	tests := []struct {
+		name       string
+		update     func(order *testOrder)
+		wantErrors map[string]string
+	}{
+		{name: "Valid order", update: func(order *testOrder) {}},
+		{
+			name:       "Missing value",
+			update:     func(order *testOrder) { order.Name = "" },
+			wantErrors: map[string]string{"name": "required"},
+		},
+		{
+			name:       "Only the first failing rule is reported",
+			update:     func(order *testOrder) { order.Name = "ab" },
+			wantErrors: map[string]string{"name": "too_short"},
+		},
+		{
+			name:       "Field without JSON name",
+			update:     func(order *testOrder) { order.Internal = "" },
+			wantErrors: map[string]string{"Internal": "required"},
+		},
+		{
+			name: "Ignored and unexported fields",
+			update: func(order *testOrder) {
+				order.Ignored = ""
+				order.private = ""
+			},
+		},
+		{
+			name:   "Omitempty field not provided",
+			update: func(order *testOrder) { order.Nickname = "" },
+		},
+		{
+			name:       "Omitempty field provided",
+			update:     func(order *testOrder) { order.Nickname = "Cloud Strife" },
+			wantErrors: map[string]string{"nickname": "not_alphanumeric"},
+		},
+		{
+			name:       "Embedded struct fields",
+			update:     func(order *testOrder) { order.CreatedAt = "yesterday" },
+			wantErrors: map[string]string{"created_at": "invalid_date"},
+		},
+		{
+			name:       "Nil required pointer",
+			update:     func(order *testOrder) { order.Quantity = nil },
+			wantErrors: map[string]string{"quantity": "required"},
+		},
+		{
+			name:       "Required pointer to zero value",
+			update:     func(order *testOrder) { order.Quantity = &zero },
+			wantErrors: map[string]string{"quantity": "too_small"},
+		},
+		{
+			name:   "Nil optional pointer",
+			update: func(order *testOrder) { order.Discount = nil },
+		},
+		{
+			name:   "Optional pointer to zero value",
+			update: func(order *testOrder) { order.Discount = &zeroDiscount },
+		},
+		{
+			name:       "Optional pointer to invalid value",
+			update:     func(order *testOrder) { order.Discount = &tooHighDiscount },
+			wantErrors: map[string]string{"discount": "too_large"},
+		},
+		{
+			name:       "Nested struct",
+			update:     func(order *testOrder) { order.Address.City = "" },
+			wantErrors: map[string]string{"addr.city": "required"},
+		},
+		{
+			name:   "Nil nested struct pointer",
+			update: func(order *testOrder) { order.Billing = nil },
+		},
+		{
+			name:       "Nested struct pointer",
+			update:     func(order *testOrder) { order.Billing = &testAddress{City: strings.Repeat("a", 21)} },
+			wantErrors: map[string]string{"billing.city": "too_long"},
+		},
+		{
+			name:       "Rule of a slice",
+			update:     func(order *testOrder) { order.Tags = []string{"a", "b", "c", "d"} },
+			wantErrors: map[string]string{"tags": "too_many_items"},
+		},
+		{
+			name:       "Dive on slice",
+			update:     func(order *testOrder) { order.Tags = []string{"", "Healing", "potion"} },
+			wantErrors: map[string]string{"tags[0]": "required", "tags[1]": "invalid_slug"},
+		},
+		{
+			name:       "Dive on slice of structs",
+			update:     func(order *testOrder) { order.Items = append(order.Items, testItem{Price: 1.555}) },
+			wantErrors: map[string]string{"items[1].name": "required", "items[1].price": "too_many_decimals"},
+		},
+		{
+			name:       "Dive on slice of struct pointers",
+			update:     func(order *testOrder) { order.Gifts = []*testItem{nil, {Name: "Ether"}} },
+			wantErrors: map[string]string{"gifts[0]": "required", "gifts[1].price": "not_positive"},
+		},
+		{
+			name:       "Dive on map",
+			update:     func(order *testOrder) { order.Prices["silver"] = -1 },
+			wantErrors: map[string]string{"prices[silver]": "not_positive"},
+		},
+		{
+			name:       "Rule before dive fails",
+			update:     func(order *testOrder) { order.Items = nil },
+			wantErrors: map[string]string{"items": "too_few_items"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := validOrder()
+			tt.update(&order)
+
+			v := New()
+			v.Struct(&order)
+
+			if len(tt.wantErrors) == 0 {
+				if v.HasErrors() {
+					t.Errorf("want no errors; got %v", v.Errors)
+				}
+				return
+			}
+
+			if !reflect.DeepEqual(v.Codes, tt.wantErrors) {
+				t.Errorf("want codes %v; got %v", tt.wantErrors, v.Codes)
+			}
+
+			for key := range tt.wantErrors {
+				if v.Errors[key] == "" {
+					t.Errorf("want an error message for %q; got %v", key, v.Errors)
+				}
+			}
+		})
+	}
+}
+
+func TestStructCodes(t *testing.T) {
+	order := validOrder()
+	order.Name = "ab"
+	order.Address.City = ""
+	order.Tags = []string{"Healing"}
+
+	v := New()
+	v.AddError("coupon", "is expired")
+	v.Struct(order)
+
+	wantErrors := map[string]string{
+		"coupon":    "is expired",
+		"name":      "must be at least 3 characters long",
+		"addr.city": "must be provided",
+		"tags[0]":   "must only contain lowercase letters, digits and dashes",
+	}
+	wantCodes := map[string]string{
+		"coupon":    DefaultCode,
+		"name":      "too_short",
+		"addr.city": "required",
+		"tags[0]":   "invalid_slug",
+	}
+
+	if !reflect.DeepEqual(v.Errors, wantErrors) {
+		t.Errorf("want errors %v; got %v", wantErrors, v.Errors)
+	}
+
+	if !reflect.DeepEqual(v.Codes, wantCodes) {
+		t.Errorf("want codes %v; got %v", wantCodes, v.Codes)
+	}
+}
+
+func TestStructPanics(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   any
+		wantErr error
+	}{
+		{
+			name: "Unknown rule",
+			value: struct {
+				Name string `validate:"required,colour"`
+			}{},
+			wantErr: ErrUnknownRule,
+		},
+		{
+			name: "Bad parameter",
+			value: struct {
+				Name string `validate:"min=three"`
+			}{},
+			wantErr: ErrInvalidRule,
+		},
+		{
+			name: "Rule on wrong field type",
+			value: struct {
+				Count int `validate:"email"`
+			}{},
+			wantErr: ErrInvalidRule,
+		},
+		{
+			name: "Bad rule after dive",
+			value: struct {
+				Tags []string `validate:"dive,positive"`
+			}{},
+			wantErr: ErrInvalidRule,
+		},
+		{
+			name: "Bad rule of a nested struct",
+			value: struct {
+				Address struct {
+					City string `validate:"max"`
+				}
+			}{},
+			wantErr: ErrInvalidRule,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := recoverError(t, func() {
+				New().Struct(tt.value)
+			})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("want panic with %v; got %v", tt.wantErr, err)
+			}
+
+			if err := Compile(tt.value); !errors.Is(err, tt.wantErr) {
+				t.Errorf("want Compile error %v; got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestStructNotAStruct(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("want panic; got none")
+		}
+	}()
+
+	New().Struct("order")
+}
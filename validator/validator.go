@@ -1,13 +1,26 @@
 package validator
 
-// Validator is a struct which contains a map of validation errors
+import (
+	"strconv"
+	"strings"
+)
+
+// DefaultCode is the error code of the messages added without code (i.e. with Check)
+const DefaultCode = "invalid"
+
+// Validator is a struct which contains a map of validation errors and a map of their
+// machine-readable codes (i.e. "too_short"), both keyed by field
 type Validator struct {
 	Errors map[string]string `json:",omitempty"`
+	Codes  map[string]string `json:",omitempty"`
+
+	// Ids and parameters of the messages added with AddLocalizedError, keyed like Errors
+	messages map[string]localizedMessage
 }
 
 // New creates a new Validator instance with an empty errors map
 func New() *Validator {
-	return &Validator{Errors: make(map[string]string)}
+	return &Validator{Errors: make(map[string]string), Codes: make(map[string]string)}
 }
 
 // HasErrors returns true if the errors map contains any entries
@@ -17,9 +30,22 @@ func (v Validator) HasErrors() bool {
 
 // AddError adds an error message to the map (so long as no entry already exists for the given key)
 func (v *Validator) AddError(key, message string) {
-	if _, exists := v.Errors[key]; !exists {
-		v.Errors[key] = message
+	v.AddErrorWithCode(key, DefaultCode, message)
+}
+
+// AddErrorWithCode adds an error message and its code to the maps (so long as no entry already exists for the given key)
+func (v *Validator) AddErrorWithCode(key, code, message string) {
+	if _, exists := v.Errors[key]; exists {
+		return
 	}
+
+	v.Errors[key] = message
+
+	if v.Codes == nil {
+		v.Codes = make(map[string]string)
+	}
+
+	v.Codes[key] = code
 }
 
 // Check adds an error message to the map only if a validation check is not 'ok'
@@ -28,3 +54,55 @@ func (v *Validator) Check(ok bool, key, message string) {
 		v.AddError(key, message)
 	}
 }
+
+// CheckWithCode adds an error message and its code to the maps only if a validation check is not 'ok'
+func (v *Validator) CheckWithCode(ok bool, key, code, message string) {
+	if !ok {
+		v.AddErrorWithCode(key, code, message)
+	}
+}
+
+// AddNestedError adds an error message under the given key, nested under the given prefix
+// (i.e. "address" and "city" give "address.city")
+func (v *Validator) AddNestedError(prefix, key, message string) {
+	v.AddError(JoinKey(prefix, key), message)
+}
+
+// Merge adds the errors of a child validator to the map, nesting their keys under the given prefix.
+// It is used to validate nested objects and list items with their own Validator
+// (i.e. v.Merge(validator.IndexKey("items", i), itemValidator)).
+func (v *Validator) Merge(prefix string, child *Validator) {
+	for key, message := range child.Errors {
+		if localized, ok := child.messages[key]; ok {
+			v.AddLocalizedError(JoinKey(prefix, key), localized.id, localized.params)
+			continue
+		}
+
+		code, ok := child.Codes[key]
+		if !ok {
+			code = DefaultCode
+		}
+
+		v.AddErrorWithCode(JoinKey(prefix, key), code, message)
+	}
+}
+
+// JoinKey joins the given error key to a prefix, i.e. "address" and "city" give "address.city",
+// "items" and "[2]" give "items[2]". The key is returned as is if the prefix is empty.
+func JoinKey(prefix, key string) string {
+	switch {
+	case prefix == "":
+		return key
+	case key == "":
+		return prefix
+	case strings.HasPrefix(key, "["):
+		return prefix + key
+	default:
+		return prefix + "." + key
+	}
+}
+
+// IndexKey returns the error key of the element at the given index of a list, i.e. "items[2]"
+func IndexKey(key string, index int) string {
+	return key + "[" + strconv.Itoa(index) + "]"
+}